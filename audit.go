@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/audit"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func newAuditCmd() *cobra.Command {
+	var (
+		output string
+		apply  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "audit owner/repo|org...",
+		Short: "Report label definitions that drift from the canonical catalog",
+		Long: `Compare the names, colors and descriptions of the labels the labeler applies
+against the canonical catalog, for each repository or every unarchived
+repository of each organization, and report the drift. With --apply, missing
+labels are created and drifted ones edited. Labels outside the catalog are
+left alone. Reads the API token from GITHUB_TOKEN.`,
+		Example: `  # Report drift across an organization
+  pr-kind-labeler audit kgateway-dev
+
+  # Fix one repository and keep a machine-readable record
+  pr-kind-labeler audit kgateway-dev/kgateway --apply --output json > drift.json`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "json" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --output %q, expected table or json", output)}
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			ctx := cmd.Context()
+			client := newGitHubClient(token, nil)
+			catalog := labels.Catalog()
+
+			drifts := []audit.Drift{}
+			for _, arg := range args {
+				owner, repo, _ := strings.Cut(arg, "/")
+				repos := []string{repo}
+				if repo == "" {
+					var err error
+					if repos, err = audit.OrgRepositories(ctx, client, owner); err != nil {
+						return err
+					}
+				}
+				for _, repo := range repos {
+					found, err := audit.Check(ctx, client, owner, repo, catalog)
+					if err != nil {
+						return fmt.Errorf("%s/%s: %w", owner, repo, err)
+					}
+					if apply && len(found) > 0 {
+						if err := audit.Fix(ctx, client, owner, repo, catalog, found); err != nil {
+							return fmt.Errorf("%s/%s: %w", owner, repo, err)
+						}
+					}
+					drifts = append(drifts, found...)
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			if output == "json" {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(drifts)
+			}
+			if len(drifts) == 0 {
+				fmt.Fprintln(out, "no drift")
+				return nil
+			}
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "REPOSITORY\tLABEL\tPROBLEM\tWANT\tGOT")
+			for _, d := range drifts {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Repository, d.Label, d.Problem, d.Want, d.Got)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if apply {
+				fmt.Fprintln(out, "fixed the drift above")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table or json")
+	cmd.Flags().BoolVar(&apply, "apply", false, "create missing labels and fix drifted ones")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/server"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/transport"
+)
+
+func newBackfillCmd() *cobra.Command {
+	var (
+		configPath string
+		workers    int
+		apply      bool
+		output     string
+		writeRPS   float64
+		writeBurst int
+	)
+	cmd := &cobra.Command{
+		Use:   "backfill owner/repo",
+		Short: "Run the labeler on every open PR of a repository",
+		Long: `List every open PR of a repository and run the labeler on each, as if its
+description had just been edited, then summarize which PRs were relabeled.
+Use it after changing kinds or label names, e.g. in the repository's
+` + server.RepoConfigPath + `, so existing PRs are migrated too.
+
+PRs are processed by --workers workers with the label policy of the server
+config and the repository's own config on top, at most one run per second
+like the server's default tenant limit. Writes are smoothed to
+--write-rps to avoid GitHub's secondary rate limits, and a PR that hits a rate
+limit is retried once it resets. Nothing is changed on GitHub unless --apply
+is set. Reads the API token from GITHUB_TOKEN.`,
+		Example: `  # Preview which PRs would be relabeled
+  pr-kind-labeler backfill kgateway-dev/kgateway
+
+  # Relabel with the server's label policy and keep the outcomes
+  pr-kind-labeler backfill kgateway-dev/kgateway --config config.yaml --apply --output json > backfill.jsonl`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --output %q, expected text or json", output)}
+			}
+			if workers < 1 {
+				return &labeler.ConfigError{Err: fmt.Errorf("--workers must be at least 1")}
+			}
+			owner, repo, err := parseRepoRef(args[0])
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			cfg, err := server.LoadConfig(configPath)
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+
+			client := newGitHubClient(token, transport.NewWriteLimiter(nil, writeRPS, writeBurst))
+			results, err := server.New(cfg, client, nil).Backfill(cmd.Context(), owner, repo, workers, apply)
+			if err != nil {
+				return &labeler.OperationalError{Err: err}
+			}
+			out := cmd.OutOrStdout()
+			enc := json.NewEncoder(out)
+			var relabeled, failed int
+			for _, r := range results {
+				switch {
+				case r.Error != "":
+					failed++
+				case r.Relabeled():
+					relabeled++
+				}
+				if output == "json" {
+					if err := enc.Encode(r); err != nil {
+						return fmt.Errorf("failed to encode result: %w", err)
+					}
+					continue
+				}
+				if r.Error != "" || r.Relabeled() {
+					fmt.Fprintf(out, "%s: %s\n", r.PR, backfillSummary(r))
+				}
+			}
+			verb := "relabeled"
+			if !apply {
+				verb = "would be relabeled"
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "backfilled %d open PRs: %d %s, %d unchanged, %d failed\n", len(results), relabeled, verb, len(results)-relabeled-failed, failed)
+			if failed > 0 {
+				return &labeler.OperationalError{Err: fmt.Errorf("%d of %d PRs failed to backfill", failed, len(results))}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&configPath, "config", "", "path to the server config file whose label policy applies")
+	cmd.Flags().IntVar(&workers, "workers", 4, "PRs processed concurrently")
+	cmd.Flags().BoolVar(&apply, "apply", false, "relabel the PRs instead of only reporting which would be relabeled")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "output format: text or json (one object per PR)")
+	cmd.Flags().Float64Var(&writeRPS, "write-rps", 1, "GitHub write requests per second, to avoid secondary rate limits (0 disables)")
+	cmd.Flags().IntVar(&writeBurst, "write-burst", 5, "GitHub write requests allowed to burst above --write-rps")
+	cmd.MarkFlagFilename("config", "yaml", "yml")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// backfillSummary describes the outcome of backfilling a PR on one line.
+func backfillSummary(r *server.Backfilled) string {
+	if r.Error != "" {
+		return "error: " + r.Error
+	}
+	var changes []string
+	for _, label := range r.Added {
+		changes = append(changes, "+"+label)
+	}
+	for _, label := range r.Removed {
+		changes = append(changes, "-"+label)
+	}
+	return strings.Join(changes, " ")
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// catalog is the machine-readable catalog of the kinds and labels the
+// labeler knows.
+type catalog struct {
+	Kinds []catalogKind `json:"kinds"`
+	// Labels are the labels of the registry, managed and retired.
+	Labels []labels.Definition `json:"labels"`
+	// LabelPrefixes prefix the managed labels whose names vary per PR or
+	// repository, e.g. module/api.
+	LabelPrefixes []string `json:"labelPrefixes"`
+}
+
+// catalogKind is a kind and the label it is applied as.
+type catalogKind struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	// Deprecated kinds are still accepted as their replacement, ReplacedBy.
+	Deprecated bool   `json:"deprecated,omitempty"`
+	ReplacedBy string `json:"replacedBy,omitempty"`
+}
+
+// newCatalog returns the catalog, with kinds and labels sorted by name and
+// deprecated kinds after the supported ones.
+func newCatalog() catalog {
+	c := catalog{
+		Labels:        labels.Registry(),
+		LabelPrefixes: []string{labels.ModuleLabelPrefix, labels.RiskLabelPrefix},
+	}
+	for _, k := range kinds.Supported() {
+		c.Kinds = append(c.Kinds, catalogKind{Name: k, Label: "kind/" + k})
+	}
+	deprecated := slices.Sorted(maps.Keys(kinds.DeprecatedKindMap))
+	for _, k := range deprecated {
+		c.Kinds = append(c.Kinds, catalogKind{Name: k, Label: "kind/" + k, Deprecated: true, ReplacedBy: kinds.DeprecatedKindMap[k]})
+	}
+	return c
+}
+
+func newCatalogCmd() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Print the kinds and labels the labeler knows",
+		Long: `Print every kind and label the labeler knows, with their colors,
+descriptions and whether the labeler manages them or only migrates them
+away, so docs sites and other bots can generate their references from the
+same source as the labeler.`,
+		Example: `  # Generate the kinds reference of a docs site
+  pr-kind-labeler catalog --format yaml > data/pr-kinds.yaml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			switch format {
+			case "json":
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(newCatalog())
+			case "yaml":
+				data, err := yaml.Marshal(newCatalog())
+				if err != nil {
+					return err
+				}
+				_, err = out.Write(data)
+				return err
+			default:
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --format %q, expected json or yaml", format)}
+			}
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "output format: json or yaml")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/changelog"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func newChangelogCmd() *cobra.Command {
+	var (
+		metadataDirs    []string
+		rollup          bool
+		componentPrefix string
+		reverted        string
+		discussion      discussionFlags
+		release         releaseFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "changelog --metadata-dir DIR...",
+		Short: "Generate a markdown changelog from exported PR metadata",
+		Long: `Read the PR metadata files exported with --export-metadata and
+--metadata-branch, e.g. from a checkout of the metadata branch, and print the
+release notes as a markdown changelog with a section per kind. When PRs carry
+component labels, such as the module/ labels of --module-labels, notes are
+grouped by component first, then by kind. PRs without a release note, or
+whose kinds are not published, are left out. Notes are sanitized, e.g.
+images dropped and pipes escaped, so one malformed note cannot break the
+changelog.
+
+The PRs that defer their note to a tracking issue with /release-note-epic
+are listed once, with the release-note block of the issue, which is fetched
+using the token from GITHUB_TOKEN. Epics whose issue has no note yet are
+left out.
+PRs that inherit their note with /stacked-on are left out too, as the PR
+at the bottom of the stack lists it.
+
+The metadata directory should hold the PRs of one release range. A PR that
+is reverted by another PR of the range, as found with --detect-reverts, is
+dropped together with its revert, or with --reverted=mark listed as
+reverted, so the changelog does not advertise changes that were undone.
+
+A release that spans repositories, such as kgateway, its Helm charts and its
+docs, gets a single changelog with --rollup: the metadata of every
+repository, e.g. a --metadata-dir per repository, is rolled up into a section
+per repository, in the order they are read, grouped by kind within it. PRs
+are referenced as owner/repo#number, and reverts and epics are resolved
+within each repository.
+
+With --discussion and --discussion-title, the changelog is also published as
+a GitHub Discussion, or the discussion of a previous run is updated, using
+the token from GITHUB_TOKEN.
+
+With --release, the changelog is limited to the PRs merged between the
+previous published release, or --since, and the tag, and is set as the notes
+of the tag's GitHub release. A tag without a release gets a draft release;
+the notes of an existing release are replaced between markers, keeping
+anything written around them. In a workflow triggered by the push of a tag or
+by a release event, --release-from-event takes the tag from the event, so
+notes go from PRs to the published release without manual steps.`,
+		Example: `  # Generate the changelog from the gh-pages metadata branch
+  git worktree add /tmp/meta gh-pages
+  pr-kind-labeler changelog --metadata-dir /tmp/meta/pr-metadata
+
+  # Announce a release in the repository's Announcements discussions
+  pr-kind-labeler changelog --metadata-dir pr-metadata --discussion kgateway-dev/kgateway --discussion-title "v2.1.0 release notes"
+
+  # Publish the notes of a pushed tag on its release, in an on: push: tags workflow
+  pr-kind-labeler changelog --metadata-dir pr-metadata --release-from-event
+
+  # Roll up the release notes of the repositories of a product release
+  pr-kind-labeler changelog --rollup --metadata-dir kgateway/pr-metadata --metadata-dir helm-charts/pr-metadata --metadata-dir docs/pr-metadata
+
+  # Group by area/ labels instead of Go modules
+  pr-kind-labeler changelog --metadata-dir pr-metadata --component-label-prefix area/`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if reverted != "drop" && reverted != "mark" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --reverted %q, expected drop or mark", reverted)}
+			}
+			if err := discussion.validate(true); err != nil {
+				return err
+			}
+			if len(metadataDirs) > 1 && !rollup {
+				return &labeler.ConfigError{Err: fmt.Errorf("several --metadata-dir need --rollup, as PR numbers are only unique within a repository")}
+			}
+			if rollup && release.enabled() {
+				return &labeler.ConfigError{Err: fmt.Errorf("--release cannot be combined with --rollup, as a release is of one repository")}
+			}
+			if err := release.resolve(); err != nil {
+				return err
+			}
+			var shipped map[int]bool
+			if release.enabled() {
+				var err error
+				if shipped, err = release.prs(cmd); err != nil {
+					return err
+				}
+			}
+			var (
+				repos   []string
+				entries = map[string][]changelog.Entry{}
+				epics   = map[string]map[int]string{}
+			)
+			for _, dir := range metadataDirs {
+				paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+				if err != nil {
+					return &labeler.ConfigError{Err: fmt.Errorf("invalid --metadata-dir: %w", err)}
+				}
+				for _, path := range paths {
+					data, err := os.ReadFile(path)
+					if err != nil {
+						return fmt.Errorf("failed to read PR metadata: %w", err)
+					}
+					var m labeler.Metadata
+					if err := json.Unmarshal(data, &m); err != nil {
+						return fmt.Errorf("failed to parse PR metadata %s: %w", path, err)
+					}
+					if shipped != nil && !shipped[m.Number] {
+						continue
+					}
+					repo := ""
+					if rollup {
+						repo = m.Repository
+					}
+					if _, ok := entries[repo]; !ok {
+						repos = append(repos, repo)
+						epics[repo] = map[int]string{}
+					}
+					// PRs without a note are kept to reconcile reverts; Render
+					// leaves them out
+					e := changelog.Entry{PR: m.Number, Reverts: m.Reverts}
+					if m.ReleaseNote != nil {
+						e.Note, e.Section, e.Epic = m.ReleaseNote.Note, m.ReleaseNote.Section, m.ReleaseNote.Epic
+					}
+					if e.Epic != 0 {
+						epics[repo][e.Epic] = m.Repository
+					}
+					if componentPrefix != "" {
+						for _, label := range m.Labels {
+							if name, ok := strings.CutPrefix(label, componentPrefix); ok && name != "" {
+								e.Components = append(e.Components, name)
+							}
+						}
+					}
+					entries[repo] = append(entries[repo], e)
+				}
+			}
+			var rolledUp []changelog.Repository
+			for _, repo := range repos {
+				reconciled := changelog.Reconcile(entries[repo], reverted == "mark")
+				notes, err := epicNotes(cmd.Context(), epics[repo])
+				if err != nil {
+					return err
+				}
+				rolledUp = append(rolledUp, changelog.Repository{Name: repo, Entries: changelog.ConsolidateEpics(reconciled, notes)})
+			}
+			var rendered string
+			switch {
+			case rollup:
+				rendered = changelog.RenderRollup(rolledUp)
+			case len(rolledUp) > 0:
+				rendered = changelog.Render(rolledUp[0].Entries)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), rendered)
+			if err := discussion.publish(cmd, "", rendered); err != nil {
+				return err
+			}
+			return release.publish(cmd, rendered)
+		},
+	}
+	cmd.Flags().StringArrayVar(&metadataDirs, "metadata-dir", nil, "directory of exported PR metadata files (NUMBER.json); repeat with --rollup for the repositories of a release")
+	cmd.Flags().BoolVar(&rollup, "rollup", false, "roll the notes of several repositories up into one changelog, grouped by repository and then by kind")
+	cmd.Flags().StringVar(&componentPrefix, "component-label-prefix", labels.ModuleLabelPrefix, "prefix of the labels naming the components a PR changes; empty disables grouping by component")
+	cmd.Flags().StringVar(&reverted, "reverted", "drop", "how PRs reverted within the range are handled: drop (leave out the PR and its revert) or mark (list the PR as reverted)")
+	discussion.register(cmd)
+	release.register(cmd)
+	cmd.MarkFlagRequired("metadata-dir")
+	cmd.MarkFlagDirname("metadata-dir")
+	cmd.RegisterFlagCompletionFunc("reverted", cobra.FixedCompletions([]string{"drop", "mark"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// epicNotes fetches the release notes of the tracking issues of epics, which
+// maps each issue to its owner/repo. Issues without a note are left out.
+func epicNotes(ctx context.Context, epics map[int]string) (map[int]string, error) {
+	notes := map[int]string{}
+	if len(epics) == 0 {
+		return notes, nil
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return nil, &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set, but is needed to fetch the release notes of epics")}
+	}
+	client := newGitHubClient(token, nil)
+	for _, number := range slices.Sorted(maps.Keys(epics)) {
+		owner, repo, _ := strings.Cut(epics[number], "/")
+		issue, _, err := client.Issues.Get(ctx, owner, repo, number)
+		if err != nil {
+			return nil, &labeler.OperationalError{Err: fmt.Errorf("failed to get tracking issue %s#%d: %w", epics[number], number, err)}
+		}
+		if note, ok := changelog.EpicNote(issue.GetBody()); ok {
+			notes[number] = note
+		}
+	}
+	return notes, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/audit"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/dashboard"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/server"
+)
+
+func newDashboardCmd() *cobra.Command {
+	var (
+		configPath string
+		output     string
+	)
+	cmd := &cobra.Command{
+		Use:   "dashboard owner/repo|org...",
+		Short: "Summarize the merge readiness of open PRs",
+		Long: `Evaluate the open, non-draft PRs of each repository, or of every unarchived
+repository of each organization that the server config's repositories filter
+enables, against the label policy of the server config, without changing
+anything. The PRs are summarized by status (mergeable, invalid or blocked),
+kind and merge blocker, as a static HTML page or as JSON, e.g. for weekly
+maintainer syncs. Reads the API token from GITHUB_TOKEN.`,
+		Example: `  # Render the dashboard of an organization for the weekly sync
+  pr-kind-labeler dashboard kgateway-dev --config config.yaml > dashboard.html
+
+  # Count the invalid PRs of a repository
+  pr-kind-labeler dashboard kgateway-dev/kgateway --output json | jq '.byStatus.invalid'`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "html" && output != "json" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --output %q, expected html or json", output)}
+			}
+			cfg, err := server.LoadConfig(configPath)
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			ctx := cmd.Context()
+			client := newGitHubClient(token, nil)
+			srv := server.New(cfg, client, nil)
+
+			var prs []dashboard.PR
+			for _, arg := range args {
+				owner, repo, _ := strings.Cut(arg, "/")
+				repos := []string{repo}
+				if repo == "" {
+					if repos, err = audit.OrgRepositories(ctx, client, owner); err != nil {
+						return err
+					}
+				}
+				for _, repo := range repos {
+					fullName := owner + "/" + repo
+					if !cfg.Repositories.Enabled(fullName) {
+						continue
+					}
+					open, err := dashboard.OpenPullRequests(ctx, client, owner, repo)
+					if err != nil {
+						return fmt.Errorf("%s: %w", fullName, err)
+					}
+					for _, pr := range open {
+						v, err := srv.MergeablePR(ctx, owner, repo, pr)
+						if err != nil {
+							return fmt.Errorf("%s#%d: %w", fullName, pr.GetNumber(), err)
+						}
+						prs = append(prs, dashboard.FromVerdict(fullName, pr, v))
+					}
+				}
+			}
+
+			d := dashboard.New(prs, time.Now())
+			out := cmd.OutOrStdout()
+			if output == "json" {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(d)
+			}
+			return d.WriteHTML(out)
+		},
+	}
+	cmd.Flags().StringVar(&configPath, "config", "", "path to the server config file whose label policy and repositories filter apply")
+	cmd.Flags().StringVarP(&output, "output", "o", "html", "output format: html or json")
+	cmd.MarkFlagFilename("config", "yaml", "yml")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"html", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/audit"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/digest"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func newDigestCmd() *cobra.Command {
+	var (
+		period     time.Duration
+		output     string
+		areaPrefix string
+		discussion discussionFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "digest owner/repo|org...",
+		Short: "Summarize recently merged PRs for a community update",
+		Long: `Summarize the PRs merged over the past --period, a week by default, in each
+repository, or in every unarchived repository of each organization, by kind
+and area with links to the PRs. The digest is rendered as markdown, e.g. for
+a GitHub Discussion, as Slack mrkdwn, or as JSON. Areas are read from the
+labels with --area-label-prefix, such as the module/ labels of
+--module-labels. With --discussion, the markdown digest is also published as
+a GitHub Discussion. Reads the API token from GITHUB_TOKEN.`,
+		Example: `  # Post last week's digest to a Slack incoming webhook
+  pr-kind-labeler digest kgateway-dev --output slack | jq -Rs '{text: .}' | curl -d @- "$SLACK_WEBHOOK_URL"
+
+  # Publish last week's digest in the community repository's Announcements
+  pr-kind-labeler digest kgateway-dev --discussion kgateway-dev/community
+
+  # Draft a monthly update grouped by area/ labels
+  pr-kind-labeler digest kgateway-dev/kgateway --period 720h --area-label-prefix area/`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != string(digest.Markdown) && output != string(digest.Slack) && output != "json" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --output %q, expected markdown, slack or json", output)}
+			}
+			if period <= 0 {
+				return &labeler.ConfigError{Err: fmt.Errorf("--period must be positive")}
+			}
+			if err := discussion.validate(false); err != nil {
+				return err
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			ctx := cmd.Context()
+			client := newGitHubClient(token, nil)
+			until := time.Now()
+			since := until.Add(-period)
+
+			var prs []digest.PR
+			for _, arg := range args {
+				owner, repo, _ := strings.Cut(arg, "/")
+				repos := []string{repo}
+				if repo == "" {
+					var err error
+					if repos, err = audit.OrgRepositories(ctx, client, owner); err != nil {
+						return err
+					}
+				}
+				for _, repo := range repos {
+					fullName := owner + "/" + repo
+					merged, err := digest.MergedPullRequests(ctx, client, owner, repo, since)
+					if err != nil {
+						return fmt.Errorf("%s: %w", fullName, err)
+					}
+					for _, pr := range merged {
+						prs = append(prs, digest.FromPullRequest(fullName, pr, areaPrefix))
+					}
+				}
+			}
+
+			d := digest.New(prs, since, until)
+			if discussion.repo != "" {
+				var body strings.Builder
+				if err := d.Write(&body, digest.Markdown); err != nil {
+					return err
+				}
+				if err := discussion.publish(cmd, d.Title(), body.String()); err != nil {
+					return err
+				}
+			}
+			out := cmd.OutOrStdout()
+			if output == "json" {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(d)
+			}
+			return d.Write(out, digest.Format(output))
+		},
+	}
+	cmd.Flags().DurationVar(&period, "period", 7*24*time.Hour, "how far back to summarize merged PRs")
+	cmd.Flags().StringVarP(&output, "output", "o", string(digest.Markdown), "output format: markdown, slack or json")
+	cmd.Flags().StringVar(&areaPrefix, "area-label-prefix", labels.ModuleLabelPrefix, "prefix of the labels naming the areas a PR changes; empty disables areas")
+	discussion.register(cmd)
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{string(digest.Markdown), string(digest.Slack), "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/discussion"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+// discussionFlags are the flags of the commands that can publish their
+// output as a GitHub Discussion.
+type discussionFlags struct {
+	repo     string
+	category string
+	title    string
+}
+
+// register adds the flags to cmd.
+func (f *discussionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.repo, "discussion", "", "also publish the output as a discussion in this owner/repo, updating the latest one of the category with the same title; needs a GITHUB_TOKEN that can write discussions")
+	cmd.Flags().StringVar(&f.category, "discussion-category", "Announcements", "name or slug of the discussion category to publish in")
+	cmd.Flags().StringVar(&f.title, "discussion-title", "", "title of the discussion")
+}
+
+// validate checks the flags, requiring a title if titleRequired.
+func (f *discussionFlags) validate(titleRequired bool) error {
+	if f.repo == "" {
+		return nil
+	}
+	if owner, repo, ok := strings.Cut(f.repo, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return &labeler.ConfigError{Err: fmt.Errorf("invalid --discussion %q, expected owner/repo", f.repo)}
+	}
+	if titleRequired && f.title == "" {
+		return &labeler.ConfigError{Err: fmt.Errorf("--discussion-title is required with --discussion")}
+	}
+	if os.Getenv("GITHUB_TOKEN") == "" {
+		return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+	}
+	return nil
+}
+
+// publish publishes body as a discussion, titled defaultTitle unless
+// --discussion-title is set, if --discussion is set.
+func (f *discussionFlags) publish(cmd *cobra.Command, defaultTitle, body string) error {
+	if f.repo == "" {
+		return nil
+	}
+	title := f.title
+	if title == "" {
+		title = defaultTitle
+	}
+	owner, repo, _ := strings.Cut(f.repo, "/")
+	post, err := discussion.Publish(cmd.Context(), newGitHubClient(os.Getenv("GITHUB_TOKEN"), nil), owner, repo, f.category, title, body)
+	if err != nil {
+		return err
+	}
+	verb := "Updated"
+	if post.Created {
+		verb = "Created"
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s discussion %s\n", verb, post.URL)
+	return nil
+}
@@ -6,6 +6,9 @@ require (
 	github.com/google/go-github/v68 v68.0.0
 	github.com/migueleliasweb/go-github-mock v1.3.0
 	github.com/spf13/cobra v1.9.1
+	golang.org/x/mod v0.17.0
+	golang.org/x/time v0.3.0
+	sigs.k8s.io/yaml v1.4.0
 )
 
 require (
@@ -14,5 +17,4 @@ require (
 	github.com/gorilla/mux v1.8.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
-	golang.org/x/time v0.3.0 // indirect
 )
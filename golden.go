@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/secrets"
+)
+
+func newGoldenCmd() *cobra.Command {
+	var (
+		outDir                          string
+		name                            string
+		enforceDescription              bool
+		enforceReleaseNoteQuality       bool
+		enforceChangelogKindExclusivity bool
+	)
+	cmd := &cobra.Command{
+		Use:   "golden owner/repo/PR",
+		Short: "Capture a real PR body and the computed label decision as a golden test fixture",
+		Long: `Fetch a PR (like GHPR mode), compute the labels the labeler would add and remove
+without changing anything, and write the result as a JSON fixture that
+TestGoldenFixtures replays. Reads the API token from GITHUB_TOKEN.`,
+		Example: `  # Capture kgateway-dev/kgateway#11221 into internal/labeler/testdata/
+  GITHUB_TOKEN=$(gh auth token) pr-kind-labeler golden kgateway-dev/kgateway/11221
+
+  # Capture with a descriptive fixture name, then replay all fixtures
+  pr-kind-labeler golden kgateway-dev/kgateway/11221 --name deprecated-kind-migration
+  go test ./internal/labeler -run TestGoldenFixtures`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			owner, repo, prNum, err := parsePRRef(args[0])
+			if err != nil {
+				return err
+			}
+			client := newGitHubClient(os.Getenv("GITHUB_TOKEN"), nil)
+
+			pr, _, err := client.PullRequests.Get(ctx, owner, repo, prNum)
+			if err != nil {
+				return fmt.Errorf("failed to get PR body: %w", err)
+			}
+			l := labeler.New(client, owner, repo, prNum, enforceDescription, enforceReleaseNoteQuality, enforceChangelogKindExclusivity)
+			g, err := l.Decide(ctx, pr.GetBody())
+			if err != nil {
+				return err
+			}
+			g.Source = args[0]
+			// fixtures are committed, so never copy a pasted credential into one
+			g.Body = secrets.Redact(g.Body)
+
+			data, err := json.MarshalIndent(g, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode fixture: %w", err)
+			}
+			if name == "" {
+				name = fmt.Sprintf("%s-%s-%d", owner, repo, prNum)
+			}
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				return fmt.Errorf("failed to create fixture directory: %w", err)
+			}
+			path := filepath.Join(outDir, name+".json")
+			if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+				return fmt.Errorf("failed to write fixture: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "wrote %s\n", path)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&outDir, "out", filepath.Join("internal", "labeler", "testdata"), "directory to write the fixture to")
+	cmd.Flags().StringVar(&name, "name", "", "fixture file name without extension (defaults to owner-repo-PR)")
+	cmd.Flags().BoolVar(&enforceDescription, "enforce-description", true, "enforce that the Description section is filled out")
+	cmd.Flags().BoolVar(&enforceReleaseNoteQuality, "enforce-release-note-quality", false, "enforce naive publication-ready release note checks")
+	cmd.Flags().BoolVar(&enforceChangelogKindExclusivity, "enforce-changelog-kind-exclusivity", false, "enforce at most one changelog kind per PR")
+	return cmd
+}
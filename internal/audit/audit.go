@@ -0,0 +1,129 @@
+// Package audit compares repositories' label definitions against the
+// canonical catalog and fixes drift.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// Problem is how a repository's label differs from the catalog.
+type Problem string
+
+const (
+	// Missing means the repository does not define the label.
+	Missing Problem = "missing"
+	// Color means the label's color differs from the catalog.
+	Color Problem = "color"
+	// Description means the label's description differs from the catalog.
+	Description Problem = "description"
+)
+
+// Drift is one difference between a repository's label and the catalog.
+type Drift struct {
+	Repository string  `json:"repository"`
+	Label      string  `json:"label"`
+	Problem    Problem `json:"problem"`
+	Want       string  `json:"want,omitempty"`
+	Got        string  `json:"got,omitempty"`
+}
+
+// Check returns how the labels of owner/repo drift from catalog, in catalog
+// order. Labels outside the catalog are the repository's own and ignored.
+func Check(ctx context.Context, client *github.Client, owner, repo string, catalog []labels.Definition) ([]Drift, error) {
+	existing, err := listLabels(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	fullName := owner + "/" + repo
+	var drifts []Drift
+	for _, def := range catalog {
+		got, ok := existing[strings.ToLower(def.Name)]
+		if !ok {
+			drifts = append(drifts, Drift{Repository: fullName, Label: def.Name, Problem: Missing})
+			continue
+		}
+		if !strings.EqualFold(got.GetColor(), def.Color) {
+			drifts = append(drifts, Drift{Repository: fullName, Label: def.Name, Problem: Color, Want: def.Color, Got: got.GetColor()})
+		}
+		if got.GetDescription() != def.Description {
+			drifts = append(drifts, Drift{Repository: fullName, Label: def.Name, Problem: Description, Want: def.Description, Got: got.GetDescription()})
+		}
+	}
+	return drifts, nil
+}
+
+// Fix brings the labels of owner/repo in line with catalog: missing labels
+// are created and drifted ones edited. drifts must come from Check.
+func Fix(ctx context.Context, client *github.Client, owner, repo string, catalog []labels.Definition, drifts []Drift) error {
+	defs := map[string]labels.Definition{}
+	for _, def := range catalog {
+		defs[def.Name] = def
+	}
+	fixed := map[string]bool{}
+	for _, d := range drifts {
+		if fixed[d.Label] {
+			continue
+		}
+		fixed[d.Label] = true
+		def := defs[d.Label]
+		label := &github.Label{Name: github.Ptr(def.Name), Color: github.Ptr(def.Color), Description: github.Ptr(def.Description)}
+		if d.Problem == Missing {
+			if _, _, err := client.Issues.CreateLabel(ctx, owner, repo, label); err != nil {
+				return fmt.Errorf("failed to create label %q: %w", def.Name, err)
+			}
+			continue
+		}
+		if _, _, err := client.Issues.EditLabel(ctx, owner, repo, labels.EncodeName(def.Name), label); err != nil {
+			return fmt.Errorf("failed to edit label %q: %w", def.Name, err)
+		}
+	}
+	return nil
+}
+
+// listLabels returns the labels of owner/repo keyed by lower-cased name, as
+// GitHub label names are case-insensitive.
+func listLabels(ctx context.Context, client *github.Client, owner, repo string) (map[string]*github.Label, error) {
+	existing := map[string]*github.Label{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Issues.ListLabels(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		for _, label := range page {
+			existing[strings.ToLower(label.GetName())] = label
+		}
+		if resp.NextPage == 0 {
+			return existing, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// OrgRepositories returns the names of the organization's repositories that
+// are not archived, so org-wide audits skip repositories that cannot change.
+func OrgRepositories(ctx context.Context, client *github.Client, org string) ([]string, error) {
+	var names []string
+	opts := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		repos, resp, err := client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of %s: %w", org, err)
+		}
+		for _, r := range repos {
+			if !r.GetArchived() {
+				names = append(names, r.GetName())
+			}
+		}
+		if resp.NextPage == 0 {
+			return names, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
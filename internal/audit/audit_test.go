@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+var testCatalog = []labels.Definition{
+	{Name: "kind/fix", Color: "1d76db", Description: "Categorizes the PR as fix."},
+	{Name: "kind/test", Color: "1d76db", Description: "Categorizes the PR as test."},
+	{Name: "release-note", Color: "0e8a16", Description: "The PR has a release note."},
+}
+
+func TestCheckAndFix(t *testing.T) {
+	var created, edited []github.Label
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchPages(
+			mock.GetReposLabelsByOwnerByRepo,
+			[]*github.Label{
+				{Name: github.Ptr("Kind/Fix"), Color: github.Ptr("1D76DB"), Description: github.Ptr("Categorizes the PR as fix.")},
+				{Name: github.Ptr("lgtm"), Color: github.Ptr("ffffff")},
+			},
+			[]*github.Label{
+				{Name: github.Ptr("release-note"), Color: github.Ptr("00ff00"), Description: github.Ptr("")},
+			},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposLabelsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var l github.Label
+				json.NewDecoder(r.Body).Decode(&l)
+				created = append(created, l)
+				w.Write(mock.MustMarshal(l))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposLabelsByOwnerByRepoByName,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var l github.Label
+				json.NewDecoder(r.Body).Decode(&l)
+				edited = append(edited, l)
+				w.Write(mock.MustMarshal(l))
+			}),
+		),
+	)
+	client := github.NewClient(httpClient)
+
+	drifts, err := Check(context.Background(), client, "owner", "repo", testCatalog)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	want := []Drift{
+		{Repository: "owner/repo", Label: "kind/test", Problem: Missing},
+		{Repository: "owner/repo", Label: "release-note", Problem: Color, Want: "0e8a16", Got: "00ff00"},
+		{Repository: "owner/repo", Label: "release-note", Problem: Description, Want: "The PR has a release note."},
+	}
+	if !reflect.DeepEqual(drifts, want) {
+		t.Fatalf("drifts = %+v, want %+v", drifts, want)
+	}
+
+	if err := Fix(context.Background(), client, "owner", "repo", testCatalog, drifts); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+	if len(created) != 1 || created[0].GetName() != "kind/test" || created[0].GetColor() != "1d76db" {
+		t.Fatalf("expected kind/test to be created, got %+v", created)
+	}
+	if len(edited) != 1 || edited[0].GetName() != "release-note" || edited[0].GetDescription() != "The PR has a release note." {
+		t.Fatalf("expected release-note to be edited once, got %+v", edited)
+	}
+}
+
+func TestOrgRepositoriesSkipsArchived(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetOrgsReposByOrg,
+			[]*github.Repository{
+				{Name: github.Ptr("kgateway")},
+				{Name: github.Ptr("old"), Archived: github.Ptr(true)},
+			},
+		),
+	)
+	got, err := OrgRepositories(context.Background(), github.NewClient(httpClient), "org")
+	if err != nil {
+		t.Fatalf("OrgRepositories: %v", err)
+	}
+	if want := []string{"kgateway"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("repositories = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+// Package dashboard summarizes the merge readiness of open PRs across
+// repositories, e.g. for weekly maintainer syncs.
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+// Status is where a PR stands against the label policy.
+type Status string
+
+const (
+	// Mergeable means the PR may be merged per the label policy.
+	Mergeable Status = "mergeable"
+	// Invalid means the PR fails the labeler's validation of its kind or
+	// release note.
+	Invalid Status = "invalid"
+	// Blocked means the PR passes validation but is held back by other
+	// labels, e.g. do-not-merge/hold.
+	Blocked Status = "blocked"
+)
+
+// noKind is the kind PRs without a kind/ label are counted under.
+const noKind = "none"
+
+// PR is the merge readiness of one open PR.
+type PR struct {
+	Repository string `json:"repository"`
+	Number     int    `json:"number"`
+	Title      string `json:"title"`
+	Author     string `json:"author"`
+	URL        string `json:"url"`
+	Status     Status `json:"status"`
+	// Kinds are the PR's kinds, from its kind/ labels.
+	Kinds []string `json:"kinds,omitempty"`
+	// Blockers are the labels that keep the PR from being merged, sorted.
+	Blockers []string `json:"blockers,omitempty"`
+}
+
+// FromVerdict returns the merge readiness of pr in repository, in the
+// owner/repo format, per the labeler's verdict on it.
+func FromVerdict(repository string, pr *github.PullRequest, v *labeler.Verdict) PR {
+	p := PR{
+		Repository: repository,
+		Number:     pr.GetNumber(),
+		Title:      pr.GetTitle(),
+		Author:     pr.GetUser().GetLogin(),
+		URL:        pr.GetHTMLURL(),
+		Status:     Mergeable,
+	}
+	for _, label := range v.Labels {
+		if kind, ok := strings.CutPrefix(label, "kind/"); ok {
+			p.Kinds = append(p.Kinds, kind)
+		}
+	}
+	seen := map[string]bool{}
+	for _, b := range v.Blockers {
+		if b.Check != "" {
+			p.Status = Invalid
+		} else if p.Status == Mergeable {
+			p.Status = Blocked
+		}
+		if b.Label != "" && !seen[b.Label] {
+			seen[b.Label] = true
+			p.Blockers = append(p.Blockers, b.Label)
+		}
+	}
+	sort.Strings(p.Kinds)
+	sort.Strings(p.Blockers)
+	return p
+}
+
+// Dashboard summarizes the merge readiness of open PRs.
+type Dashboard struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	// PRs are sorted by repository and number.
+	PRs []PR `json:"prs"`
+	// ByStatus, ByKind and ByBlocker count the PRs per status, kind and
+	// blocking label. PRs without a kind are counted under "none".
+	ByStatus  map[Status]int `json:"byStatus"`
+	ByKind    map[string]int `json:"byKind"`
+	ByBlocker map[string]int `json:"byBlocker"`
+}
+
+// New summarizes prs as of now.
+func New(prs []PR, now time.Time) *Dashboard {
+	d := &Dashboard{
+		GeneratedAt: now.UTC(),
+		PRs:         append([]PR{}, prs...),
+		ByStatus:    map[Status]int{},
+		ByKind:      map[string]int{},
+		ByBlocker:   map[string]int{},
+	}
+	sort.Slice(d.PRs, func(i, j int) bool {
+		if d.PRs[i].Repository != d.PRs[j].Repository {
+			return d.PRs[i].Repository < d.PRs[j].Repository
+		}
+		return d.PRs[i].Number < d.PRs[j].Number
+	})
+	for _, p := range d.PRs {
+		d.ByStatus[p.Status]++
+		if len(p.Kinds) == 0 {
+			d.ByKind[noKind]++
+		}
+		for _, kind := range p.Kinds {
+			d.ByKind[kind]++
+		}
+		for _, label := range p.Blockers {
+			d.ByBlocker[label]++
+		}
+	}
+	return d
+}
+
+// count is a row of a summary table.
+type count struct {
+	Name  string
+	Count int
+}
+
+// sortedCounts returns counts as rows, most frequent first.
+func sortedCounts[K ~string](counts map[K]int) []count {
+	rows := make([]count, 0, len(counts))
+	for name, n := range counts {
+		rows = append(rows, count{Name: string(name), Count: n})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Count != rows[j].Count {
+			return rows[i].Count > rows[j].Count
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
+
+var htmlPage = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"join": func(s []string) string { return strings.Join(s, ", ") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Merge readiness</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; }
+.mergeable { color: #1a7f37; }
+.invalid { color: #cf222e; }
+.blocked { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>Merge readiness</h1>
+<p>{{len .PRs}} open PR(s) as of {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}.</p>
+<h2>By status</h2>
+<table>
+<tr><th>Status</th><th>PRs</th></tr>
+{{range .ByStatus}}<tr><td class="{{.Name}}">{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+<h2>By kind</h2>
+<table>
+<tr><th>Kind</th><th>PRs</th></tr>
+{{range .ByKind}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+<h2>By merge blocker</h2>
+<table>
+<tr><th>Label</th><th>PRs</th></tr>
+{{range .ByBlocker}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{else}}<tr><td colspan="2">none</td></tr>
+{{end}}</table>
+<h2>Pull requests</h2>
+<table>
+<tr><th>PR</th><th>Title</th><th>Author</th><th>Status</th><th>Kinds</th><th>Blockers</th></tr>
+{{range .PRs}}<tr><td><a href="{{.URL}}">{{.Repository}}#{{.Number}}</a></td><td>{{.Title}}</td><td>{{.Author}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{join .Kinds}}</td><td>{{join .Blockers}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTML renders d as a static HTML page.
+func (d *Dashboard) WriteHTML(w io.Writer) error {
+	return htmlPage.Execute(w, struct {
+		GeneratedAt time.Time
+		PRs         []PR
+		ByStatus    []count
+		ByKind      []count
+		ByBlocker   []count
+	}{d.GeneratedAt, d.PRs, sortedCounts(d.ByStatus), sortedCounts(d.ByKind), sortedCounts(d.ByBlocker)})
+}
+
+// OpenPullRequests returns the open PRs of owner/repo, leaving out drafts,
+// which are not up for merging yet.
+func OpenPullRequests(ctx context.Context, client *github.Client, owner, repo string) ([]*github.PullRequest, error) {
+	var prs []*github.PullRequest
+	opts := &github.PullRequestListOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list open PRs: %w", err)
+		}
+		for _, pr := range page {
+			if !pr.GetDraft() {
+				prs = append(prs, pr)
+			}
+		}
+		if resp.NextPage == 0 {
+			return prs, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
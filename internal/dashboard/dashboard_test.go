@@ -0,0 +1,140 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestFromVerdict(t *testing.T) {
+	pr := &github.PullRequest{
+		Number:  github.Ptr(7),
+		Title:   github.Ptr("Fix a crash"),
+		User:    &github.User{Login: github.Ptr("author")},
+		HTMLURL: github.Ptr("https://github.com/owner/repo/pull/7"),
+	}
+	tests := []struct {
+		name         string
+		verdict      *labeler.Verdict
+		wantStatus   Status
+		wantKinds    []string
+		wantBlockers []string
+	}{
+		{
+			name:       "mergeable",
+			verdict:    &labeler.Verdict{Mergeable: true, Labels: []string{"kind/fix", labels.ReleaseNoteLabel}},
+			wantStatus: Mergeable,
+			wantKinds:  []string{"fix"},
+		},
+		{
+			name: "invalid",
+			verdict: &labeler.Verdict{
+				Labels: []string{labels.InvalidKindLabel},
+				Blockers: []labeler.Blocker{
+					{Reason: "has the label", Label: labels.InvalidKindLabel},
+					{Reason: "no kind", Check: labeler.CheckKind},
+				},
+			},
+			wantStatus:   Invalid,
+			wantBlockers: []string{labels.InvalidKindLabel},
+		},
+		{
+			name: "blocked",
+			verdict: &labeler.Verdict{
+				Labels:   []string{"kind/feature", "kind/fix", "do-not-merge/hold"},
+				Blockers: []labeler.Blocker{{Reason: "has the label", Label: "do-not-merge/hold"}},
+			},
+			wantStatus:   Blocked,
+			wantKinds:    []string{"feature", "fix"},
+			wantBlockers: []string{"do-not-merge/hold"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromVerdict("owner/repo", pr, tt.verdict)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(got.Kinds, tt.wantKinds) {
+				t.Errorf("kinds = %v, want %v", got.Kinds, tt.wantKinds)
+			}
+			if !reflect.DeepEqual(got.Blockers, tt.wantBlockers) {
+				t.Errorf("blockers = %v, want %v", got.Blockers, tt.wantBlockers)
+			}
+			if got.Number != 7 || got.Author != "author" || got.Repository != "owner/repo" {
+				t.Errorf("unexpected PR details: %+v", got)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	d := New([]PR{
+		{Repository: "owner/repo", Number: 2, Status: Blocked, Kinds: []string{"fix"}, Blockers: []string{"do-not-merge/hold"}},
+		{Repository: "owner/other", Number: 9, Status: Invalid, Blockers: []string{labels.InvalidKindLabel}},
+		{Repository: "owner/repo", Number: 1, Status: Mergeable, Kinds: []string{"fix", "feature"}},
+	}, time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+
+	var order []int
+	for _, p := range d.PRs {
+		order = append(order, p.Number)
+	}
+	if !reflect.DeepEqual(order, []int{9, 1, 2}) {
+		t.Errorf("expected PRs sorted by repository and number, got %v", order)
+	}
+	if want := map[Status]int{Mergeable: 1, Invalid: 1, Blocked: 1}; !reflect.DeepEqual(d.ByStatus, want) {
+		t.Errorf("ByStatus = %v, want %v", d.ByStatus, want)
+	}
+	if want := map[string]int{"fix": 2, "feature": 1, noKind: 1}; !reflect.DeepEqual(d.ByKind, want) {
+		t.Errorf("ByKind = %v, want %v", d.ByKind, want)
+	}
+	if want := map[string]int{"do-not-merge/hold": 1, labels.InvalidKindLabel: 1}; !reflect.DeepEqual(d.ByBlocker, want) {
+		t.Errorf("ByBlocker = %v, want %v", d.ByBlocker, want)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteHTML(&buf); err != nil {
+		t.Fatalf("failed to render HTML: %v", err)
+	}
+	for _, want := range []string{
+		"3 open PR(s) as of 2026-01-05 09:00 UTC",
+		"<td>fix</td><td>2</td>",
+		"<td>do-not-merge/hold</td><td>1</td>",
+		"owner/other#9",
+		"<td>fix, feature</td>",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected the page to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestOpenPullRequests(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposPullsByOwnerByRepo, []*github.PullRequest{
+			{Number: github.Ptr(1)},
+			{Number: github.Ptr(2), Draft: github.Ptr(true)},
+			{Number: github.Ptr(3)},
+		}),
+	)
+	prs, err := OpenPullRequests(context.Background(), github.NewClient(httpClient), "owner", "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []int
+	for _, pr := range prs {
+		got = append(got, pr.GetNumber())
+	}
+	if !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Fatalf("expected drafts to be left out, got %v", got)
+	}
+}
@@ -0,0 +1,133 @@
+// Package diff renders line-based unified diffs for previews.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Unified returns the unified diff of a and b, labeled from and to, or "" if
+// they are equal.
+func Unified(from, to, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := lineOps(splitLines(a), splitLines(b))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", from, to)
+	for start := 0; start < len(ops); {
+		// find the next change and the hunk around it
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		lo := max(first-context, start)
+		hi := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				hi = i
+				continue
+			}
+			if i-hi > 2*context {
+				break
+			}
+		}
+		hi = min(hi+context+1, len(ops))
+		writeHunk(&sb, ops, lo, hi)
+		start = hi
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []op, lo, hi int) {
+	aStart, bStart := 1, 1
+	for _, o := range ops[:lo] {
+		if o.kind != '+' {
+			aStart++
+		}
+		if o.kind != '-' {
+			bStart++
+		}
+	}
+	var aLen, bLen int
+	for _, o := range ops[lo:hi] {
+		if o.kind != '+' {
+			aLen++
+		}
+		if o.kind != '-' {
+			bLen++
+		}
+	}
+	// an empty range starts at the line before it, as diff -u prints it
+	if aLen == 0 {
+		aStart--
+	}
+	if bLen == 0 {
+		bStart--
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+	for _, o := range ops[lo:hi] {
+		sb.WriteByte(o.kind)
+		sb.WriteString(o.line)
+		sb.WriteByte('\n')
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lineOps returns the edit script turning a into b, from their longest
+// common subsequence. Previews are small, so the quadratic table is fine.
+func lineOps(a, b []string) []op {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
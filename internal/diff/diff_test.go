@@ -0,0 +1,43 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "added to empty",
+			a:    "",
+			b:    "a\nb\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "changed line with context",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- old\n+++ new\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "distant changes get separate hunks",
+			a:    "a\n1\n2\n3\n4\n5\n6\n7\n8\nb\n",
+			b:    "A\n1\n2\n3\n4\n5\n6\n7\n8\nB\n",
+			want: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-b\n+B\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.a, tt.b); got != tt.want {
+				t.Fatalf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
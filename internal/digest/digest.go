@@ -0,0 +1,232 @@
+// Package digest summarizes the PRs merged over a period, e.g. the past week,
+// by kind and area, for posting as a community update.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// Format is how a digest is rendered.
+type Format string
+
+const (
+	// Markdown renders GitHub-flavored markdown, e.g. for a Discussion.
+	Markdown Format = "markdown"
+	// Slack renders Slack mrkdwn.
+	Slack Format = "slack"
+)
+
+// noKind is the kind PRs without a kind/ label are listed under.
+const noKind = "other"
+
+// PR is a merged PR.
+type PR struct {
+	Repository string    `json:"repository"`
+	Number     int       `json:"number"`
+	Title      string    `json:"title"`
+	Author     string    `json:"author"`
+	URL        string    `json:"url"`
+	MergedAt   time.Time `json:"mergedAt"`
+	// Kinds are the PR's kinds, from its kind/ labels.
+	Kinds []string `json:"kinds,omitempty"`
+	// Areas are the areas the PR changes, from its labels with the area
+	// prefix, e.g. module/.
+	Areas []string `json:"areas,omitempty"`
+}
+
+// FromPullRequest returns pr in repository, in the owner/repo format, with
+// its areas read from the labels starting with areaPrefix. An empty
+// areaPrefix reads no areas.
+func FromPullRequest(repository string, pr *github.PullRequest, areaPrefix string) PR {
+	p := PR{
+		Repository: repository,
+		Number:     pr.GetNumber(),
+		Title:      pr.GetTitle(),
+		Author:     pr.GetUser().GetLogin(),
+		URL:        pr.GetHTMLURL(),
+		MergedAt:   pr.GetMergedAt().Time,
+	}
+	for _, label := range pr.Labels {
+		if kind, ok := strings.CutPrefix(label.GetName(), "kind/"); ok {
+			p.Kinds = append(p.Kinds, kind)
+		}
+		if area, ok := strings.CutPrefix(label.GetName(), areaPrefix); ok && areaPrefix != "" && area != "" {
+			p.Areas = append(p.Areas, area)
+		}
+	}
+	sort.Strings(p.Kinds)
+	sort.Strings(p.Areas)
+	return p
+}
+
+// Digest summarizes the PRs merged from Since until Until.
+type Digest struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// PRs are sorted by repository and number.
+	PRs []PR `json:"prs"`
+	// ByKind and ByArea count the PRs per kind and area. PRs without a kind
+	// are counted under "other".
+	ByKind map[string]int `json:"byKind"`
+	ByArea map[string]int `json:"byArea"`
+}
+
+// New summarizes prs merged from since until until.
+func New(prs []PR, since, until time.Time) *Digest {
+	d := &Digest{
+		Since:  since.UTC(),
+		Until:  until.UTC(),
+		PRs:    append([]PR{}, prs...),
+		ByKind: map[string]int{},
+		ByArea: map[string]int{},
+	}
+	sort.Slice(d.PRs, func(i, j int) bool {
+		if d.PRs[i].Repository != d.PRs[j].Repository {
+			return d.PRs[i].Repository < d.PRs[j].Repository
+		}
+		return d.PRs[i].Number < d.PRs[j].Number
+	})
+	for _, p := range d.PRs {
+		for _, kind := range kindsOf(p) {
+			d.ByKind[kind]++
+		}
+		for _, area := range p.Areas {
+			d.ByArea[area]++
+		}
+	}
+	return d
+}
+
+// kindsOf returns the kinds p is listed under.
+func kindsOf(p PR) []string {
+	if len(p.Kinds) == 0 {
+		return []string{noKind}
+	}
+	return p.Kinds
+}
+
+// Title returns the headline of the digest, naming its period.
+func (d *Digest) Title() string {
+	return "Merged PRs, " + d.Since.Format("Jan 2") + " – " + d.Until.Format("Jan 2, 2006")
+}
+
+// Write renders the digest in format: a headline, the PR counts per area,
+// and a section per kind, most frequent first, listing its PRs with links.
+// A PR of several kinds is listed under each.
+func (d *Digest) Write(w io.Writer, format Format) error {
+	var sb strings.Builder
+	f := markdown
+	if format == Slack {
+		f = slack
+	}
+
+	sb.WriteString(f.heading(d.Title(), 2) + "\n")
+	repos := map[string]bool{}
+	for _, p := range d.PRs {
+		repos[p.Repository] = true
+	}
+	fmt.Fprintf(&sb, "\n%d PR(s) merged in %d repositories.\n", len(d.PRs), len(repos))
+	if len(d.ByArea) > 0 {
+		var areas []string
+		for _, c := range sortedCounts(d.ByArea) {
+			areas = append(areas, fmt.Sprintf("%s (%d)", c.name, c.count))
+		}
+		sb.WriteString("\n" + f.bold("Areas:") + " " + strings.Join(areas, ", ") + "\n")
+	}
+
+	for _, c := range sortedCounts(d.ByKind) {
+		sb.WriteString("\n" + f.heading(fmt.Sprintf("%s (%d)", c.name, c.count), 3) + "\n\n")
+		for _, p := range d.PRs {
+			if !slices.Contains(kindsOf(p), c.name) {
+				continue
+			}
+			line := fmt.Sprintf("- %s (%s#%d) by @%s", f.link(p.Title, p.URL), p.Repository, p.Number, p.Author)
+			if len(p.Areas) > 0 {
+				line += " in " + strings.Join(p.Areas, ", ")
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// syntax is the markup of a format.
+type syntax struct {
+	heading func(s string, level int) string
+	bold    func(s string) string
+	link    func(text, url string) string
+}
+
+var markdown = syntax{
+	heading: func(s string, level int) string { return strings.Repeat("#", level) + " " + s },
+	bold:    func(s string) string { return "**" + s + "**" },
+	link: func(text, url string) string {
+		return "[" + strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text) + "](" + url + ")"
+	},
+}
+
+var slack = syntax{
+	// Slack has no headings
+	heading: func(s string, _ int) string { return "*" + s + "*" },
+	bold:    func(s string) string { return "*" + s + "*" },
+	link: func(text, url string) string {
+		// Slack requires &, < and > to be escaped in text
+		return "<" + url + "|" + strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text) + ">"
+	},
+}
+
+// count is a name and how often it occurs.
+type count struct {
+	name  string
+	count int
+}
+
+// sortedCounts returns counts most frequent first, then by name.
+func sortedCounts(counts map[string]int) []count {
+	rows := make([]count, 0, len(counts))
+	for name, n := range counts {
+		rows = append(rows, count{name: name, count: n})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].count != rows[j].count {
+			return rows[i].count > rows[j].count
+		}
+		return rows[i].name < rows[j].name
+	})
+	return rows
+}
+
+// MergedPullRequests returns the PRs of owner/repo merged since since. PRs
+// are listed by when they were last updated, newest first, so listing stops
+// at the first PR not updated since then.
+func MergedPullRequests(ctx context.Context, client *github.Client, owner, repo string, since time.Time) ([]*github.PullRequest, error) {
+	var prs []*github.PullRequest
+	opts := &github.PullRequestListOptions{State: "closed", Sort: "updated", Direction: "desc", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list closed PRs: %w", err)
+		}
+		for _, pr := range page {
+			if pr.GetUpdatedAt().Before(since) {
+				return prs, nil
+			}
+			if pr.MergedAt != nil && !pr.GetMergedAt().Before(since) {
+				prs = append(prs, pr)
+			}
+		}
+		if resp.NextPage == 0 {
+			return prs, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
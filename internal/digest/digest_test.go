@@ -0,0 +1,84 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestWrite(t *testing.T) {
+	since := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	d := New([]PR{
+		{Repository: "owner/repo", Number: 2, Title: "Fix a crash", Author: "alice", URL: "https://github.com/owner/repo/pull/2", Kinds: []string{"fix"}, Areas: []string{"api"}},
+		{Repository: "owner/other", Number: 9, Title: "Add [beta] <foo> & bar", Author: "bob", URL: "https://github.com/owner/other/pull/9", Kinds: []string{"feature", "fix"}},
+		{Repository: "owner/repo", Number: 1, Title: "Tidy up", Author: "carol", URL: "https://github.com/owner/repo/pull/1"},
+	}, since, since.Add(7*24*time.Hour))
+
+	if want := map[string]int{"fix": 2, "feature": 1, noKind: 1}; !reflect.DeepEqual(d.ByKind, want) {
+		t.Errorf("ByKind = %v, want %v", d.ByKind, want)
+	}
+
+	tests := []struct {
+		format Format
+		want   []string
+	}{
+		{
+			format: Markdown,
+			want: []string{
+				"## Merged PRs, Jan 5 – Jan 12, 2026\n\n3 PR(s) merged in 2 repositories.\n\n**Areas:** api (1)\n",
+				"### fix (2)\n\n- [Add \\[beta\\] <foo> & bar](https://github.com/owner/other/pull/9) (owner/other#9) by @bob\n- [Fix a crash](https://github.com/owner/repo/pull/2) (owner/repo#2) by @alice in api\n",
+				"### other (1)\n\n- [Tidy up]",
+			},
+		},
+		{
+			format: Slack,
+			want: []string{
+				"*Merged PRs, Jan 5 – Jan 12, 2026*\n",
+				"*fix (2)*\n\n- <https://github.com/owner/other/pull/9|Add [beta] &lt;foo&gt; &amp; bar> (owner/other#9) by @bob\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := d.Write(&buf, tt.format); err != nil {
+				t.Fatalf("failed to write the digest: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected the digest to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestMergedPullRequests(t *testing.T) {
+	since := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	at := func(days int) *github.Timestamp { return &github.Timestamp{Time: since.AddDate(0, 0, days)} }
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposPullsByOwnerByRepo, []*github.PullRequest{
+			{Number: github.Ptr(1), UpdatedAt: at(3), MergedAt: at(2)},
+			{Number: github.Ptr(2), UpdatedAt: at(2)},
+			{Number: github.Ptr(3), UpdatedAt: at(1), MergedAt: at(-1)},
+			{Number: github.Ptr(4), UpdatedAt: at(-2), MergedAt: at(-3)},
+		}),
+	)
+	prs, err := MergedPullRequests(context.Background(), github.NewClient(httpClient), "owner", "repo", since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []int
+	for _, pr := range prs {
+		got = append(got, pr.GetNumber())
+	}
+	if !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("expected only the PR merged since then, got %v", got)
+	}
+}
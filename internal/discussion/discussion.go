@@ -0,0 +1,130 @@
+// Package discussion creates and updates GitHub Discussions, e.g. to publish
+// digests and changelogs. Discussions are only in GitHub's GraphQL API, which
+// is called through the go-github client, so requests share its token
+// failover and fault injection.
+package discussion
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// Post is a created or updated discussion.
+type Post struct {
+	URL string `json:"url"`
+	// Created is set when the discussion was created rather than updated.
+	Created bool `json:"created"`
+}
+
+// Publish creates a discussion titled title with body in the category of
+// owner/repo named category, or updates the body of the latest discussion of
+// that category with the same title, so rerunning a release job edits its
+// post instead of duplicating it. The category is matched by name or slug,
+// case-insensitively.
+func Publish(ctx context.Context, client *github.Client, owner, repo, category, title, body string) (*Post, error) {
+	var repoData struct {
+		Repository struct {
+			ID                   string
+			DiscussionCategories struct {
+				Nodes []struct {
+					ID   string
+					Name string
+					Slug string
+				}
+			}
+		}
+	}
+	err := query(ctx, client, `query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    id
+    discussionCategories(first: 100) { nodes { id name slug } }
+  }
+}`, map[string]any{"owner": owner, "repo": repo}, &repoData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up discussion categories: %w", err)
+	}
+	categoryID := ""
+	var names []string
+	for _, c := range repoData.Repository.DiscussionCategories.Nodes {
+		if strings.EqualFold(c.Name, category) || strings.EqualFold(c.Slug, category) {
+			categoryID = c.ID
+		}
+		names = append(names, c.Name)
+	}
+	if categoryID == "" {
+		return nil, fmt.Errorf("%s/%s has no discussion category %q, expected one of %s; are discussions enabled?", owner, repo, category, strings.Join(names, ", "))
+	}
+
+	var existing struct {
+		Repository struct {
+			Discussions struct {
+				Nodes []struct {
+					ID    string
+					Title string
+				}
+			}
+		}
+	}
+	err = query(ctx, client, `query($owner: String!, $repo: String!, $category: ID!) {
+  repository(owner: $owner, name: $repo) {
+    discussions(first: 100, categoryId: $category, orderBy: {field: CREATED_AT, direction: DESC}) { nodes { id title } }
+  }
+}`, map[string]any{"owner": owner, "repo": repo, "category": categoryID}, &existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list discussions: %w", err)
+	}
+	for _, d := range existing.Repository.Discussions.Nodes {
+		if d.Title != title {
+			continue
+		}
+		var updated struct {
+			UpdateDiscussion struct{ Discussion struct{ URL string } }
+		}
+		err := query(ctx, client, `mutation($id: ID!, $body: String!) {
+  updateDiscussion(input: {discussionId: $id, body: $body}) { discussion { url } }
+}`, map[string]any{"id": d.ID, "body": body}, &updated)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update discussion: %w", err)
+		}
+		return &Post{URL: updated.UpdateDiscussion.Discussion.URL}, nil
+	}
+
+	var created struct {
+		CreateDiscussion struct{ Discussion struct{ URL string } }
+	}
+	err = query(ctx, client, `mutation($repo: ID!, $category: ID!, $title: String!, $body: String!) {
+  createDiscussion(input: {repositoryId: $repo, categoryId: $category, title: $title, body: $body}) { discussion { url } }
+}`, map[string]any{"repo": repoData.Repository.ID, "category": categoryID, "title": title, "body": body}, &created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discussion: %w", err)
+	}
+	return &Post{URL: created.CreateDiscussion.Discussion.URL, Created: true}, nil
+}
+
+// query runs a GraphQL query or mutation and decodes its data into data.
+func query(ctx context.Context, client *github.Client, q string, vars map[string]any, data any) error {
+	req, err := client.NewRequest("POST", "graphql", map[string]any{"query": q, "variables": vars})
+	if err != nil {
+		return err
+	}
+	resp := struct {
+		Data   any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{Data: data}
+	if _, err := client.Do(ctx, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			msgs[i] = e.Message
+		}
+		return fmt.Errorf("%s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package discussion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+var graphQL = mock.EndpointPattern{Pattern: "/graphql", Method: "POST"}
+
+// fakeGraphQL answers the queries and mutations of Publish, with a discussion
+// titled existing in the Announcements category, and records mutations.
+func fakeGraphQL(t *testing.T, existing string, mutations *[]string) *http.Client {
+	return mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(graphQL, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Query     string         `json:"query"`
+				Variables map[string]any `json:"variables"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("invalid GraphQL request: %v", err)
+			}
+			var data string
+			switch {
+			case strings.Contains(req.Query, "discussionCategories"):
+				data = `{"repository": {"id": "R1", "discussionCategories": {"nodes": [{"id": "C1", "name": "General", "slug": "general"}, {"id": "C2", "name": "Announcements", "slug": "announcements"}]}}}`
+			case strings.Contains(req.Query, "discussions("):
+				if req.Variables["category"] != "C2" {
+					t.Errorf("expected the discussions of the Announcements category, got %v", req.Variables)
+				}
+				data = `{"repository": {"discussions": {"nodes": [{"id": "D1", "title": "` + existing + `"}]}}}`
+			case strings.Contains(req.Query, "updateDiscussion"):
+				*mutations = append(*mutations, "update "+req.Variables["id"].(string))
+				data = `{"updateDiscussion": {"discussion": {"url": "https://github.com/owner/repo/discussions/1"}}}`
+			case strings.Contains(req.Query, "createDiscussion"):
+				*mutations = append(*mutations, "create "+req.Variables["title"].(string))
+				data = `{"createDiscussion": {"discussion": {"url": "https://github.com/owner/repo/discussions/2"}}}`
+			default:
+				t.Fatalf("unexpected GraphQL query %s", req.Query)
+			}
+			w.Write([]byte(`{"data": ` + data + `}`))
+		})),
+	)
+}
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      string
+		wantURL       string
+		wantCreated   bool
+		wantMutations string
+	}{
+		{
+			name:          "creates a discussion",
+			existing:      "Older notes",
+			wantURL:       "https://github.com/owner/repo/discussions/2",
+			wantCreated:   true,
+			wantMutations: "create v2.1.0 release notes",
+		},
+		{
+			name:          "updates the discussion of a previous run",
+			existing:      "v2.1.0 release notes",
+			wantURL:       "https://github.com/owner/repo/discussions/1",
+			wantMutations: "update D1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutations []string
+			client := github.NewClient(fakeGraphQL(t, tt.existing, &mutations))
+			post, err := Publish(context.Background(), client, "owner", "repo", "announcements", "v2.1.0 release notes", "## New Features")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if post.URL != tt.wantURL || post.Created != tt.wantCreated {
+				t.Errorf("Publish() = %+v, want URL %s and created %v", post, tt.wantURL, tt.wantCreated)
+			}
+			if strings.Join(mutations, ", ") != tt.wantMutations {
+				t.Errorf("mutations = %v, want %s", mutations, tt.wantMutations)
+			}
+		})
+	}
+}
+
+func TestPublish_UnknownCategory(t *testing.T) {
+	var mutations []string
+	client := github.NewClient(fakeGraphQL(t, "", &mutations))
+	_, err := Publish(context.Background(), client, "owner", "repo", "Releases", "title", "body")
+	if err == nil || !strings.Contains(err.Error(), `no discussion category "Releases", expected one of General, Announcements`) {
+		t.Fatalf("expected an unknown category error, got %v", err)
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	client := github.NewClient(mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(graphQL, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data": null, "errors": [{"message": "Resource not accessible by integration"}]}`))
+		})),
+	))
+	var data struct{}
+	if err := query(context.Background(), client, "query { viewer { login } }", nil, &data); err == nil || err.Error() != "Resource not accessible by integration" {
+		t.Fatalf("expected the GraphQL error, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+// Package event normalizes the webhook events the labeler acts on, so that
+// processing never depends on the payload types of the forge or event type
+// an event came from. Each source has an adapter, such as FromGitHub.
+package event
+
+import "fmt"
+
+// Action is what happened to a PR.
+type Action string
+
+// Actions the labeler acts on. Adapters pass other actions through as is,
+// e.g. closed.
+const (
+	Opened    Action = "opened"
+	Edited    Action = "edited"
+	Reopened  Action = "reopened"
+	Labeled   Action = "labeled"
+	Unlabeled Action = "unlabeled"
+	// Synchronized is a push to the PR's head branch.
+	Synchronized Action = "synchronize"
+	// Commented is a new comment on the PR.
+	Commented Action = "commented"
+	// Reviewed is a submitted review of the PR.
+	Reviewed Action = "reviewed"
+	// ChecksCompleted is a check suite or commit status on the PR head
+	// commit that completed.
+	ChecksCompleted Action = "checks-completed"
+)
+
+// PullRequest is an event about a PR.
+type PullRequest struct {
+	Action Action
+	Owner  string
+	Repo   string
+	// Number is 0 for events on a commit rather than a PR, e.g. a commit
+	// status, whose PR is looked up from HeadSHA.
+	Number int
+	// Installation is the GitHub App installation the event was delivered
+	// to, or 0 if none.
+	Installation int64
+
+	// Partial is set when the event does not carry the PR itself, as for
+	// comments, so the fields below must be looked up before processing.
+	Partial bool
+	Body    string
+	// Labels are the PR's labels after the event.
+	Labels []string
+	Author string
+	// AuthorAssociation is the author's association with the repository,
+	// e.g. MEMBER.
+	AuthorAssociation string
+	// Base is the branch the PR merges into.
+	Base    string
+	HeadSHA string
+	// Check is the context of the commit status, or the slug of the app of
+	// the check suite, that completed in a ChecksCompleted event.
+	Check string
+
+	// Comment is the comment of a Commented event, or the review summary of
+	// a Reviewed event.
+	Comment *Comment
+}
+
+// Comment is a comment on a PR.
+type Comment struct {
+	ID                int64
+	Body              string
+	Author            string
+	AuthorAssociation string
+}
+
+// FullName returns the repository as owner/repo.
+func (e *PullRequest) FullName() string {
+	return e.Owner + "/" + e.Repo
+}
+
+// String returns the PR as owner/repo#number.
+func (e *PullRequest) String() string {
+	return fmt.Sprintf("%s/%s#%d", e.Owner, e.Repo, e.Number)
+}
@@ -0,0 +1,130 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// FromGitHub normalizes a GitHub webhook payload of eventType, the
+// X-GitHub-Event header or GITHUB_EVENT_NAME. pull_request_target events are
+// treated as pull_request events. Comments are only returned when they are
+// created on a PR, as partial events, and reviews when they are submitted.
+// Completed check suites and commit statuses are returned as partial events
+// on their commit. Other events yield nil.
+func FromGitHub(eventType string, payload []byte) (*PullRequest, error) {
+	switch eventType {
+	case "pull_request", "pull_request_target":
+		var e github.PullRequestEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse %s event: %w", eventType, err)
+		}
+		pr := &PullRequest{
+			Action:       Action(e.GetAction()),
+			Owner:        e.GetRepo().GetOwner().GetLogin(),
+			Repo:         e.GetRepo().GetName(),
+			Number:       e.GetNumber(),
+			Installation: e.GetInstallation().GetID(),
+		}
+		FromGitHubPullRequest(pr, e.GetPullRequest())
+		return pr, nil
+	case "pull_request_review":
+		var e github.PullRequestReviewEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse %s event: %w", eventType, err)
+		}
+		if e.GetAction() != "submitted" {
+			return nil, nil
+		}
+		pr := &PullRequest{
+			Action:       Reviewed,
+			Owner:        e.GetRepo().GetOwner().GetLogin(),
+			Repo:         e.GetRepo().GetName(),
+			Number:       e.GetPullRequest().GetNumber(),
+			Installation: e.GetInstallation().GetID(),
+			Comment: &Comment{
+				ID:                e.GetReview().GetID(),
+				Body:              e.GetReview().GetBody(),
+				Author:            e.GetReview().GetUser().GetLogin(),
+				AuthorAssociation: e.GetReview().GetAuthorAssociation(),
+			},
+		}
+		FromGitHubPullRequest(pr, e.GetPullRequest())
+		return pr, nil
+	case "check_suite":
+		var e github.CheckSuiteEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse %s event: %w", eventType, err)
+		}
+		prs := e.GetCheckSuite().PullRequests
+		if e.GetAction() != "completed" || len(prs) == 0 {
+			return nil, nil
+		}
+		return &PullRequest{
+			Action:       ChecksCompleted,
+			Owner:        e.GetRepo().GetOwner().GetLogin(),
+			Repo:         e.GetRepo().GetName(),
+			Number:       prs[0].GetNumber(),
+			Installation: e.GetInstallation().GetID(),
+			Partial:      true,
+			HeadSHA:      e.GetCheckSuite().GetHeadSHA(),
+			Check:        e.GetCheckSuite().GetApp().GetSlug(),
+		}, nil
+	case "status":
+		var e github.StatusEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse %s event: %w", eventType, err)
+		}
+		if e.GetState() == "pending" {
+			return nil, nil
+		}
+		return &PullRequest{
+			Action:       ChecksCompleted,
+			Owner:        e.GetRepo().GetOwner().GetLogin(),
+			Repo:         e.GetRepo().GetName(),
+			Installation: e.GetInstallation().GetID(),
+			Partial:      true,
+			HeadSHA:      e.GetSHA(),
+			Check:        e.GetContext(),
+		}, nil
+	case "issue_comment":
+		var e github.IssueCommentEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse %s event: %w", eventType, err)
+		}
+		if e.GetAction() != "created" || !e.GetIssue().IsPullRequest() {
+			return nil, nil
+		}
+		return &PullRequest{
+			Action:       Commented,
+			Owner:        e.GetRepo().GetOwner().GetLogin(),
+			Repo:         e.GetRepo().GetName(),
+			Number:       e.GetIssue().GetNumber(),
+			Installation: e.GetInstallation().GetID(),
+			Partial:      true,
+			Comment: &Comment{
+				ID:                e.GetComment().GetID(),
+				Body:              e.GetComment().GetBody(),
+				Author:            e.GetComment().GetUser().GetLogin(),
+				AuthorAssociation: e.GetComment().GetAuthorAssociation(),
+			},
+		}, nil
+	}
+	return nil, nil
+}
+
+// FromGitHubPullRequest sets the PR details of e from pr, completing a
+// partial event.
+func FromGitHubPullRequest(e *PullRequest, pr *github.PullRequest) {
+	e.Partial = false
+	e.Body = pr.GetBody()
+	e.Labels = nil
+	for _, label := range pr.Labels {
+		e.Labels = append(e.Labels, label.GetName())
+	}
+	e.Author = pr.GetUser().GetLogin()
+	e.AuthorAssociation = pr.GetAuthorAssociation()
+	e.Base = pr.GetBase().GetRef()
+	e.HeadSHA = pr.GetHead().GetSHA()
+}
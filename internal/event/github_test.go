@@ -0,0 +1,106 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromGitHub(t *testing.T) {
+	repo := `"repository": {"name": "repo", "owner": {"login": "owner"}}, "installation": {"id": 42}`
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		want      *PullRequest
+		wantErr   bool
+	}{
+		{
+			name:      "pull_request",
+			eventType: "pull_request",
+			payload: `{"action": "opened", "number": 7, ` + repo + `, "pull_request": {
+				"body": "/kind fix", "labels": [{"name": "kind/fix"}], "author_association": "MEMBER",
+				"user": {"login": "alice"}, "base": {"ref": "main"}, "head": {"sha": "abc123"}}}`,
+			want: &PullRequest{
+				Action: Opened, Owner: "owner", Repo: "repo", Number: 7, Installation: 42,
+				Body: "/kind fix", Labels: []string{"kind/fix"}, Author: "alice", AuthorAssociation: "MEMBER",
+				Base: "main", HeadSHA: "abc123",
+			},
+		},
+		{
+			name:      "pull_request_target",
+			eventType: "pull_request_target",
+			payload:   `{"action": "closed", "number": 7, ` + repo + `, "pull_request": {}}`,
+			want:      &PullRequest{Action: "closed", Owner: "owner", Repo: "repo", Number: 7, Installation: 42},
+		},
+		{
+			name:      "comment on a PR",
+			eventType: "issue_comment",
+			payload: `{"action": "created", ` + repo + `, "issue": {"number": 7, "pull_request": {"url": "u"}},
+				"comment": {"id": 3, "body": "/release-note NONE", "user": {"login": "bob"}, "author_association": "OWNER"}}`,
+			want: &PullRequest{
+				Action: Commented, Owner: "owner", Repo: "repo", Number: 7, Installation: 42, Partial: true,
+				Comment: &Comment{ID: 3, Body: "/release-note NONE", Author: "bob", AuthorAssociation: "OWNER"},
+			},
+		},
+		{
+			name:      "submitted review",
+			eventType: "pull_request_review",
+			payload: `{"action": "submitted", ` + repo + `, "pull_request": {"number": 7, "body": "/kind fix", "head": {"sha": "abc123"}},
+				"review": {"id": 5, "body": "/kind cleanup", "user": {"login": "bob"}, "author_association": "MEMBER"}}`,
+			want: &PullRequest{
+				Action: Reviewed, Owner: "owner", Repo: "repo", Number: 7, Installation: 42, Body: "/kind fix", HeadSHA: "abc123",
+				Comment: &Comment{ID: 5, Body: "/kind cleanup", Author: "bob", AuthorAssociation: "MEMBER"},
+			},
+		},
+		{
+			name:      "dismissed review",
+			eventType: "pull_request_review",
+			payload:   `{"action": "dismissed", ` + repo + `, "pull_request": {"number": 7}, "review": {"id": 5}}`,
+		},
+		{
+			name:      "comment on an issue",
+			eventType: "issue_comment",
+			payload:   `{"action": "created", ` + repo + `, "issue": {"number": 7}, "comment": {"body": "hi"}}`,
+		},
+		{
+			name:      "edited comment",
+			eventType: "issue_comment",
+			payload:   `{"action": "edited", ` + repo + `, "issue": {"number": 7, "pull_request": {"url": "u"}}, "comment": {"body": "hi"}}`,
+		},
+		{
+			name:      "completed check suite",
+			eventType: "check_suite",
+			payload:   `{"action": "completed", ` + repo + `, "check_suite": {"head_sha": "abc123", "app": {"slug": "ci"}, "pull_requests": [{"number": 7}]}}`,
+			want:      &PullRequest{Action: ChecksCompleted, Owner: "owner", Repo: "repo", Number: 7, Installation: 42, Partial: true, HeadSHA: "abc123", Check: "ci"},
+		},
+		{
+			name:      "check suite of no PR",
+			eventType: "check_suite",
+			payload:   `{"action": "completed", ` + repo + `, "check_suite": {"head_sha": "abc123", "pull_requests": []}}`,
+		},
+		{
+			name:      "failed commit status",
+			eventType: "status",
+			payload:   `{"state": "failure", "sha": "abc123", "context": "ci/test", ` + repo + `}`,
+			want:      &PullRequest{Action: ChecksCompleted, Owner: "owner", Repo: "repo", Installation: 42, Partial: true, HeadSHA: "abc123", Check: "ci/test"},
+		},
+		{
+			name:      "pending commit status",
+			eventType: "status",
+			payload:   `{"state": "pending", "sha": "abc123", ` + repo + `}`,
+		},
+		{name: "other event", eventType: "push", payload: `{}`},
+		{name: "invalid payload", eventType: "pull_request", payload: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromGitHub(tt.eventType, []byte(tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("FromGitHub() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
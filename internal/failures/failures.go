@@ -0,0 +1,105 @@
+// Package failures stores, per author, the PRs that failed validation.
+package failures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// MemoryStore keeps failures in memory, e.g. for the lifetime of a server.
+type MemoryStore struct {
+	mu       sync.Mutex
+	failures map[string][]string
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{failures: map[string][]string{}}
+}
+
+// Failures implements labeler.FailureStore.
+func (s *MemoryStore) Failures(ctx context.Context, author string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.failures[author]), nil
+}
+
+// RecordFailure implements labeler.FailureStore.
+func (s *MemoryStore) RecordFailure(ctx context.Context, author, pr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[author] = record(s.failures[author], pr)
+	return nil
+}
+
+// FileStore keeps failures in a JSON file mapping authors to their failed
+// PRs, so counts survive between GitHub Action runs when the file is cached,
+// e.g. with actions/cache.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore returns a store backed by the file at path, which is created
+// on the first recorded failure.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Failures implements labeler.FailureStore.
+func (s *FileStore) Failures(ctx context.Context, author string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failures, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return failures[author], nil
+}
+
+// RecordFailure implements labeler.FailureStore.
+func (s *FileStore) RecordFailure(ctx context.Context, author, pr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failures, err := s.read()
+	if err != nil {
+		return err
+	}
+	failures[author] = record(failures[author], pr)
+	data, err := json.MarshalIndent(failures, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode failures: %w", err)
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *FileStore) read() (map[string][]string, error) {
+	failures := map[string][]string{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return failures, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+	if err := json.Unmarshal(data, &failures); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return failures, nil
+}
+
+// record adds pr to prs unless it is already there.
+func record(prs []string, pr string) []string {
+	if slices.Contains(prs, pr) {
+		return prs
+	}
+	return append(prs, pr)
+}
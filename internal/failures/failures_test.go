@@ -0,0 +1,53 @@
+package failures
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+var (
+	_ labeler.FailureStore = (*MemoryStore)(nil)
+	_ labeler.FailureStore = (*FileStore)(nil)
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]labeler.FailureStore{
+		"memory": NewMemoryStore(),
+		"file":   NewFileStore(filepath.Join(t.TempDir(), "failures.json")),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, pr := range []string{"o/r#1", "o/r#2", "o/r#1"} {
+				if err := store.RecordFailure(ctx, "alice", pr); err != nil {
+					t.Fatalf("RecordFailure() error: %v", err)
+				}
+			}
+			got, err := store.Failures(ctx, "alice")
+			if err != nil {
+				t.Fatalf("Failures() error: %v", err)
+			}
+			if want := []string{"o/r#1", "o/r#2"}; !reflect.DeepEqual(got, want) {
+				t.Fatalf("Failures(alice) = %v, want %v", got, want)
+			}
+			if got, _ := store.Failures(ctx, "bob"); len(got) != 0 {
+				t.Fatalf("Failures(bob) = %v, want none", got)
+			}
+		})
+	}
+}
+
+func TestFileStore_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failures.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(path).Failures(context.Background(), "alice"); err == nil {
+		t.Fatal("expected an error for a corrupt file")
+	}
+}
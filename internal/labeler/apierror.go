@@ -0,0 +1,121 @@
+package labeler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// Token permissions the labeler's API calls need, named as in a workflow's
+// permissions block.
+const (
+	permPullRequestsRead  = "pull-requests: read"
+	permPullRequestsWrite = "pull-requests: write"
+	permChecksRead        = "checks: read"
+	permChecksWrite       = "checks: write"
+	permContentsRead      = "contents: read"
+	permContentsWrite     = "contents: write"
+	permStatusesRead      = "statuses: read"
+	permStatusesWrite     = "statuses: write"
+)
+
+// APIError is a failed GitHub API call. It names what the labeler was doing,
+// reports how much of the rate limit was left, and tells how to fix common
+// failures, so the failure report is actionable without reading API docs.
+type APIError struct {
+	// Op is what the labeler was doing, e.g. `remove label "kind/fix"`.
+	Op string
+	// Label is the label Op is about, if any.
+	Label string
+	// Permission is the token permission Op needs, e.g. pull-requests: write.
+	Permission string
+	Err        error
+}
+
+// apiError returns err as an APIError for op, which needs permission.
+func apiError(err error, permission, format string, args ...any) *APIError {
+	return &APIError{Op: fmt.Sprintf(format, args...), Permission: permission, Err: err}
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("failed to %s: %v", e.Op, e.Err)
+	if remaining, ok := e.RateRemaining(); ok {
+		msg += fmt.Sprintf(" (%d API requests left)", remaining)
+	}
+	if hint := e.Remediation(); hint != "" {
+		msg += ". " + hint
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *APIError) Unwrap() error { return e.Err }
+
+// RateRemaining returns how many requests the token had left when the call
+// failed, if GitHub said.
+func (e *APIError) RateRemaining() (int, bool) {
+	var rateErr *github.RateLimitError
+	if errors.As(e.Err, &rateErr) {
+		return rateErr.Rate.Remaining, true
+	}
+	resp := e.response()
+	if resp == nil {
+		return 0, false
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	return remaining, err == nil
+}
+
+// Remediation tells how to fix the failure, or returns "" if the cause is
+// not a common one.
+func (e *APIError) Remediation() string {
+	var (
+		rateErr  *github.RateLimitError
+		abuseErr *github.AbuseRateLimitError
+	)
+	switch {
+	case errors.As(e.Err, &rateErr):
+		return fmt.Sprintf("The token's rate limit is exhausted until %s; wait, or pass several comma-separated tokens to fail over between", rateErr.Rate.Reset.UTC().Format("15:04 MST"))
+	case errors.As(e.Err, &abuseErr):
+		return "GitHub's secondary rate limit was hit; lower the write rate, e.g. with serve --write-rps, or retry later"
+	}
+	resp := e.response()
+	if resp == nil {
+		return ""
+	}
+	switch resp.StatusCode {
+	case http.StatusForbidden:
+		if e.Permission != "" && strings.Contains(e.message(), "Resource not accessible by integration") {
+			return fmt.Sprintf("The token lacks the %s permission; grant it in the workflow's permissions block or the GitHub App's settings", e.Permission)
+		}
+	case http.StatusNotFound:
+		if e.Label != "" {
+			return fmt.Sprintf("The label %q is not on the PR or does not exist in the repository, e.g. because it was removed or renamed by hand; run `pr-kind-labeler audit` to recreate missing labels", e.Label)
+		}
+		return "The repository or PR does not exist, or the token cannot access it; check the token is scoped to the repository"
+	}
+	return ""
+}
+
+// response returns the HTTP response of the failed call, if any.
+func (e *APIError) response() *http.Response {
+	var errResp *github.ErrorResponse
+	if errors.As(e.Err, &errResp) {
+		return errResp.Response
+	}
+	return nil
+}
+
+// message returns GitHub's error message for the failed call.
+func (e *APIError) message() string {
+	var errResp *github.ErrorResponse
+	if errors.As(e.Err, &errResp) {
+		return errResp.Message
+	}
+	return ""
+}
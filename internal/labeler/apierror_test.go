@@ -0,0 +1,71 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		message   string
+		remaining string
+		want      []string
+	}{
+		{
+			name:      "label removed by hand",
+			status:    http.StatusNotFound,
+			message:   "Label does not exist",
+			remaining: "4321",
+			want:      []string{`failed to remove label "kind/fix"`, "(4321 API requests left)", `The label "kind/fix" is not on the PR`, "pr-kind-labeler audit"},
+		},
+		{
+			name:    "missing permission",
+			status:  http.StatusForbidden,
+			message: "Resource not accessible by integration",
+			want:    []string{`failed to remove label "kind/fix"`, "lacks the pull-requests: write permission"},
+		},
+		{
+			name:    "other failure",
+			status:  http.StatusInternalServerError,
+			message: "boom",
+			want:    []string{`failed to remove label "kind/fix"`, "boom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if tt.remaining != "" {
+							w.Header().Set("X-RateLimit-Remaining", tt.remaining)
+						}
+						mock.WriteError(w, tt.status, tt.message)
+					}),
+				),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false)
+			err := l.syncLabels(context.Background(), &Plan{RemoveLabels: []string{"kind/fix"}})
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected the error to contain %q, got %q", want, err)
+				}
+			}
+			if tt.status == http.StatusInternalServerError && apiErr.Remediation() != "" {
+				t.Errorf("expected no remediation for an uncommon failure, got %q", apiErr.Remediation())
+			}
+		})
+	}
+}
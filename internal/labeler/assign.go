@@ -0,0 +1,54 @@
+package labeler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// doNotMergePrefix is the prefix shared by labels that block merging.
+const doNotMergePrefix = "do-not-merge/"
+
+// WithTriage sets the triage rotation responsible for shepherding PRs that
+// get blocked. Entries are GitHub users, or org/team-slug for teams. When a
+// run newly applies a do-not-merge/* label, the PR is handed to one entry,
+// chosen by PR number so consecutive PRs rotate through the list.
+func (l *labeler) WithTriage(rotation []string) *labeler {
+	l.triage = rotation
+	return l
+}
+
+// triageAssignee returns the rotation entry responsible for this PR, or "" if
+// no do-not-merge/* label is being applied.
+func (l *labeler) triageAssignee() string {
+	if len(l.triage) == 0 {
+		return ""
+	}
+	for label := range l.labelsToAdd {
+		if strings.HasPrefix(label, doNotMergePrefix) {
+			return l.triage[l.prNum%len(l.triage)]
+		}
+	}
+	return ""
+}
+
+// syncTriage assigns the triage rotation entry of p to the PR. Users are
+// added as assignees; teams, which cannot be assignees, are requested as
+// reviewers.
+func (l *labeler) syncTriage(ctx context.Context, p *Plan) error {
+	assignee := p.TriageAssignee
+	if assignee == "" {
+		return nil
+	}
+	if _, team, ok := strings.Cut(assignee, "/"); ok {
+		if _, _, err := l.client.PullRequests.RequestReviewers(ctx, l.owner, l.repo, l.prNum, github.ReviewersRequest{TeamReviewers: []string{team}}); err != nil {
+			return apiError(err, permPullRequestsWrite, "request review from triage team %q", assignee)
+		}
+		return nil
+	}
+	if _, _, err := l.client.Issues.AddAssignees(ctx, l.owner, l.repo, l.prNum, []string{assignee}); err != nil {
+		return apiError(err, permPullRequestsWrite, "assign triage user %q", assignee)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestProcessPR_TriageRotation(t *testing.T) {
+	tests := []struct {
+		name          string
+		prNum         int
+		initialLabels []*github.Label
+		body          string
+		wantAssignees []string
+		wantTeams     []string
+	}{
+		{
+			name:          "blocked PR is assigned by rotation",
+			prNum:         3,
+			initialLabels: []*github.Label{},
+			body:          "/kind banana\n```release-note\nOK\n```",
+			wantAssignees: []string{"bob"},
+		},
+		{
+			name:          "teams are requested as reviewers",
+			prNum:         5,
+			initialLabels: []*github.Label{},
+			body:          "/kind banana\n```release-note\nOK\n```",
+			wantTeams:     []string{"maintainers"},
+		},
+		{
+			name:          "already blocked PR is not reassigned",
+			prNum:         3,
+			initialLabels: []*github.Label{{Name: github.Ptr(labels.InvalidKindLabel)}},
+			body:          "/kind banana\n```release-note\nOK\n```",
+		},
+		{
+			name:          "valid PR is not assigned",
+			prNum:         3,
+			initialLabels: []*github.Label{},
+			body:          "/kind fix\n```release-note\nFixed a crash.\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAssignees, gotTeams []string
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+					tt.initialLabels,
+				),
+				mock.WithRequestMatch(
+					mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+					[]*github.Label{},
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposIssuesAssigneesByOwnerByRepoByIssueNumber,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						var req struct {
+							Assignees []string `json:"assignees"`
+						}
+						if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+							t.Fatalf("AddAssignees Handler: failed to decode body: %v", err)
+						}
+						gotAssignees = req.Assignees
+						json.NewEncoder(w).Encode(github.Issue{})
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposPullsRequestedReviewersByOwnerByRepoByPullNumber,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						var req github.ReviewersRequest
+						if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+							t.Fatalf("RequestReviewers Handler: failed to decode body: %v", err)
+						}
+						gotTeams = req.TeamReviewers
+						json.NewEncoder(w).Encode(github.PullRequest{})
+					}),
+				),
+			)
+
+			rotation := []string{"alice", "kgateway-dev/maintainers", "carol", "bob"}
+			l := New(github.NewClient(httpClient), "owner", "repo", tt.prNum, false).WithTriage(rotation)
+			l.ProcessPR(context.Background(), tt.body, true)
+			if !reflect.DeepEqual(gotAssignees, tt.wantAssignees) {
+				t.Fatalf("expected assignees %v, got %v", tt.wantAssignees, gotAssignees)
+			}
+			if !reflect.DeepEqual(gotTeams, tt.wantTeams) {
+				t.Fatalf("expected team reviewers %v, got %v", tt.wantTeams, gotTeams)
+			}
+		})
+	}
+}
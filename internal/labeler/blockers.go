@@ -0,0 +1,122 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// blockersMarker identifies the merge blockers comment among the PR's comments.
+const blockersMarker = "<!-- pr-kind-labeler-merge-blockers -->"
+
+// blockerItemRE captures the labels of the checklist in a merge blockers
+// comment.
+var blockerItemRE = regexp.MustCompile("(?m)^- \\[[ x]\\] `([^`]+)`")
+
+// blockerHints tell authors how to clear the do-not-merge/* labels the
+// labeler applies. Other blockers, e.g. do-not-merge/hold, get a generic hint.
+var blockerHints = map[string]string{
+	labels.InvalidKindLabel:        "add a supported `/kind` command to the PR description.",
+	labels.InvalidReleaseNoteLabel: "add a valid `release-note` block to the PR description, or `NONE` if users are not affected.",
+	labels.InvalidDescriptionLabel: "fill out the `# Description` section of the PR description.",
+	labels.PossibleSecretLabel:     "remove the credential from the PR description and rotate it.",
+	labels.NeedsUpgradeDocsLabel:   "document the required action under the upgrade docs.",
+	labels.KindMismatchLabel:       "set the `/kind` that describes the change, or change the docs it documents.",
+}
+
+// WithMergeBlockersComment keeps a checklist comment of the PR's
+// do-not-merge/* labels and how to clear each, once more than one applies.
+// The comment is updated on every run, checking off blockers as they are
+// cleared, so authors need not decode label names.
+func (l *labeler) WithMergeBlockersComment() *labeler {
+	l.mergeBlockers = true
+	return l
+}
+
+// fetchBlockersComment looks up the merge blockers comment.
+func (l *labeler) fetchBlockersComment(ctx context.Context) error {
+	if !l.mergeBlockers {
+		return nil
+	}
+	comments, err := l.listComments(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		if strings.HasPrefix(c.GetBody(), blockersMarker) {
+			l.blockersComment = c
+			break
+		}
+	}
+	return nil
+}
+
+// MergeBlockers returns the do-not-merge/* labels the PR has after the last
+// evaluation, sorted.
+func (l *labeler) MergeBlockers() []string {
+	var blockers []string
+	for _, label := range l.Decision().FinalLabels() {
+		if strings.HasPrefix(label, doNotMergePrefix) {
+			blockers = append(blockers, label)
+		}
+	}
+	return blockers
+}
+
+// blockersCommentBody returns the merge blockers comment for the last
+// evaluation, and whether it should be posted given the existing comment,
+// if any. Blockers listed in the existing comment stay listed, checked off,
+// once cleared.
+func (l *labeler) blockersCommentBody(existing string) (string, bool) {
+	current := map[string]bool{}
+	for _, label := range l.MergeBlockers() {
+		current[label] = true
+	}
+	if existing == "" && len(current) < 2 {
+		return "", false
+	}
+	listed := map[string]bool{}
+	for label := range current {
+		listed[label] = true
+	}
+	for _, m := range blockerItemRE.FindAllStringSubmatch(existing, -1) {
+		listed[m[1]] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(blockersMarker + "\n### Merge blockers\n\n")
+	if len(current) == 0 {
+		sb.WriteString("All merge blockers are cleared.\n\n")
+	} else {
+		fmt.Fprintf(&sb, "This PR cannot be merged until %d blocker(s) are cleared:\n\n", len(current))
+	}
+	for _, label := range sortedKeys(listed) {
+		check := " "
+		if !current[label] {
+			check = "x"
+		}
+		hint, ok := blockerHints[label]
+		if !ok {
+			hint = "ask a maintainer what is needed to remove this label."
+		}
+		fmt.Fprintf(&sb, "- [%s] `%s`: %s\n", check, label, hint)
+	}
+	return sb.String(), true
+}
+
+// blockersCommentChange returns the change to the merge blockers comment for
+// the last evaluation, if any. A new comment is only posted if mayPost.
+func (l *labeler) blockersCommentChange(mayPost bool) *CommentChange {
+	if !l.mergeBlockers {
+		return nil
+	}
+	existing := l.blockersComment
+	body, ok := l.blockersCommentBody(existing.GetBody())
+	if !ok || (existing != nil && existing.GetBody() == body) || (existing == nil && !mayPost) {
+		return nil
+	}
+	return &CommentChange{ID: existing.GetID(), Body: body}
+}
@@ -0,0 +1,119 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestBlockersCommentBody(t *testing.T) {
+	const validBody = "# Description\nFix.\n/kind fix\n```release-note\nNONE\n```"
+	existing := blockersMarker + "\n### Merge blockers\n\n" +
+		"- [ ] `" + labels.InvalidKindLabel + "`: hint\n" +
+		"- [ ] `" + labels.InvalidReleaseNoteLabel + "`: hint\n"
+	tests := []struct {
+		name     string
+		body     string
+		current  []string
+		existing string
+		wantPost bool
+		want     []string
+	}{
+		{
+			name:     "several blockers",
+			body:     "no kind here",
+			wantPost: true,
+			want: []string{
+				"This PR cannot be merged until 3 blocker(s) are cleared:",
+				"- [ ] `" + labels.InvalidDescriptionLabel + "`: fill out",
+				"- [ ] `" + labels.InvalidKindLabel + "`: add a supported `/kind`",
+				"- [ ] `" + labels.InvalidReleaseNoteLabel + "`: add a valid `release-note` block",
+			},
+		},
+		{
+			name: "single blocker",
+			body: "# Description\nFix.\n/kind fix",
+		},
+		{
+			name:     "blockers not applied by the labeler",
+			body:     "# Description\nFix.\n/kind fix",
+			current:  []string{"do-not-merge/hold"},
+			wantPost: true,
+			want:     []string{"- [ ] `do-not-merge/hold`: ask a maintainer", "2 blocker(s)"},
+		},
+		{
+			name:     "some blockers cleared",
+			body:     "# Description\nFix.\n/kind fix",
+			current:  []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel},
+			existing: existing,
+			wantPost: true,
+			want:     []string{"1 blocker(s)", "- [x] `" + labels.InvalidKindLabel + "`", "- [ ] `" + labels.InvalidReleaseNoteLabel + "`"},
+		},
+		{
+			name:     "all blockers cleared",
+			body:     validBody,
+			current:  []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel},
+			existing: existing,
+			wantPost: true,
+			want:     []string{"All merge blockers are cleared.", "- [x] `" + labels.InvalidKindLabel + "`", "- [x] `" + labels.InvalidReleaseNoteLabel + "`"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, true).WithMergeBlockersComment()
+			l.Simulate(tt.body, tt.current)
+			got, post := l.blockersCommentBody(tt.existing)
+			if post != tt.wantPost {
+				t.Fatalf("post = %v, want %v", post, tt.wantPost)
+			}
+			if post && !strings.HasPrefix(got, blockersMarker+"\n") {
+				t.Fatalf("expected the comment to start with the marker, got %q", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("expected the comment to contain %q, got:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessPR_MergeBlockersComment(t *testing.T) {
+	var edited github.IssueComment
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{
+			{Name: github.Ptr(labels.InvalidKindLabel)},
+			{Name: github.Ptr(labels.InvalidReleaseNoteLabel)},
+		}),
+		mock.WithRequestMatch(mock.GetReposIssuesCommentsByOwnerByRepoByIssueNumber, []*github.IssueComment{
+			{ID: github.Ptr(int64(1)), Body: github.Ptr("LGTM")},
+			{ID: github.Ptr(int64(2)), Body: github.Ptr(blockersMarker + "\n- [ ] `" + labels.InvalidKindLabel + "`: hint\n")},
+		}),
+		mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+		mock.WithRequestMatch(mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName, nil, nil),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposIssuesCommentsByOwnerByRepoByCommentId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasSuffix(r.URL.Path, "/comments/2") {
+					t.Errorf("expected the merge blockers comment to be edited, got %s", r.URL.Path)
+				}
+				json.NewDecoder(r.Body).Decode(&edited)
+				w.Write(mock.MustMarshal(edited))
+			}),
+		),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithMergeBlockersComment()
+	if _, err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nNONE\n```", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(edited.GetBody(), "All merge blockers are cleared.") {
+		t.Fatalf("expected the comment to be updated, got %q", edited.GetBody())
+	}
+}
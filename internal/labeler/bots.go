@@ -0,0 +1,106 @@
+package labeler
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/changelog"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+)
+
+// DefaultBotAuthors are the dependency update bots whose PRs get a BotPolicy
+// when it names no authors.
+var DefaultBotAuthors = []string{"dependabot[bot]", "renovate[bot]"}
+
+// BotPolicy is how the PRs of dependency update bots, which never fill in
+// the PR template, are labeled instead of failing validation.
+type BotPolicy struct {
+	// Authors are the logins of the bots, DefaultBotAuthors if empty.
+	Authors []string `json:"authors,omitempty"`
+	// Kind is the kind their PRs default to, bump if empty.
+	Kind string `json:"kind,omitempty"`
+	// TitleNote sets the release note of their PRs from the PR title, e.g.
+	// "Bump golang.org/x/net from 0.30.0 to 0.33.0", rather than NONE.
+	TitleNote bool `json:"titleNote,omitempty"`
+}
+
+// kind returns the kind bot PRs default to.
+func (p *BotPolicy) kind() string {
+	if p.Kind == "" {
+		return kinds.Bump
+	}
+	return p.Kind
+}
+
+// isAuthor reports whether login is one of the bots.
+func (p *BotPolicy) isAuthor(login string) bool {
+	authors := p.Authors
+	if len(authors) == 0 {
+		authors = DefaultBotAuthors
+	}
+	for _, a := range authors {
+		if strings.EqualFold(a, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithBotPolicy labels the PRs of the bots of p: when the body sets no kind
+// they default to p's kind, a missing release note is NONE or, with
+// TitleNote, the PR title, and the description is not enforced, so
+// dependency updates pass validation without anyone editing them.
+func (l *labeler) WithBotPolicy(p BotPolicy) *labeler {
+	l.botPolicy = &p
+	return l
+}
+
+// BotAuthored reports whether the PR was opened by a bot of the bot policy.
+func (l *labeler) BotAuthored() bool {
+	return l.botAuthored
+}
+
+// fetchBotAuthor checks whether a bot of the bot policy opened the PR, and
+// gets the PR title for the release note if needed.
+func (l *labeler) fetchBotAuthor(ctx context.Context) error {
+	l.botAuthored = false
+	if l.botPolicy == nil {
+		return nil
+	}
+	login := l.authorLogin
+	if login == "" || l.botPolicy.TitleNote {
+		pr, err := l.pullRequest(ctx)
+		if err != nil {
+			return err
+		}
+		login = pr.GetUser().GetLogin()
+		l.prTitle = pr.GetTitle()
+	}
+	l.botAuthored = l.botPolicy.isAuthor(login)
+	return nil
+}
+
+// applyBotReleaseNote labels a bot PR without a release note block for the
+// release note of the bot policy.
+func (l *labeler) applyBotReleaseNote() {
+	note := botTitleNote(l.prTitle)
+	if !l.botPolicy.TitleNote || note == "" {
+		l.markNoneReleaseNote()
+		return
+	}
+	l.markReleaseNote()
+	l.releaseNote = &ReleaseNote{Note: note, Section: changelog.SectionFor(l.kinds, "")}
+}
+
+// botTitleNote turns the title of a bot PR into a release note, dropping a
+// conventional commit prefix such as "build(deps): ".
+func botTitleNote(title string) string {
+	note := strings.TrimSpace(conventionalCommitPrefixRE.ReplaceAllString(strings.TrimSpace(title), ""))
+	if note == "" {
+		return ""
+	}
+	r, size := utf8.DecodeRuneInString(note)
+	return string(unicode.ToUpper(r)) + note[size:]
+}
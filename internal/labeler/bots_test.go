@@ -0,0 +1,122 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestProcessPR_BotPolicy(t *testing.T) {
+	dependabotBody := "Bumps [golang.org/x/net](https://github.com/golang/net) from 0.30.0 to 0.33.0.\n\nDependabot will resolve any conflicts with this PR as long as you don't alter it yourself."
+	tests := []struct {
+		name     string
+		author   string
+		body     string
+		policy   *BotPolicy
+		wantBot  bool
+		wantAdd  []string
+		wantNote string
+		wantErr  bool
+	}{
+		{
+			name:    "dependabot PR defaults to bump and NONE",
+			author:  "dependabot[bot]",
+			body:    dependabotBody,
+			policy:  &BotPolicy{},
+			wantBot: true,
+			wantAdd: []string{"kind/bump", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:     "release note from the title",
+			author:   "renovate[bot]",
+			body:     dependabotBody,
+			policy:   &BotPolicy{TitleNote: true},
+			wantBot:  true,
+			wantAdd:  []string{"kind/bump", labels.ReleaseNoteLabel},
+			wantNote: "Bump golang.org/x/net from 0.30.0 to 0.33.0",
+		},
+		{
+			name:    "configured authors and kind",
+			author:  "kgateway-bot",
+			body:    dependabotBody,
+			policy:  &BotPolicy{Authors: []string{"kgateway-bot"}, Kind: "cleanup"},
+			wantBot: true,
+			wantAdd: []string{"kind/cleanup", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:     "explicit kind and note win",
+			author:   "dependabot[bot]",
+			body:     "# Description\nBump Envoy.\n/kind fix\n```release-note\nFix CVE-2025-1234 by bumping Envoy\n```",
+			policy:   &BotPolicy{},
+			wantBot:  true,
+			wantAdd:  []string{"kind/fix", labels.ReleaseNoteLabel},
+			wantNote: "Fix CVE-2025-1234 by bumping Envoy",
+		},
+		{
+			name:    "human author is validated",
+			author:  "alice",
+			body:    dependabotBody,
+			policy:  &BotPolicy{},
+			wantAdd: []string{labels.InvalidDescriptionLabel, labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel},
+			wantErr: true,
+		},
+		{
+			name:    "policy disabled",
+			author:  "dependabot[bot]",
+			body:    dependabotBody,
+			wantAdd: []string{labels.InvalidDescriptionLabel, labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.GetReposPullsByOwnerByRepoByPullNumber, &github.PullRequest{
+					Title: github.Ptr("build(deps): bump golang.org/x/net from 0.30.0 to 0.33.0"),
+					User:  &github.User{Login: github.Ptr(tt.author)},
+				}),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, true)
+			if tt.policy != nil {
+				l.WithBotPolicy(*tt.policy)
+			}
+			_, err := l.ProcessPR(context.Background(), tt.body, false)
+			if l.BotAuthored() != tt.wantBot {
+				t.Fatalf("BotAuthored() = %v, want %v", l.BotAuthored(), tt.wantBot)
+			}
+			if got := l.Decision().LabelsToAdd; !reflect.DeepEqual(got, tt.wantAdd) {
+				t.Fatalf("labels to add = %v, want %v", got, tt.wantAdd)
+			}
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			var note string
+			if n := l.Decision().ReleaseNote; n != nil {
+				note = n.Note
+			}
+			if note != tt.wantNote {
+				t.Errorf("release note = %q, want %q", note, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestBotTitleNote(t *testing.T) {
+	tests := map[string]string{
+		"Bump github.com/spf13/cobra from 1.8.0 to 1.9.1":               "Bump github.com/spf13/cobra from 1.8.0 to 1.9.1",
+		"chore(deps): update module sigs.k8s.io/yaml to v1.5.0":         "Update module sigs.k8s.io/yaml to v1.5.0",
+		"build(deps): bump the go-deps group across 1 directory with 3": "Bump the go-deps group across 1 directory with 3",
+		"chore: ": "",
+	}
+	for title, want := range tests {
+		if got := botTitleNote(title); got != want {
+			t.Errorf("botTitleNote(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
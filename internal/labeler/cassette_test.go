@@ -0,0 +1,111 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/transport"
+)
+
+// cassetteCase is the run a cassette in testdata/cassettes was recorded
+// with, in NAME.json next to the cassette NAME.jsonl, and what it decided.
+type cassetteCase struct {
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	Body   string `json:"body"`
+	// StickyComment and CheckRun enable the labeler's options of the same
+	// name, CheckRun with the given head commit.
+	StickyComment bool   `json:"stickyComment,omitempty"`
+	CheckRun      string `json:"checkRun,omitempty"`
+	// WantAdd and WantRemove are the label changes, WantProblem a substring
+	// of the validation failures and WantOperational one of the API
+	// failures, if any.
+	WantAdd         []string `json:"wantAdd"`
+	WantRemove      []string `json:"wantRemove"`
+	WantProblem     string   `json:"wantProblem,omitempty"`
+	WantOperational string   `json:"wantOperational,omitempty"`
+}
+
+// TestCassettes replays every cassette in testdata/cassettes, recorded with
+// --record against GitHub, as a hermetic integration test. Unlike the
+// handcrafted mocks, cassettes capture how GitHub really paginates, escapes
+// and fails. Every recorded request must still be made, so a cassette is
+// re-recorded when the labeler's API usage changes.
+func TestCassettes(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "cassettes", "*.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to list cassettes: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("expected at least one cassette in testdata/cassettes/")
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".jsonl")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(strings.TrimSuffix(file, ".jsonl") + ".json")
+			if err != nil {
+				t.Fatalf("failed to read the case of the cassette: %v", err)
+			}
+			var tc cassetteCase
+			if err := json.Unmarshal(data, &tc); err != nil {
+				t.Fatalf("failed to parse the case of the cassette: %v", err)
+			}
+			interactions, err := transport.LoadCassette(file)
+			if err != nil {
+				t.Fatalf("failed to load cassette: %v", err)
+			}
+			replayer := transport.NewReplayer(interactions)
+
+			l := New(github.NewClient(&http.Client{Transport: replayer}), tc.Owner, tc.Repo, tc.Number, false)
+			if tc.StickyComment {
+				l.WithStickyComment()
+			}
+			if tc.CheckRun != "" {
+				l.WithCheckRun(tc.CheckRun, true)
+			}
+			_, err = l.ProcessPR(context.Background(), tc.Body, true)
+			problems, operational := Partition(err)
+			checkFailures(t, "validation", problems, tc.WantProblem)
+			checkFailures(t, "API", operational, tc.WantOperational)
+			if len(operational) > 0 {
+				return
+			}
+			d := l.Decision()
+			if !reflect.DeepEqual(d.LabelsToAdd, append([]string{}, tc.WantAdd...)) {
+				t.Errorf("LabelsToAdd = %v, want %v", d.LabelsToAdd, tc.WantAdd)
+			}
+			if !reflect.DeepEqual(d.LabelsToRemove, append([]string{}, tc.WantRemove...)) {
+				t.Errorf("LabelsToRemove = %v, want %v", d.LabelsToRemove, tc.WantRemove)
+			}
+			for _, in := range replayer.Unreplayed() {
+				t.Errorf("recorded request %s %s was not made", in.Request.Method, in.Request.URL)
+			}
+		})
+	}
+}
+
+// checkFailures checks that failures, named what, contain want, or that
+// there are none if want is empty.
+func checkFailures(t *testing.T, what string, failures []error, want string) {
+	t.Helper()
+	var msgs []string
+	for _, err := range failures {
+		msgs = append(msgs, err.Error())
+	}
+	got := strings.Join(msgs, "\n")
+	switch {
+	case want == "" && got != "":
+		t.Errorf("unexpected %s failures: %s", what, got)
+	case !strings.Contains(got, want):
+		t.Errorf("%s failures = %q, want %q", what, got, want)
+	}
+}
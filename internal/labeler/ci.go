@@ -0,0 +1,118 @@
+package labeler
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/prlock"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// failedConclusions are the check run conclusions that count as failing CI.
+var failedConclusions = []string{"failure", "timed_out", "startup_failure"}
+
+// WithCILabels labels PRs ci/failing while a check run or commit status on
+// their head commit fails, and unlabels them once none does, e.g. after a
+// push or a re-run. The labeler's own checks are not counted. It is meant to
+// also run on check_suite and status events.
+func (l *labeler) WithCILabels() *labeler {
+	l.ciLabels = true
+	return l
+}
+
+// WithStaleApprovalReset removes label, e.g. lgtm, from PRs that got new
+// commits after their latest approving review, so the approval is renewed
+// for the code that merges. PRs without approving reviews keep the label, as
+// it may have been given another way.
+func (l *labeler) WithStaleApprovalReset(label string) *labeler {
+	l.approvalLabel = label
+	return l
+}
+
+// fetchCI looks up the CI results of the PR head commit and the commit its
+// latest approval was given on.
+func (l *labeler) fetchCI(ctx context.Context) error {
+	if !l.ciLabels && l.approvalLabel == "" {
+		return nil
+	}
+	sha, err := l.resolveHeadSHA(ctx)
+	if err != nil {
+		return err
+	}
+	if l.ciLabels {
+		if l.ciFailures, err = l.failingChecks(ctx, sha); err != nil {
+			return err
+		}
+	}
+	if l.approvalLabel != "" {
+		reviews, err := l.listReviews(ctx)
+		if err != nil {
+			return err
+		}
+		l.approvedSHA = ""
+		for _, r := range reviews {
+			if strings.EqualFold(r.GetState(), "APPROVED") {
+				l.approvedSHA = r.GetCommitID()
+			}
+		}
+	}
+	return nil
+}
+
+// failingChecks returns the names of the failing check runs and commit
+// statuses on sha, other than the labeler's own.
+func (l *labeler) failingChecks(ctx context.Context, sha string) ([]string, error) {
+	own := []string{CheckRunName, prlock.CheckRunName}
+	var failing []string
+	opts := &github.ListCheckRunsOptions{Filter: github.Ptr("latest"), ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		runs, resp, err := l.client.Checks.ListCheckRunsForRef(ctx, l.owner, l.repo, sha, opts)
+		if err != nil {
+			return nil, apiError(err, permChecksRead, "list check runs")
+		}
+		for _, run := range runs.CheckRuns {
+			if slices.Contains(failedConclusions, run.GetConclusion()) && !slices.Contains(own, run.GetName()) {
+				failing = append(failing, run.GetName())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	status, _, err := l.client.Repositories.GetCombinedStatus(ctx, l.owner, l.repo, sha, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, apiError(err, permStatusesRead, "get commit status")
+	}
+	for _, s := range status.Statuses {
+		if (s.GetState() == "failure" || s.GetState() == "error") && !slices.Contains(own, s.GetContext()) {
+			failing = append(failing, s.GetContext())
+		}
+	}
+	return failing, nil
+}
+
+// CIFailures returns the failing checks of the PR head commit, if CI labels
+// are enabled.
+func (l *labeler) CIFailures() []string {
+	return l.ciFailures
+}
+
+// processCILabels labels the PR ci/failing while its CI fails, and removes
+// its approval label once it is stale.
+func (l *labeler) processCILabels() {
+	if l.ciLabels {
+		switch failing := len(l.ciFailures) > 0; {
+		case failing && !l.currentMap[labels.CIFailingLabel]:
+			l.labelsToAdd[labels.CIFailingLabel] = true
+		case !failing && l.currentMap[labels.CIFailingLabel]:
+			l.labelsToRemove[labels.CIFailingLabel] = true
+		}
+	}
+	if l.approvalLabel != "" && l.currentMap[l.approvalLabel] && l.approvedSHA != "" && l.approvedSHA != l.headSHA {
+		l.labelsToRemove[l.approvalLabel] = true
+	}
+}
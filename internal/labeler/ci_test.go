@@ -0,0 +1,93 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/prlock"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestDecide_CILabels(t *testing.T) {
+	body := "/kind fix\n```release-note\nFixed a crash.\n```"
+	tests := []struct {
+		name          string
+		checkRuns     []*github.CheckRun
+		statuses      []*github.RepoStatus
+		reviews       []*github.PullRequestReview
+		currentLabels []string
+		wantFailures  []string
+		wantAdd       []string
+		wantRemove    []string
+	}{
+		{
+			name: "failing check run and status",
+			checkRuns: []*github.CheckRun{
+				{Name: github.Ptr("unit"), Conclusion: github.Ptr("failure")},
+				{Name: github.Ptr("lint"), Conclusion: github.Ptr("success")},
+				{Name: github.Ptr("e2e"), Status: github.Ptr("in_progress")},
+			},
+			statuses:     []*github.RepoStatus{{Context: github.Ptr("ci/prow"), State: github.Ptr("error")}},
+			wantFailures: []string{"unit", "ci/prow"},
+			wantAdd:      []string{labels.CIFailingLabel, "kind/fix", labels.ReleaseNoteLabel},
+		},
+		{
+			name: "own checks are not counted",
+			checkRuns: []*github.CheckRun{
+				{Name: github.Ptr(CheckRunName), Conclusion: github.Ptr("failure")},
+				{Name: github.Ptr(prlock.CheckRunName), Conclusion: github.Ptr("timed_out")},
+			},
+			statuses:      []*github.RepoStatus{{Context: github.Ptr(CheckRunName), State: github.Ptr("failure")}},
+			currentLabels: []string{"kind/fix", labels.ReleaseNoteLabel, labels.CIFailingLabel},
+			wantRemove:    []string{labels.CIFailingLabel},
+		},
+		{
+			name:          "approval of an older commit is reset",
+			reviews:       []*github.PullRequestReview{{State: github.Ptr("APPROVED"), CommitID: github.Ptr("old")}},
+			currentLabels: []string{"kind/fix", labels.ReleaseNoteLabel, "lgtm"},
+			wantRemove:    []string{"lgtm"},
+		},
+		{
+			name: "approval of the head commit is kept",
+			reviews: []*github.PullRequestReview{
+				{State: github.Ptr("APPROVED"), CommitID: github.Ptr("old")},
+				{State: github.Ptr("APPROVED"), CommitID: github.Ptr("head")},
+			},
+			currentLabels: []string{"kind/fix", labels.ReleaseNoteLabel, "lgtm"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantAdd, wantRemove := append([]string{}, tt.wantAdd...), append([]string{}, tt.wantRemove...)
+			var current []*github.Label
+			for _, name := range tt.currentLabels {
+				current = append(current, &github.Label{Name: github.Ptr(name)})
+			}
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, current),
+				mock.WithRequestMatch(mock.GetReposCommitsCheckRunsByOwnerByRepoByRef, &github.ListCheckRunsResults{CheckRuns: tt.checkRuns}),
+				mock.WithRequestMatch(mock.GetReposCommitsStatusByOwnerByRepoByRef, &github.CombinedStatus{Statuses: tt.statuses}),
+				mock.WithRequestMatch(mock.GetReposPullsReviewsByOwnerByRepoByPullNumber, tt.reviews),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithCILabels().WithStaleApprovalReset("lgtm")
+			l.headSHA = "head"
+			d, err := l.Decide(context.Background(), body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(l.CIFailures(), tt.wantFailures) {
+				t.Errorf("CI failures = %v, want %v", l.CIFailures(), tt.wantFailures)
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, wantAdd) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, wantAdd)
+			}
+			if !reflect.DeepEqual(d.LabelsToRemove, wantRemove) {
+				t.Errorf("labels to remove = %v, want %v", d.LabelsToRemove, wantRemove)
+			}
+		})
+	}
+}
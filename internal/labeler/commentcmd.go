@@ -0,0 +1,68 @@
+package labeler
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// WithCommentCommands also takes /kind commands from comments on the PR, so
+// maintainers can set the kind of someone else's PR without editing its
+// description. Only comments by users with write, maintain or admin
+// permission on the repository count; the kinds they give add to those of
+// the PR body, as those of review commands do.
+func (l *labeler) WithCommentCommands() *labeler {
+	l.commentCommands = true
+	return l
+}
+
+// fetchCommentCommands looks up the comments whose /kind commands add to the
+// PR body's, checking the permission of their authors.
+func (l *labeler) fetchCommentCommands(ctx context.Context) error {
+	if !l.commentCommands {
+		return nil
+	}
+	comments, err := l.listComments(ctx)
+	if err != nil {
+		return err
+	}
+	l.commentBodies, l.ignoredCommenters = nil, nil
+	for _, c := range comments {
+		body := strings.ReplaceAll(c.GetBody(), "\r\n", "\n")
+		// the sticky comment lists /kind commands to copy
+		if c.GetUser().GetType() == "Bot" || len(scanKinds(l.normalizeKindCommands(body))) == 0 {
+			continue
+		}
+		login := c.GetUser().GetLogin()
+		ok, err := l.canWrite(ctx, login)
+		if err != nil {
+			return err
+		}
+		switch {
+		case ok:
+			l.commentBodies = append(l.commentBodies, body)
+		case !slices.Contains(l.ignoredCommenters, login):
+			l.ignoredCommenters = append(l.ignoredCommenters, login)
+		}
+	}
+	return nil
+}
+
+// canWrite reports whether user has write, maintain or admin permission on
+// the repository. Answers are cached for the run.
+func (l *labeler) canWrite(ctx context.Context, user string) (bool, error) {
+	if ok, cached := l.writers[user]; cached {
+		return ok, nil
+	}
+	level, _, err := l.client.Repositories.GetPermissionLevel(ctx, l.owner, l.repo, user)
+	if err != nil {
+		return false, apiError(err, "", "get the repository permission of %s", user)
+	}
+	// maintain and custom roles based on write are reported as write
+	ok := level.GetPermission() == "admin" || level.GetPermission() == "write"
+	if l.writers == nil {
+		l.writers = map[string]bool{}
+	}
+	l.writers[user] = ok
+	return ok, nil
+}
@@ -0,0 +1,69 @@
+package labeler
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestDecide_CommentCommands(t *testing.T) {
+	comment := func(login, userType, body string) *github.IssueComment {
+		return &github.IssueComment{User: &github.User{Login: github.Ptr(login), Type: github.Ptr(userType)}, Body: github.Ptr(body)}
+	}
+	comments := []*github.IssueComment{
+		comment("alice", "User", "Thanks!\r\n/kind cleanup"),
+		comment("mallory", "User", "/kind breaking_change"),
+		comment("bob", "User", "/kind bug_fix"),
+		comment("mallory", "User", "/kind feature"),
+		comment("github-actions[bot]", "Bot", "```\n/kind install\n```"),
+		comment("carol", "User", "LGTM"),
+	}
+	permissions := map[string]string{"alice": "write", "bob": "admin", "mallory": "read"}
+	var checked []string
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+		mock.WithRequestMatch(mock.GetReposIssuesCommentsByOwnerByRepoByIssueNumber, comments),
+		mock.WithRequestMatchHandler(
+			mock.GetReposCollaboratorsPermissionByOwnerByRepoByUsername,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user := path.Base(strings.TrimSuffix(r.URL.Path, "/permission"))
+				checked = append(checked, user)
+				w.Write(mock.MustMarshal(github.RepositoryPermissionLevel{Permission: github.Ptr(permissions[user])}))
+			}),
+		),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithCommentCommands()
+	d, err := l.Decide(context.Background(), "/kind fix\n```release-note\nFixed a crash.\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"kind/cleanup", "kind/fix", labels.ReleaseNoteLabel}
+	if !reflect.DeepEqual(d.LabelsToAdd, want) {
+		t.Fatalf("labels to add = %v, want %v", d.LabelsToAdd, want)
+	}
+	if want := []string{"alice", "mallory", "bob"}; !reflect.DeepEqual(checked, want) {
+		t.Errorf("checked the permission of %v, want %v once each", checked, want)
+	}
+	if want := "The /kind commands of @mallory were ignored"; !strings.Contains(l.Summary(), want) {
+		t.Errorf("summary lacks %q:\n%s", want, l.Summary())
+	}
+}
+
+// permissionLevels mocks the repository permission of users, e.g. write.
+func permissionLevels(permissions map[string]string) mock.MockBackendOption {
+	return mock.WithRequestMatchHandler(
+		mock.GetReposCollaboratorsPermissionByOwnerByRepoByUsername,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := path.Base(strings.TrimSuffix(r.URL.Path, "/permission"))
+			w.Write(mock.MustMarshal(github.RepositoryPermissionLevel{Permission: github.Ptr(permissions[user])}))
+		}),
+	)
+}
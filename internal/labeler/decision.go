@@ -0,0 +1,150 @@
+package labeler
+
+import "context"
+
+// Decision records a PR body and its labels together with the label changes
+// the labeler computed for them. Golden test fixtures are serialized
+// decisions.
+type Decision struct {
+	// Source identifies where the decision was captured from, e.g. owner/repo/123.
+	Source                          string       `json:"source,omitempty"`
+	Body                            string       `json:"body"`
+	CurrentLabels                   []string     `json:"currentLabels"`
+	ChangedFiles                    []string     `json:"changedFiles,omitempty"`
+	EnforceDescription              bool         `json:"enforceDescription"`
+	EnforceReleaseNoteQuality       bool         `json:"enforceReleaseNoteQuality"`
+	EnforceChangelogKindExclusivity bool         `json:"enforceChangelogKindExclusivity"`
+	LabelsToAdd                     []string     `json:"labelsToAdd"`
+	LabelsToRemove                  []string     `json:"labelsToRemove"`
+	Milestone                       string       `json:"milestone,omitempty"`
+	ReleaseNote                     *ReleaseNote `json:"releaseNote,omitempty"`
+	Error                           string       `json:"error,omitempty"`
+	// DeprecatedKinds are the deprecated kinds, e.g. bug_fix, the PR body or
+	// labels still use, to tell when their migration can be removed.
+	DeprecatedKinds []string `json:"deprecatedKinds,omitempty"`
+	// DeadLinks are the URLs of the release note that did not resolve, if
+	// they were checked.
+	DeadLinks []DeadLink `json:"deadLinks,omitempty"`
+}
+
+// ReleaseNote is a release note parsed from a PR body.
+type ReleaseNote struct {
+	// Note is the release note, without its category prefix.
+	Note string `json:"note"`
+	// Category is the changelog section the note's [category] prefix files
+	// it under regardless of kind, if it has one.
+	Category string `json:"category,omitempty"`
+	// Section is the ID of the changelog section the note is published in,
+	// or "" if none lists notes of the PR's kinds.
+	Section string `json:"section,omitempty"`
+	// SetBy is the maintainer who set the note with a /release-note comment,
+	// or "" if it came from the PR body.
+	SetBy string `json:"setBy,omitempty"`
+	// Epic is the tracking issue the note is deferred to with
+	// /release-note-epic, whose note the changelog publishes once for all
+	// the PRs of the epic; Note is empty then.
+	Epic int `json:"epic,omitempty"`
+	// StackedOn is the PR the note is inherited from with /stacked-on, whose
+	// note the changelog lists for the whole stack; Note is empty then.
+	StackedOn int `json:"stackedOn,omitempty"`
+}
+
+// Decide fetches the current labels and evaluates body without syncing
+// labels, as Plan does. Only API failures are returned as errors; validation
+// failures are recorded in the decision.
+func (l *labeler) Decide(ctx context.Context, body string) (*Decision, error) {
+	p, err := l.Plan(ctx, body)
+	if p == nil {
+		return nil, err
+	}
+	d := l.Decision()
+	d.CurrentLabels, d.LabelsToAdd, d.LabelsToRemove = p.CurrentLabels, p.AddLabels, p.RemoveLabels
+	return d, nil
+}
+
+// Simulate evaluates body against currentLabels without calling the GitHub
+// API, e.g. to validate a PR description from a local checkout before
+// pushing. The returned error holds the validation failures, if any. The
+// release note's links are not checked; see CheckLinks.
+func (l *labeler) Simulate(body string, currentLabels []string) (*Decision, error) {
+	l.currentMap = map[string]bool{}
+	for _, label := range currentLabels {
+		l.currentMap[l.defaultLabel(label)] = true
+	}
+	return l.decide(body)
+}
+
+func (l *labeler) decide(body string) (*Decision, error) {
+	l.evaluate(body)
+	return l.Decision(), l.validationErr()
+}
+
+// Decision returns the decision made by the last evaluation of the PR body.
+func (l *labeler) Decision() *Decision {
+	d := &Decision{
+		Body:                            l.body,
+		CurrentLabels:                   l.repoLabels(l.currentMap),
+		ChangedFiles:                    l.changedFiles,
+		EnforceDescription:              l.enforceDescription,
+		EnforceReleaseNoteQuality:       l.enforceReleaseNoteQuality,
+		EnforceChangelogKindExclusivity: l.enforceChangelogKindExclusivity,
+		LabelsToAdd:                     l.repoLabels(l.labelsToAdd),
+		LabelsToRemove:                  l.repoLabels(l.labelsToRemove),
+		Milestone:                       l.milestone,
+		ReleaseNote:                     l.releaseNote,
+		DeadLinks:                       l.deadLinks,
+	}
+	if len(l.deprecatedKinds) > 0 {
+		d.DeprecatedKinds = sortedKeys(l.deprecatedKinds)
+	}
+	if err := l.validationErr(); err != nil {
+		d.Error = err.Error()
+	}
+	return d
+}
+
+// Kinds returns the kinds the last evaluation found, from the PR body or
+// inherited, sorted.
+func (l *labeler) Kinds() []string {
+	return sortedKeys(l.kinds)
+}
+
+// validationErr joins the validation failures of the last evaluation.
+func (l *labeler) validationErr() error {
+	var errs []error
+	for _, err := range l.problems {
+		v, ok := err.(*ValidationError)
+		if !ok {
+			v = &ValidationError{Err: err}
+		}
+		errs = append(errs, v)
+	}
+	return joinErrs(errs...)
+}
+
+// FinalLabels returns the labels the PR has once the decision is applied.
+func (d *Decision) FinalLabels() []string {
+	return finalLabels(d.CurrentLabels, d.LabelsToAdd, d.LabelsToRemove)
+}
+
+// finalLabels returns current with add added and remove removed, sorted.
+func finalLabels(current, add, remove []string) []string {
+	final := map[string]bool{}
+	for _, label := range current {
+		final[label] = true
+	}
+	for _, label := range remove {
+		delete(final, label)
+	}
+	for _, label := range add {
+		final[label] = true
+	}
+	return sortedKeys(final)
+}
+
+// WithChangedFiles sets the paths the PR changes, e.g. from `git diff
+// --name-only` when running against a local checkout.
+func (l *labeler) WithChangedFiles(files []string) *labeler {
+	l.changedFiles = files
+	return l
+}
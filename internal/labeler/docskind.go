@@ -0,0 +1,60 @@
+package labeler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// docsKind is the kind checked WithDocsKindCheck.
+const docsKind = kinds.Documentation
+
+// DefaultDocsPaths is where documentation lives unless configured otherwise.
+var DefaultDocsPaths = []string{"docs/**", "**/*.md"}
+
+// WithDocsKindCheck requires /kind documentation PRs to change a file
+// matching one of paths, e.g. docs/**, so miscategorized PRs do not land in
+// the documentation section of the changelog. PRs that don't are labeled
+// do-not-merge/kind-mismatch, and PRs that are only /kind documentation but
+// change Go code get a warning. Empty paths use DefaultDocsPaths.
+func (l *labeler) WithDocsKindCheck(paths []string) *labeler {
+	if len(paths) == 0 {
+		paths = DefaultDocsPaths
+	}
+	l.docsKind = true
+	l.docsPaths = paths
+	return l
+}
+
+// DocsKindCode returns the Go files a /kind documentation PR changes, which
+// suggest it is miscategorized.
+func (l *labeler) DocsKindCode() []string {
+	return l.docsKindCode
+}
+
+// processDocsKind checks that a /kind documentation PR changes docs.
+func (l *labeler) processDocsKind() error {
+	l.docsKindCode = nil
+	if !l.docsKind {
+		return nil
+	}
+	if !l.kinds[docsKind] || len(l.changedFiles) == 0 || l.changesAny(l.docsPaths) {
+		if l.currentMap[labels.KindMismatchLabel] {
+			l.labelsToRemove[labels.KindMismatchLabel] = true
+		}
+		if len(l.kinds) == 1 && l.kinds[docsKind] {
+			for _, f := range l.changedFiles {
+				if strings.HasSuffix(f, ".go") {
+					l.docsKindCode = append(l.docsKindCode, f)
+				}
+			}
+		}
+		return nil
+	}
+	if !l.currentMap[labels.KindMismatchLabel] {
+		l.labelsToAdd[labels.KindMismatchLabel] = true
+	}
+	return fmt.Errorf("the PR is /kind %s but changes nothing under %s; please set the kind that describes the change, so its note lands in the right changelog section", docsKind, strings.Join(l.docsPaths, ", "))
+}
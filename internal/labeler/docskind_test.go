@@ -0,0 +1,91 @@
+package labeler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestSimulate_DocsKind(t *testing.T) {
+	docs := "/kind documentation\n```release-note\nNONE\n```"
+	tests := []struct {
+		name          string
+		body          string
+		files         []string
+		paths         []string
+		currentLabels []string
+		wantAdd       []string
+		wantRemove    []string
+		wantCode      []string
+		wantErr       bool
+	}{
+		{
+			name:    "docs changes",
+			body:    docs,
+			files:   []string{"docs/guides/routing.md"},
+			wantAdd: []string{"kind/documentation", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:    "no docs changes",
+			body:    docs,
+			files:   []string{"pkg/routing/route.go"},
+			wantAdd: []string{labels.KindMismatchLabel, "kind/documentation", labels.ReleaseNoteNoneLabel},
+			wantErr: true,
+		},
+		{
+			name:     "docs and Go changes",
+			body:     docs,
+			files:    []string{"README.md", "pkg/routing/route.go"},
+			wantAdd:  []string{"kind/documentation", labels.ReleaseNoteNoneLabel},
+			wantCode: []string{"pkg/routing/route.go"},
+		},
+		{
+			name:    "Go changes of another kind too",
+			body:    "/kind documentation\n/kind fix\n```release-note\nFixed routing.\n```",
+			files:   []string{"README.md", "pkg/routing/route.go"},
+			wantAdd: []string{"kind/documentation", "kind/fix", labels.ReleaseNoteLabel},
+		},
+		{
+			name:    "configured docs paths",
+			body:    docs,
+			files:   []string{"README.md"},
+			paths:   []string{"site/**"},
+			wantAdd: []string{labels.KindMismatchLabel, "kind/documentation", labels.ReleaseNoteNoneLabel},
+			wantErr: true,
+		},
+		{
+			name:          "kind changed clears the label",
+			body:          "/kind cleanup\n```release-note\nNONE\n```",
+			files:         []string{"pkg/routing/route.go"},
+			currentLabels: []string{labels.KindMismatchLabel, "kind/documentation", labels.ReleaseNoteNoneLabel},
+			wantAdd:       []string{"kind/cleanup"},
+			wantRemove:    []string{labels.KindMismatchLabel, "kind/documentation"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false).WithDocsKindCheck(tt.paths).WithChangedFiles(tt.files)
+			d, err := l.Simulate(tt.body, tt.currentLabels)
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if tt.wantRemove == nil {
+				tt.wantRemove = []string{}
+			}
+			if !reflect.DeepEqual(d.LabelsToRemove, tt.wantRemove) {
+				t.Errorf("labels to remove = %v, want %v", d.LabelsToRemove, tt.wantRemove)
+			}
+			if !reflect.DeepEqual(l.DocsKindCode(), tt.wantCode) {
+				t.Errorf("DocsKindCode() = %v, want %v", l.DocsKindCode(), tt.wantCode)
+			}
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "changes nothing under") {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// TestE2E runs the labeler against a real scratch repository, as the mocks
+// miss API quirks such as how label names are escaped in URLs. It opens a PR
+// in the repository named by PR_KIND_LABELER_E2E_REPO, in the owner/repo
+// format, with the token in PR_KIND_LABELER_E2E_TOKEN, and closes it and
+// deletes its branch when done. It is skipped unless both are set.
+func TestE2E(t *testing.T) {
+	fullName, token := os.Getenv("PR_KIND_LABELER_E2E_REPO"), os.Getenv("PR_KIND_LABELER_E2E_TOKEN")
+	if fullName == "" || token == "" {
+		t.Skip("PR_KIND_LABELER_E2E_REPO and PR_KIND_LABELER_E2E_TOKEN are not set")
+	}
+	owner, repo, ok := strings.Cut(fullName, "/")
+	if !ok {
+		t.Fatalf("PR_KIND_LABELER_E2E_REPO = %q, want owner/repo", fullName)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	client := github.NewClient(nil).WithAuthToken(token)
+	pr := openScratchPR(ctx, t, client, owner, repo)
+
+	valid := "# Description\n\nExercises the labeler end to end.\n\n/kind fix\n\n```release-note\nFixed the labeler end to end.\n```\n"
+	invalid := strings.Replace(valid, "/kind fix", "/kind not-a-kind", 1)
+	steps := []struct {
+		name       string
+		body       string
+		wantValid  bool
+		wantLabels []string
+		conclusion string
+	}{
+		{
+			name:       "invalid kind",
+			body:       invalid,
+			wantLabels: []string{labels.InvalidKindLabel, labels.ReleaseNoteLabel},
+			conclusion: "neutral",
+		},
+		{
+			// removes a label whose name needs escaping in the URL
+			name:       "fixed kind",
+			body:       valid,
+			wantValid:  true,
+			wantLabels: []string{"kind/fix", labels.ReleaseNoteLabel},
+			conclusion: "success",
+		},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			l := New(client, owner, repo, pr.GetNumber(), true).WithStickyComment().WithCheckRun(pr.GetHead().GetSHA(), false)
+			_, err := l.ProcessPR(ctx, step.body, true)
+			validation, operational := Partition(err)
+			if len(operational) > 0 {
+				t.Fatalf("ProcessPR failed: %v", err)
+			}
+			if valid := len(validation) == 0; valid != step.wantValid {
+				t.Fatalf("ProcessPR() = %v, want valid %v", err, step.wantValid)
+			}
+
+			got, _, err := client.Issues.ListLabelsByIssue(ctx, owner, repo, pr.GetNumber(), nil)
+			if err != nil {
+				t.Fatalf("failed to list labels: %v", err)
+			}
+			var names []string
+			for _, label := range got {
+				names = append(names, label.GetName())
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, step.wantLabels) {
+				t.Errorf("labels = %v, want %v", names, step.wantLabels)
+			}
+
+			comment, err := l.findComment(ctx)
+			if err != nil {
+				t.Fatalf("failed to find sticky comment: %v", err)
+			}
+			if comment == nil {
+				t.Error("no sticky comment")
+			}
+
+			runs, _, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, pr.GetHead().GetSHA(), &github.ListCheckRunsOptions{
+				CheckName: github.Ptr(CheckRunName),
+				Filter:    github.Ptr("latest"),
+			})
+			if err != nil {
+				t.Fatalf("failed to list check runs: %v", err)
+			}
+			if len(runs.CheckRuns) == 0 {
+				t.Fatal("no check run")
+			}
+			if got := runs.CheckRuns[0].GetConclusion(); got != step.conclusion {
+				t.Errorf("check run conclusion = %q, want %q", got, step.conclusion)
+			}
+		})
+	}
+}
+
+// openScratchPR opens a PR changing one file on a new branch of owner/repo,
+// and closes it and deletes the branch when the test ends.
+func openScratchPR(ctx context.Context, t *testing.T, client *github.Client, owner, repo string) *github.PullRequest {
+	t.Helper()
+	r, _, err := client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		t.Fatalf("failed to get repository: %v", err)
+	}
+	base, _, err := client.Git.GetRef(ctx, owner, repo, "heads/"+r.GetDefaultBranch())
+	if err != nil {
+		t.Fatalf("failed to get default branch: %v", err)
+	}
+	branch := fmt.Sprintf("pr-kind-labeler-e2e-%d", time.Now().UnixNano())
+	_, _, err = client.Git.CreateRef(ctx, owner, repo, &github.Reference{Ref: github.Ptr("refs/heads/" + branch), Object: base.Object})
+	if err != nil {
+		t.Fatalf("failed to create branch: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := client.Git.DeleteRef(context.Background(), owner, repo, "heads/"+branch); err != nil {
+			t.Logf("failed to delete branch %s: %v", branch, err)
+		}
+	})
+	_, _, err = client.Repositories.CreateFile(ctx, owner, repo, branch+".txt", &github.RepositoryContentFileOptions{
+		Message: github.Ptr("Add e2e scratch file"),
+		Content: []byte("Created by the pr-kind-labeler e2e test.\n"),
+		Branch:  github.Ptr(branch),
+	})
+	if err != nil {
+		t.Fatalf("failed to commit to branch: %v", err)
+	}
+	pr, _, err := client.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: github.Ptr("pr-kind-labeler e2e " + branch),
+		Head:  github.Ptr(branch),
+		Base:  github.Ptr(r.GetDefaultBranch()),
+		Body:  github.Ptr("Opened by the pr-kind-labeler e2e test."),
+	})
+	if err != nil {
+		t.Fatalf("failed to open PR: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _, err := client.PullRequests.Edit(context.Background(), owner, repo, pr.GetNumber(), &github.PullRequest{State: github.Ptr("closed")})
+		if err != nil {
+			t.Logf("failed to close PR #%d: %v", pr.GetNumber(), err)
+		}
+	})
+	return pr
+}
@@ -0,0 +1,37 @@
+package labeler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/changelog"
+)
+
+// epicCommandRE captures the tracking issue of a /release-note-epic #N
+// command in a PR body.
+var epicCommandRE = regexp.MustCompile(`(?m)^[ \t]*/release-note-epic[ \t]+#(\d+)[ \t]*$`)
+
+// epicOf returns the tracking issue body defers its release note to with a
+// /release-note-epic command, or 0 if it does not.
+func epicOf(body string) int {
+	m := epicCommandRE.FindStringSubmatch(body)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// processEpic labels the PR for a release note deferred to the tracking
+// issue epic, whose note the changelog publishes once for all the PRs of the
+// epic instead of a fragment per PR.
+func (l *labeler) processEpic(epic int) error {
+	if epic == l.prNum {
+		l.markInvalidReleaseNote()
+		return fmt.Errorf("/release-note-epic must name the tracking issue of the epic, not this PR")
+	}
+	l.releaseNote = &ReleaseNote{Epic: epic, Section: changelog.SectionFor(l.kinds, "")}
+	l.markReleaseNote()
+	return nil
+}
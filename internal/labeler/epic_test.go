@@ -0,0 +1,62 @@
+package labeler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestSimulate_ReleaseNoteEpic(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantAdd  []string
+		wantNote *ReleaseNote
+		wantErr  string
+	}{
+		{
+			name:     "deferred to the tracking issue",
+			body:     "/kind feature\n/release-note-epic #100",
+			wantAdd:  []string{"kind/feature", labels.ReleaseNoteLabel},
+			wantNote: &ReleaseNote{Epic: 100, Section: "feature"},
+		},
+		{
+			name:     "takes precedence over the release note block",
+			body:     "/kind feature\n/release-note-epic #100\n```release-note\nAdded part of the frobnicator.\n```",
+			wantAdd:  []string{"kind/feature", labels.ReleaseNoteLabel},
+			wantNote: &ReleaseNote{Epic: 100, Section: "feature"},
+		},
+		{
+			name:    "not this PR",
+			body:    "/kind feature\n/release-note-epic #1",
+			wantAdd: []string{labels.InvalidReleaseNoteLabel, "kind/feature"},
+			wantErr: "not this PR",
+		},
+		{
+			name:    "needs an issue number",
+			body:    "/kind feature\n/release-note-epic frobnicator",
+			wantAdd: []string{labels.InvalidReleaseNoteLabel, "kind/feature"},
+			wantErr: "missing or empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false)
+			d, err := l.Simulate(tt.body, nil)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if !reflect.DeepEqual(d.ReleaseNote, tt.wantNote) {
+				t.Errorf("release note = %+v, want %+v", d.ReleaseNote, tt.wantNote)
+			}
+			if tt.wantNote != nil && !strings.Contains(l.Summary(), "deferred to the tracking issue #100") {
+				t.Errorf("expected the summary to name the tracking issue, got:\n%s", l.Summary())
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package labeler
+
+import (
+	"errors"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/secrets"
+)
+
+// Check is the part of the PR a validation failure is about, so callers can
+// tell failures apart without matching messages.
+type Check string
+
+const (
+	// CheckKind is a problem with the /kind commands.
+	CheckKind Check = "kind"
+	// CheckReleaseNote is a problem with the release note.
+	CheckReleaseNote Check = "release-note"
+	// CheckDescription is a problem with the rest of the description, such
+	// as an empty one.
+	CheckDescription Check = "description"
+	// CheckFrontMatter is a problem with the front matter of the PR body.
+	CheckFrontMatter Check = "front-matter"
+	// CheckMilestone is a problem with the /milestone command.
+	CheckMilestone Check = "milestone"
+	// CheckUpgradeDocs is a missing upgrade docs change of an ACTION REQUIRED
+	// release note.
+	CheckUpgradeDocs Check = "upgrade-docs"
+	// CheckDocsKind is a /kind documentation PR that changes no docs.
+	CheckDocsKind Check = "docs-kind"
+	// CheckSecret is a possible credential in the PR body.
+	CheckSecret Check = "secret"
+)
+
+// ValidationError is a problem with the PR that its author must fix, such as a
+// missing /kind command or release note.
+type ValidationError struct {
+	Err error
+	// Check is what the problem is about.
+	Check Check
+}
+
+// checked tags err as a validation failure of check.
+func checked(check Check, err error) error {
+	if ve, ok := err.(*ValidationError); ok {
+		ve.Check = check
+		return ve
+	}
+	return &ValidationError{Err: err, Check: check}
+}
+
+// Error implements error. Validation messages quote the PR body, so any
+// credential pasted into it is redacted before it can be logged or echoed.
+func (e *ValidationError) Error() string { return secrets.Redact(e.Err.Error()) }
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// OperationalError is a failure of the labeler itself, such as a GitHub API
+// call that could not be completed.
+type OperationalError struct {
+	Err error
+}
+
+// Error implements error.
+func (e *OperationalError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *OperationalError) Unwrap() error { return e.Err }
+
+// ConfigError is a problem with how the labeler is configured or invoked,
+// such as an invalid flag, that neither retrying nor changing the PR fixes.
+type ConfigError struct {
+	Err error
+}
+
+// Error implements error.
+func (e *ConfigError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// Partition splits an error returned by ProcessPR into validation problems and
+// operational failures. Joined errors are flattened; errors that are neither
+// kind are treated as operational.
+func Partition(err error) (validation, operational []error) {
+	var walk func(error)
+	walk = func(err error) {
+		var v *ValidationError
+		switch e := err.(type) {
+		case nil:
+		case *ValidationError:
+			validation = append(validation, e)
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		default:
+			if errors.As(err, &v) {
+				validation = append(validation, v)
+				return
+			}
+			operational = append(operational, err)
+		}
+	}
+	walk(err)
+	return validation, operational
+}
@@ -0,0 +1,122 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FailureStore remembers, per author, the PRs that failed validation, so
+// contributors who repeatedly miss the PR template can be spotted.
+type FailureStore interface {
+	// Failures returns the PRs, as owner/repo#number, of author that failed
+	// validation.
+	Failures(ctx context.Context, author string) ([]string, error)
+	// RecordFailure adds pr to the failed PRs of author. Recording the same
+	// PR again has no effect.
+	RecordFailure(ctx context.Context, author, pr string) error
+}
+
+// Escalation configures the extra guidance given to authors whose PRs keep
+// failing validation.
+type Escalation struct {
+	// Threshold is the number of failed PRs, this one included, from which
+	// guidance is escalated.
+	Threshold int
+	// DocsURL links to the contributor docs explaining the PR template.
+	DocsURL string
+	// Mentors are GitHub users or org/team-slug teams pinged to help.
+	Mentors []string
+}
+
+// WithEscalation counts validation failures per author in store and, once an
+// author has failed on esc.Threshold PRs, escalates the sticky comment and
+// check-run summary with a link to the contributor docs and a ping to the
+// mentors. Each PR counts once, however many times it is pushed.
+func (l *labeler) WithEscalation(store FailureStore, esc Escalation) *labeler {
+	l.failureStore = store
+	l.escalation = esc
+	return l
+}
+
+// prRef identifies the PR in a FailureStore.
+func (l *labeler) prRef() string {
+	return fmt.Sprintf("%s/%s#%d", l.owner, l.repo, l.prNum)
+}
+
+// fetchFailures looks up the PRs of the author that failed validation before.
+func (l *labeler) fetchFailures(ctx context.Context) error {
+	if l.failureStore == nil || l.authorLogin == "" {
+		return nil
+	}
+	failures, err := l.failureStore.Failures(ctx, l.authorLogin)
+	if err != nil {
+		return fmt.Errorf("failed to get validation failures of %s: %w", l.authorLogin, err)
+	}
+	l.authorFailures = failures
+	return nil
+}
+
+// FailureCount returns how many of the author's PRs, this one included if it
+// failed the last evaluation, have failed validation.
+func (l *labeler) FailureCount() int {
+	n := len(l.authorFailures)
+	if len(l.problems) > 0 && !slices.Contains(l.authorFailures, l.prRef()) {
+		n++
+	}
+	return n
+}
+
+// escalated reports whether the last evaluation should escalate guidance.
+func (l *labeler) escalated() bool {
+	return l.failureStore != nil && l.escalation.Threshold > 0 && len(l.problems) > 0 &&
+		len(l.suspectedSpam) == 0 && l.FailureCount() >= l.escalation.Threshold
+}
+
+// EscalationMessage returns the escalated guidance for the last evaluation
+// as markdown, or "" if the author has not failed validation often enough.
+func (l *labeler) EscalationMessage() string {
+	if !l.escalated() {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@%s, this is the %d%s of your PRs that needed changes to its description.", l.authorLogin, l.FailureCount(), ordinalSuffix(l.FailureCount()))
+	if l.escalation.DocsURL != "" {
+		fmt.Fprintf(&sb, " The [contributor guide](%s) explains how to fill out the PR template.", l.escalation.DocsURL)
+	}
+	if len(l.escalation.Mentors) > 0 {
+		mentions := make([]string, len(l.escalation.Mentors))
+		for i, m := range l.escalation.Mentors {
+			mentions[i] = "@" + m
+		}
+		fmt.Fprintf(&sb, " %s can help if anything is unclear.", strings.Join(mentions, ", "))
+	}
+	return sb.String() + "\n"
+}
+
+func ordinalSuffix(n int) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// syncFailures records the PR as failed for its author.
+func (l *labeler) syncFailures(ctx context.Context, p *Plan) error {
+	if !p.RecordFailure {
+		return nil
+	}
+	if err := l.failureStore.RecordFailure(ctx, l.authorLogin, l.prRef()); err != nil {
+		return fmt.Errorf("failed to record validation failure of %s: %w", l.authorLogin, err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package labeler
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+type fakeFailureStore map[string][]string
+
+func (s fakeFailureStore) Failures(ctx context.Context, author string) ([]string, error) {
+	return s[author], nil
+}
+
+func (s fakeFailureStore) RecordFailure(ctx context.Context, author, pr string) error {
+	if !slices.Contains(s[author], pr) {
+		s[author] = append(s[author], pr)
+	}
+	return nil
+}
+
+func TestProcessPR_Escalation(t *testing.T) {
+	esc := Escalation{Threshold: 3, DocsURL: "https://example.com/contributing", Mentors: []string{"kgateway-dev/mentors"}}
+	tests := []struct {
+		name          string
+		previous      []string
+		body          string
+		wantEscalated bool
+		wantRecorded  []string
+	}{
+		{
+			name:         "below the threshold",
+			previous:     []string{"owner/repo#1"},
+			body:         "no kind here",
+			wantRecorded: []string{"owner/repo#1", "owner/repo#7"},
+		},
+		{
+			name:          "reaches the threshold",
+			previous:      []string{"owner/repo#1", "owner/repo#2"},
+			body:          "no kind here",
+			wantEscalated: true,
+			wantRecorded:  []string{"owner/repo#1", "owner/repo#2", "owner/repo#7"},
+		},
+		{
+			name:          "same PR pushed again counts once",
+			previous:      []string{"owner/repo#1", "owner/repo#7"},
+			body:          "no kind here",
+			wantRecorded:  []string{"owner/repo#1", "owner/repo#7"},
+			wantEscalated: false,
+		},
+		{
+			name:         "valid PR is not recorded",
+			previous:     []string{"owner/repo#1", "owner/repo#2"},
+			body:         validBody,
+			wantRecorded: []string{"owner/repo#1", "owner/repo#2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := fakeFailureStore{"alice": slices.Clone(tt.previous)}
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 7, false).
+				WithAuthor("alice", "NONE").
+				WithEscalation(store, esc)
+			l.ProcessPR(context.Background(), tt.body, true)
+			if !slices.Equal(store["alice"], tt.wantRecorded) {
+				t.Fatalf("recorded failures = %v, want %v", store["alice"], tt.wantRecorded)
+			}
+			summary := l.Summary()
+			if got := strings.Contains(summary, "@kgateway-dev/mentors can help"); got != tt.wantEscalated {
+				t.Fatalf("escalated = %v, want %v:\n%s", got, tt.wantEscalated, summary)
+			}
+			if tt.wantEscalated && !strings.Contains(summary, "@alice, this is the 3rd of your PRs") {
+				t.Fatalf("unexpected escalation message:\n%s", summary)
+			}
+		})
+	}
+}
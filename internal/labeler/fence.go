@@ -0,0 +1,75 @@
+package labeler
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// releaseNoteFence is the canonical info string of release-note blocks.
+const releaseNoteFence = "release-note"
+
+// WithReleaseNoteFences also accepts release-note blocks fenced with names,
+// e.g. releasenote or changelog, for repositories whose PR templates predate
+// the labeler. PRs using one are asked to switch to ```release-note.
+func (l *labeler) WithReleaseNoteFences(names []string) *labeler {
+	l.releaseNoteRE, l.releaseNoteFences = nil, names
+	if len(names) == 0 {
+		return l
+	}
+	alts := []string{regexp.QuoteMeta(releaseNoteFence)}
+	for _, name := range names {
+		alts = append(alts, regexp.QuoteMeta(name))
+	}
+	// longest first, so release-notes is not read as release-note
+	slices.SortStableFunc(alts, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+	l.releaseNoteRE = regexp.MustCompile("(?s)```(" + strings.Join(alts, "|") + ")\\s*(.*?)\\s*```")
+	return l
+}
+
+// ReleaseNoteFence returns the name the release-note block of the last
+// evaluation was fenced with, if it is not the canonical release-note.
+func (l *labeler) ReleaseNoteFence() string {
+	return l.releaseNoteFence
+}
+
+// releaseNoteBlockRE returns the regexp matching the release-note blocks the
+// labeler accepts, capturing the fence name and the note.
+func (l *labeler) releaseNoteBlockRE() *regexp.Regexp {
+	if l.releaseNoteRE != nil {
+		return l.releaseNoteRE
+	}
+	return releaseNoteRE
+}
+
+// conflictingReleaseNotes reports whether body has a release-note block of
+// NONE and another with a note, so it is unclear whether users are affected.
+// Only blocks fenced at the start of a line count, so fences mentioned in
+// prose are not taken for blocks.
+func (l *labeler) conflictingReleaseNotes(body string) bool {
+	var none, note bool
+	for _, m := range l.releaseNoteBlockRE().FindAllStringSubmatchIndex(body, -1) {
+		lineStart := strings.LastIndexByte(body[:m[0]], '\n') + 1
+		if strings.TrimLeft(body[lineStart:m[0]], " \t") != "" {
+			continue
+		}
+		switch entry := strings.TrimSpace(body[m[4]:m[5]]); {
+		case strings.EqualFold(entry, "NONE"):
+			none = true
+		case entry != "":
+			note = true
+		}
+	}
+	return none && note
+}
+
+// findReleaseNote returns the contents of the first release-note block of
+// body and the name it is fenced with, or ok false if body has none.
+func (l *labeler) findReleaseNote(body string) (note, fence string, ok bool) {
+	m := l.releaseNoteBlockRE().FindStringSubmatch(body)
+	if m == nil {
+		return "", "", false
+	}
+	return m[2], m[1], true
+}
@@ -0,0 +1,103 @@
+package labeler
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestReleaseNoteFences(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		fences    []string
+		wantNote  string
+		wantFence string
+	}{
+		{
+			name:     "canonical fence",
+			body:     "```release-note\nAdded foo.\n```",
+			fences:   []string{"changelog"},
+			wantNote: "Added foo.",
+		},
+		{
+			name:      "configured fence",
+			body:      "```changelog\nAdded foo.\n```",
+			fences:    []string{"releasenote", "changelog"},
+			wantNote:  "Added foo.",
+			wantFence: "changelog",
+		},
+		{
+			name:      "longer fence is not read as release-note",
+			body:      "```release-notes\nAdded foo.\n```",
+			fences:    []string{"release-notes"},
+			wantNote:  "Added foo.",
+			wantFence: "release-notes",
+		},
+		{
+			name: "fence not configured",
+			body: "```changelog\nAdded foo.\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false).WithReleaseNoteFences(tt.fences)
+			d, _ := l.Simulate("/kind feature\n"+tt.body, nil)
+			note := ""
+			if d.ReleaseNote != nil {
+				note = d.ReleaseNote.Note
+			}
+			if note != tt.wantNote {
+				t.Errorf("release note = %q, want %q", note, tt.wantNote)
+			}
+			if got := l.ReleaseNoteFence(); got != tt.wantFence {
+				t.Errorf("ReleaseNoteFence() = %q, want %q", got, tt.wantFence)
+			}
+			if warned := strings.Contains(l.Summary(), "please rename the fence"); warned != (tt.wantFence != "") {
+				t.Errorf("summary warns about the fence = %v, want %v:\n%s", warned, tt.wantFence != "", l.Summary())
+			}
+		})
+	}
+}
+
+func TestConflictingReleaseNotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name:    "NONE and a note",
+			body:    "```release-note\nNONE\n```\n\n```release-note\nAdded foo.\n```",
+			wantErr: true,
+		},
+		{
+			name:    "note and NONE in a configured fence",
+			body:    "```release-note\nAdded foo.\n```\n\n```changelog\nnone\n```",
+			wantErr: true,
+		},
+		{
+			name: "two notes",
+			body: "```release-note\nAdded foo.\n```\n\n```release-note\nAdded bar.\n```",
+		},
+		{
+			name: "NONE and an empty block",
+			body: "```release-note\nNONE\n```\n\n```release-note\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false).WithReleaseNoteFences([]string{"changelog"})
+			d, err := l.Simulate("/kind feature\n"+tt.body, nil)
+			gotErr := err != nil && strings.Contains(err.Error(), "conflicting ```release-note``` blocks")
+			if gotErr != tt.wantErr {
+				t.Fatalf("error = %v, want conflict %v", err, tt.wantErr)
+			}
+			if invalid := slices.Contains(d.LabelsToAdd, labels.InvalidReleaseNoteLabel); invalid != tt.wantErr {
+				t.Errorf("labels to add = %v, want %s %v", d.LabelsToAdd, labels.InvalidReleaseNoteLabel, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package labeler
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// needsChangedFiles reports whether a check that is enabled depends on the
+// paths the PR changes.
+func (l *labeler) needsChangedFiles() bool {
+	return l.upgradeDocs || l.docsKind || l.exportMetadata || l.detectRenames || l.moduleLabels || l.riskScoring || len(l.ignoredPaths) > 0
+}
+
+// WithIgnoredPaths skips validation, leaving labels, comments and check runs
+// alone, for PRs that only change files matching paths, e.g. .github/** or
+// vendor/**, such as automated workflow syncs.
+func (l *labeler) WithIgnoredPaths(paths []string) *labeler {
+	l.ignoredPaths = paths
+	return l
+}
+
+// onlyIgnoredPaths reports whether every file the PR changes matches one of
+// the ignored paths.
+func (l *labeler) onlyIgnoredPaths() bool {
+	if len(l.ignoredPaths) == 0 || len(l.changedFiles) == 0 {
+		return false
+	}
+	for _, f := range l.changedFiles {
+		if !slices.ContainsFunc(l.ignoredPaths, func(p string) bool { return matchPath(p, f) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// Skipped reports whether the last evaluation was skipped because the PR only
+// changes ignored paths.
+func (l *labeler) Skipped() bool {
+	return l.skipped
+}
+
+// fetchChangedFiles lists the paths the PR changes, unless they were set with
+// WithChangedFiles or no check needs them. Renamed files count under both
+// their old and new paths.
+func (l *labeler) fetchChangedFiles(ctx context.Context) error {
+	if l.changedFiles != nil || !l.needsChangedFiles() {
+		return nil
+	}
+	files := []string{}
+	renameOnly := true
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := l.client.PullRequests.ListFiles(ctx, l.owner, l.repo, l.prNum, opts)
+		if err != nil {
+			return apiError(err, permPullRequestsRead, "list changed files")
+		}
+		for _, f := range page {
+			files = append(files, f.GetFilename())
+			if prev := f.GetPreviousFilename(); prev != "" {
+				files = append(files, prev)
+			}
+			if f.GetStatus() != "renamed" || f.GetChanges() > 0 {
+				renameOnly = false
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	l.changedFiles = files
+	l.renameOnly = l.detectRenames && renameOnly && len(files) > 0
+	return nil
+}
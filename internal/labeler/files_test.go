@@ -0,0 +1,59 @@
+package labeler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestSimulate_IgnoredPaths(t *testing.T) {
+	ignored := []string{".github/**", "vendor/**"}
+	tests := []struct {
+		name        string
+		files       []string
+		wantSkipped bool
+	}{
+		{name: "only ignored paths", files: []string{".github/workflows/ci.yaml", "vendor/modules.txt"}, wantSkipped: true},
+		{name: "some other path", files: []string{".github/workflows/ci.yaml", "main.go"}},
+		{name: "renamed out of an ignored path", files: []string{"vendor/foo.go", "pkg/foo.go"}},
+		{name: "no changed files", files: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, true).WithIgnoredPaths(ignored).WithChangedFiles(tt.files)
+			d, err := l.Simulate("no kind here", []string{"kind/fix"})
+			if l.Skipped() != tt.wantSkipped {
+				t.Fatalf("Skipped() = %v, want %v", l.Skipped(), tt.wantSkipped)
+			}
+			if tt.wantSkipped && (err != nil || len(d.LabelsToAdd)+len(d.LabelsToRemove) > 0) {
+				t.Fatalf("expected no label changes or errors, got %+v, %v", d, err)
+			}
+			if !tt.wantSkipped && err == nil {
+				t.Fatal("expected validation to run")
+			}
+		})
+	}
+}
+
+func TestProcessPR_IgnoredPathsLeavePRAlone(t *testing.T) {
+	// only reads are mocked, so any write fails the test
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+		mock.WithRequestMatch(
+			mock.GetReposPullsFilesByOwnerByRepoByPullNumber,
+			[]*github.CommitFile{{Filename: github.Ptr(".github/workflows/ci.yaml")}},
+		),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, true).
+		WithIgnoredPaths([]string{".github/**"}).
+		WithStickyComment().
+		WithCheckRun("abc123", true)
+	if _, err := l.ProcessPR(context.Background(), "no kind here", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !l.Skipped() {
+		t.Fatal("expected validation to be skipped")
+	}
+}
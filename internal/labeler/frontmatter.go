@@ -0,0 +1,88 @@
+package labeler
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// frontMatterRE captures a YAML front-matter block at the start of the body.
+var frontMatterRE = regexp.MustCompile(`(?s)\A\s*---[ \t]*\n(.*?)\n?---[ \t]*(?:\n|\z)`)
+
+// frontMatter is structured PR metadata, an alternative to slash commands
+// that other automation can generate reliably:
+//
+//	---
+//	kind: [feature]
+//	release-note: Added the foo field.
+//	milestone: v2.1
+//	---
+//
+// Each field that is set takes precedence over the corresponding commands in
+// the body, which are then ignored.
+type frontMatter struct {
+	Kind        stringList `json:"kind,omitempty"`
+	ReleaseNote *string    `json:"release-note,omitempty"`
+	Milestone   string     `json:"milestone,omitempty"`
+}
+
+// stringList accepts a single string as well as a list.
+type stringList []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *stringList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*s = stringList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("expected a string or a list of strings")
+	}
+	*s = many
+	return nil
+}
+
+// applyFrontMatter removes the front-matter block from body and rewrites the
+// body so that the fields it sets replace the body's commands. A block that
+// is not a YAML mapping, such as text between two horizontal rules, is left
+// alone. A mapping that isn't valid front-matter is removed and reported.
+// A kind field replaces every form of kind command the labeler recognizes.
+func (l *labeler) applyFrontMatter(body string) (string, error) {
+	m := frontMatterRE.FindStringSubmatch(body)
+	if m == nil {
+		return body, nil
+	}
+	var mapping map[string]any
+	if err := yaml.Unmarshal([]byte(m[1]), &mapping); err != nil || len(mapping) == 0 {
+		return body, nil
+	}
+	rest := body[len(m[0]):]
+	var fm frontMatter
+	if err := yaml.UnmarshalStrict([]byte(m[1]), &fm); err != nil {
+		return rest, fmt.Errorf("invalid front-matter: %v; supported fields are kind, release-note and milestone", err)
+	}
+	var extra []string
+	if len(fm.Kind) > 0 {
+		rest = l.stripKindCommands(rest)
+		for _, k := range fm.Kind {
+			extra = append(extra, "/kind "+strings.TrimSpace(k))
+		}
+	}
+	if fm.ReleaseNote != nil {
+		rest = l.releaseNoteBlockRE().ReplaceAllString(rest, "")
+		extra = append(extra, "```release-note\n"+strings.TrimSpace(*fm.ReleaseNote)+"\n```")
+	}
+	if fm.Milestone != "" {
+		rest = milestoneRE.ReplaceAllString(rest, "")
+		extra = append(extra, "/milestone "+strings.TrimSpace(fm.Milestone))
+	}
+	if len(extra) == 0 {
+		return rest, nil
+	}
+	return rest + "\n" + strings.Join(extra, "\n") + "\n", nil
+}
@@ -0,0 +1,102 @@
+package labeler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestSimulate_FrontMatter(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantAdd       []string
+		wantNote      string
+		wantMilestone string
+		wantErr       string
+	}{
+		{
+			name:     "front-matter alone",
+			body:     "---\nkind: [feature]\nrelease-note: Added the foo field.\n---\n# Description\nAdds foo.\n",
+			wantAdd:  []string{"kind/feature", labels.ReleaseNoteLabel},
+			wantNote: "Added the foo field.",
+		},
+		{
+			name:     "single kind as a string",
+			body:     "---\nkind: fix\nrelease-note: NONE\n---\n",
+			wantAdd:  []string{"kind/fix", labels.ReleaseNoteNoneLabel},
+			wantNote: "",
+		},
+		{
+			name:     "front-matter takes precedence over body commands",
+			body:     "---\r\nkind:\r\n  - fix\r\nrelease-note: Fixed a crash.\r\n---\r\n/kind feature\r\n```release-note\r\nNONE\r\n```\r\n",
+			wantAdd:  []string{"kind/fix", labels.ReleaseNoteLabel},
+			wantNote: "Fixed a crash.",
+		},
+		{
+			name:     "unset fields fall back to body commands",
+			body:     "---\nkind: fix\n---\n```release-note\nFixed a crash.\n```\n",
+			wantAdd:  []string{"kind/fix", labels.ReleaseNoteLabel},
+			wantNote: "Fixed a crash.",
+		},
+		{
+			name:          "milestone",
+			body:          "---\nkind: fix\nrelease-note: NONE\nmilestone: v2.1\n---\n/milestone v9\n",
+			wantAdd:       []string{"kind/fix", labels.ReleaseNoteNoneLabel},
+			wantMilestone: "v2.1",
+		},
+		{
+			name:    "front-matter kinds are validated",
+			body:    "---\nkind: [bogus]\nrelease-note: NONE\n---\n",
+			wantAdd: []string{labels.InvalidKindLabel, labels.ReleaseNoteNoneLabel},
+			wantErr: `invalid /kind "bogus"`,
+		},
+		{
+			name:    "unknown field",
+			body:    "---\nkind: fix\nreleasenote: NONE\n---\n```release-note\nNONE\n```\n",
+			wantAdd: []string{labels.InvalidKindLabel, labels.ReleaseNoteNoneLabel},
+			wantErr: "invalid front-matter",
+		},
+		{
+			name:     "front-matter takes precedence over plural kind commands",
+			body:     "---\nkind: fix\nrelease-note: NONE\n---\n/kinds feature,cleanup\n",
+			wantAdd:  []string{"kind/fix", labels.ReleaseNoteNoneLabel},
+			wantNote: "",
+		},
+		{
+			name:     "front-matter takes precedence over namespaced and prefixed kind commands",
+			body:     "---\nkind: fix\nrelease-note: NONE\n---\n/kgateway kind feature\n#kind: cleanup\n/kind\n\ndesign\n",
+			wantAdd:  []string{"kind/fix", labels.ReleaseNoteNoneLabel},
+			wantNote: "",
+		},
+		{
+			name:     "horizontal rules are not front-matter",
+			body:     "---\nSome context.\n---\n/kind fix\n```release-note\nFixed a crash.\n```\n",
+			wantAdd:  []string{"kind/fix", labels.ReleaseNoteLabel},
+			wantNote: "Fixed a crash.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false).WithMilestones(nil).WithCommandNamespace("kgateway").WithKindPrefixes([]string{"#kind:"})
+			d, err := l.Simulate(tt.body, nil)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Fatalf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if tt.wantNote != "" && (d.ReleaseNote == nil || d.ReleaseNote.Note != tt.wantNote) {
+				t.Fatalf("release note = %+v, want %q", d.ReleaseNote, tt.wantNote)
+			}
+			if d.Milestone != tt.wantMilestone {
+				t.Fatalf("milestone = %q, want %q", d.Milestone, tt.wantMilestone)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package labeler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestGoldenFixtures replays every fixture in testdata/ (captured with the
+// `golden` subcommand) and checks the labeler still reaches the same decision.
+func TestGoldenFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list fixtures: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("expected at least one golden fixture in testdata/")
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			var want Decision
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatalf("failed to parse fixture: %v", err)
+			}
+
+			l := New(nil, "owner", "repo", 1, want.EnforceDescription, want.EnforceReleaseNoteQuality, want.EnforceChangelogKindExclusivity).
+				WithChangedFiles(want.ChangedFiles)
+			got, _ := l.Simulate(want.Body, want.CurrentLabels)
+			got.Source = want.Source
+
+			if !reflect.DeepEqual(*got, want) {
+				t.Fatalf("decision mismatch\nwant: %+v\ngot:  %+v", want, *got)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/changelog"
+)
+
+// helpMarker identifies the labeler's replies to /help among the PR's
+// comments.
+const helpMarker = "<!-- pr-kind-labeler-help -->"
+
+// helpCommandRE matches a comment that is a /help command.
+var helpCommandRE = regexp.MustCompile(`^\s*/help\s*$`)
+
+// command is a command the labeler takes, as listed by /help.
+type command struct {
+	// usage is the command as typed, and where reports where it is taken
+	// from and by whom.
+	usage, where, summary string
+	// enabled reports whether the labeler is set up to take the command;
+	// nil means always.
+	enabled func(l *labeler) bool
+}
+
+// commands are the commands the labeler takes, in the order /help lists
+// them. A new command is added here so /help documents it.
+var commands = []command{
+	{usage: "/kind KIND", where: "PR description", summary: "categorizes the PR as KIND; repeat it for several kinds"},
+	{usage: "/kinds KIND,KIND", where: "PR description", summary: "sets several kinds at once"},
+	{usage: "/stacked-on #PR", where: "PR description", summary: "inherits the kinds and release note of PR, whose head branch this PR is based on, when the description sets none"},
+	{usage: "/release-note-epic #ISSUE", where: "PR description", summary: "defers the release note to the tracking issue ISSUE, whose note the changelog publishes once for all the PRs of the epic"},
+	{
+		usage: "/kind KIND", where: "review summary, by maintainers", summary: "adds KIND to the kinds of the PR description",
+		enabled: func(l *labeler) bool { return l.reviewCommands },
+	},
+	{
+		usage: "/release-note TEXT", where: "comment, by maintainers", summary: "sets the release note, overriding the PR description; prefix TEXT with [CATEGORY] to pick its changelog section",
+		enabled: func(l *labeler) bool { return l.stickyComment },
+	},
+	{
+		usage: "/release-note-none", where: "comment, by maintainers", summary: "sets the release note to NONE",
+		enabled: func(l *labeler) bool { return l.stickyComment },
+	},
+	{
+		usage: "/release-note TEXT", where: "review summary, by maintainers", summary: "like the comment command; the latest one wins",
+		enabled: func(l *labeler) bool { return l.stickyComment && l.reviewCommands },
+	},
+	{usage: "/help", where: "comment", summary: "replies with this list"},
+}
+
+// IsHelpCommand reports whether body, e.g. of a comment, is a /help command.
+func IsHelpCommand(body string) bool {
+	return helpCommandRE.MatchString(body)
+}
+
+// Help renders the commands and kinds the labeler takes, as set up, as
+// markdown. It is generated from the commands and the kind catalog, so it
+// lists exactly what the labeler accepts.
+func (l *labeler) Help() string {
+	var sb strings.Builder
+	sb.WriteString(helpMarker + "\n### PR Kind Labeler commands\n\n")
+	sb.WriteString("| Command | Where | What it does |\n|---|---|---|\n")
+	for _, c := range commands {
+		if c.enabled == nil || c.enabled(l) {
+			fmt.Fprintf(&sb, "| `%s` | %s | %s |\n", c.usage, c.where, c.summary)
+		}
+	}
+
+	var forms []string
+	if l.commandNamespace != "" {
+		forms = append(forms, fmt.Sprintf("`/%s kind KIND`", l.commandNamespace))
+	}
+	for _, p := range l.kindPrefixes {
+		forms = append(forms, fmt.Sprintf("`%s KIND`", strings.TrimSpace(p)))
+	}
+	if len(forms) > 0 {
+		sb.WriteString("\n`/kind KIND` can also be written as " + strings.Join(forms, ", ") + ".\n")
+	}
+
+	sb.WriteString("\n**Kinds:** " + backtickList(sortedKeys(l.supportedKinds)) + ".\n")
+	var deprecated []string
+	for _, k := range slices.Sorted(maps.Keys(l.kindAliases)) {
+		deprecated = append(deprecated, fmt.Sprintf("`%s` (use `%s`)", k, l.kindAliases[k]))
+	}
+	if len(deprecated) > 0 {
+		sb.WriteString("\n**Deprecated kinds:** " + strings.Join(deprecated, ", ") + ".\n")
+	}
+
+	fences := append([]string{releaseNoteFence}, l.releaseNoteFences...)
+	fmt.Fprintf(&sb, "\n**Release note:** a ```` ```%s ```` block in the PR description", releaseNoteFence)
+	if len(fences) > 1 {
+		sb.WriteString(" (also fenced as " + backtickList(fences[1:]) + ")")
+	}
+	sb.WriteString(", with NONE if the PR needs none. Prefix the note with [CATEGORY] to pick its changelog section: " + backtickList(strings.Split(changelog.IDs(), ", ")) + ".\n")
+	return sb.String()
+}
+
+// PostHelp replies to a /help command with Help.
+func (l *labeler) PostHelp(ctx context.Context) error {
+	if _, _, err := l.client.Issues.CreateComment(ctx, l.owner, l.repo, l.prNum, &github.IssueComment{Body: github.Ptr(l.Help())}); err != nil {
+		return apiError(err, permPullRequestsWrite, "create help comment")
+	}
+	return nil
+}
+
+// backtickList renders items as a comma-separated list of code spans.
+func backtickList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "`" + item + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,91 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+)
+
+func TestIsHelpCommand(t *testing.T) {
+	for body, want := range map[string]bool{
+		"/help":          true,
+		"  /help\r\n":    true,
+		"/help me":       false,
+		"see /help":      false,
+		"/helpful":       false,
+		"/release-note ": false,
+	} {
+		if got := IsHelpCommand(body); got != want {
+			t.Errorf("IsHelpCommand(%q) = %v, want %v", body, got, want)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	tests := []struct {
+		name    string
+		l       *labeler
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "defaults",
+			l:       New(nil, "owner", "repo", 1, false),
+			want:    []string{"`/kind KIND`", "`/kinds KIND,KIND`", "`/help`", "`bug_fix` (use `fix`)", "`helm`"},
+			notWant: []string{"/release-note TEXT", "review summary", "also be written as", "also fenced as"},
+		},
+		{
+			name: "as set up",
+			l: New(nil, "owner", "repo", 1, false).WithStickyComment().WithReviewCommands().
+				WithCommandNamespace("kgateway").WithKindPrefixes([]string{"> /kind"}).WithReleaseNoteFences([]string{"changelog"}),
+			want: []string{"`/release-note TEXT` | comment", "`/release-note TEXT` | review summary", "`/kgateway kind KIND`, `> /kind KIND`", "also fenced as `changelog`"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			help := tt.l.Help()
+			for _, k := range kinds.Supported() {
+				if !strings.Contains(help, "`"+k+"`") {
+					t.Errorf("help does not list kind %s:\n%s", k, help)
+				}
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(help, s) {
+					t.Errorf("help does not contain %q:\n%s", s, help)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(help, s) {
+					t.Errorf("help contains %q:\n%s", s, help)
+				}
+			}
+		})
+	}
+}
+
+func TestPostHelp(t *testing.T) {
+	var posted string
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(mock.PostReposIssuesCommentsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var c github.IssueComment
+				json.NewDecoder(r.Body).Decode(&c)
+				posted = c.GetBody()
+				w.Write(mock.MustMarshal(c))
+			})),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false)
+	if err := l.PostHelp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(posted, helpMarker) {
+		t.Fatalf("expected the help comment to be posted, got:\n%s", posted)
+	}
+}
@@ -0,0 +1,75 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// LabelCache remembers PR labels between runs, e.g. across webhooks in server
+// mode, so busy repositories don't list labels on every event.
+type LabelCache interface {
+	// Get returns the cached labels and the ETag of the response they came
+	// from, and whether they are known to be current without asking GitHub.
+	Get(owner, repo string, prNum int) (labels []string, etag string, fresh bool)
+	// Set stores the labels of a PR, with the ETag of the response they came
+	// from, or "" if they were not read from the API.
+	Set(owner, repo string, prNum int, labels []string, etag string)
+	// Invalidate forgets the labels of a PR whose state is unknown.
+	Invalidate(owner, repo string, prNum int)
+}
+
+// WithLabelCache makes the labeler read current labels from cache when they
+// are fresh, revalidate them with a conditional request when they are stale,
+// and record the labels it leaves the PR with.
+func (l *labeler) WithLabelCache(cache LabelCache) *labeler {
+	l.labelCache = cache
+	return l
+}
+
+// fetchCachedLabels returns the PR labels from the cache, falling back to a
+// conditional request that GitHub answers with 304 Not Modified, without
+// counting against the rate limit, when the cached labels are still current.
+func (l *labeler) fetchCachedLabels(ctx context.Context) ([]string, error) {
+	cached, etag, fresh := l.labelCache.Get(l.owner, l.repo, l.prNum)
+	if fresh {
+		return cached, nil
+	}
+	req, err := l.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/issues/%d/labels", l.owner, l.repo, l.prNum), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	var current []*github.Label
+	resp, err := l.client.Do(ctx, req, &current)
+	if resp != nil && resp.StatusCode == http.StatusNotModified {
+		l.labelCache.Set(l.owner, l.repo, l.prNum, cached, etag)
+		return cached, nil
+	}
+	if err != nil {
+		return nil, apiError(err, permPullRequestsRead, "list labels")
+	}
+	names := make([]string, 0, len(current))
+	for _, label := range current {
+		names = append(names, label.GetName())
+	}
+	l.labelCache.Set(l.owner, l.repo, l.prNum, names, resp.Header.Get("ETag"))
+	return names, nil
+}
+
+// cacheSyncedLabels records the labels the PR has after a sync. A partially
+// failed sync leaves the labels unknown, so they are invalidated instead.
+func (l *labeler) cacheSyncedLabels(p *Plan, synced bool) {
+	if l.labelCache == nil {
+		return
+	}
+	if !synced {
+		l.labelCache.Invalidate(l.owner, l.repo, l.prNum)
+		return
+	}
+	l.labelCache.Set(l.owner, l.repo, l.prNum, p.FinalLabels(), "")
+}
@@ -0,0 +1,117 @@
+package labeler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+type fakeLabelCache struct {
+	labels      []string
+	etag        string
+	fresh       bool
+	invalidated bool
+}
+
+func (c *fakeLabelCache) Get(owner, repo string, prNum int) ([]string, string, bool) {
+	return c.labels, c.etag, c.fresh
+}
+
+func (c *fakeLabelCache) Set(owner, repo string, prNum int, labels []string, etag string) {
+	c.labels, c.etag, c.fresh = labels, etag, true
+}
+
+func (c *fakeLabelCache) Invalidate(owner, repo string, prNum int) {
+	c.invalidated = true
+}
+
+func TestProcessPR_LabelCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		cache      *fakeLabelCache
+		wantListed bool
+		wantLabels []string
+	}{
+		{
+			name:       "fresh labels are used without listing",
+			cache:      &fakeLabelCache{labels: []string{labels.InvalidKindLabel}, fresh: true},
+			wantLabels: []string{"kind/fix", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:       "stale labels are revalidated with their ETag",
+			cache:      &fakeLabelCache{labels: []string{labels.InvalidKindLabel}, etag: `"abc"`},
+			wantListed: true,
+			wantLabels: []string{"kind/fix", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:       "changed labels are listed",
+			cache:      &fakeLabelCache{labels: []string{labels.InvalidKindLabel}, etag: `"old"`},
+			wantListed: true,
+			wantLabels: []string{"kind/fix", "lgtm", labels.ReleaseNoteNoneLabel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listed := false
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						listed = true
+						if r.Header.Get("If-None-Match") == `"abc"` {
+							w.WriteHeader(http.StatusNotModified)
+							return
+						}
+						w.Header().Set("ETag", `"new"`)
+						w.Write(mock.MustMarshal([]*github.Label{{Name: github.Ptr(labels.InvalidKindLabel)}, {Name: github.Ptr("lgtm")}}))
+					}),
+				),
+				mock.WithRequestMatch(
+					mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+					[]*github.Label{},
+				),
+				mock.WithRequestMatchHandler(
+					mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusNoContent)
+					}),
+				),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 902, false).WithLabelCache(tt.cache)
+			if _, err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nNONE\n```", true); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if listed != tt.wantListed {
+				t.Fatalf("expected labels listed=%v, got %v", tt.wantListed, listed)
+			}
+			if !reflect.DeepEqual(tt.cache.labels, tt.wantLabels) || tt.cache.etag != "" {
+				t.Fatalf("expected the synced labels %v to be cached, got %v (etag %q)", tt.wantLabels, tt.cache.labels, tt.cache.etag)
+			}
+		})
+	}
+}
+
+func TestProcessPR_LabelCacheInvalidatedOnSyncFailure(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusInternalServerError, "boom")
+			}),
+		),
+	)
+	cache := &fakeLabelCache{fresh: true}
+	l := New(github.NewClient(httpClient), "owner", "repo", 903, false).WithLabelCache(cache)
+	if _, err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nNONE\n```", true); err == nil {
+		t.Fatal("expected the failed sync to be reported")
+	}
+	if !cache.invalidated {
+		t.Fatal("expected the cache to be invalidated after a failed sync")
+	}
+}
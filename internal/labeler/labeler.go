@@ -5,26 +5,25 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"net/http"
 	"regexp"
-	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v68/github"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/changelog"
 	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
 	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
 )
 
 var (
-	// commentRE strips HTML comments so example code isn't parsed.
-	commentRE = regexp.MustCompile(`(?s)<!--.*?-->`)
 	// kindRE captures /kind labels, case-insensitive, matching start of line.
+	// extractKinds uses the equivalent scanKinds.
 	kindRE = regexp.MustCompile(`(?im)^/kind\s+([a-z0-9_/-]+)`)
-	// releaseNoteRE captures the first fenced code block with the word "release-note" in it.
-	releaseNoteRE = regexp.MustCompile("(?s)```release-note\\s*(.*?)\\s*```")
-	// descriptionRE captures content under the # Description heading until the next level-1 heading or end of string.
-	// Only stops at # followed by space (level-1), not ## or ### (level-2+)
-	descriptionRE = regexp.MustCompile(`(?sm)^#[ \t]*Description[ \t]*\n(.*?)(?:^#[ \t]|\z)`)
+	// releaseNoteRE captures the fence name and contents of the first fenced
+	// code block with the word "release-note" in it.
+	releaseNoteRE = regexp.MustCompile("(?s)```(release-note)\\s*(.*?)\\s*```")
 
 	conventionalCommitPrefixRE = regexp.MustCompile(`(?i)^(build|chore|ci|docs|feat|fix|perf|refactor|revert|style|test)(\([^)]+\))?!?:\s*`)
 	breakingChangePrefixRE     = regexp.MustCompile(`(?i)^BREAKING( CHANGE)?:\s*`)
@@ -58,6 +57,205 @@ type labeler struct {
 	enforceDescription              bool
 	enforceReleaseNoteQuality       bool
 	enforceChangelogKindExclusivity bool
+	// body is the PR body as last evaluated.
+	body string
+	// problems are the validation failures of the last evaluation.
+	problems []error
+	// kindPrefixes introduce kind commands besides /kind, and
+	// commandNamespace namespaces them, e.g. /kgateway kind.
+	kindPrefixes     []string
+	commandNamespace string
+	// releaseNoteRE matches the release-note blocks of the accepted fence
+	// names, releaseNoteFences, or is nil to accept only release-note, and
+	// releaseNoteFence is the non-canonical name the last evaluation found,
+	// if any.
+	releaseNoteRE     *regexp.Regexp
+	releaseNoteFences []string
+	releaseNoteFence  string
+	// maxNoteLength is the most characters a release note may have, as set
+	// WithMaxReleaseNoteLength, or 0.
+	maxNoteLength int
+	// linkClient checks the URLs of the release note, as set WithLinkCheck,
+	// and deadLinks are those found dead.
+	linkClient *http.Client
+	deadLinks  []DeadLink
+	// supportedKinds and kindAliases are the kinds accepted and the
+	// deprecated kinds migrated to them, and labelNames and
+	// defaultLabelNames map the default label names to the repository's and
+	// back, as set WithRepoConfig.
+	supportedKinds    map[string]bool
+	kindAliases       map[string]string
+	labelNames        map[string]string
+	defaultLabelNames map[string]string
+	// kinds holds the kinds parsed from the PR body during evaluation.
+	kinds map[string]bool
+	// deprecatedKinds are the deprecated kinds the PR body or labels still
+	// used during evaluation, before they were migrated.
+	deprecatedKinds map[string]bool
+	// releaseNote is the release note parsed during evaluation, if any.
+	releaseNote *ReleaseNote
+	// milestones maps kinds to the milestone title PRs of that kind default to.
+	milestones map[string]string
+	// milestone is the milestone title the PR should target, if any.
+	milestone string
+	// milestoneOverride is set when milestone came from a /milestone command.
+	milestoneOverride bool
+	// milestoneTeams, if set, are the teams whose members may use /milestone.
+	milestoneTeams []string
+	// changedFiles lists the paths the PR changes, for path-based validators.
+	changedFiles []string
+	// upgradeDocs requires ACTION REQUIRED release notes to come with changes
+	// to upgradeDocsPaths.
+	upgradeDocs      bool
+	upgradeDocsPaths []string
+	// docsKind requires /kind documentation PRs to change docsPaths, and
+	// docsKindCode are the Go files such a PR changes besides.
+	docsKind     bool
+	docsPaths    []string
+	docsKindCode []string
+	// detectRenames defaults the kind and release note of PRs that only
+	// rename files; renameOnly is set when the PR is one.
+	detectRenames bool
+	renameOnly    bool
+	// botPolicy, if set, defaults the kind and release note of the PRs of
+	// dependency update bots; botAuthored is set when the PR is one, and
+	// prTitle is its title.
+	botPolicy   *BotPolicy
+	botAuthored bool
+	prTitle     string
+	// detectReverts labels revert PRs. revert is set when the PR is one, and
+	// revertedPR and revertedKinds are the number and kinds of the PR it
+	// reverts, if found; revertInherited is set when the PR took its kinds.
+	detectReverts   bool
+	revert          bool
+	revertedPR      int
+	revertedKinds   map[string]bool
+	revertInherited bool
+	// stack is the PR this one is stacked on with /stacked-on, if any;
+	// stackInherited is set when the PR took its kinds.
+	stack          *stackedPR
+	stackInherited bool
+	// riskScoring labels the PR with its risk, scored with kindWeights.
+	riskScoring bool
+	kindWeights map[string]int
+	// moduleLabels labels the Go modules, rooted at moduleRoots, that the PR
+	// changes.
+	moduleLabels bool
+	moduleRoots  []string
+	// needsLabels enables the needs-* labels; mergeableState is the PR's
+	// mergeable state, e.g. dirty when it conflicts with its base branch,
+	// polled mergeablePollInterval apart while unknown.
+	needsLabels           bool
+	mergeableState        string
+	mergeablePollInterval time.Duration
+	// ciLabels enables the ci/failing label, from ciFailures, the failing
+	// checks of the PR head commit.
+	ciLabels   bool
+	ciFailures []string
+	// approvalLabel is removed once the PR gets commits after approvedSHA,
+	// the commit of its latest approving review.
+	approvalLabel string
+	approvedSHA   string
+	// ignoredPaths are path patterns; PRs changing only matching files are
+	// not validated.
+	ignoredPaths []string
+	// skipped is set when the last evaluation was skipped for ignoredPaths.
+	skipped bool
+	// quarantined is set when the last evaluation only evaluated the head of
+	// an oversized PR body.
+	quarantined bool
+	// exportMetadata exports the PR metadata in the check run and, if set,
+	// to metadataBranch.
+	exportMetadata bool
+	metadataBranch string
+	// labelCache, if set, supplies current labels across runs.
+	labelCache LabelCache
+	// detectSecrets enables the possible-secret validator.
+	detectSecrets bool
+	// secretNotifier, if set, is told when a possible secret is flagged.
+	secretNotifier SecretNotifier
+	// reporters are sent the report of every applied plan.
+	reporters []Reporter
+	// secretKinds names the kinds of credential found during evaluation.
+	secretKinds []string
+	// spam configures the spam heuristics.
+	spam SpamHeuristics
+	// changedFileCount is the number of files the PR changes, as reported by
+	// GitHub, when changedFiles is not known.
+	changedFileCount      int
+	changedFileCountKnown bool
+	// authorCreatedAt is when the PR author's account was created.
+	authorCreatedAt time.Time
+	// suspectedSpam lists the spam heuristics the PR matched.
+	suspectedSpam []string
+	// authorPolicies relax validation by author association.
+	authorPolicies map[string]AuthorPolicy
+	// authorLogin is the PR author's login.
+	authorLogin string
+	// authorAssociation is the PR author's association with the repository.
+	authorAssociation string
+	// teamResolver checks team membership for team-keyed policies and commands.
+	teamResolver TeamResolver
+	// authorTeams records the author's membership of the teams policies and
+	// command restrictions refer to.
+	authorTeams map[string]bool
+	// pr caches the PR for checks that need more than its body.
+	pr *github.PullRequest
+	// stickyComment enables the comment telling authors what to fix.
+	stickyComment bool
+	// checkRun enables reporting the result as a check run on headSHA.
+	checkRun bool
+	headSHA  string
+	// checkRunBlocking concludes the check run, or commit status, as failure
+	// for invalid PRs.
+	checkRunBlocking bool
+	// commitStatus enables reporting the result as a commit status on headSHA.
+	commitStatus bool
+	// readOnlyLabels discards every label change, and disabledLabels those
+	// of the labels they match.
+	readOnlyLabels bool
+	disabledLabels []string
+	// reviewCommands enables the commands in review summaries; reviews
+	// caches the PR's reviews once listed, and reviewBodies are the summaries
+	// of the maintainers' reviews.
+	reviewCommands bool
+	reviews        []*github.PullRequestReview
+	reviewBodies   []string
+	// commentCommands enables the /kind commands in comments; commentBodies
+	// are the comments of users who can write to the repository, whose
+	// permission is cached in writers, and ignoredCommenters the users whose
+	// commands were ignored.
+	commentCommands   bool
+	commentBodies     []string
+	writers           map[string]bool
+	ignoredCommenters []string
+	// existingComment is the sticky comment as fetched before evaluation.
+	existingComment *github.IssueComment
+	// comments caches the PR's comments once listed.
+	comments []*github.IssueComment
+	// mergeBlockers enables the merge blockers comment; blockersComment is
+	// the existing one, if any.
+	mergeBlockers   bool
+	blockersComment *github.IssueComment
+	// commentInterval is the least time between new comments on the PR.
+	commentInterval time.Duration
+	// prevState is the state kept in existingComment, and state the state
+	// after the last evaluation.
+	prevState commentState
+	state     commentState
+	// releaseNoteOverride is the release note a maintainer set with a
+	// comment command, if any.
+	releaseNoteOverride *stateReleaseNote
+	// failureStore, if set, counts validation failures per author.
+	failureStore FailureStore
+	// escalation configures guidance for authors who keep failing validation.
+	escalation Escalation
+	// authorFailures are the author's PRs that failed validation before.
+	authorFailures []string
+	// triage is the rotation of users and teams assigned to blocked PRs.
+	triage []string
+	// now returns the current time; tests inject a fixed clock via WithClock.
+	now func() time.Time
 }
 
 // New creates a new Labeler instance.
@@ -81,49 +279,118 @@ func New(client *github.Client, owner, repo string, prNum int, enforceDescriptio
 		enforceDescription:              enforceDescription,
 		enforceReleaseNoteQuality:       enforceReleaseNoteQuality,
 		enforceChangelogKindExclusivity: enforceChangelogKindExclusivity,
+		now:                             time.Now,
+		mergeablePollInterval:           mergeablePollInterval,
+		supportedKinds:                  kinds.SupportedKinds,
+		kindAliases:                     kinds.DeprecatedKindMap,
 	}
 }
 
-// ProcessPR processes the PR body and updates labels accordingly.
-func (l *labeler) ProcessPR(ctx context.Context, body string, syncLabels bool) error {
-	// fetch current labels
-	if err := l.fetchLabels(ctx); err != nil {
-		return err
+// WithClock replaces the clock used by the labeler, so runs can be reproduced
+// with a fixed time.
+func (l *labeler) WithClock(now func() time.Time) *labeler {
+	l.now = now
+	return l
+}
+
+// ProcessPR processes the PR body and updates labels accordingly: it plans
+// the changes and, if syncLabels is set, applies them. The Result tells what
+// was found and done; it is nil if the PR could not be evaluated. The error
+// joins the validation and operational failures, for Partition to tell apart.
+func (l *labeler) ProcessPR(ctx context.Context, body string, syncLabels bool) (*Result, error) {
+	p, err := l.Plan(ctx, body)
+	if p == nil {
+		return nil, err
+	}
+	errs := appendErrs(nil, err)
+	if syncLabels {
+		errs = appendErrs(errs, l.Apply(ctx, p))
+	}
+	return l.result(syncLabels), joinErrs(errs...)
+}
+
+// evaluate validates the PR body against the current labels and records the
+// label changes to make. It does not call the GitHub API.
+func (l *labeler) evaluate(body string) []error {
+	l.body = body
+	l.skipped = l.onlyIgnoredPaths()
+	if l.skipped {
+		l.problems = nil
+		return nil
 	}
+	body, l.quarantined = quarantineBody(body)
 	// normalize line endings to \n (GitHub returns \r\n)
 	body = strings.ReplaceAll(body, "\r\n", "\n")
 	// strip HTML comments to make the body easier to parse.
-	sanitizedBody := commentRE.ReplaceAllString(body, "")
+	sanitizedBody := stripComments(body)
 
 	var errs []error
-	if err := l.processKindLabels(sanitizedBody); err != nil {
-		errs = append(errs, err)
+	// front-matter fields take precedence over the body's commands
+	sanitizedBody, err := l.applyFrontMatter(sanitizedBody)
+	if err != nil {
+		errs = append(errs, checked(CheckFrontMatter, err))
+	}
+	kindErr := l.processKindLabels(sanitizedBody)
+	if kindErr != nil {
+		errs = append(errs, checked(CheckKind, kindErr))
+	}
+	noteErr := l.processReleaseNotes(sanitizedBody)
+	if noteErr != nil {
+		errs = append(errs, checked(CheckReleaseNote, noteErr))
 	}
-	if err := l.processReleaseNotes(sanitizedBody); err != nil {
-		errs = append(errs, err)
+	l.processNeedsLabels(kindErr != nil, noteErr != nil)
+	if err := l.processUpgradeDocs(); err != nil {
+		errs = append(errs, checked(CheckUpgradeDocs, err))
 	}
-	if l.enforceDescription {
+	if err := l.processDocsKind(); err != nil {
+		errs = append(errs, checked(CheckDocsKind, err))
+	}
+	l.processModuleLabels()
+	l.processRevertLabel()
+	l.processRiskLabel()
+	l.processCILabels()
+	if l.enforceDescription && !l.botAuthored {
 		if err := l.processDescription(sanitizedBody); err != nil {
-			errs = append(errs, err)
+			errs = append(errs, checked(CheckDescription, err))
 		}
 	}
-	if syncLabels {
-		if err := l.syncLabels(ctx); err != nil {
-			errs = append(errs, err)
-		}
+	if err := l.processMilestone(sanitizedBody); err != nil {
+		errs = append(errs, checked(CheckMilestone, err))
+	}
+	if l.processSpam(sanitizedBody) {
+		errs = nil
 	}
-	return joinErrs(errs...)
+	l.processQuarantine()
+	// secrets are flagged even on likely spam, which is where they get scraped
+	if err := l.processSecrets(body); err != nil {
+		errs = append([]error{checked(CheckSecret, err)}, errs...)
+	}
+	l.dropLabelChanges()
+	l.problems = errs
+	l.updateState()
+	return errs
 }
 
 // fetchLabels fetches the current labels for the PR
 func (l *labeler) fetchLabels(ctx context.Context) error {
+	if l.labelCache != nil {
+		current, err := l.fetchCachedLabels(ctx)
+		if err != nil {
+			return err
+		}
+		l.currentMap = map[string]bool{}
+		for _, label := range current {
+			l.currentMap[l.defaultLabel(label)] = true
+		}
+		return nil
+	}
 	current, _, err := l.client.Issues.ListLabelsByIssue(ctx, l.owner, l.repo, l.prNum, nil)
 	if err != nil {
-		return fmt.Errorf("failed to list labels: %w", err)
+		return apiError(err, permPullRequestsRead, "list labels")
 	}
 	currentMap := map[string]bool{}
 	for _, L := range current {
-		currentMap[L.GetName()] = true
+		currentMap[l.defaultLabel(L.GetName())] = true
 	}
 	l.currentMap = currentMap
 	return nil
@@ -131,7 +398,34 @@ func (l *labeler) fetchLabels(ctx context.Context) error {
 
 // processKindLabels handles the extraction and validation of kind labels
 func (l *labeler) processKindLabels(body string) error {
+	l.deprecatedKinds = map[string]bool{}
+	for label := range l.currentMap {
+		if kind, ok := strings.CutPrefix(label, "kind/"); ok && l.kindAliases[kind] != "" {
+			l.deprecatedKinds[kind] = true
+		}
+	}
 	kinds := l.extractKinds(body)
+	for _, review := range l.reviewBodies {
+		maps.Copy(kinds, l.extractKinds(review))
+	}
+	for _, comment := range l.commentBodies {
+		maps.Copy(kinds, l.extractKinds(comment))
+	}
+	l.revertInherited = len(kinds) == 0 && len(l.revertedKinds) > 0
+	if l.revertInherited {
+		kinds = maps.Clone(l.revertedKinds)
+	}
+	l.stackInherited = len(kinds) == 0 && len(l.stackKinds()) > 0
+	if l.stackInherited {
+		kinds = maps.Clone(l.stackKinds())
+	}
+	if len(kinds) == 0 && l.renameOnly {
+		kinds = map[string]bool{renameOnlyKind: true}
+	}
+	if len(kinds) == 0 && l.botAuthored {
+		kinds = map[string]bool{l.botPolicy.kind(): true}
+	}
+	l.kinds = kinds
 	if err := l.verifyKinds(kinds); err != nil {
 		return err
 	}
@@ -141,11 +435,11 @@ func (l *labeler) processKindLabels(body string) error {
 // extractKinds extracts all /kind commands from the PR body
 func (l *labeler) extractKinds(body string) map[string]bool {
 	parsedKinds := map[string]bool{}
-	for _, match := range kindRE.FindAllStringSubmatch(body, -1) {
-		kind := strings.ToLower(match[1])
+	for _, kind := range scanKinds(l.normalizeKindCommands(body)) {
 		// temporary migration: if the kind is deprecated, use the new kind
-		newKind, ok := kinds.DeprecatedKindMap[kind]
+		newKind, ok := l.kindAliases[kind]
 		if ok {
+			l.deprecatedKinds[kind] = true
 			parsedKinds[newKind] = true
 			continue
 		}
@@ -154,29 +448,134 @@ func (l *labeler) extractKinds(body string) map[string]bool {
 	return parsedKinds
 }
 
+// scanKinds returns the lowercased kinds of the /kind commands in body, in
+// a single pass over its lines. It matches what kindRE matches, but without
+// the regexp engine's per-match overhead, which dominates on large bodies.
+func scanKinds(body string) []string {
+	var found []string
+	for len(body) > 0 {
+		line, rest, hasNext := strings.Cut(body, "\n")
+		body = rest
+		if len(line) < len("/kind") || !strings.EqualFold(line[:len("/kind")], "/kind") {
+			continue
+		}
+		args := line[len("/kind"):]
+		kind := strings.TrimLeft(args, " \t\f\r")
+		switch {
+		case kind == "" && hasNext:
+			// like kindRE, take the kind from the next line
+			kind = strings.TrimLeft(body, " \t\n\f\r")
+		case len(kind) == len(args):
+			continue
+		}
+		n := strings.IndexFunc(kind, func(r rune) bool { return !isKindChar(r) })
+		if n < 0 {
+			n = len(kind)
+		}
+		if n > 0 {
+			found = append(found, strings.ToLower(kind[:n]))
+		}
+	}
+	return found
+}
+
+func isKindChar(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_' || r == '/' || r == '-'
+}
+
+// stripComments removes HTML comments from body so example code isn't
+// parsed. An unterminated comment is left as is.
+func stripComments(body string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(body, "<!--")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(body[start+len("<!--"):], "-->")
+		if end < 0 {
+			break
+		}
+		sb.WriteString(body[:start])
+		body = body[start+len("<!--")+end+len("-->"):]
+	}
+	if sb.Len() == 0 {
+		return body
+	}
+	sb.WriteString(body)
+	return sb.String()
+}
+
+// commandLines returns the lines of body that start with prefix, ignoring
+// case, so that line-anchored command regexps only run over candidate lines
+// rather than the whole body.
+func commandLines(body, prefix string) string {
+	var sb strings.Builder
+	for len(body) > 0 {
+		var line string
+		line, body, _ = strings.Cut(body, "\n")
+		if len(line) >= len(prefix) && strings.EqualFold(line[:len(prefix)], prefix) {
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// descriptionSection returns the content under the # Description heading
+// until the next level-1 heading or the end of body. Only # followed by a
+// space or tab ends the section, not ## or ### (level-2+).
+func descriptionSection(body string) (string, bool) {
+	start := -1
+	for off := 0; off < len(body); {
+		line, _, hasNext := strings.Cut(body[off:], "\n")
+		switch {
+		case start < 0:
+			if hasNext && isDescriptionHeading(line) {
+				start = off + len(line) + 1
+			}
+		case len(line) > 1 && line[0] == '#' && (line[1] == ' ' || line[1] == '\t'):
+			return body[start:off], true
+		}
+		off += len(line) + 1
+	}
+	if start < 0 {
+		return "", false
+	}
+	return body[start:], true
+}
+
+func isDescriptionHeading(line string) bool {
+	title, ok := strings.CutPrefix(line, "#")
+	if !ok {
+		return false
+	}
+	title, ok = strings.CutPrefix(strings.TrimLeft(title, " \t"), "Description")
+	return ok && strings.TrimLeft(title, " \t") == ""
+}
+
 // verifyKinds checks if all extracted kinds are supported
 func (l *labeler) verifyKinds(extractedKinds map[string]bool) error {
 	if len(extractedKinds) == 0 {
 		if !l.currentMap[labels.InvalidKindLabel] {
 			l.labelsToAdd[labels.InvalidKindLabel] = true
 		}
-		return fmt.Errorf("no /kind labels found, labeling %q. supported kinds: %v", labels.InvalidKindLabel, slices.Collect(maps.Keys(kinds.SupportedKinds)))
+		return fmt.Errorf("no /kind labels found, labeling %q. supported kinds: %s", labels.InvalidKindLabel, l.renderKinds())
 	}
-	for k := range extractedKinds {
-		if kinds.SupportedKinds[k] {
+	for _, k := range sortedKeys(extractedKinds) {
+		if l.supportedKinds[k] {
 			continue
 		}
 		if !l.currentMap[labels.InvalidKindLabel] {
 			l.labelsToAdd[labels.InvalidKindLabel] = true
 		}
-		return fmt.Errorf("invalid /kind %q detected, labeling %q. supported kinds: %v", k, labels.InvalidKindLabel, slices.Collect(maps.Keys(kinds.SupportedKinds)))
+		return fmt.Errorf("invalid /kind %q detected, labeling %q. supported kinds: %s", k, labels.InvalidKindLabel, l.renderKinds())
 	}
 	if l.enforceChangelogKindExclusivity {
 		if invalidKinds := invalidChangelogKindCombination(extractedKinds); len(invalidKinds) > 0 {
 			if !l.currentMap[labels.InvalidKindLabel] {
 				l.labelsToAdd[labels.InvalidKindLabel] = true
 			}
-			return fmt.Errorf("multiple changelog /kind labels detected: %v. Choose exactly one changelog kind per PR so the generated changelog has one category. Changelog kinds are: %v", invalidKinds, slices.Collect(maps.Keys(changelogKinds)))
+			return fmt.Errorf("multiple changelog /kind labels detected: %v. Choose exactly one changelog kind per PR so the generated changelog has one category. Changelog kinds are: %s", invalidKinds, strings.Join(sortedKeys(changelogKinds), ", "))
 		}
 	}
 	if l.currentMap[labels.InvalidKindLabel] {
@@ -216,7 +615,7 @@ func (l *labeler) syncKindLabels(extractedKinds map[string]bool) error {
 			continue
 		}
 		currentKindType := strings.TrimPrefix(label, "kind/")
-		if newKindEquivalent, isDeprecated := kinds.DeprecatedKindMap[currentKindType]; isDeprecated {
+		if newKindEquivalent, isDeprecated := l.kindAliases[currentKindType]; isDeprecated {
 			if extractedKinds[newKindEquivalent] {
 				l.labelsToRemove[label] = true
 				continue
@@ -237,10 +636,37 @@ func (l *labeler) processReleaseNotes(body string) error {
 	if l.currentMap[labels.DeprecatedReleaseNoteLabel] {
 		l.labelsToRemove[labels.DeprecatedReleaseNoteLabel] = true
 	}
+	l.releaseNote = nil
+	if l.releaseNoteOverride != nil {
+		l.applyReleaseNoteOverride(l.releaseNoteOverride)
+		return nil
+	}
+	if epic := epicOf(body); epic != 0 {
+		return l.processEpic(epic)
+	}
 
 	// validate the release note block is present
-	match := releaseNoteRE.FindStringSubmatch(body)
-	if len(match) < 2 {
+	block, fence, found := l.findReleaseNote(body)
+	l.releaseNoteFence = ""
+	if found && fence != releaseNoteFence {
+		l.releaseNoteFence = fence
+	}
+	if !found && l.stack != nil {
+		return l.processStackedNote()
+	}
+	if l.conflictingReleaseNotes(body) {
+		l.markInvalidReleaseNote()
+		return fmt.Errorf("conflicting ```release-note``` blocks: one is NONE and another has a note; keep only the one that applies")
+	}
+	if strings.TrimSpace(block) == "" && l.botAuthored {
+		l.applyBotReleaseNote()
+		return nil
+	}
+	if strings.TrimSpace(block) == "" && (l.renameOnly || l.autoNoneReleaseNote()) {
+		l.markNoneReleaseNote()
+		return nil
+	}
+	if !found {
 		if !l.currentMap[labels.InvalidReleaseNoteLabel] {
 			l.labelsToAdd[labels.InvalidReleaseNoteLabel] = true
 		}
@@ -254,39 +680,39 @@ func (l *labeler) processReleaseNotes(body string) error {
 	}
 
 	// process the release note block
-	entry := strings.TrimSpace(match[1])
+	entry := strings.TrimSpace(block)
 	switch {
 	case entry == "":
 		l.markInvalidReleaseNote()
 		return fmt.Errorf("missing or empty ```release-note``` block; please add your line or 'NONE'")
 	case strings.EqualFold(entry, "NONE"):
 		// handle special NONE case
-		if !l.currentMap[labels.ReleaseNoteNoneLabel] {
-			l.labelsToAdd[labels.ReleaseNoteNoneLabel] = true
+		l.markNoneReleaseNote()
+	default:
+		category, note := changelog.ParseCategory(entry)
+		if _, ok := changelog.Lookup(category); category != "" && !ok {
+			l.markInvalidReleaseNote()
+			return fmt.Errorf("unknown release note category [%s]; use one of %s, or remove the prefix to file the note by /kind", category, changelog.IDs())
 		}
-		if l.currentMap[labels.InvalidReleaseNoteLabel] {
-			l.labelsToRemove[labels.InvalidReleaseNoteLabel] = true
+		if strings.TrimSpace(note) == "" {
+			l.markInvalidReleaseNote()
+			return fmt.Errorf("empty release note after the [%s] category; please add your line", category)
 		}
-		if l.currentMap[labels.ReleaseNoteLabel] {
-			l.labelsToRemove[labels.ReleaseNoteLabel] = true
+		if l.maxNoteLength > 0 {
+			if err := noteTooLong(note, l.maxNoteLength); err != nil {
+				l.markInvalidReleaseNote()
+				return err
+			}
 		}
-	default:
 		if l.enforceReleaseNoteQuality {
-			if err := validateReleaseNote(entry); err != nil {
+			if err := validateReleaseNote(note, l.releaseNoteLengthLimit()); err != nil {
 				l.markInvalidReleaseNote()
 				return err
 			}
 		}
+		l.releaseNote = &ReleaseNote{Note: note, Category: category, Section: changelog.SectionFor(l.kinds, category)}
 		// validate release note was found
-		if !l.currentMap[labels.ReleaseNoteLabel] {
-			l.labelsToAdd[labels.ReleaseNoteLabel] = true
-		}
-		if l.currentMap[labels.InvalidReleaseNoteLabel] {
-			l.labelsToRemove[labels.InvalidReleaseNoteLabel] = true
-		}
-		if l.currentMap[labels.ReleaseNoteNoneLabel] {
-			l.labelsToRemove[labels.ReleaseNoteNoneLabel] = true
-		}
+		l.markReleaseNote()
 	}
 	return nil
 }
@@ -303,10 +729,36 @@ func (l *labeler) markInvalidReleaseNote() {
 	}
 }
 
-func validateReleaseNote(entry string) error {
+// markReleaseNote labels the PR as having a release note.
+func (l *labeler) markReleaseNote() {
+	if !l.currentMap[labels.ReleaseNoteLabel] {
+		l.labelsToAdd[labels.ReleaseNoteLabel] = true
+	}
+	if l.currentMap[labels.InvalidReleaseNoteLabel] {
+		l.labelsToRemove[labels.InvalidReleaseNoteLabel] = true
+	}
+	if l.currentMap[labels.ReleaseNoteNoneLabel] {
+		l.labelsToRemove[labels.ReleaseNoteNoneLabel] = true
+	}
+}
+
+// markNoneReleaseNote labels the PR as not needing a release note.
+func (l *labeler) markNoneReleaseNote() {
+	if !l.currentMap[labels.ReleaseNoteNoneLabel] {
+		l.labelsToAdd[labels.ReleaseNoteNoneLabel] = true
+	}
+	if l.currentMap[labels.InvalidReleaseNoteLabel] {
+		l.labelsToRemove[labels.InvalidReleaseNoteLabel] = true
+	}
+	if l.currentMap[labels.ReleaseNoteLabel] {
+		l.labelsToRemove[labels.ReleaseNoteLabel] = true
+	}
+}
+
+func validateReleaseNote(entry string, maxLength int) error {
 	var reasons []string
-	if len(entry) > maxReleaseNoteLength {
-		reasons = append(reasons, fmt.Sprintf("must be %d characters or fewer", maxReleaseNoteLength))
+	if len(entry) > maxLength {
+		reasons = append(reasons, fmt.Sprintf("must be %d characters or fewer", maxLength))
 	}
 	for _, r := range entry {
 		if r > 127 {
@@ -344,15 +796,15 @@ func validateReleaseNote(entry string) error {
 // processDescription handles the description validation and labeling
 func (l *labeler) processDescription(body string) error {
 	// validate the description block is present
-	match := descriptionRE.FindStringSubmatch(body)
-	if len(match) < 2 {
+	section, ok := descriptionSection(body)
+	if !ok {
 		if !l.currentMap[labels.InvalidDescriptionLabel] {
 			l.labelsToAdd[labels.InvalidDescriptionLabel] = true
 		}
 		return fmt.Errorf("missing # Description section in PR body; please add a description explaining the changes")
 	}
 	// check if the description content is meaningful (not empty or just whitespace)
-	descriptionContent := strings.TrimSpace(match[1])
+	descriptionContent := strings.TrimSpace(section)
 	if descriptionContent == "" {
 		if !l.currentMap[labels.InvalidDescriptionLabel] {
 			l.labelsToAdd[labels.InvalidDescriptionLabel] = true
@@ -366,35 +818,39 @@ func (l *labeler) processDescription(body string) error {
 	return nil
 }
 
-func (l *labeler) syncLabels(ctx context.Context) error {
+// syncLabels adds and removes the labels of p.
+func (l *labeler) syncLabels(ctx context.Context, p *Plan) error {
 	var errs []error
-	labelsToAdd := make([]string, 0, len(l.labelsToAdd))
-	for k := range l.labelsToAdd {
-		labelsToAdd = append(labelsToAdd, k)
-	}
-	sort.Strings(labelsToAdd)
-
-	_, _, err := l.client.Issues.AddLabelsToIssue(ctx, l.owner, l.repo, l.prNum, labelsToAdd)
-	if err != nil {
-		errs = append(errs, fmt.Errorf("failed to add labels %q: %w", labelsToAdd, err))
-	}
-
-	labelsToRemove := make([]string, 0, len(l.labelsToRemove))
-	for k := range l.labelsToRemove {
-		labelsToRemove = append(labelsToRemove, k)
+	if len(p.AddLabels) > 0 {
+		if _, _, err := l.client.Issues.AddLabelsToIssue(ctx, l.owner, l.repo, l.prNum, p.AddLabels); err != nil {
+			errs = append(errs, apiError(err, permPullRequestsWrite, "add labels %q", p.AddLabels))
+		}
 	}
-	sort.Strings(labelsToRemove)
 
-	for _, label := range labelsToRemove {
-		_, err = l.client.Issues.RemoveLabelForIssue(ctx, l.owner, l.repo, l.prNum, label)
+	for _, label := range p.RemoveLabels {
+		_, err := l.client.Issues.RemoveLabelForIssue(ctx, l.owner, l.repo, l.prNum, labels.EncodeName(label))
 		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to remove label %q: %w", label, err))
+			apiErr := apiError(err, permPullRequestsWrite, "remove label %q", label)
+			apiErr.Label = label
+			errs = append(errs, apiErr)
 		}
 	}
+	l.cacheSyncedLabels(p, len(errs) == 0)
 
 	return errors.Join(errs...)
 }
 
+// sortedKeys returns the keys of m in sorted order so output built from sets
+// is stable across runs.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 type joinError []error
 
 // Error implements error.
@@ -413,6 +869,22 @@ func (j joinError) Error() string {
 	return sb.String()
 }
 
+// Unwrap returns the joined errors so errors.As and errors.Is see each of them.
+func (j joinError) Unwrap() []error {
+	return j
+}
+
+// appendErrs appends err to errs, flattening the errors it joins, if any.
+func appendErrs(errs []error, err error) []error {
+	switch e := err.(type) {
+	case nil:
+		return errs
+	case joinError:
+		return append(errs, e...)
+	}
+	return append(errs, err)
+}
+
 func joinErrs(errs ...error) error {
 	if len(errs) == 0 {
 		return nil
@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/url"
 	"reflect"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
@@ -60,7 +61,7 @@ func TestProcessPR_NoKindSupplied(t *testing.T) {
 
 	c := github.NewClient(httpClient)
 	l := New(c, "foo", "bar", 42, false)
-	err := l.ProcessPR(context.Background(), "```release-note\nOK\n```", true)
+	_, err := l.ProcessPR(context.Background(), "```release-note\nOK\n```", true)
 	if err == nil || !strings.Contains(err.Error(), "no /kind") {
 		t.Fatalf("expected an error when no kind is supplied, got %v", err)
 	}
@@ -113,7 +114,7 @@ func TestProcessPR_InvalidKind(t *testing.T) {
 	)
 	c := github.NewClient(httpClient)
 	l := New(c, "foo", "bar", 42, false)
-	err := l.ProcessPR(context.Background(), "/kind banana\n```release-note\nOK\n```", true)
+	_, err := l.ProcessPR(context.Background(), "/kind banana\n```release-note\nOK\n```", true)
 	if err == nil || !strings.Contains(err.Error(), "invalid /kind") {
 		t.Fatalf("expected kind-invalid error, got %v", err)
 	}
@@ -169,7 +170,7 @@ func TestProcessPR_ValidKind_InvalidReleaseNote(t *testing.T) {
 		),
 	)
 	l := New(github.NewClient(httpClient), "foo", "bar", 45, false)
-	err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\n\n```", true)
+	_, err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\n\n```", true)
 	if err == nil || !strings.Contains(err.Error(), "missing or empty") {
 		t.Fatalf("expected missing release-note error, got %v", err)
 	}
@@ -225,7 +226,7 @@ func TestProcessPR_ValidKindAndReleaseNote(t *testing.T) {
 		),
 	)
 	l := New(github.NewClient(httpClient), "foo", "bar", 43, false)
-	err := l.ProcessPR(context.Background(), "/kind feature\n```release-note\nNew feature implemented\n```", true)
+	_, err := l.ProcessPR(context.Background(), "/kind feature\n```release-note\nNew feature implemented\n```", true)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -281,7 +282,7 @@ func TestProcessPR_MultipleKinds(t *testing.T) {
 		),
 	)
 	l := New(github.NewClient(httpClient), "foo", "bar", 44, false)
-	err := l.ProcessPR(context.Background(), "/kind feature\n/kind cleanup\n```release-note\nCleanup and feature\n```", true)
+	_, err := l.ProcessPR(context.Background(), "/kind feature\n/kind cleanup\n```release-note\nCleanup and feature\n```", true)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -336,7 +337,7 @@ func TestProcessPR_ReleaseNoteNone(t *testing.T) {
 		),
 	)
 	l := New(github.NewClient(httpClient), "foo", "bar", 46, false)
-	err := l.ProcessPR(context.Background(), "/kind cleanup\n```release-note\nNONE\n```", true)
+	_, err := l.ProcessPR(context.Background(), "/kind cleanup\n```release-note\nNONE\n```", true)
 	if err != nil {
 		t.Fatalf("expected no error on NONE, got %v", err)
 	}
@@ -422,7 +423,7 @@ func TestValidateReleaseNoteQuality(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := validateReleaseNote(tc.entry)
+			err := validateReleaseNote(tc.entry, maxReleaseNoteLength)
 			if tc.wantError == "" {
 				if err != nil {
 					t.Fatalf("expected no error, got %v", err)
@@ -627,8 +628,8 @@ func TestProcessPR_EditedToInvalid(t *testing.T) {
 			mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
 			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				pathPrefix := fmt.Sprintf("/repos/%s/%s/issues/%d/labels/", "foo", "bar", 47)
-				labelNameSegment := strings.TrimPrefix(r.URL.Path, pathPrefix)
-				decodedLabelName, err := url.PathUnescape(labelNameSegment)
+				labelNameSegment := strings.TrimPrefix(r.URL.EscapedPath(), pathPrefix)
+				decodedLabelName, err := labels.DecodeName(labelNameSegment)
 				if err != nil {
 					t.Fatalf("Failed to unescape label name segment '%s': %v", labelNameSegment, err)
 				}
@@ -639,7 +640,7 @@ func TestProcessPR_EditedToInvalid(t *testing.T) {
 	)
 
 	l := New(github.NewClient(httpClient), "foo", "bar", 47, false)
-	err := l.ProcessPR(context.Background(), "/kind fix\nNo release-note here", true)
+	_, err := l.ProcessPR(context.Background(), "/kind fix\nNo release-note here", true)
 	if err == nil || !strings.Contains(err.Error(), "missing or empty ```release-note``` block") {
 		t.Fatalf("ProcessPR error expected to contain 'missing or empty ```release-note``` block', got: %v", err.Error())
 	}
@@ -690,8 +691,8 @@ func TestProcessPR_EditedToValid(t *testing.T) {
 			mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
 			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				pathPrefix := fmt.Sprintf("/repos/%s/%s/issues/%d/labels/", "foo", "bar", 47)
-				labelNameSegment := strings.TrimPrefix(r.URL.Path, pathPrefix)
-				decodedLabelName, err := url.PathUnescape(labelNameSegment)
+				labelNameSegment := strings.TrimPrefix(r.URL.EscapedPath(), pathPrefix)
+				decodedLabelName, err := labels.DecodeName(labelNameSegment)
 				if err != nil {
 					t.Fatalf("Failed to unescape label name segment '%s': %v", labelNameSegment, err)
 				}
@@ -702,7 +703,7 @@ func TestProcessPR_EditedToValid(t *testing.T) {
 	)
 
 	l := New(github.NewClient(httpClient), "foo", "bar", 47, false)
-	err := l.ProcessPR(context.Background(), "/kind fix\\n```release-note\\nFixed it\\n```", true)
+	_, err := l.ProcessPR(context.Background(), "/kind fix\\n```release-note\\nFixed it\\n```", true)
 	if err != nil {
 		t.Fatalf("expected no error from ProcessPR, got %v", err)
 	}
@@ -815,8 +816,8 @@ func TestProcessPR_LabelMigrationTableDriven(t *testing.T) {
 					mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
 					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 						pathPrefix := fmt.Sprintf("/repos/%s/%s/issues/%d/labels/", "owner", "repo", tc.prNum)
-						labelNameSegment := strings.TrimPrefix(r.URL.Path, pathPrefix)
-						decodedLabelName, err := url.PathUnescape(labelNameSegment)
+						labelNameSegment := strings.TrimPrefix(r.URL.EscapedPath(), pathPrefix)
+						decodedLabelName, err := labels.DecodeName(labelNameSegment)
 						if err != nil {
 							t.Fatalf("Failed to unescape label name segment '%s': %v", labelNameSegment, err)
 						}
@@ -828,7 +829,7 @@ func TestProcessPR_LabelMigrationTableDriven(t *testing.T) {
 			)
 
 			l := New(github.NewClient(httpClient), "owner", "repo", tc.prNum, false)
-			err := l.ProcessPR(context.Background(), tc.prBody, true)
+			_, err := l.ProcessPR(context.Background(), tc.prBody, true)
 			if err != nil {
 				t.Fatalf("Expected no error, but got: %v", err)
 			}
@@ -891,8 +892,8 @@ func TestProcessPR_RemovesKindInvalid_WhenValidKindProvided(t *testing.T) {
 			mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
 			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				pathPrefix := fmt.Sprintf("/repos/%s/%s/issues/%d/labels/", "owner", "repo", prNum)
-				labelNameSegment := strings.TrimPrefix(r.URL.Path, pathPrefix)
-				decodedLabelName, err := url.PathUnescape(labelNameSegment)
+				labelNameSegment := strings.TrimPrefix(r.URL.EscapedPath(), pathPrefix)
+				decodedLabelName, err := labels.DecodeName(labelNameSegment)
 				if err != nil {
 					t.Fatalf("Failed to unescape label name segment '%s': %v", labelNameSegment, err)
 				}
@@ -904,7 +905,7 @@ func TestProcessPR_RemovesKindInvalid_WhenValidKindProvided(t *testing.T) {
 	)
 
 	l := New(github.NewClient(httpClient), "owner", "repo", prNum, false)
-	err := l.ProcessPR(context.Background(), "/kind feature\\n```release-note\\nNONE\\n```", true)
+	_, err := l.ProcessPR(context.Background(), "/kind feature\\n```release-note\\nNONE\\n```", true)
 	if err != nil {
 		t.Fatalf("Expected no error, but got: %v", err)
 	}
@@ -961,7 +962,7 @@ func TestProcessPR_MissingDescription(t *testing.T) {
 
 	c := github.NewClient(httpClient)
 	l := New(c, "foo", "bar", 50, true)
-	err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nFixed bug\n```", true)
+	_, err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nFixed bug\n```", true)
 	if err == nil || !strings.Contains(err.Error(), "missing # Description section") {
 		t.Fatalf("expected missing Description error, got %v", err)
 	}
@@ -1020,7 +1021,7 @@ func TestProcessPR_EmptyDescription(t *testing.T) {
 	c := github.NewClient(httpClient)
 	l := New(c, "foo", "bar", 51, true)
 	prBody := "# Description\n\n# Change Type\n/kind fix\n\n```release-note\nFixed bug\n```"
-	err := l.ProcessPR(context.Background(), prBody, true)
+	_, err := l.ProcessPR(context.Background(), prBody, true)
 	if err == nil || !strings.Contains(err.Error(), "empty # Description section") {
 		t.Fatalf("expected empty Description error, got %v", err)
 	}
@@ -1078,7 +1079,7 @@ func TestProcessPR_ValidDescription(t *testing.T) {
 	c := github.NewClient(httpClient)
 	l := New(c, "foo", "bar", 52, true)
 	prBody := "# Description\n\nThis PR fixes a critical bug in the authentication flow.\n\n# Change Type\n/kind fix\n\n```release-note\nFixed authentication bug\n```"
-	err := l.ProcessPR(context.Background(), prBody, true)
+	_, err := l.ProcessPR(context.Background(), prBody, true)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -1136,7 +1137,7 @@ func TestProcessPR_DescriptionValidationDisabled(t *testing.T) {
 	c := github.NewClient(httpClient)
 	l := New(c, "foo", "bar", 53, false)
 	// No description section, but validation is disabled
-	err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nFixed bug\n```", true)
+	_, err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nFixed bug\n```", true)
 	if err != nil {
 		t.Fatalf("expected no error when description validation disabled, got %v", err)
 	}
@@ -1189,8 +1190,8 @@ func TestProcessPR_RemovesInvalidDescription_WhenValidDescriptionProvided(t *tes
 			mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
 			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				pathPrefix := fmt.Sprintf("/repos/%s/%s/issues/%d/labels/", "foo", "bar", 54)
-				labelNameSegment := strings.TrimPrefix(r.URL.Path, pathPrefix)
-				decodedLabelName, err := url.PathUnescape(labelNameSegment)
+				labelNameSegment := strings.TrimPrefix(r.URL.EscapedPath(), pathPrefix)
+				decodedLabelName, err := labels.DecodeName(labelNameSegment)
 				if err != nil {
 					t.Fatalf("Failed to unescape label name segment '%s': %v", labelNameSegment, err)
 				}
@@ -1203,7 +1204,7 @@ func TestProcessPR_RemovesInvalidDescription_WhenValidDescriptionProvided(t *tes
 	c := github.NewClient(httpClient)
 	l := New(c, "foo", "bar", 54, true)
 	prBody := "# Description\n\nThis PR fixes an important bug.\n\n# Change Type\n/kind fix\n\n```release-note\nFixed important bug\n```"
-	err := l.ProcessPR(context.Background(), prBody, true)
+	_, err := l.ProcessPR(context.Background(), prBody, true)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -1261,7 +1262,7 @@ func TestProcessPR_ValidDescriptionWithSubheadings(t *testing.T) {
 	c := github.NewClient(httpClient)
 	l := New(c, "foo", "bar", 55, true)
 	prBody := "# Description\n\n## Motivation\n\nThis fixes a bug.\n\n## Implementation\n\nUsed a different approach.\n\n# Change Type\n/kind fix\n\n```release-note\nFixed bug\n```"
-	err := l.ProcessPR(context.Background(), prBody, true)
+	_, err := l.ProcessPR(context.Background(), prBody, true)
 	if err != nil {
 		t.Fatalf("expected no error with subheadings in description, got %v", err)
 	}
@@ -1313,8 +1314,8 @@ func processPRForTest(t *testing.T, initialLabels []*github.Label, prBody string
 			mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
 			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				pathPrefix := fmt.Sprintf("/repos/%s/%s/issues/%d/labels/", "owner", "repo", prNum)
-				labelNameSegment := strings.TrimPrefix(r.URL.Path, pathPrefix)
-				decodedLabelName, err := url.PathUnescape(labelNameSegment)
+				labelNameSegment := strings.TrimPrefix(r.URL.EscapedPath(), pathPrefix)
+				decodedLabelName, err := labels.DecodeName(labelNameSegment)
 				if err != nil {
 					t.Fatalf("Failed to unescape label name segment '%s': %v", labelNameSegment, err)
 				}
@@ -1326,6 +1327,309 @@ func processPRForTest(t *testing.T, initialLabels []*github.Label, prBody string
 	)
 
 	l := New(github.NewClient(httpClient), "owner", "repo", prNum, false, enforceReleaseNoteQuality, enforceChangelogKindExclusivity)
-	err := l.ProcessPR(context.Background(), prBody, true)
+	_, err := l.ProcessPR(context.Background(), prBody, true)
 	return actualLabelsAdded, actualLabelsRemoved, err
 }
+
+func TestProcessPR_DeterministicErrors(t *testing.T) {
+	body := "/kind zebra\n/kind banana\n/kind apple\n```release-note\nOK\n```"
+	var first string
+	for i := 0; i < 20; i++ {
+		_, _, err := processPRForTest(t, []*github.Label{}, body)
+		if err == nil {
+			t.Fatal("expected an invalid kind error")
+		}
+		if i == 0 {
+			first = err.Error()
+			continue
+		}
+		if err.Error() != first {
+			t.Fatalf("expected stable error message across runs\nfirst: %s\ngot:   %s", first, err.Error())
+		}
+	}
+	if !strings.Contains(first, `invalid /kind "apple"`) {
+		t.Fatalf("expected the first invalid kind in sorted order to be reported, got %v", first)
+	}
+}
+
+func TestProcessPR_SupportedKindsRenderedInCanonicalOrder(t *testing.T) {
+	_, _, err := processPRForTest(t, []*github.Label{}, "```release-note\nOK\n```")
+	want := "supported kinds: breaking_change, bump, cleanup, deprecation, design, documentation, feature, fix, flake, install, test"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected error to contain %q, got %v", want, err)
+	}
+}
+
+func TestProcessPR_PartitionsValidationAndOperationalErrors(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			[]*github.Label{},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusForbidden, "Resource not accessible by integration")
+			}),
+		),
+	)
+	l := New(github.NewClient(httpClient), "foo", "bar", 56, false)
+	_, err := l.ProcessPR(context.Background(), "/kind banana\n```release-note\nOK\n```", true)
+	validation, operational := Partition(err)
+	if len(validation) != 1 || !strings.Contains(validation[0].Error(), "invalid /kind") {
+		t.Fatalf("expected one invalid kind validation error, got %v", validation)
+	}
+	if len(operational) != 1 || !strings.Contains(operational[0].Error(), "failed to add labels") {
+		t.Fatalf("expected one add labels operational error, got %v", operational)
+	}
+}
+
+func TestSimulate_ValidationErrorChecks(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Check
+	}{
+		{name: "invalid kind", body: "# Description\nFix.\n/kind banana\n```release-note\nNONE\n```", want: []Check{CheckKind}},
+		{name: "missing release note", body: "# Description\nFix.\n/kind fix", want: []Check{CheckReleaseNote}},
+		{name: "missing description", body: "/kind fix\n```release-note\nNONE\n```", want: []Check{CheckDescription}},
+		{name: "everything", body: "nothing", want: []Check{CheckKind, CheckReleaseNote, CheckDescription}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(nil, "foo", "bar", 1, true).Simulate(tt.body, nil)
+			validation, operational := Partition(err)
+			if len(operational) != 0 {
+				t.Fatalf("unexpected operational errors %v", operational)
+			}
+			var got []Check
+			for _, e := range validation {
+				got = append(got, e.(*ValidationError).Check)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("checks = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessPR_ListLabelsFailureIsOperational(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusInternalServerError, "boom")
+			}),
+		),
+	)
+	l := New(github.NewClient(httpClient), "foo", "bar", 57, false)
+	_, err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nOK\n```", true)
+	validation, operational := Partition(err)
+	if len(validation) != 0 || len(operational) != 1 {
+		t.Fatalf("expected only an operational error, got validation=%v operational=%v", validation, operational)
+	}
+}
+
+func TestProcessPR_RedactsSecretsInErrors(t *testing.T) {
+	token := "ghp_" + strings.Repeat("x", 36)
+	_, _, err := processPRForTest(t, []*github.Label{}, "/kind "+token+"\n```release-note\nOK\n```")
+	if err == nil {
+		t.Fatal("expected an invalid kind error")
+	}
+	if strings.Contains(err.Error(), token) {
+		t.Fatalf("expected the token to be redacted, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `invalid /kind "[REDACTED]"`) {
+		t.Fatalf("expected a redacted invalid kind error, got %v", err)
+	}
+}
+
+func TestSimulate_ReleaseNoteCategory(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *ReleaseNote
+		wantErr string
+	}{
+		{
+			name: "section from kind",
+			body: "/kind fix\n```release-note\nFixed a crash.\n```",
+			want: &ReleaseNote{Note: "Fixed a crash.", Section: kinds.Fix},
+		},
+		{
+			name: "category overrides kind",
+			body: "/kind feature\n```release-note\n[Helm] Added the podLabels value.\n```",
+			want: &ReleaseNote{Note: "Added the podLabels value.", Category: "helm", Section: "helm"},
+		},
+		{
+			name: "category on a kind without a section",
+			body: "/kind cleanup\n```release-note\n[security] Dropped a vulnerable dependency.\n```",
+			want: &ReleaseNote{Note: "Dropped a vulnerable dependency.", Category: "security", Section: "security"},
+		},
+		{
+			name: "markdown link is not a category",
+			body: "/kind feature\n```release-note\n[Gateway API](https://gateway-api.sigs.k8s.io) v1.3 is supported.\n```",
+			want: &ReleaseNote{Note: "[Gateway API](https://gateway-api.sigs.k8s.io) v1.3 is supported.", Section: kinds.Feature},
+		},
+		{
+			name: "markdown link without spaces is not a category",
+			body: "/kind feature\n```release-note\n[kgateway](https://kgateway.dev) supports v1.3.\n```",
+			want: &ReleaseNote{Note: "[kgateway](https://kgateway.dev) supports v1.3.", Section: kinds.Feature},
+		},
+		{
+			name:    "unknown category",
+			body:    "/kind fix\n```release-note\n[perf] Faster.\n```",
+			wantErr: "unknown release note category [perf]",
+		},
+		{
+			name:    "category without a note",
+			body:    "/kind fix\n```release-note\n[helm]\n```",
+			wantErr: "empty release note after the [helm] category",
+		},
+		{
+			name: "none has no note",
+			body: "/kind fix\n```release-note\nNONE\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false, true)
+			d, err := l.Simulate(tt.body, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if !slices.Contains(d.LabelsToAdd, labels.InvalidReleaseNoteLabel) {
+					t.Fatalf("expected %s to be added, got %v", labels.InvalidReleaseNoteLabel, d.LabelsToAdd)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(d.ReleaseNote, tt.want) {
+				t.Fatalf("release note = %+v, want %+v", d.ReleaseNote, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanKinds_MatchesKindRE(t *testing.T) {
+	bodies := []string{
+		"/kind fix",
+		"/KIND Feature\n/kind breaking_change",
+		"  /kind fix",
+		"/kindfix",
+		"/kind",
+		"/kind\nfix",
+		"/kind \t\r\n\n  cleanup more",
+		"/kind fix, /kind bump",
+		"text /kind fix\n/kind area/foo-bar!",
+		"/kind 🚀",
+		"/kind\n",
+	}
+	for _, body := range bodies {
+		var want []string
+		for _, match := range kindRE.FindAllStringSubmatch(body, -1) {
+			want = append(want, strings.ToLower(match[1]))
+		}
+		if got := scanKinds(body); !reflect.DeepEqual(got, want) {
+			t.Errorf("scanKinds(%q) = %q, want %q", body, got, want)
+		}
+	}
+}
+
+func TestDescriptionSection_MatchesRegexp(t *testing.T) {
+	// the pattern descriptionSection replaced
+	descriptionRE := regexp.MustCompile(`(?sm)^#[ \t]*Description[ \t]*\n(.*?)(?:^#[ \t]|\z)`)
+	bodies := []string{
+		"# Description\nFix the bug.",
+		"# Description\nFix.\n## Details\nMore.\n# Testing\nDone.",
+		"#Description  \n\n# Other",
+		"# Description\n#\tNext",
+		"# Description",
+		"# Descriptions\nFix.",
+		"## Description\nFix.",
+		"Intro\n#  Description\t\nFix.\n#nospace\n# Next",
+		"# Description\n",
+		"",
+	}
+	for _, body := range bodies {
+		want, wantOK := "", false
+		if match := descriptionRE.FindStringSubmatch(body); match != nil {
+			want, wantOK = match[1], true
+		}
+		if got, ok := descriptionSection(body); got != want || ok != wantOK {
+			t.Errorf("descriptionSection(%q) = %q, %v, want %q, %v", body, got, ok, want, wantOK)
+		}
+	}
+}
+
+func TestStripComments_MatchesRegexp(t *testing.T) {
+	// the pattern stripComments replaced
+	commentRE := regexp.MustCompile(`(?s)<!--.*?-->`)
+	bodies := []string{
+		"no comments",
+		"<!-- a -->/kind fix",
+		"a<!-- b\nc -->d<!--e-->f",
+		"<!-->-->x",
+		"a <!-- unterminated",
+		"<!-- a --> b <!-- unterminated",
+		"<!--<!-- nested -->-->",
+		"",
+	}
+	for _, body := range bodies {
+		if got, want := stripComments(body), commentRE.ReplaceAllString(body, ""); got != want {
+			t.Errorf("stripComments(%q) = %q, want %q", body, got, want)
+		}
+	}
+}
+
+// largeBody returns a body of about 100KB with many code blocks.
+func largeBody() string {
+	var sb strings.Builder
+	sb.WriteString("# Description\nRefactor the parser.\n/kind cleanup\n```release-note\nNONE\n```\n")
+	for sb.Len() < 100<<10 {
+		sb.WriteString("Some context about the change.\n```go\nfunc f() { return }\n```\n<!-- reviewer note -->\n")
+	}
+	return sb.String()
+}
+
+func BenchmarkExtractKinds(b *testing.B) {
+	body := largeBody()
+	l := New(nil, "owner", "repo", 1, true)
+	b.SetBytes(int64(len(body)))
+	for b.Loop() {
+		l.extractKinds(body)
+	}
+}
+
+func BenchmarkSimulate_LargeBody(b *testing.B) {
+	body := largeBody()
+	b.SetBytes(int64(len(body)))
+	for b.Loop() {
+		if _, err := New(nil, "owner", "repo", 1, true).Simulate(body, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAppendErrs(t *testing.T) {
+	a, b, c := fmt.Errorf("a"), fmt.Errorf("b"), fmt.Errorf("c")
+	tests := []struct {
+		name string
+		err  error
+		want []error
+	}{
+		{name: "nil", want: []error{a}},
+		{name: "joined errors are flattened", err: joinErrs(b, c), want: []error{a, b, c}},
+		{name: "other errors are appended as is", err: &OperationalError{Err: b}, want: []error{a, &OperationalError{Err: b}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appendErrs([]error{a}, tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("appendErrs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLinkCheckTimeout is how long WithLinkCheck waits for a URL by
+// default.
+const DefaultLinkCheckTimeout = 5 * time.Second
+
+// maxCheckedLinks bounds the URLs checked per release note, so a note full
+// of links cannot stall the run.
+const maxCheckedLinks = 10
+
+// noteURLRE matches the URLs of a release note, bare or in markdown links.
+var noteURLRE = regexp.MustCompile("https?://[^\\s<>()\\[\\]\"'`]+")
+
+// DeadLink is a URL of the release note that does not resolve.
+type DeadLink struct {
+	URL string `json:"url"`
+	// Reason is why the URL is considered dead, e.g. "HTTP 404" or "timed
+	// out".
+	Reason string `json:"reason"`
+}
+
+// WithLinkCheck checks that the URLs of the release note resolve, with a
+// HEAD request that times out after timeout, and warns about dead links
+// before they are published. Dead links do not fail validation, as the
+// linked site may just be down.
+func (l *labeler) WithLinkCheck(timeout time.Duration) *labeler {
+	l.linkClient = &http.Client{Timeout: timeout}
+	return l
+}
+
+// DeadLinks returns the dead links of the release note found by the last
+// link check.
+func (l *labeler) DeadLinks() []DeadLink {
+	return l.deadLinks
+}
+
+// CheckLinks checks the URLs of the release note of the last evaluation, as
+// Plan does, and returns the dead ones, e.g. after Simulate, which checks
+// none. Without WithLinkCheck nothing is checked.
+func (l *labeler) CheckLinks(ctx context.Context) []DeadLink {
+	l.checkLinks(ctx)
+	return l.deadLinks
+}
+
+// checkLinks checks the URLs of the release note of the last evaluation.
+func (l *labeler) checkLinks(ctx context.Context) {
+	l.deadLinks = nil
+	if l.linkClient == nil || l.releaseNote == nil {
+		return
+	}
+	var urls []string
+	for _, u := range noteURLRE.FindAllString(l.releaseNote.Note, -1) {
+		u = strings.TrimRight(u, ".,;:!?")
+		if !slices.Contains(urls, u) {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) > maxCheckedLinks {
+		urls = urls[:maxCheckedLinks]
+	}
+	reasons := make([]string, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reasons[i] = l.checkLink(ctx, u)
+		}()
+	}
+	wg.Wait()
+	for i, reason := range reasons {
+		if reason != "" {
+			l.deadLinks = append(l.deadLinks, DeadLink{URL: urls[i], Reason: reason})
+		}
+	}
+}
+
+// checkLink returns why url is dead, or "" if it resolves. Sites that do
+// not allow HEAD are retried with GET. Only missing pages, server errors and
+// unreachable hosts count as dead, not e.g. pages behind a login.
+func (l *labeler) checkLink(ctx context.Context, url string) string {
+	status, err := l.linkStatus(ctx, http.MethodHead, url)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = l.linkStatus(ctx, http.MethodGet, url)
+	}
+	var timeout interface{ Timeout() bool }
+	switch {
+	case errors.As(err, &timeout) && timeout.Timeout():
+		return "timed out"
+	case err != nil:
+		return "unreachable"
+	case status == http.StatusNotFound || status == http.StatusGone || status >= 500:
+		return fmt.Sprintf("HTTP %d", status)
+	}
+	return ""
+}
+
+// linkStatus requests url with method and returns the response status.
+func (l *labeler) linkStatus(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := l.linkClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
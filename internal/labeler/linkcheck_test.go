@@ -0,0 +1,70 @@
+package labeler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckLinks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/login":
+			w.WriteHeader(http.StatusForbidden)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	note := "Added the frobnicator, see " + srv.URL + "/ok, [the docs](" + srv.URL + "/missing) and " +
+		srv.URL + "/get-only, " + srv.URL + "/login, " + srv.URL + "/slow, " + srv.URL + "/broken, " +
+		closed.URL + "/gone and " + srv.URL + "/ok again."
+	l := New(nil, "owner", "repo", 1, false).WithLinkCheck(100 * time.Millisecond)
+	if _, err := l.Simulate("/kind feature\n```release-note\n"+note+"\n```", nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	l.checkLinks(context.Background())
+
+	want := []DeadLink{
+		{URL: srv.URL + "/missing", Reason: "HTTP 404"},
+		{URL: srv.URL + "/slow", Reason: "timed out"},
+		{URL: srv.URL + "/broken", Reason: "HTTP 502"},
+		{URL: closed.URL + "/gone", Reason: "unreachable"},
+	}
+	if !reflect.DeepEqual(l.DeadLinks(), want) {
+		t.Errorf("dead links = %+v, want %+v", l.DeadLinks(), want)
+	}
+	if summary := l.Summary(); !strings.Contains(summary, "- "+srv.URL+"/missing (HTTP 404)") {
+		t.Errorf("expected the summary to warn about dead links, got:\n%s", summary)
+	}
+	if d := l.Decision(); d.Error != "" || len(d.DeadLinks) != len(want) {
+		t.Errorf("expected dead links to be reported without failing validation, got %+v", d)
+	}
+}
+
+func TestCheckLinks_Disabled(t *testing.T) {
+	l := New(nil, "owner", "repo", 1, false)
+	if _, err := l.Simulate("/kind feature\n```release-note\nSee http://127.0.0.1:1/missing.\n```", nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	l.checkLinks(context.Background())
+	if len(l.DeadLinks()) > 0 {
+		t.Errorf("expected links not to be checked, got %+v", l.DeadLinks())
+	}
+}
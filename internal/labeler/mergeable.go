@@ -0,0 +1,71 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// Verdict is whether a PR may be merged per the label policy.
+type Verdict struct {
+	Mergeable bool `json:"mergeable"`
+	// Blockers say why the PR may not be merged.
+	Blockers []Blocker `json:"blockers,omitempty"`
+	// Labels are the labels the PR has once the labeler's decision is
+	// applied.
+	Labels []string `json:"labels"`
+}
+
+// Blocker is a reason a PR may not be merged.
+type Blocker struct {
+	Reason string `json:"reason"`
+	// Label is the label that blocks the merge, if any.
+	Label string `json:"label,omitempty"`
+	// Check is the validation the blocker comes from, if any.
+	Check Check `json:"check,omitempty"`
+}
+
+// Err returns the blockers as validation errors, or nil if the PR may be
+// merged.
+func (v *Verdict) Err() error {
+	var errs []error
+	for _, b := range v.Blockers {
+		errs = append(errs, &ValidationError{Err: errors.New(b.Reason), Check: b.Check})
+	}
+	return joinErrs(errs...)
+}
+
+// Mergeable decides whether the PR may be merged per the label policy: it
+// carries no do-not-merge/* label, whether the labeler applies it or someone
+// else did, and its kind and release note are valid. The PR is evaluated as
+// it is now without changing anything, so merge automation can ask the
+// labeler instead of duplicating its rules.
+func (l *labeler) Mergeable(ctx context.Context, body string) (*Verdict, error) {
+	if err := l.fetchComment(ctx); err != nil {
+		return nil, err
+	}
+	d, err := l.Decide(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	v := &Verdict{Labels: d.FinalLabels()}
+	for _, label := range v.Labels {
+		if strings.HasPrefix(label, doNotMergePrefix) {
+			v.Blockers = append(v.Blockers, Blocker{Reason: fmt.Sprintf("has the %s label", label), Label: label})
+		}
+	}
+	for _, err := range l.problems {
+		var ve *ValidationError
+		if errors.As(err, &ve) && (ve.Check == CheckKind || ve.Check == CheckReleaseNote) {
+			v.Blockers = append(v.Blockers, Blocker{Reason: ve.Error(), Check: ve.Check})
+		}
+	}
+	if len(l.suspectedSpam) > 0 {
+		v.Blockers = append(v.Blockers, Blocker{Reason: fmt.Sprintf("is labeled %s for a maintainer to triage", labels.SuspectedSpamLabel), Label: labels.SuspectedSpamLabel})
+	}
+	v.Mergeable = len(v.Blockers) == 0
+	return v, nil
+}
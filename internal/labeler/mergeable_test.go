@@ -0,0 +1,99 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestMergeable(t *testing.T) {
+	const validBody = "# Description\nFix.\n/kind fix\n```release-note\nNONE\n```"
+	tests := []struct {
+		name          string
+		body          string
+		current       []string
+		wantMergeable bool
+		wantBlockers  []string
+		wantCheck     Check
+	}{
+		{
+			name:          "valid PR",
+			body:          validBody,
+			wantMergeable: true,
+		},
+		{
+			name:         "missing kind",
+			body:         "# Description\nFix.\n```release-note\nNONE\n```",
+			wantBlockers: []string{labels.InvalidKindLabel},
+			wantCheck:    CheckKind,
+		},
+		{
+			name:         "invalid release note",
+			body:         "# Description\nFix.\n/kind fix",
+			wantBlockers: []string{labels.InvalidReleaseNoteLabel},
+			wantCheck:    CheckReleaseNote,
+		},
+		{
+			name:         "do-not-merge label not applied by the labeler",
+			body:         validBody,
+			current:      []string{"do-not-merge/hold"},
+			wantBlockers: []string{"do-not-merge/hold"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current []*github.Label
+			for _, name := range tt.current {
+				current = append(current, &github.Label{Name: github.Ptr(name)})
+			}
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, current),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, true)
+			v, err := l.Mergeable(context.Background(), tt.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v.Mergeable != tt.wantMergeable {
+				t.Fatalf("Mergeable = %v, want %v (blockers: %+v)", v.Mergeable, tt.wantMergeable, v.Blockers)
+			}
+			if tt.wantMergeable {
+				if err := v.Err(); err != nil {
+					t.Fatalf("expected no error for a mergeable PR, got %v", err)
+				}
+				return
+			}
+			for _, want := range tt.wantBlockers {
+				found := false
+				for _, b := range v.Blockers {
+					found = found || strings.Contains(b.Reason, want)
+				}
+				if !found {
+					t.Errorf("expected a blocker mentioning %q, got %+v", want, v.Blockers)
+				}
+			}
+			var ve *ValidationError
+			if !errors.As(v.Err(), &ve) {
+				t.Fatalf("expected a validation error, got %v", v.Err())
+			}
+			if tt.wantCheck != "" && !hasBlockerCheck(v, tt.wantCheck) {
+				t.Errorf("expected a blocker from the %s check, got %+v", tt.wantCheck, v.Blockers)
+			}
+		})
+	}
+}
+
+func hasBlockerCheck(v *Verdict, check Check) bool {
+	for _, b := range v.Blockers {
+		if b.Check == check {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package labeler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// metadataDir is where metadata files are kept on the metadata branch.
+const metadataDir = "pr-metadata"
+
+// Metadata is the structured metadata parsed from a PR. It is exported so
+// release tooling can query PRs without re-parsing their bodies.
+type Metadata struct {
+	Repository  string       `json:"repository"`
+	Number      int          `json:"number"`
+	Title       string       `json:"title,omitempty"`
+	Author      string       `json:"author,omitempty"`
+	Kinds       []string     `json:"kinds"`
+	ReleaseNote *ReleaseNote `json:"releaseNote,omitempty"`
+	Milestone   string       `json:"milestone,omitempty"`
+	// Areas are the top-level directories the PR changes, "." for files at
+	// the root.
+	Areas []string `json:"areas"`
+	// Size buckets the number of changed lines, from XS to XXL.
+	Size         string   `json:"size,omitempty"`
+	Additions    int      `json:"additions"`
+	Deletions    int      `json:"deletions"`
+	ChangedFiles int      `json:"changedFiles"`
+	Labels       []string `json:"labels"`
+	Valid        bool     `json:"valid"`
+	// Reverts is the PR this PR reverts, as found with WithRevertDetection,
+	// or 0 if none.
+	Reverts int `json:"reverts,omitempty"`
+	// Risk is the PR's release risk, if scored with WithRiskScoring.
+	Risk *Risk `json:"risk,omitempty"`
+}
+
+// WithMetadataExport exports the PR's Metadata as JSON in the check-run
+// output, if WithCheckRun is set, and, if branch is not empty, as
+// pr-metadata/NUMBER.json on that branch, e.g. gh-pages.
+func (l *labeler) WithMetadataExport(branch string) *labeler {
+	l.exportMetadata = true
+	l.metadataBranch = branch
+	return l
+}
+
+// fetchMetadata looks up the PR details the metadata includes.
+func (l *labeler) fetchMetadata(ctx context.Context) error {
+	if !l.exportMetadata {
+		return nil
+	}
+	_, err := l.pullRequest(ctx)
+	return err
+}
+
+// Metadata returns the metadata of the last evaluation.
+func (l *labeler) Metadata() *Metadata {
+	m := &Metadata{
+		Repository:   l.owner + "/" + l.repo,
+		Number:       l.prNum,
+		Title:        l.pr.GetTitle(),
+		Author:       l.pr.GetUser().GetLogin(),
+		Kinds:        sortedKeys(l.kinds),
+		ReleaseNote:  l.releaseNote,
+		Milestone:    l.milestone,
+		Areas:        l.areas(),
+		Additions:    l.pr.GetAdditions(),
+		Deletions:    l.pr.GetDeletions(),
+		ChangedFiles: len(l.changedFiles),
+		Labels:       l.Decision().FinalLabels(),
+		Valid:        len(l.problems) == 0,
+		Reverts:      l.revertedPR,
+		Risk:         l.Risk(),
+	}
+	if l.pr != nil {
+		m.Size = size(m.Additions + m.Deletions)
+	}
+	return m
+}
+
+// areas returns the top-level directories the PR changes, "." for files at
+// the root.
+func (l *labeler) areas() []string {
+	areas := map[string]bool{}
+	for _, f := range l.changedFiles {
+		area, _, ok := strings.Cut(f, "/")
+		if !ok {
+			area = "."
+		}
+		areas[area] = true
+	}
+	return sortedKeys(areas)
+}
+
+// size buckets a number of changed lines like the size/* labels common on
+// Kubernetes projects.
+func size(lines int) string {
+	switch {
+	case lines < 10:
+		return "XS"
+	case lines < 30:
+		return "S"
+	case lines < 100:
+		return "M"
+	case lines < 500:
+		return "L"
+	case lines < 1000:
+		return "XL"
+	}
+	return "XXL"
+}
+
+// metadataJSON returns the metadata of the last evaluation as indented JSON.
+func (l *labeler) metadataJSON() []byte {
+	data, _ := json.MarshalIndent(l.Metadata(), "", "  ")
+	return append(data, '\n')
+}
+
+// syncMetadataBranch writes the metadata of p to the metadata branch, unless
+// it is unchanged.
+func (l *labeler) syncMetadataBranch(ctx context.Context, p *Plan) error {
+	if p.MetadataBranch == "" {
+		return nil
+	}
+	path := fmt.Sprintf("%s/%d.json", metadataDir, l.prNum)
+	content := p.Metadata
+	opts := &github.RepositoryContentFileOptions{
+		Message: github.Ptr(fmt.Sprintf("Update metadata of #%d", l.prNum)),
+		Content: content,
+		Branch:  github.Ptr(p.MetadataBranch),
+	}
+	file, _, resp, err := l.client.Repositories.GetContents(ctx, l.owner, l.repo, path, &github.RepositoryContentGetOptions{Ref: p.MetadataBranch})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		if _, _, err := l.client.Repositories.CreateFile(ctx, l.owner, l.repo, path, opts); err != nil {
+			return apiError(err, permContentsWrite, "create %s on %s", path, p.MetadataBranch)
+		}
+		return nil
+	}
+	if err != nil {
+		return apiError(err, permContentsRead, "get %s on %s", path, p.MetadataBranch)
+	}
+	if existing, err := file.GetContent(); err == nil && bytes.Equal([]byte(existing), content) {
+		return nil
+	}
+	opts.SHA = file.SHA
+	if _, _, err := l.client.Repositories.UpdateFile(ctx, l.owner, l.repo, path, opts); err != nil {
+		return apiError(err, permContentsWrite, "update %s on %s", path, p.MetadataBranch)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestSize(t *testing.T) {
+	for lines, want := range map[int]string{0: "XS", 9: "XS", 10: "S", 99: "M", 100: "L", 999: "XL", 1000: "XXL"} {
+		if got := size(lines); got != want {
+			t.Errorf("size(%d) = %q, want %q", lines, got, want)
+		}
+	}
+}
+
+func metadataClient(t *testing.T, output *github.CheckRunOutput, opts ...mock.MockBackendOption) *github.Client {
+	t.Helper()
+	return github.NewClient(mock.NewMockedHTTPClient(append([]mock.MockBackendOption{
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+		mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+		mock.WithRequestMatch(
+			mock.GetReposPullsFilesByOwnerByRepoByPullNumber,
+			[]*github.CommitFile{{Filename: github.Ptr("pkg/foo/foo.go")}, {Filename: github.Ptr("docs/foo.md")}, {Filename: github.Ptr("Makefile")}},
+		),
+		mock.WithRequestMatch(
+			mock.GetReposPullsByOwnerByRepoByPullNumber,
+			github.PullRequest{
+				Title:     github.Ptr("Fix the foo"),
+				User:      &github.User{Login: github.Ptr("alice")},
+				Additions: github.Ptr(40),
+				Deletions: github.Ptr(2),
+			},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposCheckRunsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var got github.CreateCheckRunOptions
+				json.NewDecoder(r.Body).Decode(&got)
+				if got.Output != nil {
+					*output = *got.Output
+				}
+				w.Write(mock.MustMarshal(github.CheckRun{}))
+			}),
+		),
+	}, opts...)...))
+}
+
+func TestProcessPR_MetadataExport(t *testing.T) {
+	var output github.CheckRunOutput
+	l := New(metadataClient(t, &output), "owner", "repo", 1, false).WithCheckRun("abc123", false).WithMetadataExport("")
+	if _, err := l.ProcessPR(context.Background(), validBody, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &Metadata{
+		Repository:   "owner/repo",
+		Number:       1,
+		Title:        "Fix the foo",
+		Author:       "alice",
+		Kinds:        []string{"fix"},
+		ReleaseNote:  l.releaseNote,
+		Areas:        []string{".", "docs", "pkg"},
+		Size:         "M",
+		Additions:    40,
+		Deletions:    2,
+		ChangedFiles: 3,
+		Labels:       []string{"kind/fix", "release-note"},
+		Valid:        true,
+	}
+	if got := l.Metadata(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected metadata\nwant: %+v\ngot:  %+v", want, got)
+	}
+	if !strings.HasPrefix(output.GetText(), "PR metadata:\n\n```json\n") {
+		t.Fatalf("check run text lacks the metadata:\n%s", output.GetText())
+	}
+	if !strings.Contains(output.GetText(), string(l.metadataJSON())) {
+		t.Fatalf("check run text does not match the metadata:\n%s", output.GetText())
+	}
+}
+
+func TestProcessPR_MetadataBranch(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing func(l *labeler) string
+		wantPut  bool
+		wantSHA  string
+	}{
+		{name: "new file", wantPut: true},
+		{name: "changed file", existing: func(*labeler) string { return "{}\n" }, wantPut: true, wantSHA: "old"},
+		{name: "unchanged file", existing: func(l *labeler) string { return string(l.metadataJSON()) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l *labeler
+			var put *github.RepositoryContentFileOptions
+			var output github.CheckRunOutput
+			client := metadataClient(t, &output,
+				mock.WithRequestMatchHandler(
+					mock.GetReposContentsByOwnerByRepoByPath,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if r.URL.Path != "/repos/owner/repo/contents/pr-metadata/1.json" || r.URL.Query().Get("ref") != "gh-pages" {
+							t.Errorf("unexpected request for %s", r.URL)
+						}
+						if tt.existing == nil {
+							mock.WriteError(w, http.StatusNotFound, "Not Found")
+							return
+						}
+						w.Write(mock.MustMarshal(github.RepositoryContent{
+							Type:     github.Ptr("file"),
+							Encoding: github.Ptr(""),
+							Content:  github.Ptr(tt.existing(l)),
+							SHA:      github.Ptr("old"),
+						}))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PutReposContentsByOwnerByRepoByPath,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						put = &github.RepositoryContentFileOptions{}
+						json.NewDecoder(r.Body).Decode(put)
+						w.Write(mock.MustMarshal(github.RepositoryContentResponse{}))
+					}),
+				),
+			)
+			l = New(client, "owner", "repo", 1, false).WithMetadataExport("gh-pages")
+			if _, err := l.ProcessPR(context.Background(), validBody, true); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (put != nil) != tt.wantPut {
+				t.Fatalf("file written = %v, want %v", put != nil, tt.wantPut)
+			}
+			if put == nil {
+				return
+			}
+			if put.GetBranch() != "gh-pages" || put.GetSHA() != tt.wantSHA {
+				t.Errorf("file written to %q with SHA %q", put.GetBranch(), put.GetSHA())
+			}
+			if string(put.Content) != string(l.metadataJSON()) {
+				t.Errorf("unexpected content:\n%s", put.Content)
+			}
+		})
+	}
+}
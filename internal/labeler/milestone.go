@@ -0,0 +1,104 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// milestoneRE captures /milestone overrides, case-insensitive, matching start of line.
+var milestoneRE = regexp.MustCompile(`(?im)^/milestone[ \t]+(\S+)[ \t]*$`)
+
+// WithMilestones sets the milestone title PRs of each kind default to, e.g.
+// breaking_change → next-major. PRs without a /milestone override are moved to
+// the milestone of their first mapped kind in sorted order.
+func (l *labeler) WithMilestones(milestones map[string]string) *labeler {
+	l.milestones = milestones
+	return l
+}
+
+// WithMilestoneTeams restricts /milestone to authors in one of teams, given
+// as org/team-slug. Other authors' PRs get the per-kind default. Membership is
+// checked with the resolver set by WithTeamResolver.
+func (l *labeler) WithMilestoneTeams(teams []string) *labeler {
+	l.milestoneTeams = teams
+	return l
+}
+
+// processMilestone determines the milestone the PR should target. A
+// /milestone command in the body takes precedence over the per-kind default.
+func (l *labeler) processMilestone(body string) error {
+	l.milestone, l.milestoneOverride = "", false
+	var err error
+	if matches := milestoneRE.FindAllStringSubmatch(commandLines(body, "/milestone"), -1); len(matches) > 0 {
+		if len(l.milestoneTeams) == 0 || l.authorInAnyTeam(l.milestoneTeams) {
+			// the last command wins so authors can append a correction
+			l.milestone = matches[len(matches)-1][1]
+			l.milestoneOverride = true
+			return nil
+		}
+		err = fmt.Errorf("/milestone is restricted to members of %s; remove it from the PR body or ask a maintainer to set the milestone", strings.Join(l.milestoneTeams, ", "))
+	}
+	for _, k := range sortedKeys(l.kinds) {
+		if title, ok := l.milestones[k]; ok {
+			l.milestone = title
+			break
+		}
+	}
+	return err
+}
+
+// syncMilestone sets the PR milestone to the one of p. An unknown milestone
+// requested via /milestone is reported as a validation error; an unknown
+// default milestone is a configuration problem.
+func (l *labeler) syncMilestone(ctx context.Context, p *Plan) error {
+	if p.Milestone == "" {
+		return nil
+	}
+	issue, _, err := l.client.Issues.Get(ctx, l.owner, l.repo, l.prNum)
+	if err != nil {
+		return &OperationalError{Err: apiError(err, permPullRequestsRead, "get PR milestone")}
+	}
+	if strings.EqualFold(issue.GetMilestone().GetTitle(), p.Milestone) {
+		return nil
+	}
+	number, err := l.findMilestone(ctx, p.Milestone)
+	if err != nil {
+		return &OperationalError{Err: err}
+	}
+	if number == 0 {
+		err := fmt.Errorf("milestone %q does not exist or is closed", p.Milestone)
+		if p.MilestoneRequested {
+			return &ValidationError{Err: fmt.Errorf("%w; fix the /milestone command in the PR body", err)}
+		}
+		return &OperationalError{Err: err}
+	}
+	if _, _, err := l.client.Issues.Edit(ctx, l.owner, l.repo, l.prNum, &github.IssueRequest{Milestone: &number}); err != nil {
+		return &OperationalError{Err: apiError(err, permPullRequestsWrite, "set milestone %q", p.Milestone)}
+	}
+	return nil
+}
+
+// findMilestone returns the number of the open milestone with the given
+// title, or 0 if there is none.
+func (l *labeler) findMilestone(ctx context.Context, title string) (int, error) {
+	opts := &github.MilestoneListOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		milestones, resp, err := l.client.Issues.ListMilestones(ctx, l.owner, l.repo, opts)
+		if err != nil {
+			return 0, apiError(err, permPullRequestsRead, "list milestones")
+		}
+		for _, m := range milestones {
+			if strings.EqualFold(m.GetTitle(), title) {
+				return m.GetNumber(), nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return 0, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,110 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func processMilestoneForTest(t *testing.T, milestones map[string]string, prBody string) (int, error) {
+	t.Helper()
+
+	editedMilestone := 0
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			[]*github.Label{},
+		),
+		mock.WithRequestMatch(
+			mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			[]*github.Label{},
+		),
+		mock.WithRequestMatch(
+			mock.GetReposIssuesByOwnerByRepoByIssueNumber,
+			github.Issue{Milestone: &github.Milestone{Title: github.Ptr("v1.19"), Number: github.Ptr(1)}},
+		),
+		mock.WithRequestMatch(
+			mock.GetReposMilestonesByOwnerByRepo,
+			[]*github.Milestone{
+				{Title: github.Ptr("v1.19"), Number: github.Ptr(1)},
+				{Title: github.Ptr("v1.20"), Number: github.Ptr(2)},
+				{Title: github.Ptr("next-major"), Number: github.Ptr(3)},
+			},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposIssuesByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req github.IssueRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("EditIssue Handler: failed to decode body: %v", err)
+				}
+				editedMilestone = req.GetMilestone()
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(github.Issue{})
+			}),
+		),
+	)
+
+	l := New(github.NewClient(httpClient), "owner", "repo", 901, false).WithMilestones(milestones)
+	_, err := l.ProcessPR(context.Background(), prBody, true)
+	return editedMilestone, err
+}
+
+func TestProcessPR_Milestones(t *testing.T) {
+	milestones := map[string]string{"breaking_change": "next-major", "fix": "v1.19"}
+	tests := []struct {
+		name          string
+		body          string
+		wantMilestone int
+		wantErr       string
+	}{
+		{
+			name:          "kind default",
+			body:          "/kind breaking_change\n```release-note\nRemoved the v1alpha1 API.\n```",
+			wantMilestone: 3,
+		},
+		{
+			name:          "already on the default milestone",
+			body:          "/kind fix\n```release-note\nFixed a crash.\n```",
+			wantMilestone: 0,
+		},
+		{
+			name:          "override wins over kind default",
+			body:          "/kind breaking_change\n/milestone v1.20\n```release-note\nRemoved the v1alpha1 API.\n```",
+			wantMilestone: 2,
+		},
+		{
+			name:          "unmapped kind",
+			body:          "/kind cleanup\n```release-note\nNONE\n```",
+			wantMilestone: 0,
+		},
+		{
+			name:    "unknown override",
+			body:    "/kind fix\n/milestone v9.99\n```release-note\nFixed a crash.\n```",
+			wantErr: `milestone "v9.99" does not exist`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processMilestoneForTest(t, milestones, tt.body)
+			if tt.wantErr != "" {
+				validation, _ := Partition(err)
+				if len(validation) != 1 || !strings.Contains(validation[0].Error(), tt.wantErr) {
+					t.Fatalf("expected validation error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tt.wantMilestone {
+				t.Fatalf("expected milestone %d to be set, got %d", tt.wantMilestone, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package labeler
+
+import "fmt"
+
+// Mode controls how validation failures are enforced, so repos can adopt the
+// labeler incrementally before turning on enforcement.
+type Mode string
+
+const (
+	// ModeStrict labels the PR and fails on validation failures.
+	ModeStrict Mode = "strict"
+	// ModeLenient labels the PR but does not fail on validation failures.
+	ModeLenient Mode = "lenient"
+	// ModeReportOnly reports validation failures without labeling the PR or failing.
+	ModeReportOnly Mode = "report-only"
+)
+
+// ParseMode parses a mode name.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeStrict, ModeLenient, ModeReportOnly:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid mode %q, expected one of %s, %s, %s", s, ModeStrict, ModeLenient, ModeReportOnly)
+}
+
+// SyncLabels reports whether labels are written to the PR in this mode.
+func (m Mode) SyncLabels() bool {
+	return m != ModeReportOnly
+}
+
+// FailOnValidation reports whether validation failures fail the run in this mode.
+func (m Mode) FailOnValidation() bool {
+	return m == ModeStrict
+}
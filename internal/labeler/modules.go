@@ -0,0 +1,107 @@
+package labeler
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// WithModuleLabels labels PRs in repositories with several Go modules with
+// module/NAME for each module they change, and removes the module labels of
+// modules they no longer change. A module is the directory holding a go.mod,
+// named by its path, e.g. module/tools/cli; the root module is named after
+// the repository. Modules are read from the tree at the PR head unless set
+// with WithModuleRoots.
+func (l *labeler) WithModuleLabels() *labeler {
+	l.moduleLabels = true
+	return l
+}
+
+// WithModuleRoots sets the directories holding a go.mod, e.g. from a local
+// checkout, with "." for the repository root.
+func (l *labeler) WithModuleRoots(roots []string) *labeler {
+	l.moduleRoots = roots
+	return l
+}
+
+// fetchModuleRoots finds the go.mod files in the tree at the PR head, unless
+// module roots were set with WithModuleRoots. Directories the go command
+// ignores, such as vendor and testdata, are skipped.
+func (l *labeler) fetchModuleRoots(ctx context.Context) error {
+	if !l.moduleLabels || l.moduleRoots != nil {
+		return nil
+	}
+	pr, err := l.pullRequest(ctx)
+	if err != nil {
+		return err
+	}
+	tree, _, err := l.client.Git.GetTree(ctx, l.owner, l.repo, pr.GetHead().GetSHA(), true)
+	if err != nil {
+		return apiError(err, permContentsRead, "get tree")
+	}
+	roots := []string{}
+	for _, e := range tree.Entries {
+		if e.GetType() == "blob" && path.Base(e.GetPath()) == "go.mod" && !ignoredModuleDir(path.Dir(e.GetPath())) {
+			roots = append(roots, path.Dir(e.GetPath()))
+		}
+	}
+	l.moduleRoots = roots
+	return nil
+}
+
+// ignoredModuleDir reports whether the go command ignores dir, because one of
+// its elements is vendor or testdata, or starts with . or _.
+func ignoredModuleDir(dir string) bool {
+	if dir == "." {
+		return false
+	}
+	for _, elem := range strings.Split(dir, "/") {
+		if elem == "vendor" || elem == "testdata" || strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleName returns the name of the module file belongs to, the one whose
+// root is its nearest parent, or "" if it belongs to none.
+func (l *labeler) moduleName(file string) string {
+	best := ""
+	for _, root := range l.moduleRoots {
+		if (root == "." || strings.HasPrefix(file, root+"/")) && (best == "" || best == "." || len(root) > len(best)) {
+			best = root
+		}
+	}
+	if best == "." {
+		return l.repo
+	}
+	return best
+}
+
+// processModuleLabels labels the modules the PR changes. Repositories with a
+// single module get no module labels, as there is nothing to route.
+func (l *labeler) processModuleLabels() {
+	if !l.moduleLabels {
+		return
+	}
+	changed := map[string]bool{}
+	if len(l.moduleRoots) > 1 {
+		for _, f := range l.changedFiles {
+			if name := l.moduleName(f); name != "" {
+				changed[labels.ModuleLabelPrefix+name] = true
+			}
+		}
+	}
+	for label := range changed {
+		if !l.currentMap[label] {
+			l.labelsToAdd[label] = true
+		}
+	}
+	for label := range l.currentMap {
+		if strings.HasPrefix(label, labels.ModuleLabelPrefix) && !changed[label] {
+			l.labelsToRemove[label] = true
+		}
+	}
+}
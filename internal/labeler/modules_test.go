@@ -0,0 +1,105 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+const moduleBody = "# Description\nFix.\n/kind fix\n```release-note\nNONE\n```"
+
+func TestSimulate_ModuleLabels(t *testing.T) {
+	roots := []string{".", "api", "tools/cli"}
+	tests := []struct {
+		name          string
+		roots         []string
+		files         []string
+		currentLabels []string
+		wantAdd       []string
+		wantRemove    []string
+	}{
+		{
+			name:    "nested modules",
+			roots:   roots,
+			files:   []string{"api/v1/types.go", "tools/cli/main.go", "tools/gen/main.go"},
+			wantAdd: []string{"kind/fix", "module/api", "module/repo", "module/tools/cli", "release-note-none"},
+		},
+		{
+			name:          "stale module label removed",
+			roots:         roots,
+			files:         []string{"api/v1/types.go"},
+			currentLabels: []string{"kind/fix", "release-note-none", "module/api", "module/tools/cli"},
+			wantAdd:       []string{},
+			wantRemove:    []string{"module/tools/cli"},
+		},
+		{
+			name:    "single module",
+			roots:   []string{"."},
+			files:   []string{"main.go"},
+			wantAdd: []string{"kind/fix", "release-note-none"},
+		},
+		{
+			name:    "file outside every module",
+			roots:   []string{"api", "tools/cli"},
+			files:   []string{"README.md", "apis/foo.go"},
+			wantAdd: []string{"kind/fix", "release-note-none"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, true).WithModuleLabels().WithModuleRoots(tt.roots).WithChangedFiles(tt.files)
+			d, err := l.Simulate(moduleBody, tt.currentLabels)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if tt.wantRemove == nil {
+				tt.wantRemove = []string{}
+			}
+			if !reflect.DeepEqual(d.LabelsToRemove, tt.wantRemove) {
+				t.Errorf("labels to remove = %v, want %v", d.LabelsToRemove, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestDecide_ModuleRootsFromTree(t *testing.T) {
+	blob := func(path string) *github.TreeEntry {
+		return &github.TreeEntry{Path: github.Ptr(path), Type: github.Ptr("blob")}
+	}
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+		mock.WithRequestMatch(mock.GetReposPullsFilesByOwnerByRepoByPullNumber, []*github.CommitFile{{Filename: github.Ptr("api/types.go")}}),
+		mock.WithRequestMatch(
+			mock.GetReposPullsByOwnerByRepoByPullNumber,
+			github.PullRequest{Head: &github.PullRequestBranch{SHA: github.Ptr("abc123")}},
+		),
+		mock.WithRequestMatch(
+			mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+			github.Tree{Entries: []*github.TreeEntry{
+				blob("go.mod"),
+				blob("api/go.mod"),
+				blob("api/types.go"),
+				blob("vendor/example.com/foo/go.mod"),
+				blob("internal/testdata/mod/go.mod"),
+				{Path: github.Ptr("tools"), Type: github.Ptr("tree")},
+			}},
+		),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, true).WithModuleLabels()
+	d, err := l.Decide(context.Background(), moduleBody)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{".", "api"}; !reflect.DeepEqual(l.moduleRoots, want) {
+		t.Fatalf("module roots = %v, want %v", l.moduleRoots, want)
+	}
+	if want := []string{"kind/fix", "module/api", "release-note-none"}; !reflect.DeepEqual(d.LabelsToAdd, want) {
+		t.Fatalf("labels to add = %v, want %v", d.LabelsToAdd, want)
+	}
+}
@@ -0,0 +1,119 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// rebaseCommentMarker identifies the labeler's rebase instructions among the
+// PR's comments.
+const rebaseCommentMarker = "<!-- pr-kind-labeler-rebase -->"
+
+// mergeablePolls is how often the PR is looked up while GitHub computes
+// whether it conflicts, mergeablePollInterval apart.
+const (
+	mergeablePolls        = 3
+	mergeablePollInterval = 2 * time.Second
+)
+
+// WithNeedsLabels labels PRs with what they need before review, as a family
+// of needs-* labels: needs-kind and needs-release-note while their kind or
+// release note is invalid, and needs-rebase while they conflict with their
+// base branch. Unlike the do-not-merge/* labels, they are hygiene signals
+// for reviewers' filters. When needs-rebase is added, the author is told how
+// to rebase in a comment. needs-rebase is only updated when the labeler runs,
+// so it should also run on pushes to PRs, e.g. synchronize events; a PR that
+// starts conflicting after a base branch push is labeled on its next event.
+func (l *labeler) WithNeedsLabels() *labeler {
+	l.needsLabels = true
+	return l
+}
+
+// fetchMergeableState looks up whether the PR conflicts with its base branch.
+// GitHub computes it in the background after every push, so while it is
+// unknown the PR is looked up again, up to mergeablePolls times.
+func (l *labeler) fetchMergeableState(ctx context.Context) error {
+	if !l.needsLabels {
+		return nil
+	}
+	for poll := 0; ; poll++ {
+		pr, err := l.pullRequest(ctx)
+		if err != nil {
+			return err
+		}
+		l.mergeableState = pr.GetMergeableState()
+		if pr.Mergeable != nil && !pr.GetMergeable() {
+			l.mergeableState = "dirty"
+		}
+		if _, known := l.needsRebase(); known || poll == mergeablePolls {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(l.mergeablePollInterval):
+		}
+		l.pr = nil
+	}
+}
+
+// needsRebase reports whether the PR needs a rebase, and whether that is
+// known: GitHub computes the mergeable state in the background and reports
+// it as unknown until it is done.
+func (l *labeler) needsRebase() (needs, known bool) {
+	switch l.mergeableState {
+	case "", "unknown":
+		return false, false
+	case "dirty":
+		return true, true
+	}
+	return false, true
+}
+
+// processNeedsLabels labels the PR with what it needs, and unlabels what it
+// no longer needs. invalidKind and invalidReleaseNote are the results of the
+// kind and release note validators.
+func (l *labeler) processNeedsLabels(invalidKind, invalidReleaseNote bool) {
+	if !l.needsLabels {
+		return
+	}
+	needs := map[string]bool{
+		labels.NeedsKindLabel:        invalidKind,
+		labels.NeedsReleaseNoteLabel: invalidReleaseNote,
+	}
+	if rebase, known := l.needsRebase(); known {
+		needs[labels.NeedsRebaseLabel] = rebase
+	}
+	for label, need := range needs {
+		switch {
+		case need && !l.currentMap[label]:
+			l.labelsToAdd[label] = true
+		case !need && l.currentMap[label]:
+			l.labelsToRemove[label] = true
+		}
+	}
+}
+
+// rebaseComment returns the comment telling the author how to rebase, if the
+// last evaluation adds needs-rebase.
+func (l *labeler) rebaseComment() *CommentChange {
+	if !l.labelsToAdd[labels.NeedsRebaseLabel] {
+		return nil
+	}
+	base := l.pr.GetBase().GetRef()
+	if base == "" {
+		base = "main"
+	}
+	var sb strings.Builder
+	sb.WriteString(rebaseCommentMarker + "\n")
+	fmt.Fprintf(&sb, "This PR conflicts with `%s` and was labeled `%s`. To resolve the conflicts, rebase it on the latest `%s`:\n\n", base, labels.NeedsRebaseLabel, base)
+	sb.WriteString("```sh\ngit fetch upstream\n")
+	fmt.Fprintf(&sb, "git rebase upstream/%s\n", base)
+	sb.WriteString("# resolve the conflicts, git add the files, then: git rebase --continue\ngit push --force-with-lease\n```\n")
+	sb.WriteString("\nThe label is removed on the next push once the PR no longer conflicts.\n")
+	return &CommentChange{Body: sb.String()}
+}
@@ -0,0 +1,120 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestDecide_NeedsLabels(t *testing.T) {
+	valid := "/kind fix\n```release-note\nFixed a crash.\n```"
+	tests := []struct {
+		name           string
+		body           string
+		mergeableState []string
+		currentLabels  []string
+		wantAdd        []string
+		wantRemove     []string
+	}{
+		{
+			name:           "missing kind and release note",
+			body:           "Fixes a crash.",
+			mergeableState: []string{"clean"},
+			wantAdd:        []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel, labels.NeedsKindLabel, labels.NeedsReleaseNoteLabel},
+		},
+		{
+			name:           "conflicting PR needs a rebase",
+			body:           valid,
+			mergeableState: []string{"dirty"},
+			wantAdd:        []string{"kind/fix", labels.NeedsRebaseLabel, labels.ReleaseNoteLabel},
+		},
+		{
+			name:           "fixed PR loses its needs labels",
+			body:           valid,
+			mergeableState: []string{"behind"},
+			currentLabels:  []string{"kind/fix", labels.ReleaseNoteLabel, labels.NeedsKindLabel, labels.NeedsRebaseLabel},
+			wantRemove:     []string{labels.NeedsKindLabel, labels.NeedsRebaseLabel},
+		},
+		{
+			name:           "mergeable state is polled while unknown",
+			body:           valid,
+			mergeableState: []string{"unknown", "unknown", "dirty"},
+			wantAdd:        []string{"kind/fix", labels.NeedsRebaseLabel, labels.ReleaseNoteLabel},
+		},
+		{
+			name:           "unknown mergeable state keeps needs-rebase",
+			body:           valid,
+			mergeableState: []string{"unknown", "unknown", "unknown", "unknown"},
+			currentLabels:  []string{"kind/fix", labels.ReleaseNoteLabel, labels.NeedsRebaseLabel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantAdd, wantRemove := append([]string{}, tt.wantAdd...), append([]string{}, tt.wantRemove...)
+			var current []*github.Label
+			for _, name := range tt.currentLabels {
+				current = append(current, &github.Label{Name: github.Ptr(name)})
+			}
+			var prs []any
+			for _, state := range tt.mergeableState {
+				prs = append(prs, &github.PullRequest{MergeableState: github.Ptr(state)})
+			}
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, current),
+				mock.WithRequestMatch(mock.GetReposPullsByOwnerByRepoByPullNumber, prs...),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithNeedsLabels()
+			l.mergeablePollInterval = 0
+			d, err := l.Decide(context.Background(), tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, wantAdd) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, wantAdd)
+			}
+			if !reflect.DeepEqual(d.LabelsToRemove, wantRemove) {
+				t.Errorf("labels to remove = %v, want %v", d.LabelsToRemove, wantRemove)
+			}
+		})
+	}
+}
+
+func TestPlan_RebaseComment(t *testing.T) {
+	tests := []struct {
+		name          string
+		currentLabels []*github.Label
+		wantComment   bool
+	}{
+		{name: "posted when the PR starts conflicting", wantComment: true},
+		{name: "not posted again while it conflicts", currentLabels: []*github.Label{{Name: github.Ptr(labels.NeedsRebaseLabel)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposPullsFilesByOwnerByRepoByPullNumber, []*github.CommitFile{}),
+				mock.WithRequestMatch(mock.GetReposPullsByOwnerByRepoByPullNumber, &github.PullRequest{
+					Mergeable: github.Ptr(false),
+					Base:      &github.PullRequestBranch{Ref: github.Ptr("release-1.2")},
+				}),
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, tt.currentLabels),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithNeedsLabels()
+			p, _ := l.Plan(context.Background(), "/kind fix")
+			if p == nil {
+				t.Fatal("expected a plan")
+			}
+			if got := p.RebaseComment != nil; got != tt.wantComment {
+				t.Fatalf("rebase comment = %+v, want one: %v", p.RebaseComment, tt.wantComment)
+			}
+			if tt.wantComment && !strings.Contains(p.RebaseComment.Body, "git rebase upstream/release-1.2") {
+				t.Fatalf("expected rebase instructions for the base branch:\n%s", p.RebaseComment.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package labeler
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// notePreviewContext is how much of the cut off part of a release note the
+// truncation preview shows.
+const notePreviewContext = 40
+
+// WithMaxReleaseNoteLength fails release notes longer than n characters,
+// showing where they would be cut off, so release notes stay skimmable and
+// the detail goes into the description. It also replaces the length limit
+// of release note quality checks. 0 leaves the length unchecked, apart from
+// the quality checks.
+func (l *labeler) WithMaxReleaseNoteLength(n int) *labeler {
+	l.maxNoteLength = n
+	return l
+}
+
+// releaseNoteLengthLimit returns the most characters a release note may
+// have, or 0 if its length is unchecked.
+func (l *labeler) releaseNoteLengthLimit() int {
+	if l.maxNoteLength > 0 {
+		return l.maxNoteLength
+	}
+	if l.enforceReleaseNoteQuality {
+		return maxReleaseNoteLength
+	}
+	return 0
+}
+
+// noteTooLong returns the error of a release note over the limit of max
+// characters, previewing where it would be cut off, or nil if it fits.
+func noteTooLong(note string, max int) error {
+	n := utf8.RuneCountInString(note)
+	if n <= max {
+		return nil
+	}
+	runes := []rune(note)
+	kept, cut := string(runes[:max]), string(runes[max:])
+	if len(runes)-max > notePreviewContext {
+		cut = string(runes[max:max+notePreviewContext]) + "..."
+	}
+	return fmt.Errorf("release note is %d characters, over the limit of %d; it would be cut off here:\n> %s ✂ %s\nKeep the release note to one skimmable sentence and move the detail into the # Description section",
+		n, max, oneLine(kept), oneLine(cut))
+}
+
+// oneLine joins the lines of s, so it can be quoted in one line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
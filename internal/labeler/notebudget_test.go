@@ -0,0 +1,62 @@
+package labeler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestSimulate_MaxReleaseNoteLength(t *testing.T) {
+	long := "Added the frobnicator, which frobnicates listeners. It can be configured per route and per gateway, and defaults to off."
+	tests := []struct {
+		name     string
+		max      int
+		quality  bool
+		note     string
+		wantErr  []string
+		wantNote bool
+	}{
+		{name: "within the limit", max: 200, note: long, wantNote: true},
+		{name: "unlimited", note: strings.Repeat("a", 1000), wantNote: true},
+		{
+			name:    "over the limit",
+			max:     40,
+			note:    long,
+			wantErr: []string{"is 120 characters, over the limit of 40", "> Added the frobnicator, which frobnicates ✂ listeners. It can be configured per rou...", "# Description"},
+		},
+		{
+			name:    "multi-line note previewed on one line",
+			max:     10,
+			note:    "Added the\nfrobnicator.",
+			wantErr: []string{"> Added the ✂ frobnicator."},
+		},
+		{
+			name:     "replaces the quality limit",
+			max:      600,
+			quality:  true,
+			note:     "Added " + strings.Repeat("a", 550) + ".",
+			wantNote: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := "/kind feature\n```release-note\n" + tt.note + "\n```"
+			d, err := New(nil, "owner", "repo", 1, false, tt.quality).WithMaxReleaseNoteLength(tt.max).Simulate(body, nil)
+			for _, want := range tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error containing %q, got %v", want, err)
+				}
+			}
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := d.ReleaseNote != nil; got != tt.wantNote {
+				t.Errorf("release note set = %v, want %v", got, tt.wantNote)
+			}
+			if invalid := strings.Contains(strings.Join(d.LabelsToAdd, ","), labels.InvalidReleaseNoteLabel); invalid == tt.wantNote {
+				t.Errorf("labels to add = %v, want %s: %v", d.LabelsToAdd, labels.InvalidReleaseNoteLabel, !tt.wantNote)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package labeler
+
+import (
+	"time"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/config"
+)
+
+// Options configure every check and reporter of a labeler at once. The
+// action, the server and the validate and local commands build their
+// labelers from Options with NewFromOptions, so they all reach the same
+// verdict for the same settings.
+type Options struct {
+	EnforceDescription              bool
+	EnforceReleaseNoteQuality       bool
+	EnforceChangelogKindExclusivity bool
+
+	// RepoConfig is the repository's config, if any.
+	RepoConfig *config.Config
+	// KindPrefixes, CommandNamespace, ReleaseNoteFences and
+	// MaxReleaseNoteLength are as set by the With methods of the same names.
+	KindPrefixes         []string
+	CommandNamespace     string
+	ReleaseNoteFences    []string
+	MaxReleaseNoteLength int
+
+	Milestones     map[string]string
+	MilestoneTeams []string
+	Triage         []string
+	AuthorPolicies map[string]AuthorPolicy
+	// AuthorLogin and AuthorAssociation are the PR author's, when the caller
+	// already has them.
+	AuthorLogin       string
+	AuthorAssociation string
+	TeamResolver      TeamResolver
+	LabelCache        LabelCache
+	Spam              SpamHeuristics
+
+	// DetectSecrets enables the possible-secret validator, telling
+	// SecretNotifier, if set.
+	DetectSecrets  bool
+	SecretNotifier SecretNotifier
+
+	// LinkCheck checks the URLs of release notes, waiting LinkCheckTimeout
+	// for each.
+	LinkCheck        bool
+	LinkCheckTimeout time.Duration
+
+	UpgradeDocs      bool
+	UpgradeDocsPaths []string
+	DocsKindCheck    bool
+	DocsPaths        []string
+	IgnoredPaths     []string
+	DisabledLabels   []string
+	DetectRenames    bool
+	DetectReverts    bool
+	// Bots, if set, defaults the kind and release note of bot PRs.
+	Bots            *BotPolicy
+	ReviewCommands  bool
+	CommentCommands bool
+	RiskScoring     bool
+	KindWeights     map[string]int
+	ModuleLabels    bool
+	NeedsLabels     bool
+	CILabels        bool
+	// ApprovalLabel, if set, is removed from PRs that got commits after
+	// their latest approving review.
+	ApprovalLabel string
+
+	// ExportMetadata exports the PR metadata, and commits it to
+	// MetadataBranch if set.
+	ExportMetadata bool
+	MetadataBranch string
+	// FailureStore, if set, enables Escalation.
+	FailureStore FailureStore
+	Escalation   Escalation
+
+	StickyComment        bool
+	MergeBlockersComment bool
+	CommentInterval      time.Duration
+	// CheckRun and CommitStatus report the result on HeadSHA, concluding
+	// failure for invalid PRs if Blocking.
+	CheckRun     bool
+	CommitStatus bool
+	HeadSHA      string
+	Blocking     bool
+	Reporters    []Reporter
+	// ReadOnlyLabels discards every label change.
+	ReadOnlyLabels bool
+}
+
+// NewFromOptions creates a labeler for a PR, set up per o.
+func NewFromOptions(client *github.Client, owner, repo string, prNum int, o *Options) *labeler {
+	l := New(client, owner, repo, prNum, o.EnforceDescription, o.EnforceReleaseNoteQuality, o.EnforceChangelogKindExclusivity).
+		WithKindPrefixes(o.KindPrefixes).
+		WithCommandNamespace(o.CommandNamespace).
+		WithReleaseNoteFences(o.ReleaseNoteFences).
+		WithMaxReleaseNoteLength(o.MaxReleaseNoteLength).
+		WithMilestones(o.Milestones).
+		WithMilestoneTeams(o.MilestoneTeams).
+		WithTriage(o.Triage).
+		WithSpamHeuristics(o.Spam).
+		WithAuthorPolicies(o.AuthorPolicies).
+		WithAuthor(o.AuthorLogin, o.AuthorAssociation).
+		WithTeamResolver(o.TeamResolver).
+		WithLabelCache(o.LabelCache).
+		WithRepoConfig(o.RepoConfig).
+		WithDisabledLabels(o.DisabledLabels).
+		WithCommentInterval(o.CommentInterval).
+		WithReporters(o.Reporters...)
+	if o.DetectSecrets {
+		l.WithSecretDetection(o.SecretNotifier)
+	}
+	if o.LinkCheck {
+		l.WithLinkCheck(o.LinkCheckTimeout)
+	}
+	if o.UpgradeDocs {
+		l.WithUpgradeDocs(o.UpgradeDocsPaths)
+	}
+	if o.DocsKindCheck {
+		l.WithDocsKindCheck(o.DocsPaths)
+	}
+	if len(o.IgnoredPaths) > 0 {
+		l.WithIgnoredPaths(o.IgnoredPaths)
+	}
+	if o.DetectRenames {
+		l.WithRenameDetection()
+	}
+	if o.DetectReverts {
+		l.WithRevertDetection()
+	}
+	if o.Bots != nil {
+		l.WithBotPolicy(*o.Bots)
+	}
+	if o.ReviewCommands {
+		l.WithReviewCommands()
+	}
+	if o.CommentCommands {
+		l.WithCommentCommands()
+	}
+	if o.RiskScoring {
+		l.WithRiskScoring(o.KindWeights)
+	}
+	if o.ModuleLabels {
+		l.WithModuleLabels()
+	}
+	if o.NeedsLabels {
+		l.WithNeedsLabels()
+	}
+	if o.CILabels {
+		l.WithCILabels()
+	}
+	if o.ApprovalLabel != "" {
+		l.WithStaleApprovalReset(o.ApprovalLabel)
+	}
+	if o.ExportMetadata {
+		l.WithMetadataExport(o.MetadataBranch)
+	}
+	if o.FailureStore != nil {
+		l.WithEscalation(o.FailureStore, o.Escalation)
+	}
+	if o.StickyComment {
+		l.WithStickyComment()
+	}
+	if o.MergeBlockersComment {
+		l.WithMergeBlockersComment()
+	}
+	if o.CheckRun {
+		l.WithCheckRun(o.HeadSHA, o.Blocking)
+	}
+	if o.CommitStatus {
+		l.WithCommitStatus(o.HeadSHA, o.Blocking)
+	}
+	if o.ReadOnlyLabels {
+		l.WithoutLabelChanges()
+	}
+	return l
+}
@@ -0,0 +1,44 @@
+package labeler
+
+import (
+	"path"
+	"strings"
+)
+
+// matchPath reports whether name matches pattern, a path.Match pattern in
+// which a ** element also matches any number of directories, e.g.
+// docs/upgrading/** or **/*_test.go.
+func matchPath(pattern, name string) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(name); i >= 0; i-- {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ValidPathPattern reports whether pattern is a well-formed path pattern.
+func ValidPathPattern(pattern string) bool {
+	for _, elem := range strings.Split(pattern, "/") {
+		if _, err := path.Match(elem, ""); err != nil {
+			return false
+		}
+	}
+	return pattern != ""
+}
@@ -0,0 +1,26 @@
+package labeler
+
+import "testing"
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"docs/upgrading/**", "docs/upgrading/v2.md", true},
+		{"docs/upgrading/**", "docs/upgrading/v2/gateway.md", true},
+		{"docs/upgrading/**", "docs/upgrading", true},
+		{"docs/upgrading/**", "docs/install.md", false},
+		{"**/*_test.go", "internal/labeler/labeler_test.go", true},
+		{"**/*_test.go", "main_test.go", true},
+		{"**/*_test.go", "main.go", false},
+		{"*.md", "docs/README.md", false},
+		{"docs/*/index.md", "docs/a/index.md", true},
+		{"docs/*/index.md", "docs/a/b/index.md", false},
+	}
+	for _, tt := range tests {
+		if got := matchPath(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
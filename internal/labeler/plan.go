@@ -0,0 +1,250 @@
+package labeler
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// Plan is every change one run of the labeler makes to a PR: the labels it
+// adds and removes, the comments it posts or edits, the check run it reports
+// and its other side effects. Plan computes it without changing anything and
+// Apply performs it, so dry runs, previews, tests and undo share one
+// representation. A plan is never modified once computed.
+type Plan struct {
+	// PlannedAt is when the plan was computed.
+	PlannedAt time.Time `json:"plannedAt"`
+	// CurrentLabels are the PR's labels the plan was computed against.
+	CurrentLabels []string `json:"currentLabels"`
+	// AddLabels and RemoveLabels are the labels to add and remove, sorted.
+	AddLabels    []string `json:"addLabels,omitempty"`
+	RemoveLabels []string `json:"removeLabels,omitempty"`
+	// Milestone is the title of the milestone the PR should target, if any.
+	// MilestoneRequested is set when it comes from a /milestone command, so
+	// an unknown milestone is the author's mistake rather than the config's.
+	Milestone          string `json:"milestone,omitempty"`
+	MilestoneRequested bool   `json:"milestoneRequested,omitempty"`
+	// TriageAssignee is the triage rotation entry the PR is handed to, if any.
+	TriageAssignee string `json:"triageAssignee,omitempty"`
+	// NotifySecret names the kinds of credential maintainers are told about,
+	// if any.
+	NotifySecret []string `json:"notifySecret,omitempty"`
+	// RecordFailure records the PR as failed for its author.
+	RecordFailure bool `json:"recordFailure,omitempty"`
+	// Comment and BlockersComment are the sticky and merge blockers comments
+	// to post or edit, if any, and RebaseComment the rebase instructions to
+	// post.
+	Comment         *CommentChange `json:"comment,omitempty"`
+	BlockersComment *CommentChange `json:"blockersComment,omitempty"`
+	RebaseComment   *CommentChange `json:"rebaseComment,omitempty"`
+	// CheckRun is the check run to report, if any.
+	CheckRun *CheckRunReport `json:"checkRun,omitempty"`
+	// CommitStatus is the commit status to report, if any.
+	CommitStatus *CommitStatusReport `json:"commitStatus,omitempty"`
+	// Metadata is the PR metadata to commit to MetadataBranch, if any.
+	MetadataBranch string `json:"metadataBranch,omitempty"`
+	Metadata       []byte `json:"metadata,omitempty"`
+	// Report is sent to the reporters set with WithReporters, if any.
+	Report *Report `json:"report,omitempty"`
+}
+
+// CommentChange is a comment to post or edit.
+type CommentChange struct {
+	// ID is the comment to edit, or 0 to post a new comment.
+	ID   int64  `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+// CheckRunReport is a completed check run to report.
+type CheckRunReport struct {
+	// HeadSHA is the commit to report on, or "" for the PR head.
+	HeadSHA    string                 `json:"headSHA,omitempty"`
+	Conclusion string                 `json:"conclusion"`
+	Output     *github.CheckRunOutput `json:"output"`
+}
+
+// CommitStatusReport is a commit status to report.
+type CommitStatusReport struct {
+	// HeadSHA is the commit to report on, or "" for the PR head.
+	HeadSHA     string `json:"headSHA,omitempty"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+}
+
+// FinalLabels returns the labels the PR has once the plan is applied.
+func (p *Plan) FinalLabels() []string {
+	return finalLabels(p.CurrentLabels, p.AddLabels, p.RemoveLabels)
+}
+
+// Snapshot returns the snapshot of the plan's label changes, or nil if it
+// changes no labels.
+func (p *Plan) Snapshot() *Snapshot {
+	if len(p.AddLabels)+len(p.RemoveLabels) == 0 {
+		return nil
+	}
+	return &Snapshot{
+		TakenAt: p.PlannedAt,
+		Labels:  p.CurrentLabels,
+		Added:   p.AddLabels,
+		Removed: p.RemoveLabels,
+	}
+}
+
+// Plan fetches what it needs about the PR, evaluates body and returns the
+// changes to make, without changing anything. The returned error holds the
+// validation failures, if any, alongside the plan; the plan is nil only for
+// API failures.
+//
+// The PR's labels are fetched last, right before the plan is diffed against
+// them, so labels a maintainer changes while the slower steps run are not
+// flipped back when the plan is applied.
+func (l *labeler) Plan(ctx context.Context, body string) (*Plan, error) {
+	if err := l.fetchChangedFiles(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if l.onlyIgnoredPaths() {
+		// automation-only PRs are left alone
+		if err := l.fetchLabels(ctx); err != nil {
+			return nil, &OperationalError{Err: err}
+		}
+		l.evaluate(body)
+		return l.newPlan(), nil
+	}
+	if err := l.fetchSpamSignals(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchAuthor(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchFailures(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchBotAuthor(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchComment(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchReviewCommands(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchCommentCommands(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchBlockersComment(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchMetadata(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchModuleRoots(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchRevert(ctx, body); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchStack(ctx, body); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchRisk(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchMergeableState(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchCI(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	if err := l.fetchLabels(ctx); err != nil {
+		return nil, &OperationalError{Err: err}
+	}
+	l.evaluate(body)
+	l.checkLinks(ctx)
+	return l.plan(), l.validationErr()
+}
+
+// newPlan returns a plan that changes nothing.
+func (l *labeler) newPlan() *Plan {
+	return &Plan{PlannedAt: l.now().UTC(), CurrentLabels: l.repoLabels(l.currentMap)}
+}
+
+// plan returns the changes of the last evaluation.
+func (l *labeler) plan() *Plan {
+	p := l.newPlan()
+	p.AddLabels = l.repoLabels(l.labelsToAdd)
+	p.RemoveLabels = l.repoLabels(l.labelsToRemove)
+	p.Milestone, p.MilestoneRequested = l.milestone, l.milestoneOverride
+	p.TriageAssignee = l.triageAssignee()
+	if l.secretNotifier != nil && l.labelsToAdd[labels.PossibleSecretLabel] {
+		p.NotifySecret = slices.Clone(l.secretKinds)
+	}
+	p.RecordFailure = l.failureStore != nil && l.authorLogin != "" && len(l.problems) > 0 && len(l.suspectedSpam) == 0 &&
+		!slices.Contains(l.authorFailures, l.prRef())
+
+	mayPost := l.mayPostComment()
+	if p.Comment = l.commentChange(mayPost); p.Comment != nil && p.Comment.ID == 0 {
+		mayPost = false
+	}
+	p.BlockersComment = l.blockersCommentChange(mayPost)
+	p.RebaseComment = l.rebaseComment()
+
+	if l.checkRun {
+		conclusion, output := l.checkRunOutput()
+		p.CheckRun = &CheckRunReport{HeadSHA: l.headSHA, Conclusion: conclusion, Output: output}
+	}
+	if l.commitStatus {
+		p.CommitStatus = l.commitStatusReport()
+	}
+	if l.metadataBranch != "" {
+		p.MetadataBranch, p.Metadata = l.metadataBranch, l.metadataJSON()
+	}
+	if len(l.reporters) > 0 {
+		p.Report = l.Report()
+	}
+	return p
+}
+
+// Apply performs p. Every change is attempted even if others fail; the
+// returned error joins the failures.
+func (l *labeler) Apply(ctx context.Context, p *Plan) error {
+	var errs []error
+	if err := l.syncLabels(ctx, p); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	if err := l.syncMilestone(ctx, p); err != nil {
+		errs = append(errs, err)
+	}
+	if err := l.syncTriage(ctx, p); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	if err := l.syncSecretNotification(ctx, p); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	if err := l.syncFailures(ctx, p); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	if err := l.syncComment(ctx, p.Comment, "comment"); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	if err := l.syncComment(ctx, p.BlockersComment, "merge blockers comment"); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	if err := l.syncComment(ctx, p.RebaseComment, "rebase comment"); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	if err := l.syncCheckRun(ctx, p); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	if err := l.syncCommitStatus(ctx, p); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	if err := l.syncMetadataBranch(ctx, p); err != nil {
+		errs = append(errs, &OperationalError{Err: err})
+	}
+	errs = append(errs, l.syncReporters(ctx, p)...)
+	return joinErrs(errs...)
+}
@@ -0,0 +1,147 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestPlan_ChangesNothing(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{{Name: github.Ptr("kind/fix")}}),
+		mock.WithRequestMatch(mock.GetReposIssuesCommentsByOwnerByRepoByIssueNumber, []*github.IssueComment{}),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false).
+		WithClock(func() time.Time { return now }).
+		WithStickyComment().
+		WithCheckRun("abc123", true)
+	// only the reads above are mocked, so any write fails the test
+	p, err := l.Plan(context.Background(), "no kind here")
+	if p == nil {
+		t.Fatalf("expected a plan, got error %v", err)
+	}
+	if _, operational := Partition(err); len(operational) > 0 {
+		t.Fatalf("unexpected operational errors: %v", operational)
+	}
+	if want := []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel}; !reflect.DeepEqual(p.AddLabels, want) {
+		t.Errorf("AddLabels = %v, want %v", p.AddLabels, want)
+	}
+	if len(p.RemoveLabels) != 0 {
+		t.Errorf("RemoveLabels = %v, want none", p.RemoveLabels)
+	}
+	if p.Comment == nil || p.Comment.ID != 0 {
+		t.Errorf("expected a new sticky comment, got %+v", p.Comment)
+	}
+	if p.CheckRun == nil || p.CheckRun.HeadSHA != "abc123" || p.CheckRun.Conclusion != "failure" {
+		t.Errorf("expected a failed check run on abc123, got %+v", p.CheckRun)
+	}
+	s := p.Snapshot()
+	if s == nil || !s.TakenAt.Equal(now) || !reflect.DeepEqual(s.Labels, []string{"kind/fix"}) {
+		t.Errorf("unexpected snapshot %+v", s)
+	}
+	if want := []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel, "kind/fix"}; !reflect.DeepEqual(p.FinalLabels(), want) {
+		t.Errorf("FinalLabels = %v, want %v", p.FinalLabels(), want)
+	}
+}
+
+func TestApply(t *testing.T) {
+	var (
+		added    []string
+		removed  []string
+		comments []github.IssueComment
+		checkRun github.CreateCheckRunOptions
+	)
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&added)
+				w.Write(mock.MustMarshal([]*github.Label{}))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				removed = append(removed, r.URL.Path)
+				w.Write(mock.MustMarshal([]*github.Label{}))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposIssuesCommentsByOwnerByRepoByCommentId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var c github.IssueComment
+				json.NewDecoder(r.Body).Decode(&c)
+				comments = append(comments, c)
+				w.Write(mock.MustMarshal(c))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposCheckRunsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&checkRun)
+				w.Write(mock.MustMarshal(github.CheckRun{}))
+			}),
+		),
+	)
+	p := &Plan{
+		PlannedAt:     time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC),
+		CurrentLabels: []string{"kind/fix"},
+		AddLabels:     []string{"kind/cleanup"},
+		RemoveLabels:  []string{"kind/fix"},
+		Comment:       &CommentChange{ID: 7, Body: "updated"},
+		CheckRun:      &CheckRunReport{HeadSHA: "abc123", Conclusion: "success", Output: &github.CheckRunOutput{Title: github.Ptr("ok"), Summary: github.Ptr("ok")}},
+	}
+	if err := New(github.NewClient(httpClient), "owner", "repo", 1, false).Apply(context.Background(), p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(added, []string{"kind/cleanup"}) || len(removed) != 1 {
+		t.Errorf("added %v and removed %v, want [kind/cleanup] and kind/fix", added, removed)
+	}
+	if len(comments) != 1 || comments[0].GetBody() != "updated" {
+		t.Errorf("expected comment 7 to be updated, got %+v", comments)
+	}
+	if checkRun.HeadSHA != "abc123" || ParseSnapshot(checkRun.GetExternalID()) == nil {
+		t.Errorf("expected a check run on abc123 with the label snapshot, got %+v", checkRun)
+	}
+}
+
+func TestPlan_DiffsAgainstLabelsChangedMidRun(t *testing.T) {
+	onPR := []*github.Label{{Name: github.Ptr("kind/cleanup")}}
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(onPR)
+			}),
+		),
+		mock.WithRequestMatch(mock.GetReposPullsFilesByOwnerByRepoByPullNumber, []*github.CommitFile{}),
+		mock.WithRequestMatchHandler(
+			mock.GetReposPullsByOwnerByRepoByPullNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// a maintainer fixes the kind label while the labeler runs
+				onPR = []*github.Label{{Name: github.Ptr("kind/fix")}}
+				json.NewEncoder(w).Encode(&github.PullRequest{})
+			}),
+		),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithRiskScoring(nil)
+	p, err := l.Plan(context.Background(), "/kind fix\n```release-note\nNONE\n```")
+	if p == nil {
+		t.Fatalf("expected a plan, got error %v", err)
+	}
+	if !reflect.DeepEqual(p.CurrentLabels, []string{"kind/fix"}) {
+		t.Errorf("CurrentLabels = %v, want the labels after the maintainer's change", p.CurrentLabels)
+	}
+	if len(p.RemoveLabels) != 0 {
+		t.Errorf("RemoveLabels = %v, want none", p.RemoveLabels)
+	}
+}
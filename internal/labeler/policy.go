@@ -0,0 +1,176 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// AuthorAssociations are the author_association values GitHub reports for
+// PRs, from most to least trusted.
+var AuthorAssociations = []string{
+	"OWNER",
+	"MEMBER",
+	"COLLABORATOR",
+	"CONTRIBUTOR",
+	"FIRST_TIME_CONTRIBUTOR",
+	"FIRST_TIMER",
+	"MANNEQUIN",
+	"NONE",
+}
+
+// AuthorPolicy relaxes validation for authors with a given association.
+type AuthorPolicy struct {
+	// AutoNoneKinds are kinds for which a missing release note is treated as
+	// NONE, e.g. flake fixes by members. Every kind on the PR must be listed.
+	AutoNoneKinds []string `json:"autoNoneKinds,omitempty"`
+}
+
+// WithAuthorPolicies sets policies keyed on the PR author's association with
+// the repository, e.g. MEMBER, or on a GitHub team as org/team-slug. An
+// author gets every policy that matches them; authors no policy matches, such
+// as external contributors, get the default validation. Team keys need a
+// resolver set with WithTeamResolver.
+func (l *labeler) WithAuthorPolicies(policies map[string]AuthorPolicy) *labeler {
+	l.authorPolicies = policies
+	return l
+}
+
+// WithAuthor sets the PR author's login and association, when the caller
+// already has them from the webhook payload. Otherwise they are looked up
+// when a policy or permission check needs them.
+func (l *labeler) WithAuthor(login, association string) *labeler {
+	l.authorLogin = login
+	l.authorAssociation = strings.ToUpper(association)
+	return l
+}
+
+// TeamResolver answers whether a user is a member of a GitHub team, given as
+// org/team-slug.
+type TeamResolver interface {
+	IsMember(ctx context.Context, team, user string) (bool, error)
+}
+
+// WithTeamResolver sets how team membership is checked for team-keyed author
+// policies and team-restricted commands.
+func (l *labeler) WithTeamResolver(r TeamResolver) *labeler {
+	l.teamResolver = r
+	return l
+}
+
+// ValidPolicyKey reports whether key is an author association or an
+// org/team-slug team reference.
+func ValidPolicyKey(key string) bool {
+	return ValidAuthorAssociation(key) || isTeamKey(key)
+}
+
+// isTeamKey reports whether key refers to a team rather than an association.
+func isTeamKey(key string) bool {
+	org, slug, ok := strings.Cut(key, "/")
+	return ok && org != "" && slug != "" && !strings.Contains(slug, "/")
+}
+
+// ValidAuthorAssociation reports whether association is one GitHub reports.
+func ValidAuthorAssociation(association string) bool {
+	for _, a := range AuthorAssociations {
+		if a == association {
+			return true
+		}
+	}
+	return false
+}
+
+// pullRequest returns the PR, fetching it at most once per labeler.
+func (l *labeler) pullRequest(ctx context.Context) (*github.PullRequest, error) {
+	if l.pr != nil {
+		return l.pr, nil
+	}
+	pr, _, err := l.client.PullRequests.Get(ctx, l.owner, l.repo, l.prNum)
+	if err != nil {
+		return nil, apiError(err, permPullRequestsRead, "get PR")
+	}
+	l.pr = pr
+	return pr, nil
+}
+
+// authorTeamsToCheck returns the teams author policies and command
+// restrictions refer to.
+func (l *labeler) authorTeamsToCheck() []string {
+	teams := map[string]bool{}
+	for key := range l.authorPolicies {
+		if isTeamKey(key) {
+			teams[key] = true
+		}
+	}
+	for _, team := range l.milestoneTeams {
+		teams[team] = true
+	}
+	return sortedKeys(teams)
+}
+
+// fetchAuthor looks up the author's association and team memberships if a
+// policy, command restriction or failure count depends on them.
+func (l *labeler) fetchAuthor(ctx context.Context) error {
+	teams := l.authorTeamsToCheck()
+	if len(l.authorPolicies) == 0 && len(teams) == 0 && l.failureStore == nil {
+		return nil
+	}
+	if l.authorLogin == "" || l.authorAssociation == "" {
+		pr, err := l.pullRequest(ctx)
+		if err != nil {
+			return err
+		}
+		l.authorLogin = pr.GetUser().GetLogin()
+		l.authorAssociation = strings.ToUpper(pr.GetAuthorAssociation())
+	}
+	l.authorTeams = map[string]bool{}
+	if len(teams) == 0 {
+		return nil
+	}
+	if l.teamResolver == nil {
+		return fmt.Errorf("team membership is needed for %s but no team resolver is configured", strings.Join(teams, ", "))
+	}
+	for _, team := range teams {
+		member, err := l.teamResolver.IsMember(ctx, team, l.authorLogin)
+		if err != nil {
+			return err
+		}
+		l.authorTeams[team] = member
+	}
+	return nil
+}
+
+// authorInAnyTeam reports whether the author is a member of one of teams.
+func (l *labeler) authorInAnyTeam(teams []string) bool {
+	for _, team := range teams {
+		if l.authorTeams[team] {
+			return true
+		}
+	}
+	return false
+}
+
+// autoNoneReleaseNote reports whether the author's policy lets this PR omit
+// its release note.
+func (l *labeler) autoNoneReleaseNote() bool {
+	if len(l.kinds) == 0 {
+		return false
+	}
+	allowed := map[string]bool{}
+	for key, policy := range l.authorPolicies {
+		if key != l.authorAssociation && !l.authorTeams[key] {
+			continue
+		}
+		for _, k := range policy.AutoNoneKinds {
+			allowed[k] = true
+		}
+	}
+	for k := range l.kinds {
+		if !allowed[k] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestSimulate_AuthorPolicies(t *testing.T) {
+	policies := map[string]AuthorPolicy{
+		"MEMBER": {AutoNoneKinds: []string{"flake", "cleanup"}},
+	}
+	tests := []struct {
+		name        string
+		association string
+		body        string
+		wantAdd     []string
+		wantErr     bool
+	}{
+		{
+			name:        "member flake fix without note",
+			association: "MEMBER",
+			body:        "/kind flake",
+			wantAdd:     []string{"kind/flake", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:        "association is case-insensitive",
+			association: "member",
+			body:        "/kind flake\n/kind cleanup\n```release-note\n```",
+			wantAdd:     []string{"kind/cleanup", "kind/flake", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:        "member note is still used when supplied",
+			association: "MEMBER",
+			body:        "/kind flake\n```release-note\nFixed a flaky test.\n```",
+			wantAdd:     []string{"kind/flake", labels.ReleaseNoteLabel},
+		},
+		{
+			name:        "member with a kind outside the policy",
+			association: "MEMBER",
+			body:        "/kind flake\n/kind fix",
+			wantAdd:     []string{labels.InvalidReleaseNoteLabel, "kind/fix", "kind/flake"},
+			wantErr:     true,
+		},
+		{
+			name:        "external contributor must supply a note",
+			association: "CONTRIBUTOR",
+			body:        "/kind flake",
+			wantAdd:     []string{labels.InvalidReleaseNoteLabel, "kind/flake"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false).WithAuthorPolicies(policies).WithAuthor("author", tt.association)
+			d, err := l.Simulate(tt.body, nil)
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessPR_FetchesAuthorAssociation(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			[]*github.Label{},
+		),
+		mock.WithRequestMatch(
+			mock.GetReposPullsByOwnerByRepoByPullNumber,
+			github.PullRequest{AuthorAssociation: github.Ptr("OWNER")},
+		),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false).
+		WithAuthorPolicies(map[string]AuthorPolicy{"OWNER": {AutoNoneKinds: []string{"flake"}}})
+	if _, err := l.ProcessPR(context.Background(), "/kind flake", false); err != nil {
+		t.Fatalf("expected the owner policy to allow a missing note, got %v", err)
+	}
+}
+
+type fakeTeamResolver map[string]bool
+
+func (f fakeTeamResolver) IsMember(ctx context.Context, team, user string) (bool, error) {
+	return f[team+"@"+user], nil
+}
+
+func TestProcessPR_TeamPolicies(t *testing.T) {
+	tests := []struct {
+		name           string
+		author         string
+		body           string
+		milestoneTeams []string
+		wantMilestone  string
+		wantErr        bool
+	}{
+		{
+			name:          "team member may omit the note",
+			author:        "alice",
+			body:          "/kind cleanup",
+			wantMilestone: "next",
+		},
+		{
+			name:          "non-member must supply a note",
+			author:        "mallory",
+			body:          "/kind cleanup",
+			wantMilestone: "next",
+			wantErr:       true,
+		},
+		{
+			name:           "team member may use /milestone",
+			author:         "alice",
+			body:           "/kind cleanup\n/milestone v2.1",
+			milestoneTeams: []string{"org/maintainers"},
+			wantMilestone:  "v2.1",
+		},
+		{
+			name:           "non-member /milestone falls back to the default",
+			author:         "mallory",
+			body:           "/kind cleanup\n```release-note\nNONE\n```\n/milestone v2.1",
+			milestoneTeams: []string{"org/maintainers"},
+			wantMilestone:  "next",
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+					[]*github.Label{},
+				),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).
+				WithAuthor(tt.author, "CONTRIBUTOR").
+				WithAuthorPolicies(map[string]AuthorPolicy{"org/maintainers": {AutoNoneKinds: []string{"cleanup"}}}).
+				WithTeamResolver(fakeTeamResolver{"org/maintainers@alice": true}).
+				WithMilestones(map[string]string{"cleanup": "next"}).
+				WithMilestoneTeams(tt.milestoneTeams)
+			_, err := l.ProcessPR(context.Background(), tt.body, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := l.Decision().Milestone; got != tt.wantMilestone {
+				t.Fatalf("milestone = %q, want %q", got, tt.wantMilestone)
+			}
+		})
+	}
+}
+
+func TestProcessPR_TeamPolicyWithoutResolver(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			[]*github.Label{},
+		),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false).
+		WithAuthor("alice", "MEMBER").
+		WithMilestoneTeams([]string{"org/maintainers"})
+	_, err := l.ProcessPR(context.Background(), "/kind cleanup\n```release-note\nNONE\n```", false)
+	_, operational := Partition(err)
+	if len(operational) != 1 {
+		t.Fatalf("expected a missing resolver to be an operational error, got %v", operational)
+	}
+}
@@ -0,0 +1,24 @@
+package labeler
+
+import (
+	"time"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/provenance"
+)
+
+// Provenance returns a record of the last decision, timestamped with the
+// labeler's clock, ready to be signed.
+func (l *labeler) Provenance(version string) provenance.Record {
+	d := l.Decision()
+	return provenance.Record{
+		Repository:     l.owner + "/" + l.repo,
+		PullRequest:    l.prNum,
+		BodySHA256:     provenance.BodySHA256(l.body),
+		Labels:         d.FinalLabels(),
+		LabelsAdded:    d.LabelsToAdd,
+		LabelsRemoved:  d.LabelsToRemove,
+		Valid:          len(l.problems) == 0,
+		Timestamp:      l.now().UTC().Format(time.RFC3339),
+		LabelerVersion: version,
+	}
+}
@@ -0,0 +1,72 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// PRStatus is where a PR stands with the labeler, in terms its author can act
+// on without reading workflow logs.
+type PRStatus struct {
+	Verdict
+	// Problems are the validation failures of the PR description.
+	Problems []string `json:"problems,omitempty"`
+	// ManagedLabels are the labels the labeler manages that the PR has now.
+	ManagedLabels []string `json:"managedLabels"`
+	// PendingAdd and PendingRemove are the labels the labeler has yet to add
+	// or remove, e.g. because it has not run since the description was last
+	// edited.
+	PendingAdd    []string `json:"pendingAdd,omitempty"`
+	PendingRemove []string `json:"pendingRemove,omitempty"`
+	// Steps are what to do, in order, for the PR to become mergeable.
+	Steps []string `json:"steps,omitempty"`
+}
+
+// Status evaluates the PR as it is now, without changing anything, and
+// returns where it stands and how to unblock it.
+func (l *labeler) Status(ctx context.Context, body string) (*PRStatus, error) {
+	v, err := l.Mergeable(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	d := l.Decision()
+	s := &PRStatus{Verdict: *v, ManagedLabels: []string{}}
+	for _, err := range l.problems {
+		s.Problems = append(s.Problems, (&ValidationError{Err: err}).Error())
+	}
+	for _, label := range d.CurrentLabels {
+		if labels.Managed(label) {
+			s.ManagedLabels = append(s.ManagedLabels, label)
+		}
+	}
+	s.PendingAdd, s.PendingRemove = d.LabelsToAdd, d.LabelsToRemove
+
+	seen := map[string]bool{}
+	for _, b := range v.Blockers {
+		label := b.Label
+		switch b.Check {
+		case CheckKind:
+			label = labels.InvalidKindLabel
+		case CheckReleaseNote:
+			label = labels.InvalidReleaseNoteLabel
+		}
+		if seen[label] {
+			continue
+		}
+		seen[label] = true
+		step, ok := blockerHints[label]
+		switch {
+		case label == labels.SuspectedSpamLabel:
+			step = "wait for a maintainer to triage the PR."
+		case !ok:
+			step = fmt.Sprintf("ask a maintainer what is needed to remove the `%s` label.", label)
+		}
+		s.Steps = append(s.Steps, step)
+	}
+	if len(s.PendingAdd)+len(s.PendingRemove) > 0 {
+		s.Steps = append(s.Steps, "let the labeler catch up, e.g. by editing the PR description or re-running its workflow, so the labels match the description.")
+	}
+	return s, nil
+}
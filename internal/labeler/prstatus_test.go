@@ -0,0 +1,54 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestStatus(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{
+			{Name: github.Ptr("kind/fix")},
+			{Name: github.Ptr(labels.ReleaseNoteNoneLabel)},
+			{Name: github.Ptr("do-not-merge/hold")},
+			{Name: github.Ptr("area/api")},
+		}),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, true)
+	s, err := l.Status(context.Background(), "# Description\nFix.\n```release-note\nNONE\n```")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Mergeable {
+		t.Fatal("expected the PR to be blocked")
+	}
+	if len(s.Problems) != 1 || !strings.Contains(s.Problems[0], "/kind") {
+		t.Errorf("expected the missing kind as the only problem, got %q", s.Problems)
+	}
+	if want := []string{"kind/fix", labels.ReleaseNoteNoneLabel}; !reflect.DeepEqual(s.ManagedLabels, want) {
+		t.Errorf("ManagedLabels = %v, want %v", s.ManagedLabels, want)
+	}
+	if !reflect.DeepEqual(s.PendingAdd, []string{labels.InvalidKindLabel}) || len(s.PendingRemove) > 0 {
+		t.Errorf("unexpected pending changes +%v -%v", s.PendingAdd, s.PendingRemove)
+	}
+	want := []string{
+		"ask a maintainer what is needed to remove the `do-not-merge/hold` label.",
+		blockerHints[labels.InvalidKindLabel],
+		"let the labeler catch up",
+	}
+	if len(s.Steps) != len(want) {
+		t.Fatalf("Steps = %q, want %d steps", s.Steps, len(want))
+	}
+	for i := range want {
+		if !strings.HasPrefix(s.Steps[i], want[i]) {
+			t.Errorf("step %d = %q, want %q", i+1, s.Steps[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package labeler
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// Limits on the PR body that is evaluated. Go's regexp package runs in time
+// linear in its input, so no pattern can backtrack catastrophically, but
+// every validator scans the body, some line by line, and megabyte-long
+// bodies or lines would still stall processing until webhooks time out.
+const (
+	// MaxBodySize is the size, in bytes, of the head of the PR body that is
+	// evaluated.
+	MaxBodySize = 128 << 10
+	// MaxLineLength is the length, in bytes, lines of the PR body are cut to.
+	MaxLineLength = 16 << 10
+)
+
+// Quarantined reports whether the last evaluation only evaluated the head of
+// a PR body that exceeded MaxBodySize or had lines longer than MaxLineLength.
+func (l *labeler) Quarantined() bool {
+	return l.quarantined
+}
+
+// quarantineBody returns the head of body that is safe to evaluate, and
+// whether anything was cut off.
+func quarantineBody(body string) (string, bool) {
+	if len(body) <= MaxLineLength {
+		return body, false
+	}
+	var sb strings.Builder
+	truncated := false
+	for line := range strings.Lines(body) {
+		if len(line) > MaxLineLength {
+			line = truncateUTF8(line, MaxLineLength) + "\n"
+			truncated = true
+		}
+		if sb.Len()+len(line) > MaxBodySize {
+			truncated = true
+			break
+		}
+		sb.WriteString(line)
+	}
+	return sb.String(), truncated
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character.
+func truncateUTF8(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// processQuarantine labels a quarantined PR needs-human-review, so a
+// maintainer looks at what was not evaluated, and removes the label once the
+// body is back within the limits.
+func (l *labeler) processQuarantine() {
+	switch {
+	case l.quarantined && !l.currentMap[labels.NeedsHumanReviewLabel]:
+		l.labelsToAdd[labels.NeedsHumanReviewLabel] = true
+	case !l.quarantined && l.currentMap[labels.NeedsHumanReviewLabel]:
+		l.labelsToRemove[labels.NeedsHumanReviewLabel] = true
+	}
+}
@@ -0,0 +1,75 @@
+package labeler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestQuarantineBody(t *testing.T) {
+	valid := "# Description\nFix.\n/kind fix\n```release-note\nNONE\n```\n"
+	tests := []struct {
+		name          string
+		body          string
+		wantTruncated bool
+	}{
+		{name: "small body", body: valid},
+		{name: "long body within limits", body: valid + strings.Repeat("padding\n", MaxBodySize/16)},
+		{name: "oversized body", body: valid + strings.Repeat("padding\n", MaxBodySize/4), wantTruncated: true},
+		{name: "long line", body: valid + strings.Repeat("é", MaxLineLength) + "\nmore\n", wantTruncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := quarantineBody(tt.body)
+			if truncated != tt.wantTruncated {
+				t.Fatalf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+			if !tt.wantTruncated && got != tt.body {
+				t.Fatal("expected the body to be left as is")
+			}
+			if !strings.HasPrefix(got, valid) || len(got) > MaxBodySize || !utf8.ValidString(got) {
+				t.Fatalf("expected a valid head of the body within %d bytes, got %d bytes", MaxBodySize, len(got))
+			}
+			for line := range strings.Lines(got) {
+				if len(line) > MaxLineLength+1 {
+					t.Fatalf("expected lines of at most %d bytes, got %d", MaxLineLength, len(line))
+				}
+			}
+		})
+	}
+}
+
+func TestSimulate_Quarantine(t *testing.T) {
+	valid := "# Description\nFix.\n/kind fix\n```release-note\nNONE\n```\n"
+	// a megabyte of unterminated comments and fences stresses every pattern
+	pathological := valid + strings.Repeat("<!-- ```release-note /kind ", 1<<20/27)
+
+	start := time.Now()
+	l := New(nil, "owner", "repo", 1, true)
+	d, err := l.Simulate(pathological, nil)
+	if err != nil {
+		t.Fatalf("expected the head of the body to validate, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("evaluation took %s", elapsed)
+	}
+	if !l.Quarantined() {
+		t.Fatal("expected the PR to be quarantined")
+	}
+	if want := []string{"kind/fix", labels.NeedsHumanReviewLabel, labels.ReleaseNoteNoneLabel}; !reflect.DeepEqual(d.LabelsToAdd, want) {
+		t.Fatalf("labels to add = %v, want %v", d.LabelsToAdd, want)
+	}
+
+	l = New(nil, "owner", "repo", 1, true)
+	d, err = l.Simulate(valid, []string{"kind/fix", labels.NeedsHumanReviewLabel, labels.ReleaseNoteNoneLabel})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.Quarantined() || !reflect.DeepEqual(d.LabelsToRemove, []string{labels.NeedsHumanReviewLabel}) {
+		t.Fatalf("expected the label to be removed once the body is fixed, got %+v", d)
+	}
+}
@@ -0,0 +1,86 @@
+package labeler
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/changelog"
+)
+
+// releaseNoteCommandRE matches a comment that is a /release-note TEXT or
+// /release-note-none command.
+var releaseNoteCommandRE = regexp.MustCompile(`(?s)^\s*/release-note(?:-none\s*$|[ \t]+(\S.*?)\s*$)`)
+
+// stateReleaseNote is a release note set with a comment command.
+type stateReleaseNote struct {
+	// Note is the release note, or NONE.
+	Note string `json:"note"`
+	// SetBy is the login of the maintainer who set it.
+	SetBy string `json:"setBy"`
+	// CommentID is the comment the command was given in, or ReviewID the
+	// review.
+	CommentID int64 `json:"commentID"`
+	ReviewID  int64 `json:"reviewID,omitempty"`
+	// SetAt is when the command was given.
+	SetAt time.Time `json:"setAt,omitzero"`
+}
+
+// ParseReleaseNoteCommand returns the release note set by a /release-note
+// TEXT or /release-note-none comment, NONE for the latter, and whether body
+// is such a command.
+func ParseReleaseNoteCommand(body string) (string, bool) {
+	m := releaseNoteCommandRE.FindStringSubmatch(strings.ReplaceAll(body, "\r\n", "\n"))
+	if m == nil {
+		return "", false
+	}
+	if m[1] == "" {
+		return "NONE", true
+	}
+	return m[1], true
+}
+
+// releaseNoteCommand returns the latest release note command given by a
+// maintainer, a user who can write to the repository, among comments, or nil
+// if there is none newer than prev.
+func (l *labeler) releaseNoteCommand(ctx context.Context, comments []*github.IssueComment, prev *stateReleaseNote) (*stateReleaseNote, error) {
+	var latest *stateReleaseNote
+	for _, c := range comments {
+		note, ok := ParseReleaseNoteCommand(c.GetBody())
+		if !ok {
+			continue
+		}
+		if prev != nil && (c.GetID() <= prev.CommentID || (!prev.SetAt.IsZero() && !c.GetCreatedAt().After(prev.SetAt))) {
+			continue
+		}
+		if latest != nil && c.GetID() <= latest.CommentID {
+			continue
+		}
+		ok, err := l.canWrite(ctx, c.GetUser().GetLogin())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			latest = &stateReleaseNote{Note: note, SetBy: c.GetUser().GetLogin(), CommentID: c.GetID(), SetAt: c.GetCreatedAt().Time}
+		}
+	}
+	return latest, nil
+}
+
+// applyReleaseNoteOverride labels the PR for a release note set by a
+// maintainer, which satisfies validation whatever the PR body says.
+func (l *labeler) applyReleaseNoteOverride(o *stateReleaseNote) {
+	if strings.EqualFold(o.Note, "NONE") {
+		l.markNoneReleaseNote()
+		return
+	}
+	category, note := changelog.ParseCategory(o.Note)
+	if _, ok := changelog.Lookup(category); !ok || strings.TrimSpace(note) == "" {
+		category, note = "", o.Note
+	}
+	l.markReleaseNote()
+	l.releaseNote = &ReleaseNote{Note: note, Category: category, Section: changelog.SectionFor(l.kinds, category), SetBy: o.SetBy}
+}
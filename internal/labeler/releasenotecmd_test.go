@@ -0,0 +1,135 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestParseReleaseNoteCommand(t *testing.T) {
+	tests := []struct {
+		body   string
+		want   string
+		wantOK bool
+	}{
+		{body: "/release-note Fixed a crash.", want: "Fixed a crash.", wantOK: true},
+		{body: "  /release-note [helm] Added a value.\r\n", want: "[helm] Added a value.", wantOK: true},
+		{body: "/release-note-none", want: "NONE", wantOK: true},
+		{body: "/release-note-none please", wantOK: false},
+		{body: "/release-note", wantOK: false},
+		{body: "LGTM\n/release-note Fixed a crash.", wantOK: false},
+		{body: "/release-notes Fixed a crash.", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseReleaseNoteCommand(tt.body)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseReleaseNoteCommand(%q) = %q, %v, want %q, %v", tt.body, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func comment(id int64, login, association, body string) *github.IssueComment {
+	return &github.IssueComment{
+		ID:                github.Ptr(id),
+		User:              &github.User{Login: github.Ptr(login)},
+		AuthorAssociation: github.Ptr(association),
+		Body:              github.Ptr(body),
+	}
+}
+
+func TestProcessPR_ReleaseNoteCommand(t *testing.T) {
+	recorded := commentState{ReleaseNote: &stateReleaseNote{Note: "Recorded note.", SetBy: "bob", CommentID: 5}}
+	tests := []struct {
+		name      string
+		comments  []*github.IssueComment
+		wantNote  *ReleaseNote
+		wantAdd   []string
+		wantValid bool
+	}{
+		{
+			name:      "maintainer sets the note",
+			comments:  []*github.IssueComment{comment(3, "alice", "MEMBER", "/release-note [security] Fixed a token leak.")},
+			wantNote:  &ReleaseNote{Note: "Fixed a token leak.", Category: "security", Section: "security", SetBy: "alice"},
+			wantAdd:   []string{"kind/fix", labels.ReleaseNoteLabel},
+			wantValid: true,
+		},
+		{
+			name: "latest maintainer command wins",
+			comments: []*github.IssueComment{
+				comment(3, "alice", "MEMBER", "/release-note Fixed a crash."),
+				comment(4, "carol", "OWNER", "/release-note-none"),
+			},
+			wantAdd:   []string{"kind/fix", labels.ReleaseNoteNoneLabel},
+			wantValid: true,
+		},
+		{
+			name:     "author without write access is ignored",
+			comments: []*github.IssueComment{comment(3, "mallory", "CONTRIBUTOR", "/release-note-none")},
+			wantAdd:  []string{labels.InvalidReleaseNoteLabel, "kind/fix"},
+		},
+		{
+			name:     "member without write access is ignored",
+			comments: []*github.IssueComment{comment(3, "dave", "MEMBER", "/release-note-none")},
+			wantAdd:  []string{labels.InvalidReleaseNoteLabel, "kind/fix"},
+		},
+		{
+			name:      "recorded note survives its comment",
+			comments:  []*github.IssueComment{comment(7, "", "", commentMarker+"\n"+recorded.render())},
+			wantNote:  &ReleaseNote{Note: "Recorded note.", Section: "fix", SetBy: "bob"},
+			wantAdd:   []string{"kind/fix", labels.ReleaseNoteLabel},
+			wantValid: true,
+		},
+		{
+			name: "older command does not replace the recorded note",
+			comments: []*github.IssueComment{
+				comment(2, "alice", "MEMBER", "/release-note-none"),
+				comment(7, "", "", commentMarker+"\n"+recorded.render()),
+			},
+			wantNote:  &ReleaseNote{Note: "Recorded note.", Section: "fix", SetBy: "bob"},
+			wantAdd:   []string{"kind/fix", labels.ReleaseNoteLabel},
+			wantValid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posted string
+			save := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var c github.IssueComment
+				json.NewDecoder(r.Body).Decode(&c)
+				posted = c.GetBody()
+				w.Write(mock.MustMarshal(c))
+			})
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.GetReposIssuesCommentsByOwnerByRepoByIssueNumber, tt.comments),
+				mock.WithRequestMatchHandler(mock.PostReposIssuesCommentsByOwnerByRepoByIssueNumber, save),
+				mock.WithRequestMatchHandler(mock.PatchReposIssuesCommentsByOwnerByRepoByCommentId, save),
+				permissionLevels(map[string]string{"alice": "write", "carol": "admin", "mallory": "read", "dave": "read"}),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithStickyComment()
+			_, err := l.ProcessPR(context.Background(), "/kind fix", true)
+			d := l.Decision()
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Fatalf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if !reflect.DeepEqual(d.ReleaseNote, tt.wantNote) {
+				t.Fatalf("release note = %+v, want %+v", d.ReleaseNote, tt.wantNote)
+			}
+			if tt.wantValid != (err == nil) {
+				t.Fatalf("valid = %v, want %v: %v", err == nil, tt.wantValid, err)
+			}
+			if tt.wantValid && !strings.Contains(posted, `"releaseNote":{`) {
+				t.Fatalf("expected the note to be recorded in the comment state:\n%s", posted)
+			}
+		})
+	}
+}
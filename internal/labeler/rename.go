@@ -0,0 +1,18 @@
+package labeler
+
+// renameOnlyKind is the kind PRs that only rename files default to.
+const renameOnlyKind = "cleanup"
+
+// WithRenameDetection recognizes PRs that only rename or move files, without
+// changing their contents, from the status GitHub reports for each file. Such
+// PRs default to /kind cleanup when the body sets no kind, and a missing
+// release note is treated as NONE, so restructuring PRs need no boilerplate.
+func (l *labeler) WithRenameDetection() *labeler {
+	l.detectRenames = true
+	return l
+}
+
+// RenameOnly reports whether the PR only renames files.
+func (l *labeler) RenameOnly() bool {
+	return l.renameOnly
+}
@@ -0,0 +1,89 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestProcessPR_RenameDetection(t *testing.T) {
+	renamed := func(from, to string, changes int) *github.CommitFile {
+		return &github.CommitFile{Filename: github.Ptr(to), PreviousFilename: github.Ptr(from), Status: github.Ptr("renamed"), Changes: github.Ptr(changes)}
+	}
+	tests := []struct {
+		name           string
+		body           string
+		files          []*github.CommitFile
+		detect         bool
+		wantRenameOnly bool
+		wantAdd        []string
+		wantErr        bool
+	}{
+		{
+			name:           "rename-only PR defaults kind and release note",
+			body:           "# Description\nMove the foo package.",
+			files:          []*github.CommitFile{renamed("pkg/foo/a.go", "internal/foo/a.go", 0), renamed("pkg/foo/b.go", "internal/foo/b.go", 0)},
+			detect:         true,
+			wantRenameOnly: true,
+			wantAdd:        []string{"kind/cleanup", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:           "explicit kind wins",
+			body:           "# Description\nMove the foo package.\n/kind documentation",
+			files:          []*github.CommitFile{renamed("docs/a.md", "site/a.md", 0)},
+			detect:         true,
+			wantRenameOnly: true,
+			wantAdd:        []string{"kind/documentation", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:    "rename with content changes",
+			body:    "# Description\nMove the foo package.",
+			files:   []*github.CommitFile{renamed("pkg/foo/a.go", "internal/foo/a.go", 4)},
+			detect:  true,
+			wantAdd: []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel},
+			wantErr: true,
+		},
+		{
+			name:    "rename and added file",
+			body:    "# Description\nMove the foo package.",
+			files:   []*github.CommitFile{renamed("pkg/foo/a.go", "internal/foo/a.go", 0), {Filename: github.Ptr("internal/foo/c.go"), Status: github.Ptr("added"), Changes: github.Ptr(10)}},
+			detect:  true,
+			wantAdd: []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel},
+			wantErr: true,
+		},
+		{
+			name:    "detection disabled",
+			body:    "# Description\nMove the foo package.",
+			files:   []*github.CommitFile{renamed("pkg/foo/a.go", "internal/foo/a.go", 0)},
+			wantAdd: []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.GetReposPullsFilesByOwnerByRepoByPullNumber, tt.files),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, true)
+			if tt.detect {
+				l.WithRenameDetection()
+			}
+			_, err := l.ProcessPR(context.Background(), tt.body, false)
+			if l.RenameOnly() != tt.wantRenameOnly {
+				t.Fatalf("RenameOnly() = %v, want %v", l.RenameOnly(), tt.wantRenameOnly)
+			}
+			if got := l.Decision().LabelsToAdd; !reflect.DeepEqual(got, tt.wantAdd) {
+				t.Fatalf("labels to add = %v, want %v", got, tt.wantAdd)
+			}
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
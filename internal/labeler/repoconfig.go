@@ -0,0 +1,58 @@
+package labeler
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/config"
+)
+
+// WithRepoConfig uses the kinds, deprecated kinds and label names the
+// repository defines in c instead of the compiled-in ones. Labels are
+// renamed only where they meet GitHub: the PR's labels are read, and the
+// planned changes reported, by the repository's names.
+func (l *labeler) WithRepoConfig(c *config.Config) *labeler {
+	if c == nil {
+		return l
+	}
+	l.supportedKinds = c.SupportedKinds()
+	l.kindAliases = c.Aliases()
+	l.labelNames = maps.Clone(c.Labels)
+	l.defaultLabelNames = map[string]string{}
+	for from, to := range c.Labels {
+		l.defaultLabelNames[to] = from
+	}
+	return l
+}
+
+// renderKinds returns the supported kinds as a comma-separated list.
+func (l *labeler) renderKinds() string {
+	return strings.Join(sortedKeys(l.supportedKinds), ", ")
+}
+
+// repoLabel returns the repository's name for the label named label by
+// default.
+func (l *labeler) repoLabel(label string) string {
+	if renamed, ok := l.labelNames[label]; ok {
+		return renamed
+	}
+	return label
+}
+
+// defaultLabel returns the default name of the label the repository names
+// label.
+func (l *labeler) defaultLabel(label string) string {
+	if name, ok := l.defaultLabelNames[label]; ok {
+		return name
+	}
+	return label
+}
+
+// repoLabels returns the repository's names for the labels of m, sorted.
+func (l *labeler) repoLabels(m map[string]bool) []string {
+	renamed := make(map[string]bool, len(m))
+	for label := range m {
+		renamed[l.repoLabel(label)] = true
+	}
+	return sortedKeys(renamed)
+}
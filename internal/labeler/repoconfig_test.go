@@ -0,0 +1,67 @@
+package labeler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/config"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestSimulate_RepoConfig(t *testing.T) {
+	c := &config.Config{
+		Kinds:           []string{"fix", "chore"},
+		DeprecatedKinds: map[string]string{"bug": "fix"},
+		Labels: map[string]string{
+			"kind/chore":                "type: chore",
+			labels.ReleaseNoteNoneLabel: "changelog: none",
+		},
+	}
+	tests := []struct {
+		name       string
+		body       string
+		current    []string
+		wantAdd    []string
+		wantRemove []string
+		wantErr    string
+	}{
+		{
+			name:    "custom kind with renamed labels",
+			body:    "/kind chore\n```release-note\nNONE\n```",
+			wantAdd: []string{"changelog: none", "type: chore"},
+		},
+		{
+			name:       "renamed labels are recognized",
+			body:       "/kind fix\n```release-note\nFixed a crash.\n```",
+			current:    []string{"changelog: none", "type: chore"},
+			wantAdd:    []string{"kind/fix", labels.ReleaseNoteLabel},
+			wantRemove: []string{"changelog: none", "type: chore"},
+		},
+		{
+			name:    "custom alias is migrated",
+			body:    "/kind bug\n```release-note\nFixed a crash.\n```",
+			wantAdd: []string{"kind/fix", labels.ReleaseNoteLabel},
+		},
+		{
+			name:    "compiled-in kind is not supported",
+			body:    "/kind feature\n```release-note\nNONE\n```",
+			wantAdd: []string{"changelog: none", labels.InvalidKindLabel},
+			wantErr: "supported kinds: chore, fix",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(nil, "owner", "repo", 1, false).WithRepoConfig(c).Simulate(tt.body, tt.current)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, append([]string{}, tt.wantAdd...)) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if !reflect.DeepEqual(d.LabelsToRemove, append([]string{}, tt.wantRemove...)) {
+				t.Errorf("labels to remove = %v, want %v", d.LabelsToRemove, tt.wantRemove)
+			}
+		})
+	}
+}
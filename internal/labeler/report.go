@@ -0,0 +1,395 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/diff"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// commentMarker identifies the labeler's sticky comment among the PR's comments.
+const commentMarker = "<!-- pr-kind-labeler -->"
+
+// CheckRunName is the name of the check run the labeler reports to.
+const CheckRunName = "pr-kind-labeler"
+
+// annotationPath is the file check run annotations are reported on. The
+// problems are with the PR description rather than a file, so they point at
+// the template authors fill it from; GitHub lists them on the check run even
+// where the PR does not change the file.
+const annotationPath = ".github/pull_request_template.md"
+
+// WithStickyComment tells PR authors what to fix in a single comment that is
+// edited on every run instead of a new comment per push. No comment is
+// created for a valid PR, but an existing one is updated once it passes. A
+// failed kind or release note comes with the supported kinds and a release
+// note block to copy into the PR description. The
+// comment also keeps the state later runs need, e.g. for TimeToGreen, and
+// enables maintainers to set the release note with a /release-note TEXT or
+// /release-note-none comment.
+func (l *labeler) WithStickyComment() *labeler {
+	l.stickyComment = true
+	return l
+}
+
+// WithCheckRun reports the result as a check run on the PR head commit, with
+// an annotation per problem, so branch protection can require it. headSHA may
+// be empty to look it up. If blocking, invalid PRs conclude as failure;
+// otherwise as neutral.
+func (l *labeler) WithCheckRun(headSHA string, blocking bool) *labeler {
+	l.checkRun = true
+	l.headSHA = headSHA
+	l.checkRunBlocking = blocking
+	return l
+}
+
+// Summary renders the validation result of the last evaluation as markdown.
+// Problems are redacted like the errors they come from.
+func (l *labeler) Summary() string {
+	var sb strings.Builder
+	switch {
+	case len(l.suspectedSpam) > 0:
+		fmt.Fprintf(&sb, "This PR was labeled `%s` for a maintainer to triage.\n", labels.SuspectedSpamLabel)
+	case len(l.problems) == 0:
+		sb.WriteString("All checks passed: the /kind, release note and description are valid.\n")
+		if s := l.state; !s.GreenAt.IsZero() {
+			fmt.Fprintf(&sb, "\nTime to green: %s after the first failed check.\n", formatDuration(s.GreenAt.Sub(s.FirstFailedAt)))
+		}
+	default:
+		sb.WriteString("Please update the PR description to fix the following:\n\n")
+		for _, err := range l.problems {
+			msg := (&ValidationError{Err: err}).Error()
+			sb.WriteString("- " + strings.ReplaceAll(msg, "\n", "\n  ") + "\n")
+		}
+		if msg := l.EscalationMessage(); msg != "" {
+			sb.WriteString("\n" + msg)
+		}
+	}
+	if l.quarantined {
+		fmt.Fprintf(&sb, "\nThe PR body is too large to validate in full, so only its head was checked and it was labeled `%s` for a maintainer to review.\n", labels.NeedsHumanReviewLabel)
+	}
+	if l.renameOnly && len(l.suspectedSpam) == 0 {
+		fmt.Fprintf(&sb, "\nThis PR only renames files, so it defaults to `/kind %s` and a release note of `NONE`.\n", renameOnlyKind)
+	}
+	if l.botAuthored && len(l.suspectedSpam) == 0 {
+		note := "`NONE`"
+		if l.releaseNote != nil {
+			note = "its title"
+		}
+		fmt.Fprintf(&sb, "\nThis PR was opened by a dependency update bot, so it defaults to `/kind %s` and a release note of %s.\n", l.botPolicy.kind(), note)
+	}
+	if len(l.docsKindCode) > 0 && len(l.suspectedSpam) == 0 {
+		fmt.Fprintf(&sb, "\nThis PR is `/kind %s` but changes Go code (`%s`); if it changes behavior, please set the kind that describes it, so its note lands in the right changelog section.\n", docsKind, strings.Join(l.docsKindCode, "`, `"))
+	}
+	if f := l.releaseNoteFence; f != "" && len(l.suspectedSpam) == 0 {
+		fmt.Fprintf(&sb, "\nThe release note is fenced as ```%s; please rename the fence to ```%s, which changelog tooling expects.\n", f, releaseNoteFence)
+	}
+	if len(l.deadLinks) > 0 && len(l.suspectedSpam) == 0 {
+		sb.WriteString("\nThe release note links to URLs that do not resolve; please fix them before they are published:\n")
+		for _, d := range l.deadLinks {
+			fmt.Fprintf(&sb, "- %s (%s)\n", d.URL, d.Reason)
+		}
+	}
+	if l.revertInherited && len(l.suspectedSpam) == 0 {
+		fmt.Fprintf(&sb, "\nThis PR is a revert, so it defaults to the kind of the PR it reverts: `/kind %s`.\n", strings.Join(sortedKeys(l.revertedKinds), "`, `/kind "))
+	}
+	if s := l.stack; s != nil && s.stacked && len(l.suspectedSpam) == 0 {
+		if l.stackInherited {
+			fmt.Fprintf(&sb, "\nThis PR is stacked on #%d, so it defaults to its kind: `/kind %s`.\n", s.number, strings.Join(sortedKeys(s.kinds), "`, `/kind "))
+		}
+		if n := l.releaseNote; n != nil && n.StackedOn != 0 {
+			fmt.Fprintf(&sb, "\nThe release note is inherited from #%d, which this PR is stacked on; the changelog lists it once for the stack.\n", n.StackedOn)
+		}
+	}
+	if len(l.ignoredCommenters) > 0 && len(l.suspectedSpam) == 0 {
+		fmt.Fprintf(&sb, "\nThe /kind commands of @%s were ignored, as only users who can write to the repository may set the kind in a comment.\n", strings.Join(l.ignoredCommenters, ", @"))
+	}
+	if r := l.Risk(); r != nil && len(l.suspectedSpam) == 0 {
+		fmt.Fprintf(&sb, "\nRelease risk: %s (score %d).\n", r.Level, r.Score)
+	}
+	if n := l.releaseNote; n != nil && n.Epic != 0 && len(l.suspectedSpam) == 0 {
+		fmt.Fprintf(&sb, "\nThe release note is deferred to the tracking issue #%d, whose ```%s block the changelog publishes once for all the PRs of the epic.\n", n.Epic, releaseNoteFence)
+	}
+	if o := l.releaseNoteOverride; o != nil && len(l.suspectedSpam) == 0 {
+		fmt.Fprintf(&sb, "\nRelease note set by @%s: `%s`\n", o.SetBy, o.Note)
+	}
+	if add, remove := l.repoLabels(l.labelsToAdd), l.repoLabels(l.labelsToRemove); len(add)+len(remove) > 0 {
+		sb.WriteString("\nLabels:")
+		for _, label := range add {
+			sb.WriteString(" +`" + label + "`")
+		}
+		for _, label := range remove {
+			sb.WriteString(" -`" + label + "`")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// comment returns the sticky comment body for the last evaluation, and
+// whether it should be posted given whether one exists already.
+func (l *labeler) comment(exists bool) (string, bool) {
+	if len(l.suspectedSpam) > 0 || (len(l.problems) == 0 && !exists && l.state.ReleaseNote == nil) {
+		return "", false
+	}
+	return commentMarker + "\n" + l.state.render() + l.Summary() + l.fixExamples(), true
+}
+
+// fixExamples returns, when the kind or release note failed validation, the
+// supported kinds and a release note block for the author to copy into the PR
+// description, or "" otherwise. Contributors new to the repository rarely know
+// either by heart.
+func (l *labeler) fixExamples() string {
+	var kind, note bool
+	for _, err := range l.problems {
+		var ve *ValidationError
+		if errors.As(err, &ve) {
+			kind = kind || ve.Check == CheckKind
+			note = note || ve.Check == CheckReleaseNote
+		}
+	}
+	var sb strings.Builder
+	if kind {
+		sb.WriteString("\nAdd the line of each kind that applies to the PR description:\n\n```\n")
+		for _, k := range sortedKeys(l.supportedKinds) {
+			sb.WriteString("/kind " + k + "\n")
+		}
+		sb.WriteString("```\n")
+	}
+	if note {
+		fmt.Fprintf(&sb, "\nAdd a release note for users to the PR description, or `NONE` if the change does not affect them:\n\n````\n```%s\nDescribe the change for users.\n```\n````\n", releaseNoteFence)
+	}
+	return sb.String()
+}
+
+// checkRunOutput returns the conclusion and output of the check run for the
+// last evaluation.
+func (l *labeler) checkRunOutput() (string, *github.CheckRunOutput) {
+	conclusion, title := "success", "PR description is valid"
+	switch {
+	case len(l.suspectedSpam) > 0:
+		conclusion, title = "neutral", "PR needs triage"
+	case len(l.problems) > 0:
+		conclusion = "neutral"
+		if l.checkRunBlocking {
+			conclusion = "failure"
+		}
+		title = fmt.Sprintf("%d problem(s) with the PR description", len(l.problems))
+	}
+	output := &github.CheckRunOutput{Title: github.Ptr(title), Summary: github.Ptr(l.Summary())}
+	if l.exportMetadata {
+		output.Text = github.Ptr("PR metadata:\n\n```json\n" + string(l.metadataJSON()) + "```\n")
+	}
+	if len(l.suspectedSpam) == 0 {
+		output.Annotations = l.annotations()
+	}
+	return conclusion, output
+}
+
+// annotations returns a check run annotation per problem of the last
+// evaluation, titled by the check it failed.
+func (l *labeler) annotations() []*github.CheckRunAnnotation {
+	level := "warning"
+	if l.checkRunBlocking {
+		level = "failure"
+	}
+	var annotations []*github.CheckRunAnnotation
+	for _, err := range l.problems {
+		title := "description"
+		var ve *ValidationError
+		if errors.As(err, &ve) && ve.Check != "" {
+			title = string(ve.Check)
+		}
+		annotations = append(annotations, &github.CheckRunAnnotation{
+			Path:            github.Ptr(annotationPath),
+			StartLine:       github.Ptr(1),
+			EndLine:         github.Ptr(1),
+			AnnotationLevel: github.Ptr(level),
+			Title:           github.Ptr(title),
+			Message:         github.Ptr((&ValidationError{Err: err}).Error()),
+		})
+	}
+	return annotations
+}
+
+// findComment returns the labeler's sticky comment, or nil if there is none.
+func (l *labeler) findComment(ctx context.Context) (*github.IssueComment, error) {
+	comments, err := l.listComments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stickyComment(comments), nil
+}
+
+// stickyComment returns the labeler's sticky comment among comments.
+func stickyComment(comments []*github.IssueComment) *github.IssueComment {
+	for _, c := range comments {
+		if strings.HasPrefix(c.GetBody(), commentMarker) {
+			return c
+		}
+	}
+	return nil
+}
+
+// listComments returns every comment on the PR, oldest first. They are
+// listed once per labeler.
+func (l *labeler) listComments(ctx context.Context) ([]*github.IssueComment, error) {
+	if l.comments != nil {
+		return l.comments, nil
+	}
+	all := []*github.IssueComment{}
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := l.client.Issues.ListComments(ctx, l.owner, l.repo, l.prNum, opts)
+		if err != nil {
+			return nil, apiError(err, permPullRequestsRead, "list comments")
+		}
+		all = append(all, comments...)
+		if resp.NextPage == 0 {
+			l.comments = all
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// commentChange returns the change to the sticky comment for the last
+// evaluation, if any. A new comment is only posted if mayPost.
+func (l *labeler) commentChange(mayPost bool) *CommentChange {
+	if !l.stickyComment {
+		return nil
+	}
+	existing := l.existingComment
+	body, ok := l.comment(existing != nil)
+	if !ok || (existing != nil && existing.GetBody() == body) || (existing == nil && !mayPost) {
+		return nil
+	}
+	return &CommentChange{ID: existing.GetID(), Body: body}
+}
+
+// syncComment posts or edits c, the comment named what in errors.
+func (l *labeler) syncComment(ctx context.Context, c *CommentChange, what string) error {
+	if c == nil {
+		return nil
+	}
+	if c.ID != 0 {
+		if _, _, err := l.client.Issues.EditComment(ctx, l.owner, l.repo, c.ID, &github.IssueComment{Body: github.Ptr(c.Body)}); err != nil {
+			return apiError(err, permPullRequestsWrite, "update %s", what)
+		}
+		return nil
+	}
+	if _, _, err := l.client.Issues.CreateComment(ctx, l.owner, l.repo, l.prNum, &github.IssueComment{Body: github.Ptr(c.Body)}); err != nil {
+		return apiError(err, permPullRequestsWrite, "create %s", what)
+	}
+	return nil
+}
+
+// resolveHeadSHA returns the PR head commit, looking it up if not set.
+func (l *labeler) resolveHeadSHA(ctx context.Context) (string, error) {
+	if l.headSHA != "" {
+		return l.headSHA, nil
+	}
+	pr, err := l.pullRequest(ctx)
+	if err != nil {
+		return "", err
+	}
+	l.headSHA = pr.GetHead().GetSHA()
+	return l.headSHA, nil
+}
+
+// syncCheckRun reports the check run of p as completed. Its external ID is
+// the Snapshot of the labels before p changed them, if it does.
+func (l *labeler) syncCheckRun(ctx context.Context, p *Plan) error {
+	if p.CheckRun == nil {
+		return nil
+	}
+	sha := p.CheckRun.HeadSHA
+	if sha == "" {
+		var err error
+		if sha, err = l.resolveHeadSHA(ctx); err != nil {
+			return err
+		}
+	}
+	_, _, err := l.client.Checks.CreateCheckRun(ctx, l.owner, l.repo, github.CreateCheckRunOptions{
+		Name:       CheckRunName,
+		HeadSHA:    sha,
+		Status:     github.Ptr("completed"),
+		Conclusion: github.Ptr(p.CheckRun.Conclusion),
+		ExternalID: p.Snapshot().externalID(),
+		Output:     p.CheckRun.Output,
+	})
+	if err != nil {
+		return apiError(err, permChecksWrite, "create check run")
+	}
+	return nil
+}
+
+// latestCheckRunSummary returns the summary of the labeler's latest check run
+// on the PR head commit, or "" if there is none.
+func (l *labeler) latestCheckRunSummary(ctx context.Context) (string, error) {
+	sha, err := l.resolveHeadSHA(ctx)
+	if err != nil {
+		return "", err
+	}
+	runs, _, err := l.client.Checks.ListCheckRunsForRef(ctx, l.owner, l.repo, sha, &github.ListCheckRunsOptions{
+		CheckName: github.Ptr(CheckRunName),
+		Filter:    github.Ptr("latest"),
+	})
+	if err != nil {
+		return "", apiError(err, permChecksRead, "list check runs")
+	}
+	if len(runs.CheckRuns) == 0 {
+		return "", nil
+	}
+	return runs.CheckRuns[0].GetOutput().GetSummary(), nil
+}
+
+// Preview renders, as unified diffs against what the PR has now, the labels,
+// sticky comment and check-run summary the last evaluation would publish.
+// It is meant for dry runs, so maintainers reviewing config or policy
+// changes see the exact messaging authors would get.
+func (l *labeler) Preview(ctx context.Context) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(l.LabelDiff())
+
+	existing := l.existingComment
+	if !l.stickyComment {
+		var err error
+		if existing, err = l.findComment(ctx); err != nil {
+			return "", err
+		}
+	}
+	before := existing.GetBody()
+	after, ok := l.comment(existing != nil)
+	if !ok {
+		after = before
+	}
+	sb.WriteString(diff.Unified("comment (current)", "comment (after)", before, after))
+
+	before, err := l.latestCheckRunSummary(ctx)
+	if err != nil {
+		return "", err
+	}
+	_, output := l.checkRunOutput()
+	sb.WriteString(diff.Unified("check run summary (current)", "check run summary (after)", before, output.GetSummary()))
+	return sb.String(), nil
+}
+
+// LabelDiff renders the PR's labels before and after the last evaluation's
+// label changes as a unified diff, or returns "" if it changes none.
+func (l *labeler) LabelDiff() string {
+	d := l.Decision()
+	return diff.Unified("labels (current)", "labels (after)", lines(d.CurrentLabels), lines(d.FinalLabels()))
+}
+
+func lines(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return strings.Join(items, "\n") + "\n"
+}
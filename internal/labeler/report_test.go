@@ -0,0 +1,230 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+const validBody = "/kind fix\n```release-note\nFixed a crash.\n```"
+
+func TestProcessPR_StickyComment(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		existing   []*github.IssueComment
+		wantCreate bool
+		wantEdit   string
+	}{
+		{
+			name:       "invalid PR without a comment",
+			body:       "no kind here",
+			wantCreate: true,
+		},
+		{
+			name:     "invalid PR with an outdated comment",
+			body:     "no kind here",
+			existing: []*github.IssueComment{{ID: github.Ptr(int64(7)), Body: github.Ptr(commentMarker + "\nold")}},
+			wantEdit: "Please update the PR description",
+		},
+		{
+			name:     "valid PR without a comment",
+			body:     validBody,
+			existing: []*github.IssueComment{{ID: github.Ptr(int64(3)), Body: github.Ptr("LGTM")}},
+		},
+		{
+			name:     "valid PR with a comment",
+			body:     validBody,
+			existing: []*github.IssueComment{{ID: github.Ptr(int64(7)), Body: github.Ptr(commentMarker + "\nold")}},
+			wantEdit: "All checks passed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created, edited string
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.GetReposIssuesCommentsByOwnerByRepoByIssueNumber, tt.existing),
+				mock.WithRequestMatchHandler(
+					mock.PostReposIssuesCommentsByOwnerByRepoByIssueNumber,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						var c github.IssueComment
+						json.NewDecoder(r.Body).Decode(&c)
+						created = c.GetBody()
+						w.Write(mock.MustMarshal(c))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PatchReposIssuesCommentsByOwnerByRepoByCommentId,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if !strings.HasSuffix(r.URL.Path, "/7") {
+							t.Errorf("edited the wrong comment: %s", r.URL.Path)
+						}
+						var c github.IssueComment
+						json.NewDecoder(r.Body).Decode(&c)
+						edited = c.GetBody()
+						w.Write(mock.MustMarshal(c))
+					}),
+				),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithStickyComment()
+			l.ProcessPR(context.Background(), tt.body, true)
+			if tt.wantCreate != (created != "") {
+				t.Fatalf("created comment %q, want created = %v", created, tt.wantCreate)
+			}
+			if created != "" && !strings.HasPrefix(created, commentMarker) {
+				t.Fatalf("created comment lacks the marker: %q", created)
+			}
+			if tt.wantEdit == "" && edited != "" {
+				t.Fatalf("unexpected edit %q", edited)
+			}
+			if tt.wantEdit != "" && !strings.Contains(edited, tt.wantEdit) {
+				t.Fatalf("edited comment to %q, want it to contain %q", edited, tt.wantEdit)
+			}
+		})
+	}
+}
+
+func TestComment_FixExamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		want     []string
+		dontWant []string
+	}{
+		{
+			name: "missing kind and note",
+			body: "no kind here",
+			want: []string{"\n/kind feature\n", "\n/kind fix\n", "````\n```release-note\n"},
+		},
+		{
+			name:     "missing note",
+			body:     "/kind fix",
+			want:     []string{"````\n```release-note\n"},
+			dontWant: []string{"\n/kind feature\n"},
+		},
+		{
+			name:     "valid",
+			body:     validBody,
+			dontWant: []string{"/kind fix", "````"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false).WithStickyComment()
+			l.Simulate(tt.body, nil)
+			got, _ := l.comment(true)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("comment lacks %q:\n%s", want, got)
+				}
+			}
+			for _, dontWant := range tt.dontWant {
+				if strings.Contains(got, dontWant) {
+					t.Errorf("comment has %q:\n%s", dontWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessPR_CheckRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		blocking       bool
+		wantConclusion string
+		// wantAnnotations are the titles and levels of the annotations.
+		wantAnnotations []string
+	}{
+		{name: "valid", body: validBody, blocking: true, wantConclusion: "success"},
+		{
+			name: "invalid and blocking", body: "no kind here", blocking: true, wantConclusion: "failure",
+			wantAnnotations: []string{"kind failure", "release-note failure"},
+		},
+		{
+			name: "invalid and not blocking", body: "/kind fix", wantConclusion: "neutral",
+			wantAnnotations: []string{"release-note warning"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got github.CreateCheckRunOptions
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatchHandler(
+					mock.PostReposCheckRunsByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						json.NewDecoder(r.Body).Decode(&got)
+						w.Write(mock.MustMarshal(github.CheckRun{}))
+					}),
+				),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithCheckRun("abc123", tt.blocking)
+			l.ProcessPR(context.Background(), tt.body, true)
+			if got.HeadSHA != "abc123" || got.Name != CheckRunName {
+				t.Fatalf("check run created for %q on %q", got.Name, got.HeadSHA)
+			}
+			if got.GetConclusion() != tt.wantConclusion {
+				t.Fatalf("conclusion = %q, want %q", got.GetConclusion(), tt.wantConclusion)
+			}
+			var annotations []string
+			for _, a := range got.GetOutput().Annotations {
+				if a.GetPath() != annotationPath || a.GetMessage() == "" {
+					t.Errorf("annotation %q on %q", a.GetMessage(), a.GetPath())
+				}
+				annotations = append(annotations, a.GetTitle()+" "+a.GetAnnotationLevel())
+			}
+			if !reflect.DeepEqual(annotations, tt.wantAnnotations) {
+				t.Errorf("annotations = %v, want %v", annotations, tt.wantAnnotations)
+			}
+		})
+	}
+}
+
+func TestPreview(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{{Name: github.Ptr("kind/fix")}}),
+		mock.WithRequestMatch(mock.GetReposIssuesCommentsByOwnerByRepoByIssueNumber, []*github.IssueComment{}),
+		mock.WithRequestMatch(
+			mock.GetReposPullsByOwnerByRepoByPullNumber,
+			github.PullRequest{Head: &github.PullRequestBranch{SHA: github.Ptr("abc123")}},
+		),
+		mock.WithRequestMatch(
+			mock.GetReposCommitsCheckRunsByOwnerByRepoByRef,
+			github.ListCheckRunsResults{CheckRuns: []*github.CheckRun{{Output: &github.CheckRunOutput{Summary: github.Ptr("All checks passed: the /kind, release note and description are valid.\n")}}}},
+		),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false)
+	if _, err := l.ProcessPR(context.Background(), "/kind fix", false); err == nil {
+		t.Fatal("expected a validation error")
+	}
+	preview, err := l.Preview(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := l.LabelDiff(), "--- labels (current)\n+++ labels (after)\n@@ -1,1 +1,2 @@\n+do-not-merge/release-note-invalid\n kind/fix\n"; got != want {
+		t.Errorf("LabelDiff() =\n%s\nwant:\n%s", got, want)
+	}
+	for _, want := range []string{
+		"--- labels (current)\n+++ labels (after)\n",
+		"+do-not-merge/release-note-invalid\n",
+		"--- comment (current)\n+++ comment (after)\n@@ -0,0 ",
+		"+" + commentMarker + "\n",
+		"--- check run summary (current)\n+++ check run summary (after)\n",
+		"-All checks passed",
+		"+Please update the PR description to fix the following:\n",
+	} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("preview lacks %q:\n%s", want, preview)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Reporter names, as selected in config. The comment, check run and commit
+// status are reported by the labeler itself, since later runs read them back,
+// and are planned so previews and undo see them; the other reporters are
+// Reporter implementations that receive the run's Report.
+const (
+	ReporterComment  = "comment"
+	ReporterCheckRun = "check-run"
+	ReporterStatus   = "status"
+	ReporterSummary  = "summary"
+	ReporterSlack    = "slack"
+)
+
+// ReporterNames are the reporters that can be selected, in the order they are
+// documented.
+var ReporterNames = []string{ReporterComment, ReporterCheckRun, ReporterStatus, ReporterSummary, ReporterSlack}
+
+// Report is the outcome of a run, in the form every reporter consumes.
+type Report struct {
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	// Valid is set when the PR passed validation.
+	Valid bool `json:"valid"`
+	// Conclusion and Title are those of the check run: success, neutral or
+	// failure, and a one-line description.
+	Conclusion string `json:"conclusion"`
+	Title      string `json:"title"`
+	// Summary is the validation result as markdown, as in the sticky
+	// comment, and Problems are the validation failures.
+	Summary  string   `json:"summary"`
+	Problems []string `json:"problems,omitempty"`
+	// LabelsAdded and LabelsRemoved are the run's label changes.
+	LabelsAdded   []string `json:"labelsAdded,omitempty"`
+	LabelsRemoved []string `json:"labelsRemoved,omitempty"`
+}
+
+// PR returns the PR as owner/repo#number.
+func (r *Report) PR() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+// Reporter publishes the reports of runs, e.g. to a chat channel.
+type Reporter interface {
+	// Name identifies the reporter in errors.
+	Name() string
+	Report(ctx context.Context, r *Report) error
+}
+
+// WithReporters publishes the Report of every run that applies its plan to
+// reporters, besides the comment, check run and commit status the labeler
+// reports itself.
+func (l *labeler) WithReporters(reporters ...Reporter) *labeler {
+	l.reporters = append(l.reporters, reporters...)
+	return l
+}
+
+// Report returns the report of the last evaluation.
+func (l *labeler) Report() *Report {
+	conclusion, output := l.checkRunOutput()
+	r := &Report{
+		Owner:         l.owner,
+		Repo:          l.repo,
+		Number:        l.prNum,
+		Valid:         len(l.problems) == 0,
+		Conclusion:    conclusion,
+		Title:         output.GetTitle(),
+		Summary:       output.GetSummary(),
+		LabelsAdded:   l.repoLabels(l.labelsToAdd),
+		LabelsRemoved: l.repoLabels(l.labelsToRemove),
+	}
+	for _, err := range l.problems {
+		r.Problems = append(r.Problems, (&ValidationError{Err: err}).Error())
+	}
+	return r
+}
+
+// syncReporters publishes the report of p to every reporter.
+func (l *labeler) syncReporters(ctx context.Context, p *Plan) []error {
+	if p.Report == nil {
+		return nil
+	}
+	var errs []error
+	for _, r := range l.reporters {
+		if err := r.Report(ctx, p.Report); err != nil {
+			errs = append(errs, &OperationalError{Err: fmt.Errorf("failed to report to %s: %w", r.Name(), err)})
+		}
+	}
+	return errs
+}
+
+// Deduplicate wraps r so it is not sent the same report for a PR twice in a
+// row, e.g. when a PR is edited without changing the outcome. With interval
+// set, a PR is also reported at most once per interval unless its validity
+// changes, so PRs whose authors iterate on them do not flood the channel.
+// Reports are remembered in memory, so reporters shared across the runs of a
+// server are deduplicated across them.
+func Deduplicate(r Reporter, interval time.Duration) Reporter {
+	return &dedupReporter{next: r, interval: interval, now: time.Now, last: map[string]reported{}}
+}
+
+type dedupReporter struct {
+	next     Reporter
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	last map[string]reported
+}
+
+// reported is the last report sent for a PR and when.
+type reported struct {
+	report *Report
+	at     time.Time
+}
+
+// Name implements Reporter.
+func (d *dedupReporter) Name() string {
+	return d.next.Name()
+}
+
+// Report implements Reporter.
+func (d *dedupReporter) Report(ctx context.Context, r *Report) error {
+	d.mu.Lock()
+	last, ok := d.last[r.PR()]
+	now := d.now()
+	if ok && (sameReport(last.report, r) || (r.Valid == last.report.Valid && now.Sub(last.at) < d.interval)) {
+		d.mu.Unlock()
+		return nil
+	}
+	d.last[r.PR()] = reported{report: r, at: now}
+	d.mu.Unlock()
+	if err := d.next.Report(ctx, r); err != nil {
+		// a failed report is retried by the next run
+		d.mu.Lock()
+		if ok {
+			d.last[r.PR()] = last
+		} else {
+			delete(d.last, r.PR())
+		}
+		d.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// sameReport reports whether a and b report the same outcome. Label changes
+// are left out, as a run following one that changed labels changes none.
+func sameReport(a, b *Report) bool {
+	return a.Conclusion == b.Conclusion && a.Title == b.Title && slices.Equal(a.Problems, b.Problems)
+}
@@ -0,0 +1,98 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+type fakeReporter struct {
+	err     error
+	reports []*Report
+}
+
+func (r *fakeReporter) Name() string { return "fake" }
+
+func (r *fakeReporter) Report(ctx context.Context, report *Report) error {
+	r.reports = append(r.reports, report)
+	return r.err
+}
+
+func TestProcessPR_Reporters(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}, []*github.Label{}, []*github.Label{}),
+		mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}, []*github.Label{}),
+	)
+	reporter := &fakeReporter{}
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithReporters(reporter)
+	if _, err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nNONE\n```", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reporter.reports) != 0 {
+		t.Fatalf("reported %d times without applying the plan, want 0", len(reporter.reports))
+	}
+
+	_, err := l.ProcessPR(context.Background(), "/kind fix", true)
+	if len(reporter.reports) != 1 {
+		t.Fatalf("reported %d times, want 1", len(reporter.reports))
+	}
+	r := reporter.reports[0]
+	if r.PR() != "owner/repo#1" || r.Valid || len(r.Problems) == 0 || r.Conclusion != "neutral" {
+		t.Errorf("unexpected report %+v", r)
+	}
+	if len(r.LabelsAdded) == 0 {
+		t.Errorf("report has no label changes")
+	}
+	if _, operational := Partition(err); len(operational) != 0 {
+		t.Fatalf("unexpected operational errors: %v", operational)
+	}
+
+	reporter.err = errors.New("boom")
+	_, err = l.ProcessPR(context.Background(), "/kind fix", true)
+	if _, operational := Partition(err); len(operational) != 1 {
+		t.Fatalf("operational errors = %v, want the failed report", operational)
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := &fakeReporter{}
+	d := Deduplicate(next, time.Hour).(*dedupReporter)
+	d.now = func() time.Time { return now }
+	report := func(number int, problems ...string) *Report {
+		return &Report{Owner: "owner", Repo: "repo", Number: number, Valid: len(problems) == 0, Problems: problems}
+	}
+	ctx := context.Background()
+	steps := []struct {
+		name    string
+		after   time.Duration
+		report  *Report
+		err     error
+		wantNew bool
+	}{
+		{name: "first report", report: report(1, "no kind"), wantNew: true},
+		{name: "same report", report: report(1, "no kind")},
+		{name: "other PR", report: report(2, "no kind"), wantNew: true},
+		{name: "new problem within the interval", report: report(1, "no release note")},
+		{name: "passing within the interval", report: report(1), wantNew: true},
+		{name: "failing again within the interval", report: report(1, "no kind"), wantNew: true},
+		{name: "failed report", after: 2 * time.Hour, report: report(1, "no release note"), err: errors.New("boom"), wantNew: true},
+		{name: "retried after failing", report: report(1, "no release note"), wantNew: true},
+	}
+	for _, step := range steps {
+		now = now.Add(step.after)
+		next.err = step.err
+		before := len(next.reports)
+		err := d.Report(ctx, step.report)
+		if (err != nil) != (step.err != nil) {
+			t.Fatalf("%s: error = %v, want %v", step.name, err, step.err)
+		}
+		if gotNew := len(next.reports) > before; gotNew != step.wantNew {
+			t.Fatalf("%s: reported %v, want %v", step.name, gotNew, step.wantNew)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package labeler
+
+import "errors"
+
+// Result is what a ProcessPR run found and did, for library consumers and the
+// CLI to act on without matching error messages.
+type Result struct {
+	// Valid is set when the PR passed validation.
+	Valid bool `json:"valid"`
+	// Kinds are the kinds found in the PR body or inherited, sorted.
+	Kinds []string `json:"kinds"`
+	// ReleaseNote is the PR's release note, if it has a valid one.
+	ReleaseNote *ReleaseNote `json:"releaseNote,omitempty"`
+	// LabelsAdded and LabelsRemoved are the run's label changes, made if
+	// Applied is set and only planned otherwise.
+	LabelsAdded   []string `json:"labelsAdded"`
+	LabelsRemoved []string `json:"labelsRemoved"`
+	Applied       bool     `json:"applied"`
+	// Problems are the validation failures.
+	Problems []Problem `json:"problems,omitempty"`
+}
+
+// Problem is a validation failure and the check it failed.
+type Problem struct {
+	Check   Check  `json:"check"`
+	Message string `json:"message"`
+}
+
+// result returns the result of the last evaluation.
+func (l *labeler) result(applied bool) *Result {
+	r := &Result{
+		Valid:         len(l.problems) == 0,
+		Kinds:         l.Kinds(),
+		ReleaseNote:   l.releaseNote,
+		LabelsAdded:   l.repoLabels(l.labelsToAdd),
+		LabelsRemoved: l.repoLabels(l.labelsToRemove),
+		Applied:       applied,
+	}
+	for _, err := range l.problems {
+		p := Problem{Check: CheckDescription, Message: (&ValidationError{Err: err}).Error()}
+		var ve *ValidationError
+		if errors.As(err, &ve) && ve.Check != "" {
+			p.Check = ve.Check
+		}
+		r.Problems = append(r.Problems, p)
+	}
+	return r
+}
@@ -0,0 +1,72 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestProcessPR_Result(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		sync         bool
+		want         *Result
+		wantProblems []Check
+	}{
+		{
+			name: "valid",
+			body: "# Description\nFix.\n/kind fix\n```release-note\nFixed the frobnicator.\n```",
+			sync: true,
+			want: &Result{
+				Valid:         true,
+				Kinds:         []string{"fix"},
+				ReleaseNote:   &ReleaseNote{Note: "Fixed the frobnicator.", Section: "fix"},
+				LabelsAdded:   []string{"kind/fix", labels.ReleaseNoteLabel},
+				LabelsRemoved: []string{},
+				Applied:       true,
+			},
+		},
+		{
+			name: "invalid",
+			body: "nothing",
+			want: &Result{
+				Kinds:         []string{},
+				LabelsAdded:   []string{labels.InvalidDescriptionLabel, labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel},
+				LabelsRemoved: []string{},
+			},
+			wantProblems: []Check{CheckKind, CheckReleaseNote, CheckDescription},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+			)
+			r, _ := New(github.NewClient(httpClient), "owner", "repo", 1, true).ProcessPR(context.Background(), tt.body, tt.sync)
+			if r == nil {
+				t.Fatal("expected a result")
+			}
+			var got []Check
+			for _, p := range r.Problems {
+				if p.Message == "" {
+					t.Errorf("problem %q has no message", p.Check)
+				}
+				got = append(got, p.Check)
+			}
+			if !reflect.DeepEqual(got, tt.wantProblems) {
+				t.Errorf("problems = %q, want %q", got, tt.wantProblems)
+			}
+			r.Problems = nil
+			if !reflect.DeepEqual(r, tt.want) {
+				t.Errorf("result = %+v, want %+v", r, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package labeler
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+var (
+	// revertTitleRE matches the title git and GitHub give revert PRs.
+	revertTitleRE = regexp.MustCompile(`^Revert ".*"$`)
+	// revertCommitRE captures the reverted commit from the message git revert
+	// writes.
+	revertCommitRE = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-f]{7,40})\b`)
+	// revertsPRRE captures the reverted PR from the body of the PRs GitHub's
+	// revert button opens, e.g. "Reverts kgateway-dev/kgateway#123".
+	revertsPRRE = regexp.MustCompile(`(?m)^Reverts (?:([\w.-]+)/([\w.-]+))?#(\d+)`)
+)
+
+// WithRevertDetection recognizes revert PRs, by a `Revert "..."` title or a
+// body saying "This reverts commit SHA" or "Reverts owner/repo#N", and labels
+// them revert. A revert PR whose body sets no kind inherits the kinds of the
+// PR it reverts, so the changelog lists it next to what it undoes.
+func (l *labeler) WithRevertDetection() *labeler {
+	l.detectReverts = true
+	return l
+}
+
+// Revert reports whether the PR reverts another one, and the number of the
+// reverted PR in the same repository, or 0 if it could not be found.
+func (l *labeler) Revert() (bool, int) {
+	return l.revert, l.revertedPR
+}
+
+// fetchRevert decides whether the PR is a revert and, if so, looks up the
+// kinds of the PR it reverts.
+func (l *labeler) fetchRevert(ctx context.Context, body string) error {
+	if !l.detectReverts {
+		return nil
+	}
+	pr, err := l.pullRequest(ctx)
+	if err != nil {
+		return err
+	}
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	prMatch := revertsPRRE.FindStringSubmatch(body)
+	commitMatch := revertCommitRE.FindStringSubmatch(body)
+	l.revert = revertTitleRE.MatchString(pr.GetTitle()) || prMatch != nil || commitMatch != nil
+	l.revertedPR, l.revertedKinds = 0, nil
+	switch {
+	case prMatch != nil:
+		owner, repo := l.owner, l.repo
+		if prMatch[1] != "" {
+			owner, repo = prMatch[1], prMatch[2]
+		}
+		number, _ := strconv.Atoi(prMatch[3])
+		reverted, _, err := l.client.PullRequests.Get(ctx, owner, repo, number)
+		if err != nil {
+			return apiError(err, permPullRequestsRead, "get reverted PR %s/%s#%d", owner, repo, number)
+		}
+		if owner == l.owner && repo == l.repo {
+			l.revertedPR = number
+		}
+		l.revertedKinds = kindsOf(reverted.Labels)
+	case commitMatch != nil:
+		prs, _, err := l.client.PullRequests.ListPullRequestsWithCommit(ctx, l.owner, l.repo, commitMatch[1], nil)
+		if err != nil {
+			return apiError(err, permPullRequestsRead, "list PRs of reverted commit %s", commitMatch[1])
+		}
+		for _, reverted := range prs {
+			if reverted.GetNumber() != l.prNum && reverted.MergedAt != nil {
+				l.revertedPR = reverted.GetNumber()
+				l.revertedKinds = kindsOf(reverted.Labels)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// kindsOf returns the kinds of the kind/ labels among prLabels.
+func kindsOf(prLabels []*github.Label) map[string]bool {
+	found := map[string]bool{}
+	for _, label := range prLabels {
+		if kind, ok := strings.CutPrefix(label.GetName(), "kind/"); ok {
+			found[kind] = true
+		}
+	}
+	return found
+}
+
+// processRevertLabel labels revert PRs, and unlabels PRs that no longer look
+// like one, e.g. after their title was changed.
+func (l *labeler) processRevertLabel() {
+	if !l.detectReverts {
+		return
+	}
+	switch {
+	case l.revert && !l.currentMap[labels.RevertLabel]:
+		l.labelsToAdd[labels.RevertLabel] = true
+	case !l.revert && l.currentMap[labels.RevertLabel]:
+		l.labelsToRemove[labels.RevertLabel] = true
+	}
+}
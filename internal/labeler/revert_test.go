@@ -0,0 +1,103 @@
+package labeler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestDecide_RevertDetection(t *testing.T) {
+	reverted := &github.PullRequest{
+		Number:   github.Ptr(5),
+		MergedAt: &github.Timestamp{Time: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)},
+		Labels:   []*github.Label{{Name: github.Ptr("kind/feature")}, {Name: github.Ptr(labels.ReleaseNoteLabel)}},
+	}
+	note := "\n```release-note\nRevert the frobnicator.\n```"
+	tests := []struct {
+		name          string
+		title         string
+		body          string
+		currentLabels []string
+		wantRevert    bool
+		wantPR        int
+		wantAdd       []string
+		wantRemove    []string
+	}{
+		{
+			name:       "revert button PR inherits the kind",
+			title:      `Revert "Add the frobnicator"`,
+			body:       "Reverts owner/repo#5" + note,
+			wantRevert: true,
+			wantPR:     5,
+			wantAdd:    []string{"kind/feature", labels.ReleaseNoteLabel, labels.RevertLabel},
+		},
+		{
+			name:       "git revert message looks up the PR of the commit",
+			title:      "Back out the frobnicator",
+			body:       "This reverts commit 0123456789abcdef0123456789abcdef01234567." + note,
+			wantRevert: true,
+			wantPR:     5,
+			wantAdd:    []string{"kind/feature", labels.ReleaseNoteLabel, labels.RevertLabel},
+		},
+		{
+			name:       "explicit kind wins",
+			title:      `Revert "Add the frobnicator"`,
+			body:       "Reverts #5\n/kind fix" + note,
+			wantRevert: true,
+			wantPR:     5,
+			wantAdd:    []string{"kind/fix", labels.ReleaseNoteLabel, labels.RevertLabel},
+		},
+		{
+			name:          "no longer a revert",
+			title:         "Add the frobnicator again",
+			body:          "/kind feature" + note,
+			currentLabels: []string{labels.RevertLabel},
+			wantAdd:       []string{"kind/feature", labels.ReleaseNoteLabel},
+			wantRemove:    []string{labels.RevertLabel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current []*github.Label
+			for _, name := range tt.currentLabels {
+				current = append(current, &github.Label{Name: github.Ptr(name)})
+			}
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, current),
+				mock.WithRequestMatchHandler(
+					mock.GetReposPullsByOwnerByRepoByPullNumber,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if strings.HasSuffix(r.URL.Path, "/pulls/5") {
+							w.Write(mock.MustMarshal(reverted))
+							return
+						}
+						w.Write(mock.MustMarshal(&github.PullRequest{Number: github.Ptr(9), Title: github.Ptr(tt.title)}))
+					}),
+				),
+				mock.WithRequestMatch(mock.GetReposCommitsPullsByOwnerByRepoByCommitSha, []*github.PullRequest{reverted}),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 9, false).WithRevertDetection()
+			d, err := l.Decide(context.Background(), tt.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if revert, pr := l.Revert(); revert != tt.wantRevert || pr != tt.wantPR {
+				t.Errorf("Revert() = %v, %d, want %v, %d", revert, pr, tt.wantRevert, tt.wantPR)
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Errorf("LabelsToAdd = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if len(d.LabelsToRemove)+len(tt.wantRemove) > 0 && !reflect.DeepEqual(d.LabelsToRemove, tt.wantRemove) {
+				t.Errorf("LabelsToRemove = %v, want %v", d.LabelsToRemove, tt.wantRemove)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package labeler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// WithReviewCommands also takes /kind and /release-note commands from the
+// summaries of submitted PR reviews, as maintainers often give them while
+// reviewing. Only reviews by maintainers, users who can write to the
+// repository, count, and dismissed reviews are
+// ignored. Kinds from reviews add to those of the PR body, and a
+// /release-note command in a review competes with those in comments, the
+// latest winning; it needs the sticky comment, like the comment commands.
+func (l *labeler) WithReviewCommands() *labeler {
+	l.reviewCommands = true
+	return l
+}
+
+// HasCommand reports whether body, e.g. of a comment or review, gives a /kind
+// or /release-note command, so events without one can be skipped.
+func HasCommand(body string) bool {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	if _, ok := ParseReleaseNoteCommand(body); ok {
+		return true
+	}
+	return len(scanKinds(body)) > 0
+}
+
+// listReviews returns the PR's reviews, listing them on first use.
+func (l *labeler) listReviews(ctx context.Context) ([]*github.PullRequestReview, error) {
+	if l.reviews != nil {
+		return l.reviews, nil
+	}
+	all := []*github.PullRequestReview{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := l.client.PullRequests.ListReviews(ctx, l.owner, l.repo, l.prNum, opts)
+		if err != nil {
+			return nil, apiError(err, permPullRequestsRead, "list reviews")
+		}
+		all = append(all, reviews...)
+		if resp.NextPage == 0 {
+			l.reviews = all
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// maintainerReviews returns the submitted, undismissed reviews giving a
+// command by maintainers among reviews, checking the permission of their
+// authors.
+func (l *labeler) maintainerReviews(ctx context.Context, reviews []*github.PullRequestReview) ([]*github.PullRequestReview, error) {
+	var found []*github.PullRequestReview
+	for _, r := range reviews {
+		switch strings.ToUpper(r.GetState()) {
+		case "DISMISSED", "PENDING":
+			continue
+		}
+		body := strings.ReplaceAll(r.GetBody(), "\r\n", "\n")
+		if _, ok := ParseReleaseNoteCommand(body); !ok && len(scanKinds(l.normalizeKindCommands(body))) == 0 {
+			continue
+		}
+		ok, err := l.canWrite(ctx, r.GetUser().GetLogin())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			found = append(found, r)
+		}
+	}
+	return found, nil
+}
+
+// fetchReviewCommands looks up the review summaries whose /kind commands add
+// to the PR body's.
+func (l *labeler) fetchReviewCommands(ctx context.Context) error {
+	if !l.reviewCommands {
+		return nil
+	}
+	reviews, err := l.listReviews(ctx)
+	if err != nil {
+		return err
+	}
+	reviews, err = l.maintainerReviews(ctx, reviews)
+	if err != nil {
+		return err
+	}
+	l.reviewBodies = nil
+	for _, r := range reviews {
+		if r.GetBody() != "" {
+			l.reviewBodies = append(l.reviewBodies, strings.ReplaceAll(r.GetBody(), "\r\n", "\n"))
+		}
+	}
+	return nil
+}
+
+// reviewReleaseNoteCommand returns the latest release note command given in
+// a maintainer's review among reviews, or nil if there is none newer than
+// prev.
+func (l *labeler) reviewReleaseNoteCommand(ctx context.Context, reviews []*github.PullRequestReview, prev *stateReleaseNote) (*stateReleaseNote, error) {
+	reviews, err := l.maintainerReviews(ctx, reviews)
+	if err != nil {
+		return nil, err
+	}
+	var latest *stateReleaseNote
+	for _, r := range reviews {
+		note, ok := ParseReleaseNoteCommand(r.GetBody())
+		if !ok {
+			continue
+		}
+		at := r.GetSubmittedAt().Time
+		if prev != nil && (r.GetID() == prev.ReviewID || !at.After(prev.SetAt)) {
+			continue
+		}
+		if latest == nil || at.After(latest.SetAt) {
+			latest = &stateReleaseNote{Note: note, SetBy: r.GetUser().GetLogin(), ReviewID: r.GetID(), SetAt: at}
+		}
+	}
+	return latest, nil
+}
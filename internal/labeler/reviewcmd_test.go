@@ -0,0 +1,112 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func review(id int64, login, association, state, body string, at time.Time) *github.PullRequestReview {
+	return &github.PullRequestReview{
+		ID:                github.Ptr(id),
+		User:              &github.User{Login: github.Ptr(login)},
+		AuthorAssociation: github.Ptr(association),
+		State:             github.Ptr(state),
+		Body:              github.Ptr(body),
+		SubmittedAt:       &github.Timestamp{Time: at},
+	}
+}
+
+func TestHasCommand(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{body: "LGTM\n/kind fix", want: true},
+		{body: "/release-note-none", want: true},
+		{body: "LGTM, thanks!"},
+		{body: "please add a /kind command"},
+	}
+	for _, tt := range tests {
+		if got := HasCommand(tt.body); got != tt.want {
+			t.Errorf("HasCommand(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestDecide_ReviewCommands(t *testing.T) {
+	at := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	reviews := []*github.PullRequestReview{
+		review(1, "alice", "MEMBER", "APPROVED", "LGTM\r\n/kind cleanup", at),
+		review(2, "mallory", "CONTRIBUTOR", "COMMENTED", "/kind breaking_change", at),
+		review(3, "bob", "OWNER", "DISMISSED", "/kind feature", at),
+		review(4, "carol", "COLLABORATOR", "CHANGES_REQUESTED", "/kind bug_fix", at),
+	}
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+		mock.WithRequestMatch(mock.GetReposPullsReviewsByOwnerByRepoByPullNumber, reviews),
+		permissionLevels(map[string]string{"alice": "write", "mallory": "read", "carol": "write"}),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithReviewCommands()
+	d, err := l.Decide(context.Background(), "/kind fix\n```release-note\nFixed a crash.\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"kind/cleanup", "kind/fix", labels.ReleaseNoteLabel}
+	if !reflect.DeepEqual(d.LabelsToAdd, want) {
+		t.Fatalf("labels to add = %v, want %v", d.LabelsToAdd, want)
+	}
+	if !reflect.DeepEqual(d.DeprecatedKinds, []string{"bug_fix"}) {
+		t.Fatalf("deprecated kinds = %v, want [bug_fix]", d.DeprecatedKinds)
+	}
+}
+
+func TestReviewReleaseNoteCommand(t *testing.T) {
+	t0 := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	reviews := []*github.PullRequestReview{
+		review(1, "alice", "MEMBER", "APPROVED", "/release-note Fixed a crash.", t0),
+		review(2, "carol", "OWNER", "COMMENTED", "/release-note-none", t0.Add(time.Hour)),
+		review(3, "mallory", "NONE", "COMMENTED", "/release-note Mine.", t0.Add(2*time.Hour)),
+		// the author association does not grant write access
+		review(4, "dave", "MEMBER", "COMMENTED", "/release-note Also mine.", t0.Add(3*time.Hour)),
+	}
+	httpClient := mock.NewMockedHTTPClient(
+		permissionLevels(map[string]string{"alice": "write", "carol": "admin", "mallory": "read", "dave": "read"}),
+	)
+	tests := []struct {
+		name string
+		prev *stateReleaseNote
+		want *stateReleaseNote
+	}{
+		{
+			name: "latest maintainer review wins",
+			want: &stateReleaseNote{Note: "NONE", SetBy: "carol", ReviewID: 2, SetAt: t0.Add(time.Hour)},
+		},
+		{
+			name: "recorded review is not taken again",
+			prev: &stateReleaseNote{Note: "NONE", SetBy: "carol", ReviewID: 2, SetAt: t0.Add(time.Hour)},
+		},
+		{
+			name: "newer comment command wins",
+			prev: &stateReleaseNote{Note: "Other.", SetBy: "bob", CommentID: 9, SetAt: t0.Add(90 * time.Minute)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false)
+			got, err := l.reviewReleaseNoteCommand(context.Background(), reviews, tt.prev)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("reviewReleaseNoteCommand() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
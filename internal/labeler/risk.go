@@ -0,0 +1,112 @@
+package labeler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// Risk levels, from the risk/ label applied for each.
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// DefaultKindWeights are the risk weights of the kinds, used for kinds
+// WithRiskScoring is given no weight for. Unlisted kinds weigh 0.
+var DefaultKindWeights = map[string]int{
+	kinds.BreakingChange: 3,
+	kinds.Feature:        2,
+	kinds.Deprecation:    2,
+	kinds.Install:        2,
+	kinds.Fix:            1,
+	kinds.Bump:           1,
+	kinds.Cleanup:        1,
+}
+
+// sizeRisk is the risk added per size bucket of the changed lines.
+var sizeRisk = map[string]int{"M": 1, "L": 2, "XL": 3, "XXL": 4}
+
+// Risk is the release risk of a PR.
+type Risk struct {
+	// Score is the weight of the PR's riskiest kind, plus 0 to 4 for its
+	// size and 0 to 2 for the number of areas it changes.
+	Score int `json:"score"`
+	// Level buckets Score as low (0-2), medium (3-5) or high (6 or more).
+	Level string `json:"level"`
+}
+
+// WithRiskScoring scores the release risk of PRs from their kinds, size and
+// the number of top-level areas they change, and labels them risk/low,
+// risk/medium or risk/high, so reviewers can prioritize and release managers
+// can assess churn. weights overrides DefaultKindWeights per kind.
+func (l *labeler) WithRiskScoring(weights map[string]int) *labeler {
+	l.riskScoring = true
+	l.kindWeights = map[string]int{}
+	for kind, w := range DefaultKindWeights {
+		l.kindWeights[kind] = w
+	}
+	for kind, w := range weights {
+		l.kindWeights[kind] = w
+	}
+	return l
+}
+
+// fetchRisk looks up the PR size the risk score includes.
+func (l *labeler) fetchRisk(ctx context.Context) error {
+	if !l.riskScoring {
+		return nil
+	}
+	_, err := l.pullRequest(ctx)
+	return err
+}
+
+// Risk returns the risk of the last evaluation, or nil if risk scoring is
+// not enabled.
+func (l *labeler) Risk() *Risk {
+	if !l.riskScoring {
+		return nil
+	}
+	score := 0
+	for kind := range l.kinds {
+		score = max(score, l.kindWeights[kind])
+	}
+	if l.pr != nil {
+		score += sizeRisk[size(l.pr.GetAdditions()+l.pr.GetDeletions())]
+	}
+	switch areas := len(l.areas()); {
+	case areas > 3:
+		score += 2
+	case areas > 1:
+		score++
+	}
+	r := &Risk{Score: score, Level: RiskLow}
+	switch {
+	case score >= 6:
+		r.Level = RiskHigh
+	case score >= 3:
+		r.Level = RiskMedium
+	}
+	return r
+}
+
+// processRiskLabel labels the PR with its risk level, replacing the label of
+// any other level.
+func (l *labeler) processRiskLabel() {
+	r := l.Risk()
+	if r == nil {
+		return
+	}
+	want := labels.RiskLabelPrefix + r.Level
+	if !l.currentMap[want] {
+		l.labelsToAdd[want] = true
+	}
+	for label := range l.currentMap {
+		if strings.HasPrefix(label, labels.RiskLabelPrefix) && label != want {
+			l.labelsToRemove[label] = true
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestDecide_RiskScoring(t *testing.T) {
+	note := "\n```release-note\nNONE\n```"
+	tests := []struct {
+		name          string
+		body          string
+		lines         int
+		files         []string
+		weights       map[string]int
+		currentLabels []string
+		want          Risk
+		wantAdd       string
+		wantRemove    []string
+	}{
+		{
+			name:    "small docs change",
+			body:    "/kind documentation" + note,
+			lines:   5,
+			files:   []string{"docs/a.md"},
+			want:    Risk{Score: 0, Level: RiskLow},
+			wantAdd: labels.RiskLabelPrefix + RiskLow,
+		},
+		{
+			name:    "medium feature across areas",
+			body:    "/kind feature" + note,
+			lines:   50,
+			files:   []string{"api/a.go", "pkg/b.go"},
+			want:    Risk{Score: 4, Level: RiskMedium},
+			wantAdd: labels.RiskLabelPrefix + RiskMedium,
+		},
+		{
+			name:          "large breaking change replaces the old level",
+			body:          "/kind breaking_change\n/kind fix" + note,
+			lines:         600,
+			files:         []string{"api/a.go", "pkg/b.go", "internal/c.go", "cmd/d.go", "go.mod"},
+			currentLabels: []string{labels.RiskLabelPrefix + RiskLow},
+			want:          Risk{Score: 8, Level: RiskHigh},
+			wantAdd:       labels.RiskLabelPrefix + RiskHigh,
+			wantRemove:    []string{labels.RiskLabelPrefix + RiskLow},
+		},
+		{
+			name:    "weights override the defaults",
+			body:    "/kind documentation" + note,
+			lines:   5,
+			files:   []string{"docs/a.md"},
+			weights: map[string]int{"documentation": 3},
+			want:    Risk{Score: 3, Level: RiskMedium},
+			wantAdd: labels.RiskLabelPrefix + RiskMedium,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current []*github.Label
+			for _, name := range tt.currentLabels {
+				current = append(current, &github.Label{Name: github.Ptr(name)})
+			}
+			var files []*github.CommitFile
+			for _, f := range tt.files {
+				files = append(files, &github.CommitFile{Filename: github.Ptr(f), Status: github.Ptr("modified")})
+			}
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, current),
+				mock.WithRequestMatch(mock.GetReposPullsFilesByOwnerByRepoByPullNumber, files),
+				mock.WithRequestMatch(mock.GetReposPullsByOwnerByRepoByPullNumber, &github.PullRequest{Additions: github.Ptr(tt.lines)}),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithRiskScoring(tt.weights)
+			d, err := l.Decide(context.Background(), tt.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := l.Risk(); got == nil || *got != tt.want {
+				t.Errorf("Risk() = %+v, want %+v", got, tt.want)
+			}
+			if !strings.Contains(strings.Join(d.LabelsToAdd, ","), tt.wantAdd) {
+				t.Errorf("LabelsToAdd = %v, want %s among them", d.LabelsToAdd, tt.wantAdd)
+			}
+			if len(d.LabelsToRemove)+len(tt.wantRemove) > 0 && !reflect.DeepEqual(d.LabelsToRemove, tt.wantRemove) {
+				t.Errorf("LabelsToRemove = %v, want %v", d.LabelsToRemove, tt.wantRemove)
+			}
+			if l.Metadata().Risk == nil {
+				t.Error("expected the risk in the metadata")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/secrets"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// SecretNotifier privately tells maintainers that a PR body appears to
+// contain a credential, so it can be rotated before the PR is merged or
+// scraped. kinds names what was found; the credential itself is never passed.
+type SecretNotifier interface {
+	NotifySecret(ctx context.Context, owner, repo string, prNum int, kinds []string) error
+}
+
+// WithSecretDetection flags PR bodies that contain known token formats or
+// high-entropy strings with the do-not-merge/possible-secret label. notifier,
+// if not nil, is told the first time the label is applied.
+func (l *labeler) WithSecretDetection(notifier SecretNotifier) *labeler {
+	l.detectSecrets = true
+	l.secretNotifier = notifier
+	return l
+}
+
+// processSecrets scans the whole body, HTML comments included, since comments
+// are as public as the rest of the PR.
+func (l *labeler) processSecrets(body string) error {
+	l.secretKinds = nil
+	if !l.detectSecrets {
+		return nil
+	}
+	l.secretKinds = secrets.Find(body)
+	if len(l.secretKinds) == 0 {
+		if l.currentMap[labels.PossibleSecretLabel] {
+			l.labelsToRemove[labels.PossibleSecretLabel] = true
+		}
+		return nil
+	}
+	if !l.currentMap[labels.PossibleSecretLabel] {
+		l.labelsToAdd[labels.PossibleSecretLabel] = true
+	}
+	return fmt.Errorf("possible credential in PR body (%s), labeling %q. Remove it from the PR body and its edit history, and rotate it", strings.Join(l.secretKinds, ", "), labels.PossibleSecretLabel)
+}
+
+// syncSecretNotification notifies maintainers when this run applies the
+// possible-secret label, so each leak is reported once.
+func (l *labeler) syncSecretNotification(ctx context.Context, p *Plan) error {
+	if len(p.NotifySecret) == 0 {
+		return nil
+	}
+	if err := l.secretNotifier.NotifySecret(ctx, l.owner, l.repo, l.prNum, p.NotifySecret); err != nil {
+		return fmt.Errorf("failed to notify maintainers of a possible secret: %w", err)
+	}
+	return nil
+}
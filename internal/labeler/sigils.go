@@ -0,0 +1,83 @@
+package labeler
+
+import "strings"
+
+// WithKindPrefixes also recognizes kind commands introduced by prefixes, e.g.
+// "> /kind" or "#kind:", besides /kind, so repositories migrating from other
+// PR templates need not rewrite them at once. Prefixes match at the start of
+// a line, after any indentation, case-insensitively.
+func (l *labeler) WithKindPrefixes(prefixes []string) *labeler {
+	l.kindPrefixes = prefixes
+	return l
+}
+
+// WithCommandNamespace also recognizes kind commands namespaced by ns, e.g.
+// `/kgateway kind feature` or `/kgateway kinds feature,cleanup` for ns
+// kgateway, for orgs that route several bots through one command.
+func (l *labeler) WithCommandNamespace(ns string) *labeler {
+	l.commandNamespace = ns
+	return l
+}
+
+// normalizeKindCommands rewrites the kind commands of body in the alternate
+// forms the labeler recognizes as /kind commands, one per kind: commands
+// introduced by its kind prefixes or namespaced by its command namespace,
+// and the plural /kinds a,b.
+func (l *labeler) normalizeKindCommands(body string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		for _, p := range l.kindPrefixes {
+			if hasPrefixFold(trimmed, p) {
+				trimmed = "/kind " + trimmed[len(p):]
+				break
+			}
+		}
+		if ns := "/" + l.commandNamespace; l.commandNamespace != "" && hasPrefixFold(trimmed, ns) {
+			if rest := strings.TrimLeft(trimmed[len(ns):], " \t"); len(rest) < len(trimmed[len(ns):]) && hasPrefixFold(rest, "kind") {
+				trimmed = "/" + rest
+			}
+		}
+		if hasPrefixFold(trimmed, "/kinds") && len(trimmed) > len("/kinds") && (trimmed[len("/kinds")] == ' ' || trimmed[len("/kinds")] == '\t') {
+			var cmds []string
+			for _, kind := range strings.FieldsFunc(trimmed[len("/kinds"):], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+				cmds = append(cmds, "/kind "+kind)
+			}
+			trimmed = strings.Join(cmds, "\n")
+		}
+		if trimmed != strings.TrimLeft(line, " \t") {
+			lines[i] = trimmed
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stripKindCommands removes the lines of body holding kind commands, in any
+// form normalizeKindCommands recognizes. A bare /kind line is removed with
+// the line its kind is taken from.
+func (l *labeler) stripKindCommands(body string) string {
+	lines := strings.Split(body, "\n")
+	var kept []string
+	for i := 0; i < len(lines); i++ {
+		line := l.normalizeKindCommands(lines[i])
+		if len(scanKinds(line)) > 0 {
+			continue
+		}
+		if strings.EqualFold(strings.TrimRight(line, " \t\f\r"), "/kind") {
+			j := i + 1
+			for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+				j++
+			}
+			if j < len(lines) && len(scanKinds("/kind\n"+lines[j])) > 0 {
+				i = j
+				continue
+			}
+		}
+		kept = append(kept, lines[i])
+	}
+	return strings.Join(kept, "\n")
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
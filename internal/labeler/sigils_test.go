@@ -0,0 +1,66 @@
+package labeler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKindCommandForms(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		prefixes  []string
+		namespace string
+		want      []string
+	}{
+		{
+			name:     "quoted command",
+			body:     "> /kind fix\n",
+			prefixes: []string{"> /kind"},
+			want:     []string{"kind/fix"},
+		},
+		{
+			name:     "hash sigil with colon, indented and mixed case",
+			body:     "  #Kind:feature\n/kind cleanup",
+			prefixes: []string{"> /kind", "#kind:"},
+			want:     []string{"kind/cleanup", "kind/feature"},
+		},
+		{
+			name: "not recognized without prefixes",
+			body: "#kind: fix\n/kind cleanup",
+			want: []string{"kind/cleanup"},
+		},
+		{
+			name: "plural command",
+			body: "/kinds feature,cleanup\n/kinds  fix documentation",
+			want: []string{"kind/cleanup", "kind/documentation", "kind/feature", "kind/fix"},
+		},
+		{
+			name:      "namespaced commands",
+			body:      "/kgateway kind feature\n/KGateway kinds fix, cleanup\n/kgatewaykind bump",
+			namespace: "kgateway",
+			want:      []string{"kind/cleanup", "kind/feature", "kind/fix"},
+		},
+		{
+			name: "namespaced command not recognized without namespace",
+			body: "/kgateway kind feature\n/kind cleanup",
+			want: []string{"kind/cleanup"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false).WithKindPrefixes(tt.prefixes).WithCommandNamespace(tt.namespace)
+			d, _ := l.Simulate(tt.body+"\n```release-note\nNONE\n```", nil)
+			var got []string
+			for _, label := range d.LabelsToAdd {
+				if strings.HasPrefix(label, "kind/") {
+					got = append(got, label)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("kind labels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package labeler
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Snapshot records a PR's labels before a run of the labeler changed them.
+// It is kept as the external ID of the run's check run, so that label changes
+// made by a bad config rollout can be undone.
+type Snapshot struct {
+	// TakenAt is when the run planned the label changes.
+	TakenAt time.Time `json:"takenAt"`
+	// Labels are the PR's labels before the run.
+	Labels []string `json:"labels"`
+	// Added and Removed are the labels the run added and removed.
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// externalID returns the snapshot as the external ID of a check run.
+func (s *Snapshot) externalID() *string {
+	if s == nil {
+		return nil
+	}
+	data, _ := json.Marshal(s)
+	id := string(data)
+	return &id
+}
+
+// ParseSnapshot returns the snapshot kept in the external ID of a labeler
+// check run, or nil if the run changed no labels.
+func ParseSnapshot(externalID string) *Snapshot {
+	var s Snapshot
+	if err := json.Unmarshal([]byte(externalID), &s); err != nil || s.TakenAt.IsZero() {
+		return nil
+	}
+	return &s
+}
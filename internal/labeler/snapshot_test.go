@@ -0,0 +1,69 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestProcessPR_CheckRunSnapshot(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		current []string
+		want    *Snapshot
+	}{
+		{
+			name:    "labels changed",
+			current: []string{"kind/bug", "lgtm"},
+			want: &Snapshot{
+				TakenAt: now,
+				Labels:  []string{"kind/bug", "lgtm"},
+				Added:   []string{"kind/fix", labels.ReleaseNoteNoneLabel},
+				Removed: []string{"kind/bug"},
+			},
+		},
+		{
+			name:    "labels unchanged",
+			current: []string{"kind/fix", labels.ReleaseNoteNoneLabel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current []*github.Label
+			for _, name := range tt.current {
+				current = append(current, &github.Label{Name: github.Ptr(name)})
+			}
+			var got github.CreateCheckRunOptions
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, current),
+				mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName, nil),
+				mock.WithRequestMatchHandler(
+					mock.PostReposCheckRunsByOwnerByRepo,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						json.NewDecoder(r.Body).Decode(&got)
+						w.Write(mock.MustMarshal(github.CheckRun{}))
+					}),
+				),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).
+				WithCheckRun("abc123", false).
+				WithClock(func() time.Time { return now })
+			if _, err := l.ProcessPR(context.Background(), "/kind fix\n```release-note\nNONE\n```", true); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if snapshot := ParseSnapshot(got.GetExternalID()); !reflect.DeepEqual(snapshot, tt.want) {
+				t.Fatalf("snapshot = %+v, want %+v", snapshot, tt.want)
+			}
+		})
+	}
+}
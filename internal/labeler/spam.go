@@ -0,0 +1,110 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// SpamHeuristics configures the checks that mark a PR as likely spam, such as
+// drive-by Hacktoberfest PRs. Zero values disable a check.
+type SpamHeuristics struct {
+	// EmptyDiff flags PRs that change no files.
+	EmptyDiff bool
+	// MinBodyLength flags PRs without a /kind whose body, HTML comments
+	// excluded, is shorter than this many characters.
+	MinBodyLength int
+	// MinAccountAge flags PRs without a /kind opened by accounts younger than this.
+	MinAccountAge time.Duration
+}
+
+func (h SpamHeuristics) enabled() bool {
+	return h.EmptyDiff || h.MinBodyLength > 0 || h.MinAccountAge > 0
+}
+
+// WithSpamHeuristics enables spam detection. A PR matching any heuristic is
+// labeled needs-triage/spam? for a maintainer to look at, instead of being
+// labeled and failed like a genuine PR with an incomplete description.
+func (l *labeler) WithSpamHeuristics(h SpamHeuristics) *labeler {
+	l.spam = h
+	return l
+}
+
+// fetchSpamSignals looks up what the spam heuristics need beyond the PR body:
+// the number of changed files and the author's account age.
+func (l *labeler) fetchSpamSignals(ctx context.Context) error {
+	if !l.spam.enabled() {
+		return nil
+	}
+	pr, err := l.pullRequest(ctx)
+	if err != nil {
+		return err
+	}
+	if l.changedFiles == nil && l.spam.EmptyDiff {
+		l.changedFileCount = pr.GetChangedFiles()
+		l.changedFileCountKnown = true
+	}
+	if l.spam.MinAccountAge > 0 {
+		user, _, err := l.client.Users.Get(ctx, pr.GetUser().GetLogin())
+		if err != nil {
+			return apiError(err, "", "get PR author")
+		}
+		l.authorCreatedAt = user.GetCreatedAt().Time
+	}
+	return nil
+}
+
+// spamReasons returns which heuristics body matches.
+func (l *labeler) spamReasons(body string) []string {
+	var reasons []string
+	if l.spam.EmptyDiff {
+		switch {
+		case l.changedFiles != nil && len(l.changedFiles) == 0,
+			l.changedFiles == nil && l.changedFileCountKnown && l.changedFileCount == 0:
+			reasons = append(reasons, "changes no files")
+		}
+	}
+	if len(l.kinds) > 0 {
+		return reasons
+	}
+	if n := l.spam.MinBodyLength; n > 0 && len(strings.TrimSpace(body)) < n {
+		reasons = append(reasons, fmt.Sprintf("has no /kind and a body shorter than %d characters", n))
+	}
+	if age := l.spam.MinAccountAge; age > 0 && !l.authorCreatedAt.IsZero() && l.now().Sub(l.authorCreatedAt) < age {
+		reasons = append(reasons, fmt.Sprintf("has no /kind and was opened by an account younger than %s", age))
+	}
+	return reasons
+}
+
+// SuspectedSpam returns why the last evaluation treated the PR as likely
+// spam, or nil if it did not.
+func (l *labeler) SuspectedSpam() []string {
+	return l.suspectedSpam
+}
+
+// processSpam replaces the label changes and validation problems of a likely
+// spam PR with the needs-triage/spam? label, so maintainers triage it rather
+// than its author being asked to fix it.
+func (l *labeler) processSpam(body string) bool {
+	l.suspectedSpam = nil
+	if !l.spam.enabled() {
+		return false
+	}
+	l.suspectedSpam = l.spamReasons(body)
+	if len(l.suspectedSpam) == 0 {
+		if l.currentMap[labels.SuspectedSpamLabel] {
+			l.labelsToRemove[labels.SuspectedSpamLabel] = true
+		}
+		return false
+	}
+	l.labelsToAdd = map[string]bool{}
+	l.labelsToRemove = map[string]bool{}
+	l.milestone, l.milestoneOverride = "", false
+	if !l.currentMap[labels.SuspectedSpamLabel] {
+		l.labelsToAdd[labels.SuspectedSpamLabel] = true
+	}
+	return true
+}
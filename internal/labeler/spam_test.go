@@ -0,0 +1,144 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestSimulate_SpamHeuristics(t *testing.T) {
+	tests := []struct {
+		name          string
+		heuristics    SpamHeuristics
+		changedFiles  []string
+		body          string
+		currentLabels []string
+		wantAdd       []string
+		wantRemove    []string
+		wantSpam      bool
+	}{
+		{
+			name:         "empty diff",
+			heuristics:   SpamHeuristics{EmptyDiff: true},
+			changedFiles: []string{},
+			body:         "/kind fix\n```release-note\nNONE\n```",
+			wantAdd:      []string{labels.SuspectedSpamLabel},
+			wantSpam:     true,
+		},
+		{
+			name:       "short body without kind",
+			heuristics: SpamHeuristics{MinBodyLength: 40},
+			body:       "<!-- the whole PR template is in comments -->\nupdate readme",
+			wantAdd:    []string{labels.SuspectedSpamLabel},
+			wantSpam:   true,
+		},
+		{
+			name:       "short body with kind is validated normally",
+			heuristics: SpamHeuristics{MinBodyLength: 40},
+			body:       "/kind fix",
+			wantAdd:    []string{labels.InvalidReleaseNoteLabel, "kind/fix"},
+		},
+		{
+			name:          "label removed once the PR looks genuine",
+			heuristics:    SpamHeuristics{MinBodyLength: 10},
+			body:          "/kind fix\n```release-note\nNONE\n```",
+			currentLabels: []string{labels.SuspectedSpamLabel, "kind/fix", labels.ReleaseNoteNoneLabel},
+			wantRemove:    []string{labels.SuspectedSpamLabel},
+		},
+		{
+			name:          "disabled heuristics leave the label alone",
+			body:          "/kind fix\n```release-note\nNONE\n```",
+			currentLabels: []string{labels.SuspectedSpamLabel, "kind/fix", labels.ReleaseNoteNoneLabel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false).WithSpamHeuristics(tt.heuristics).WithChangedFiles(tt.changedFiles)
+			d, err := l.Simulate(tt.body, tt.currentLabels)
+			if tt.wantAdd == nil {
+				tt.wantAdd = []string{}
+			}
+			if tt.wantRemove == nil {
+				tt.wantRemove = []string{}
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if !reflect.DeepEqual(d.LabelsToRemove, tt.wantRemove) {
+				t.Errorf("labels to remove = %v, want %v", d.LabelsToRemove, tt.wantRemove)
+			}
+			if gotSpam := len(l.SuspectedSpam()) > 0; gotSpam != tt.wantSpam {
+				t.Fatalf("suspected spam = %v, want %v", l.SuspectedSpam(), tt.wantSpam)
+			}
+			if tt.wantSpam && err != nil {
+				t.Fatalf("expected likely spam not to fail validation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestProcessPR_SpamNewAccount(t *testing.T) {
+	now := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		body      string
+		wantSpam  bool
+	}{
+		{
+			name:      "new account without kind",
+			createdAt: now.Add(-24 * time.Hour),
+			body:      "please merge",
+			wantSpam:  true,
+		},
+		{
+			name:      "new account with kind",
+			createdAt: now.Add(-24 * time.Hour),
+			body:      "/kind documentation\n```release-note\nNONE\n```",
+		},
+		{
+			name:      "established account without kind",
+			createdAt: now.Add(-365 * 24 * time.Hour),
+			body:      "please merge",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+					[]*github.Label{},
+				),
+				mock.WithRequestMatch(
+					mock.GetReposPullsByOwnerByRepoByPullNumber,
+					github.PullRequest{User: &github.User{Login: github.Ptr("newbie")}, ChangedFiles: github.Ptr(1)},
+				),
+				mock.WithRequestMatch(
+					mock.GetUsersByUsername,
+					github.User{Login: github.Ptr("newbie"), CreatedAt: &github.Timestamp{Time: tt.createdAt}},
+				),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).
+				WithClock(func() time.Time { return now }).
+				WithSpamHeuristics(SpamHeuristics{MinAccountAge: 7 * 24 * time.Hour})
+			_, err := l.ProcessPR(context.Background(), tt.body, false)
+			gotSpam := len(l.SuspectedSpam()) > 0
+			if gotSpam != tt.wantSpam {
+				t.Fatalf("suspected spam = %v, want %v", l.SuspectedSpam(), tt.wantSpam)
+			}
+			if gotSpam && !strings.Contains(l.SuspectedSpam()[0], "account younger than 168h0m0s") {
+				t.Fatalf("unexpected spam reason %v", l.SuspectedSpam())
+			}
+			if gotSpam && err != nil {
+				t.Fatalf("expected likely spam not to fail validation, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package labeler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/changelog"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// stackedOnRE captures the PR of a /stacked-on #N command in a PR body.
+var stackedOnRE = regexp.MustCompile(`(?m)^[ \t]*/stacked-on[ \t]+#(\d+)[ \t]*$`)
+
+// stackedPR is the PR another one is stacked on.
+type stackedPR struct {
+	number int
+	// stacked is set when the stacked PR's base branch is this PR's head
+	// branch; base and head name them.
+	stacked    bool
+	base, head string
+	// kinds are the kinds of the PR's labels, and noteLabel its release note
+	// label, if any.
+	kinds     map[string]bool
+	noteLabel string
+}
+
+// stackedOn returns the PR body stacks the PR on with a /stacked-on
+// command, or 0 if it does not.
+func stackedOn(body string) int {
+	m := stackedOnRE.FindStringSubmatch(body)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// fetchStack looks up the PR the body stacks the PR on with /stacked-on, so
+// an intermediate PR of a stack can inherit the kinds and release note of the
+// PR at the bottom of it instead of repeating them.
+func (l *labeler) fetchStack(ctx context.Context, body string) error {
+	l.stack = nil
+	number := stackedOn(stripComments(strings.ReplaceAll(body, "\r\n", "\n")))
+	if number == 0 {
+		return nil
+	}
+	l.stack = &stackedPR{number: number}
+	if number == l.prNum {
+		return nil
+	}
+	pr, err := l.pullRequest(ctx)
+	if err != nil {
+		return err
+	}
+	bottom, _, err := l.client.PullRequests.Get(ctx, l.owner, l.repo, number)
+	if err != nil {
+		return apiError(err, permPullRequestsRead, "get PR #%d the PR is stacked on", number)
+	}
+	l.stack.base, l.stack.head = pr.GetBase().GetRef(), bottom.GetHead().GetRef()
+	l.stack.stacked = l.stack.base == l.stack.head &&
+		bottom.GetHead().GetRepo().GetFullName() == pr.GetBase().GetRepo().GetFullName()
+	l.stack.kinds = map[string]bool{}
+	for _, label := range bottom.Labels {
+		name := l.defaultLabel(label.GetName())
+		if kind, ok := strings.CutPrefix(name, "kind/"); ok {
+			l.stack.kinds[kind] = true
+		}
+		switch name {
+		case labels.ReleaseNoteLabel, labels.ReleaseNoteNoneLabel:
+			l.stack.noteLabel = name
+		}
+	}
+	return nil
+}
+
+// stackKinds returns the kinds a PR that sets none inherits from the PR it is
+// stacked on, or nil if it is not stacked.
+func (l *labeler) stackKinds() map[string]bool {
+	if l.stack == nil || !l.stack.stacked {
+		return nil
+	}
+	return l.stack.kinds
+}
+
+// processStackedNote labels a PR without a release note block for the note
+// of the PR it is stacked on. The changelog lists the note once, with the
+// bottom PR, so the stacked PR's note records StackedOn instead of the text.
+func (l *labeler) processStackedNote() error {
+	s := l.stack
+	switch {
+	case s.number == l.prNum:
+		l.markInvalidReleaseNote()
+		return fmt.Errorf("/stacked-on must name the PR this one is stacked on, not this PR")
+	case !s.stacked:
+		l.markInvalidReleaseNote()
+		return fmt.Errorf("/stacked-on #%d: this PR's base branch %q is not the head branch %q of #%d; retarget the PR onto #%d or add a ```release-note``` block", s.number, s.base, s.head, s.number, s.number)
+	case s.noteLabel == labels.ReleaseNoteNoneLabel:
+		l.markNoneReleaseNote()
+	case s.noteLabel == labels.ReleaseNoteLabel:
+		l.releaseNote = &ReleaseNote{StackedOn: s.number, Section: changelog.SectionFor(l.kinds, "")}
+		l.markReleaseNote()
+	default:
+		l.markInvalidReleaseNote()
+		return fmt.Errorf("/stacked-on #%d: #%d has no valid release note yet to inherit; fix it there or add a ```release-note``` block", s.number, s.number)
+	}
+	return nil
+}
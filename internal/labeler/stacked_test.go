@@ -0,0 +1,128 @@
+package labeler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestDecide_StackedOn(t *testing.T) {
+	repo := &github.Repository{FullName: github.Ptr("owner/repo")}
+	bottom := func(noteLabel string) *github.PullRequest {
+		pr := &github.PullRequest{
+			Number: github.Ptr(5),
+			Head:   &github.PullRequestBranch{Ref: github.Ptr("frobnicator-1"), Repo: repo},
+			Labels: []*github.Label{{Name: github.Ptr("kind/feature")}},
+		}
+		if noteLabel != "" {
+			pr.Labels = append(pr.Labels, &github.Label{Name: github.Ptr(noteLabel)})
+		}
+		return pr
+	}
+	tests := []struct {
+		name        string
+		body        string
+		base        string
+		bottom      *github.PullRequest
+		wantAdd     []string
+		wantStacked int
+		wantErr     string
+	}{
+		{
+			name:        "inherits the kind and note",
+			body:        "/stacked-on #5",
+			base:        "frobnicator-1",
+			bottom:      bottom(labels.ReleaseNoteLabel),
+			wantAdd:     []string{"kind/feature", labels.ReleaseNoteLabel},
+			wantStacked: 5,
+		},
+		{
+			name:    "inherits a NONE note",
+			body:    "/stacked-on #5",
+			base:    "frobnicator-1",
+			bottom:  bottom(labels.ReleaseNoteNoneLabel),
+			wantAdd: []string{"kind/feature", labels.ReleaseNoteNoneLabel},
+		},
+		{
+			name:    "own kind and note win",
+			body:    "/stacked-on #5\n/kind fix\n```release-note\nFixed the frobnicator.\n```",
+			base:    "frobnicator-1",
+			bottom:  bottom(labels.ReleaseNoteLabel),
+			wantAdd: []string{"kind/fix", labels.ReleaseNoteLabel},
+		},
+		{
+			name:    "bottom PR without a valid note",
+			body:    "/stacked-on #5",
+			base:    "frobnicator-1",
+			bottom:  bottom(""),
+			wantAdd: []string{labels.InvalidReleaseNoteLabel, "kind/feature"},
+			wantErr: "#5 has no valid release note yet",
+		},
+		{
+			name:    "not based on the bottom PR",
+			body:    "/stacked-on #5",
+			base:    "main",
+			bottom:  bottom(labels.ReleaseNoteLabel),
+			wantAdd: []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel},
+			wantErr: `base branch "main" is not the head branch "frobnicator-1" of #5`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatchHandler(
+					mock.GetReposPullsByOwnerByRepoByPullNumber,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if strings.HasSuffix(r.URL.Path, "/pulls/5") {
+							w.Write(mock.MustMarshal(tt.bottom))
+							return
+						}
+						w.Write(mock.MustMarshal(&github.PullRequest{
+							Number: github.Ptr(9),
+							Base:   &github.PullRequestBranch{Ref: github.Ptr(tt.base), Repo: repo},
+						}))
+					}),
+				),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 9, false)
+			d, err := l.Decide(context.Background(), tt.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Errorf("LabelsToAdd = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			var stacked int
+			if d.ReleaseNote != nil {
+				stacked = d.ReleaseNote.StackedOn
+			}
+			if stacked != tt.wantStacked {
+				t.Errorf("ReleaseNote.StackedOn = %d, want %d", stacked, tt.wantStacked)
+			}
+			if tt.wantErr == "" && d.Error != "" || !strings.Contains(d.Error, tt.wantErr) {
+				t.Errorf("Error = %q, want %q", d.Error, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecide_StackedOnSelf(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+	)
+	d, err := New(github.NewClient(httpClient), "owner", "repo", 9, false).Decide(context.Background(), "/stacked-on #9\n/kind fix")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(d.Error, "not this PR") {
+		t.Errorf("Error = %q, want the PR stacked on itself reported", d.Error)
+	}
+}
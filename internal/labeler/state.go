@@ -0,0 +1,120 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// stateRE captures the state kept in the sticky comment.
+var stateRE = regexp.MustCompile(`(?m)^<!-- pr-kind-labeler-state (\{.*\}) -->$`)
+
+// commentState is what the labeler remembers about a PR between runs. It is
+// kept in the sticky comment, so no datastore is needed.
+type commentState struct {
+	// FirstFailedAt is when the PR first failed validation.
+	FirstFailedAt time.Time `json:"firstFailedAt,omitzero"`
+	// GreenAt is when the PR first passed validation after failing it.
+	GreenAt time.Time `json:"greenAt,omitzero"`
+	// ReleaseNote is the release note a maintainer set with a comment
+	// command, kept even if the command comment is deleted.
+	ReleaseNote *stateReleaseNote `json:"releaseNote,omitempty"`
+}
+
+// parseState returns the state kept in a sticky comment body. A missing or
+// hand-edited state is treated as empty rather than failing the run.
+func parseState(body string) commentState {
+	var s commentState
+	if m := stateRE.FindStringSubmatch(body); m != nil {
+		if err := json.Unmarshal([]byte(m[1]), &s); err != nil {
+			return commentState{}
+		}
+	}
+	return s
+}
+
+// render returns the state as a line for the sticky comment, or "" if it is
+// empty.
+func (s commentState) render() string {
+	if s == (commentState{}) {
+		return ""
+	}
+	data, _ := json.Marshal(s)
+	return "<!-- pr-kind-labeler-state " + string(data) + " -->\n"
+}
+
+// fetchComment looks up the sticky comment, the state kept in it, and any
+// newer /release-note command, in a comment or, WithReviewCommands, a review.
+func (l *labeler) fetchComment(ctx context.Context) error {
+	if !l.stickyComment {
+		return nil
+	}
+	comments, err := l.listComments(ctx)
+	if err != nil {
+		return err
+	}
+	l.existingComment = stickyComment(comments)
+	l.prevState = parseState(l.existingComment.GetBody())
+	l.releaseNoteOverride = l.prevState.ReleaseNote
+	cmd, err := l.releaseNoteCommand(ctx, comments, l.prevState.ReleaseNote)
+	if err != nil {
+		return err
+	}
+	if cmd != nil {
+		l.releaseNoteOverride = cmd
+	}
+	if !l.reviewCommands {
+		return nil
+	}
+	reviews, err := l.listReviews(ctx)
+	if err != nil {
+		return err
+	}
+	cmd, err = l.reviewReleaseNoteCommand(ctx, reviews, l.prevState.ReleaseNote)
+	if err != nil {
+		return err
+	}
+	if cmd != nil {
+		if o := l.releaseNoteOverride; o == nil || o == l.prevState.ReleaseNote || cmd.SetAt.After(o.SetAt) {
+			l.releaseNoteOverride = cmd
+		}
+	}
+	return nil
+}
+
+// updateState records when the PR first failed validation and when it then
+// first passed.
+func (l *labeler) updateState() {
+	s := l.prevState
+	s.ReleaseNote = l.releaseNoteOverride
+	switch {
+	case len(l.suspectedSpam) > 0:
+	case len(l.problems) > 0:
+		if s.FirstFailedAt.IsZero() {
+			s.FirstFailedAt = l.now().UTC()
+		}
+	case !s.FirstFailedAt.IsZero() && s.GreenAt.IsZero():
+		s.GreenAt = l.now().UTC()
+	}
+	l.state = s
+}
+
+// TimeToGreen returns how long the PR took to pass validation after first
+// failing it, and true, on the run where it first passes. It needs the
+// sticky comment, which keeps the time of the first failure.
+func (l *labeler) TimeToGreen() (time.Duration, bool) {
+	if !l.prevState.GreenAt.IsZero() || l.state.GreenAt.IsZero() {
+		return 0, false
+	}
+	return l.state.GreenAt.Sub(l.state.FirstFailedAt), true
+}
+
+// formatDuration renders d to the minute, or to the second under a minute.
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
@@ -0,0 +1,89 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestProcessPR_TimeToGreen(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	failedAt := now.Add(-2*time.Hour - 13*time.Minute)
+	tests := []struct {
+		name        string
+		state       commentState
+		body        string
+		wantComment string
+		wantTTG     time.Duration
+	}{
+		{
+			name:        "first failure is recorded",
+			body:        "no kind here",
+			wantComment: `<!-- pr-kind-labeler-state {"firstFailedAt":"2025-10-15T12:00:00Z"} -->`,
+		},
+		{
+			name:        "later failure keeps the first",
+			state:       commentState{FirstFailedAt: failedAt},
+			body:        "still no kind",
+			wantComment: `{"firstFailedAt":"2025-10-15T09:47:00Z"}`,
+		},
+		{
+			name:        "turning green reports the duration",
+			state:       commentState{FirstFailedAt: failedAt},
+			body:        validBody,
+			wantComment: "Time to green: 2h13m after the first failed check.",
+			wantTTG:     2*time.Hour + 13*time.Minute,
+		},
+		{
+			name:        "staying green reports nothing new",
+			state:       commentState{FirstFailedAt: failedAt, GreenAt: now.Add(-time.Hour)},
+			body:        validBody,
+			wantComment: "Time to green: 1h13m after the first failed check.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var existing []*github.IssueComment
+			if tt.state != (commentState{}) {
+				existing = []*github.IssueComment{{ID: github.Ptr(int64(7)), Body: github.Ptr(commentMarker + "\n" + tt.state.render() + "old")}}
+			}
+			var posted string
+			save := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var c github.IssueComment
+				json.NewDecoder(r.Body).Decode(&c)
+				posted = c.GetBody()
+				w.Write(mock.MustMarshal(c))
+			})
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.GetReposIssuesCommentsByOwnerByRepoByIssueNumber, existing),
+				mock.WithRequestMatchHandler(mock.PostReposIssuesCommentsByOwnerByRepoByIssueNumber, save),
+				mock.WithRequestMatchHandler(mock.PatchReposIssuesCommentsByOwnerByRepoByCommentId, save),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).
+				WithClock(func() time.Time { return now }).
+				WithStickyComment()
+			l.ProcessPR(context.Background(), tt.body, true)
+			if !strings.Contains(posted, tt.wantComment) {
+				t.Fatalf("comment lacks %q:\n%s", tt.wantComment, posted)
+			}
+			ttg, ok := l.TimeToGreen()
+			if ok != (tt.wantTTG != 0) || ttg != tt.wantTTG {
+				t.Fatalf("TimeToGreen() = %v, %v, want %v", ttg, ok, tt.wantTTG)
+			}
+		})
+	}
+}
+
+func TestParseState_HandEdited(t *testing.T) {
+	if s := parseState(commentMarker + "\n<!-- pr-kind-labeler-state {not json} -->\n"); s != (commentState{}) {
+		t.Fatalf("parseState() = %+v, want empty", s)
+	}
+}
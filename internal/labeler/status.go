@@ -0,0 +1,123 @@
+package labeler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// maxStatusDescription is the longest description GitHub accepts for a commit
+// status.
+const maxStatusDescription = 140
+
+// WithCommitStatus reports the result as a commit status on the PR head
+// commit, for repositories whose token or App cannot create check runs.
+// headSHA may be empty to look it up. If blocking, invalid PRs report
+// failure; otherwise success, as commit statuses have no neutral state.
+func (l *labeler) WithCommitStatus(headSHA string, blocking bool) *labeler {
+	l.commitStatus = true
+	l.headSHA = headSHA
+	l.checkRunBlocking = blocking
+	return l
+}
+
+// WithoutLabelChanges never adds or removes labels, so the labeler acts
+// purely as a validator reporting through its check run or commit status.
+// As the labels that trigger them are not applied, the PR is neither handed
+// to the triage rotation nor are maintainers told about possible secrets.
+func (l *labeler) WithoutLabelChanges() *labeler {
+	l.readOnlyLabels = true
+	return l
+}
+
+// WithDisabledLabels leaves the labels matching patterns alone, so
+// repositories can adopt the labeler partially, e.g. without
+// release-note-none or any do-not-merge/* label. Patterns are path patterns,
+// such as do-not-merge/*; one prefixed with ! enables the labels it matches
+// again, and the last matching pattern wins, so "*,!kind/*" manages only the
+// kind labels. Validation is unchanged: failures are still reported, e.g.
+// in the sticky comment or check run, and the side effects of a disabled
+// label, such as notifying maintainers of a possible secret, are skipped.
+func (l *labeler) WithDisabledLabels(patterns []string) *labeler {
+	l.disabledLabels = patterns
+	return l
+}
+
+// ValidLabelPattern reports whether pattern, optionally prefixed with !, is
+// a well-formed WithDisabledLabels pattern.
+func ValidLabelPattern(pattern string) bool {
+	pattern = strings.TrimPrefix(pattern, "!")
+	return pattern != "" && ValidPathPattern(pattern)
+}
+
+// labelDisabled reports whether label is disabled by WithDisabledLabels.
+func (l *labeler) labelDisabled(label string) bool {
+	disabled := false
+	for _, p := range l.disabledLabels {
+		if enable, ok := strings.CutPrefix(p, "!"); ok {
+			if matchPath(enable, label) {
+				disabled = false
+			}
+		} else if matchPath(p, label) {
+			disabled = true
+		}
+	}
+	return disabled
+}
+
+// dropLabelChanges discards the label changes of the last evaluation if
+// labels are read-only.
+func (l *labeler) dropLabelChanges() {
+	if l.readOnlyLabels {
+		clear(l.labelsToAdd)
+		clear(l.labelsToRemove)
+	}
+	if len(l.disabledLabels) == 0 {
+		return
+	}
+	for _, changes := range []map[string]bool{l.labelsToAdd, l.labelsToRemove} {
+		for label := range changes {
+			if l.labelDisabled(label) {
+				delete(changes, label)
+			}
+		}
+	}
+}
+
+// commitStatusReport returns the commit status for the last evaluation.
+func (l *labeler) commitStatusReport() *CommitStatusReport {
+	conclusion, output := l.checkRunOutput()
+	state := "success"
+	if conclusion == "failure" {
+		state = "failure"
+	}
+	description := output.GetTitle()
+	if len(description) > maxStatusDescription {
+		description = description[:maxStatusDescription-3] + "..."
+	}
+	return &CommitStatusReport{HeadSHA: l.headSHA, State: state, Description: description}
+}
+
+// syncCommitStatus reports the commit status of p.
+func (l *labeler) syncCommitStatus(ctx context.Context, p *Plan) error {
+	if p.CommitStatus == nil {
+		return nil
+	}
+	sha := p.CommitStatus.HeadSHA
+	if sha == "" {
+		var err error
+		if sha, err = l.resolveHeadSHA(ctx); err != nil {
+			return err
+		}
+	}
+	_, _, err := l.client.Repositories.CreateStatus(ctx, l.owner, l.repo, sha, &github.RepoStatus{
+		State:       github.Ptr(p.CommitStatus.State),
+		Description: github.Ptr(p.CommitStatus.Description),
+		Context:     github.Ptr(CheckRunName),
+	})
+	if err != nil {
+		return apiError(err, permStatusesWrite, "create commit status")
+	}
+	return nil
+}
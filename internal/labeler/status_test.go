@@ -0,0 +1,135 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestCommitStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		blocking  bool
+		wantState string
+	}{
+		{
+			name:      "valid",
+			body:      "/kind fix\n```release-note\nNONE\n```",
+			blocking:  true,
+			wantState: "success",
+		},
+		{
+			name:      "invalid and blocking",
+			body:      "no kind here",
+			blocking:  true,
+			wantState: "failure",
+		},
+		{
+			name:      "invalid and not blocking",
+			body:      "no kind here",
+			wantState: "success",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status github.RepoStatus
+			// no label writes are mocked, so any label change fails the test
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{{Name: github.Ptr("kind/cleanup")}}),
+				mock.WithRequestMatchHandler(
+					mock.PostReposStatusesByOwnerByRepoBySha,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if r.URL.Path != "/repos/owner/repo/statuses/abc123" {
+							t.Errorf("unexpected commit status path %s", r.URL.Path)
+						}
+						json.NewDecoder(r.Body).Decode(&status)
+						w.Write(mock.MustMarshal(status))
+					}),
+				),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).
+				WithCommitStatus("abc123", tt.blocking).
+				WithoutLabelChanges()
+			_, err := l.ProcessPR(context.Background(), tt.body, true)
+			if _, operational := Partition(err); len(operational) > 0 {
+				t.Fatalf("unexpected operational errors: %v", operational)
+			}
+			if d := l.Decision(); len(d.LabelsToAdd)+len(d.LabelsToRemove) > 0 {
+				t.Errorf("expected no label changes, got +%v -%v", d.LabelsToAdd, d.LabelsToRemove)
+			}
+			if status.GetState() != tt.wantState || status.GetContext() != CheckRunName || status.GetDescription() == "" {
+				t.Errorf("unexpected commit status %+v, want state %s", status, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestSimulate_DisabledLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		patterns   []string
+		body       string
+		current    []string
+		wantAdd    []string
+		wantRemove []string
+		wantErr    bool
+	}{
+		{
+			name:     "release-note-none left alone",
+			patterns: []string{labels.ReleaseNoteNoneLabel},
+			body:     "/kind cleanup\n```release-note\nNONE\n```",
+			wantAdd:  []string{"kind/cleanup"},
+		},
+		{
+			name:     "do-not-merge labels never applied but still reported",
+			patterns: []string{"do-not-merge/*"},
+			body:     "no kind",
+			wantAdd:  []string{},
+			wantErr:  true,
+		},
+		{
+			name:       "only kind labels",
+			patterns:   []string{"*", "!kind/*"},
+			body:       "/kind fix\n```release-note\nFixed a crash.\n```",
+			current:    []string{"kind/cleanup", labels.ReleaseNoteNoneLabel},
+			wantAdd:    []string{"kind/fix"},
+			wantRemove: []string{"kind/cleanup"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(nil, "owner", "repo", 1, false).WithDisabledLabels(tt.patterns).Simulate(tt.body, tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(d.LabelsToAdd, append([]string{}, tt.wantAdd...)) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if !reflect.DeepEqual(d.LabelsToRemove, append([]string{}, tt.wantRemove...)) {
+				t.Errorf("labels to remove = %v, want %v", d.LabelsToRemove, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestValidLabelPattern(t *testing.T) {
+	for pattern, want := range map[string]bool{
+		"do-not-merge/*":    true,
+		"!kind/*":           true,
+		"release-note-none": true,
+		"!":                 false,
+		"kind/[":            false,
+	} {
+		if got := ValidLabelPattern(pattern); got != want {
+			t.Errorf("ValidLabelPattern(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package labeler
+
+import (
+	"strings"
+	"time"
+)
+
+// WithCommentInterval posts at most one new comment per interval on a PR, so
+// authors iterating on their description are not flooded with
+// notifications. Comments that would exceed the limit are posted by a later
+// run instead. Edits to existing comments are not limited, as they do not
+// notify.
+func (l *labeler) WithCommentInterval(interval time.Duration) *labeler {
+	l.commentInterval = interval
+	return l
+}
+
+// mayPostComment reports whether a new comment may be posted now: no comment
+// of the labeler's was posted within the comment interval.
+func (l *labeler) mayPostComment() bool {
+	if l.commentInterval <= 0 {
+		return true
+	}
+	var last time.Time
+	for _, c := range l.comments {
+		body := c.GetBody()
+		if !strings.HasPrefix(body, commentMarker) && !strings.HasPrefix(body, blockersMarker) {
+			continue
+		}
+		if t := c.GetCreatedAt().Time; t.After(last) {
+			last = t
+		}
+	}
+	return l.now().Sub(last) >= l.commentInterval
+}
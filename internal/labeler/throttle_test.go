@@ -0,0 +1,81 @@
+package labeler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestProcessPR_CommentInterval(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		comments    []*github.IssueComment
+		wantCreated int
+	}{
+		{
+			name:        "no earlier comments",
+			wantCreated: 1,
+		},
+		{
+			name: "labeler commented within the interval",
+			comments: []*github.IssueComment{
+				{ID: github.Ptr(int64(1)), Body: github.Ptr(blockersMarker + "\n- [ ] `do-not-merge/hold`"), CreatedAt: &github.Timestamp{Time: now.Add(-5 * time.Minute)}},
+			},
+		},
+		{
+			name: "labeler commented before the interval",
+			comments: []*github.IssueComment{
+				{ID: github.Ptr(int64(1)), Body: github.Ptr(blockersMarker + "\n- [ ] `do-not-merge/hold`"), CreatedAt: &github.Timestamp{Time: now.Add(-15 * time.Minute)}},
+			},
+			wantCreated: 1,
+		},
+		{
+			name: "other comments do not count",
+			comments: []*github.IssueComment{
+				{ID: github.Ptr(int64(1)), Body: github.Ptr("LGTM"), CreatedAt: &github.Timestamp{Time: now.Add(-time.Minute)}},
+			},
+			wantCreated: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := 0
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatch(mock.GetReposIssuesCommentsByOwnerByRepoByIssueNumber, tt.comments),
+				mock.WithRequestMatch(mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+				mock.WithRequestMatchHandler(
+					mock.PostReposIssuesCommentsByOwnerByRepoByIssueNumber,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						created++
+						w.Write(mock.MustMarshal(github.IssueComment{}))
+					}),
+				),
+				mock.WithRequestMatch(mock.PatchReposIssuesCommentsByOwnerByRepoByCommentId, github.IssueComment{}),
+			)
+			l := New(github.NewClient(httpClient), "owner", "repo", 1, false).
+				WithClock(func() time.Time { return now }).
+				WithStickyComment().
+				WithMergeBlockersComment().
+				WithCommentInterval(10 * time.Minute)
+			// both comments are due, but at most one may be posted per interval
+			l.ProcessPR(context.Background(), "no kind here", true)
+			if created != tt.wantCreated {
+				t.Fatalf("expected %d new comment(s), got %d", tt.wantCreated, created)
+			}
+		})
+	}
+}
+
+func TestMayPostComment_Disabled(t *testing.T) {
+	l := New(nil, "owner", "repo", 1, false)
+	l.comments = []*github.IssueComment{{Body: github.Ptr(commentMarker), CreatedAt: &github.Timestamp{Time: time.Now()}}}
+	if !l.mayPostComment() {
+		t.Fatalf("expected comments not to be limited without an interval")
+	}
+}
@@ -0,0 +1,60 @@
+package labeler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// actionRequiredPrefix starts release notes for changes users must act on
+// when upgrading.
+const actionRequiredPrefix = "ACTION REQUIRED"
+
+// DefaultUpgradeDocsPaths is where upgrade docs live unless configured
+// otherwise.
+var DefaultUpgradeDocsPaths = []string{"docs/upgrading/**"}
+
+// WithUpgradeDocs requires PRs whose release note starts with ACTION REQUIRED
+// to change a file matching one of paths, e.g. docs/upgrading/**, so users
+// are told how to upgrade. PRs that don't are labeled
+// do-not-merge/needs-upgrade-docs. Empty paths use DefaultUpgradeDocsPaths.
+func (l *labeler) WithUpgradeDocs(paths []string) *labeler {
+	if len(paths) == 0 {
+		paths = DefaultUpgradeDocsPaths
+	}
+	l.upgradeDocs = true
+	l.upgradeDocsPaths = paths
+	return l
+}
+
+// processUpgradeDocs checks that an ACTION REQUIRED release note comes with
+// upgrade docs.
+func (l *labeler) processUpgradeDocs() error {
+	if !l.upgradeDocs {
+		return nil
+	}
+	actionRequired := l.releaseNote != nil && strings.HasPrefix(strings.ToUpper(l.releaseNote.Note), actionRequiredPrefix)
+	if !actionRequired || l.changesAny(l.upgradeDocsPaths) {
+		if l.currentMap[labels.NeedsUpgradeDocsLabel] {
+			l.labelsToRemove[labels.NeedsUpgradeDocsLabel] = true
+		}
+		return nil
+	}
+	if !l.currentMap[labels.NeedsUpgradeDocsLabel] {
+		l.labelsToAdd[labels.NeedsUpgradeDocsLabel] = true
+	}
+	return fmt.Errorf("the release note starts with %s but the PR changes nothing under %s; please document the upgrade steps there", actionRequiredPrefix, strings.Join(l.upgradeDocsPaths, ", "))
+}
+
+// changesAny reports whether the PR changes a file matching one of patterns.
+func (l *labeler) changesAny(patterns []string) bool {
+	for _, f := range l.changedFiles {
+		for _, p := range patterns {
+			if matchPath(p, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package labeler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestSimulate_UpgradeDocs(t *testing.T) {
+	actionRequired := "/kind breaking_change\n```release-note\nACTION REQUIRED: The foo field was removed.\n```"
+	tests := []struct {
+		name          string
+		body          string
+		files         []string
+		paths         []string
+		currentLabels []string
+		wantAdd       []string
+		wantRemove    []string
+		wantErr       bool
+	}{
+		{
+			name:    "missing upgrade docs",
+			body:    actionRequired,
+			files:   []string{"api/v1/types.go"},
+			wantAdd: []string{labels.NeedsUpgradeDocsLabel, "kind/breaking_change", labels.ReleaseNoteLabel},
+			wantErr: true,
+		},
+		{
+			name:    "upgrade docs changed",
+			body:    actionRequired,
+			files:   []string{"api/v1/types.go", "docs/upgrading/v2.md"},
+			wantAdd: []string{"kind/breaking_change", labels.ReleaseNoteLabel},
+		},
+		{
+			name:    "configured path",
+			body:    actionRequired,
+			files:   []string{"site/content/upgrade.md"},
+			paths:   []string{"site/**/upgrade*.md"},
+			wantAdd: []string{"kind/breaking_change", labels.ReleaseNoteLabel},
+		},
+		{
+			name:    "category prefix before ACTION REQUIRED",
+			body:    "/kind breaking_change\n```release-note\n[helm] ACTION REQUIRED: Rename the foo value.\n```",
+			wantAdd: []string{labels.NeedsUpgradeDocsLabel, "kind/breaking_change", labels.ReleaseNoteLabel},
+			wantErr: true,
+		},
+		{
+			name:    "ordinary note",
+			body:    "/kind fix\n```release-note\nFixed a crash.\n```",
+			wantAdd: []string{"kind/fix", labels.ReleaseNoteLabel},
+		},
+		{
+			name:          "label removed once docs are added",
+			body:          actionRequired,
+			files:         []string{"docs/upgrading/v2.md"},
+			currentLabels: []string{labels.NeedsUpgradeDocsLabel, "kind/breaking_change", labels.ReleaseNoteLabel},
+			wantAdd:       []string{},
+			wantRemove:    []string{labels.NeedsUpgradeDocsLabel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(nil, "owner", "repo", 1, false).WithUpgradeDocs(tt.paths).WithChangedFiles(tt.files)
+			d, err := l.Simulate(tt.body, tt.currentLabels)
+			if !reflect.DeepEqual(d.LabelsToAdd, tt.wantAdd) {
+				t.Errorf("labels to add = %v, want %v", d.LabelsToAdd, tt.wantAdd)
+			}
+			if tt.wantRemove == nil {
+				tt.wantRemove = []string{}
+			}
+			if !reflect.DeepEqual(d.LabelsToRemove, tt.wantRemove) {
+				t.Errorf("labels to remove = %v, want %v", d.LabelsToRemove, tt.wantRemove)
+			}
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "changes nothing under") {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProcessPR_UpgradeDocsListsFiles(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+		mock.WithRequestMatchPages(
+			mock.GetReposPullsFilesByOwnerByRepoByPullNumber,
+			[]*github.CommitFile{{Filename: github.Ptr("api/v1/types.go")}},
+			[]*github.CommitFile{{Filename: github.Ptr("docs/upgrading/v2.md"), PreviousFilename: github.Ptr("docs/upgrade.md")}},
+		),
+	)
+	l := New(github.NewClient(httpClient), "owner", "repo", 1, false).WithUpgradeDocs(nil)
+	_, err := l.ProcessPR(context.Background(), "/kind breaking_change\n```release-note\nACTION REQUIRED: Removed foo.\n```", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"api/v1/types.go", "docs/upgrading/v2.md", "docs/upgrade.md"}
+	if got := l.Decision().ChangedFiles; !reflect.DeepEqual(got, want) {
+		t.Fatalf("changed files = %v, want %v", got, want)
+	}
+}
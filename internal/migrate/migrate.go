@@ -0,0 +1,172 @@
+// Package migrate renames labels across a repository: label definitions, and
+// the open and recently closed issues and PRs that carry them.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// Options controls a migration.
+type Options struct {
+	// ClosedSince also migrates issues and PRs closed after it. The zero
+	// value migrates open ones only.
+	ClosedSince time.Time
+	// DeleteOld deletes the old label definition once issues are relabeled,
+	// removing it from anything older than ClosedSince too.
+	DeleteOld bool
+	// DryRun reports what would change without changing anything.
+	DryRun bool
+}
+
+// Action is what a migration did, or would do, for one label.
+type Action string
+
+const (
+	// Renamed means the old label definition was renamed. GitHub moves every
+	// issue and PR, however old, to the new name.
+	Renamed Action = "renamed"
+	// Relabeled means the new label already existed, so issues and PRs were
+	// moved from the old label one by one.
+	Relabeled Action = "relabeled"
+	// Missing means the repository has no old label, so nothing carries it.
+	Missing Action = "missing"
+)
+
+// Change reports the migration of one label.
+type Change struct {
+	Old    string
+	New    string
+	Action Action
+	// Issues are the issue and PR numbers moved to the new label, for Relabeled.
+	Issues []int
+	// Deleted is set when the old label definition was deleted.
+	Deleted bool
+}
+
+// Run migrates every old label in renames to its new name in owner/repo.
+// Labels are migrated in sorted order, and a failure stops the migration so
+// it can be rerun once fixed; finished changes are returned with the error.
+func Run(ctx context.Context, client *github.Client, owner, repo string, renames map[string]string, opts Options) ([]Change, error) {
+	var changes []Change
+	for _, old := range sortedKeys(renames) {
+		change, err := migrate(ctx, client, owner, repo, old, renames[old], opts)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func migrate(ctx context.Context, client *github.Client, owner, repo, old, replacement string, opts Options) (Change, error) {
+	change := Change{Old: old, New: replacement}
+	oldExists, err := labelExists(ctx, client, owner, repo, old)
+	if err != nil {
+		return change, err
+	}
+	if !oldExists {
+		change.Action = Missing
+		return change, nil
+	}
+	newExists, err := labelExists(ctx, client, owner, repo, replacement)
+	if err != nil {
+		return change, err
+	}
+	if !newExists {
+		change.Action = Renamed
+		if opts.DryRun {
+			return change, nil
+		}
+		if _, _, err := client.Issues.EditLabel(ctx, owner, repo, labels.EncodeName(old), &github.Label{Name: github.Ptr(replacement)}); err != nil {
+			return change, fmt.Errorf("failed to rename label %q to %q: %w", old, replacement, err)
+		}
+		return change, nil
+	}
+
+	change.Action = Relabeled
+	issues, err := labeledIssues(ctx, client, owner, repo, old, opts.ClosedSince)
+	if err != nil {
+		return change, err
+	}
+	for _, number := range issues {
+		if !opts.DryRun {
+			if _, _, err := client.Issues.AddLabelsToIssue(ctx, owner, repo, number, []string{replacement}); err != nil {
+				return change, fmt.Errorf("failed to add label %q to #%d: %w", replacement, number, err)
+			}
+			if _, err := client.Issues.RemoveLabelForIssue(ctx, owner, repo, number, labels.EncodeName(old)); err != nil {
+				return change, fmt.Errorf("failed to remove label %q from #%d: %w", old, number, err)
+			}
+		}
+		change.Issues = append(change.Issues, number)
+	}
+	if opts.DeleteOld {
+		if !opts.DryRun {
+			if _, err := client.Issues.DeleteLabel(ctx, owner, repo, labels.EncodeName(old)); err != nil {
+				return change, fmt.Errorf("failed to delete label %q: %w", old, err)
+			}
+		}
+		change.Deleted = true
+	}
+	return change, nil
+}
+
+func labelExists(ctx context.Context, client *github.Client, owner, repo, name string) (bool, error) {
+	_, resp, err := client.Issues.GetLabel(ctx, owner, repo, labels.EncodeName(name))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get label %q: %w", name, err)
+	}
+	return true, nil
+}
+
+// labeledIssues returns the open issues and PRs labeled label, and those
+// closed after closedSince when it is set. GitHub can only filter issues by
+// when they were last updated, so closed ones are filtered by when they
+// were closed here.
+func labeledIssues(ctx context.Context, client *github.Client, owner, repo, label string, closedSince time.Time) ([]int, error) {
+	state := "open"
+	if !closedSince.IsZero() {
+		state = "all"
+	}
+	opts := &github.IssueListByRepoOptions{
+		State:       state,
+		Labels:      []string{label},
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var numbers []int
+	for {
+		issues, resp, err := client.Issues.ListByRepo(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues labeled %q: %w", label, err)
+		}
+		for _, issue := range issues {
+			if issue.GetState() == "closed" && issue.GetClosedAt().Before(closedSince) {
+				continue
+			}
+			numbers = append(numbers, issue.GetNumber())
+		}
+		if resp.NextPage == 0 {
+			return numbers, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,203 @@
+package migrate
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// labelHandler serves the label definitions in defined and 404s the rest.
+func labelHandler(defined ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.EscapedPath()
+		name, err := labels.DecodeName(path[strings.LastIndex(path, "/")+1:])
+		if err != nil {
+			mock.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for _, d := range defined {
+			if d == name {
+				w.Write(mock.MustMarshal(github.Label{Name: github.Ptr(name)}))
+				return
+			}
+		}
+		mock.WriteError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func TestRun_RenamesDefinitionWhenNewLabelIsMissing(t *testing.T) {
+	var renamed string
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(mock.GetReposLabelsByOwnerByRepoByName, labelHandler("old")),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposLabelsByOwnerByRepoByName,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				renamed = r.URL.Path
+				w.Write(mock.MustMarshal(github.Label{}))
+			}),
+		),
+	)
+	changes, err := Run(context.Background(), github.NewClient(httpClient), "owner", "repo", map[string]string{"old": "new", "gone": "other"}, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Change{
+		{Old: "gone", New: "other", Action: Missing},
+		{Old: "old", New: "new", Action: Renamed},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	if renamed != "/repos/owner/repo/labels/old" {
+		t.Fatalf("expected the old label to be renamed, got %q", renamed)
+	}
+}
+
+func TestRun_EscapesLabelNames(t *testing.T) {
+	old := "needs-triage/größe? 🚀"
+	var renamed string
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(mock.GetReposLabelsByOwnerByRepoByName, labelHandler(old)),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposLabelsByOwnerByRepoByName,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				renamed = r.URL.EscapedPath()
+				w.Write(mock.MustMarshal(github.Label{}))
+			}),
+		),
+	)
+	changes, err := Run(context.Background(), github.NewClient(httpClient), "owner", "repo", map[string]string{old: "new"}, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []Change{{Old: old, New: "new", Action: Renamed}}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	if want := "/repos/owner/repo/labels/" + labels.EncodeName(old); renamed != want {
+		t.Fatalf("renamed %q, want %q", renamed, want)
+	}
+}
+
+func TestRun_RelabelsIssuesWhenBothLabelsExist(t *testing.T) {
+	closedSince := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	var added, removed []string
+	var deleted bool
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(mock.GetReposLabelsByOwnerByRepoByName, labelHandler("old", "new")),
+		mock.WithRequestMatchPages(
+			mock.GetReposIssuesByOwnerByRepo,
+			[]*github.Issue{
+				{Number: github.Ptr(1), State: github.Ptr("open")},
+				{Number: github.Ptr(2), State: github.Ptr("closed"), ClosedAt: &github.Timestamp{Time: closedSince.Add(time.Hour)}},
+			},
+			[]*github.Issue{
+				// updated recently but closed long ago
+				{Number: github.Ptr(3), State: github.Ptr("closed"), ClosedAt: &github.Timestamp{Time: closedSince.Add(-time.Hour)}},
+			},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				added = append(added, r.URL.Path)
+				w.Write(mock.MustMarshal([]*github.Label{}))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				removed = append(removed, r.URL.Path)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.DeleteReposLabelsByOwnerByRepoByName,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deleted = true
+			}),
+		),
+	)
+	changes, err := Run(context.Background(), github.NewClient(httpClient), "owner", "repo", map[string]string{"old": "new"}, Options{ClosedSince: closedSince, DeleteOld: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Change{{Old: "old", New: "new", Action: Relabeled, Issues: []int{1, 2}, Deleted: true}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	if wantAdded := []string{"/repos/owner/repo/issues/1/labels", "/repos/owner/repo/issues/2/labels"}; !reflect.DeepEqual(added, wantAdded) {
+		t.Fatalf("added = %v, want %v", added, wantAdded)
+	}
+	if wantRemoved := []string{"/repos/owner/repo/issues/1/labels/old", "/repos/owner/repo/issues/2/labels/old"}; !reflect.DeepEqual(removed, wantRemoved) {
+		t.Fatalf("removed = %v, want %v", removed, wantRemoved)
+	}
+	if !deleted {
+		t.Fatal("expected the old label definition to be deleted")
+	}
+}
+
+func TestRun_ListsClosedIssuesRegardlessOfUpdates(t *testing.T) {
+	closedSince := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(mock.GetReposLabelsByOwnerByRepoByName, labelHandler("old", "new")),
+		mock.WithRequestMatchHandler(
+			mock.GetReposIssuesByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// since filters by update time, which would skip issues
+				// closed in the window but not touched since
+				if q := r.URL.Query(); q.Has("since") || q.Get("state") != "all" {
+					t.Errorf("unexpected issue filter %s", r.URL.RawQuery)
+				}
+				w.Write(mock.MustMarshal([]*github.Issue{
+					{Number: github.Ptr(2), State: github.Ptr("closed"), ClosedAt: &github.Timestamp{Time: closedSince.Add(time.Hour)}},
+				}))
+			}),
+		),
+	)
+	changes, err := Run(context.Background(), github.NewClient(httpClient), "owner", "repo", map[string]string{"old": "new"}, Options{ClosedSince: closedSince, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []Change{{Old: "old", New: "new", Action: Relabeled, Issues: []int{2}}}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestRun_DryRunChangesNothing(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(mock.GetReposLabelsByOwnerByRepoByName, labelHandler("a", "b", "c")),
+		mock.WithRequestMatch(
+			mock.GetReposIssuesByOwnerByRepo,
+			[]*github.Issue{{Number: github.Ptr(5), State: github.Ptr("open")}},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Error("unexpected label change in a dry run")
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposLabelsByOwnerByRepoByName,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Error("unexpected label rename in a dry run")
+			}),
+		),
+	)
+	changes, err := Run(context.Background(), github.NewClient(httpClient), "owner", "repo", map[string]string{"a": "b", "c": "d"}, Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Change{
+		{Old: "a", New: "b", Action: Relabeled, Issues: []int{5}},
+		{Old: "c", New: "d", Action: Renamed},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+}
@@ -0,0 +1,121 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// serviceAccountDir is where Kubernetes mounts the pod's service account credentials.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// KubeClient is a minimal Kubernetes REST client. The operator only needs to
+// read two resource types and patch one status, which does not justify
+// depending on client-go.
+type KubeClient struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+// InCluster returns a client authenticated as the pod's service account, and
+// the namespace the pod runs in.
+func InCluster() (*KubeClient, string, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, "", fmt.Errorf("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set")
+	}
+	token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read service account token: %w", err)
+	}
+	namespace, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read service account namespace: %w", err)
+	}
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, "", fmt.Errorf("failed to parse service account CA")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &KubeClient{
+		BaseURL: "https://" + net.JoinHostPort(host, port),
+		Token:   strings.TrimSpace(string(token)),
+		HTTP:    &http.Client{Transport: transport},
+	}, strings.TrimSpace(string(namespace)), nil
+}
+
+func (k *KubeClient) do(ctx context.Context, method, path, contentType string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, k.BaseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if k.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.Token)
+	}
+	resp, err := k.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (k *KubeClient) listLabelerConfigs(ctx context.Context, namespace string) ([]LabelerConfig, error) {
+	var list labelerConfigList
+	path := fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s", Group, Version, namespace, Resource)
+	if err := k.do(ctx, http.MethodGet, path, "", nil, &list); err != nil {
+		return nil, fmt.Errorf("failed to list LabelerConfigs: %w", err)
+	}
+	return list.Items, nil
+}
+
+func (k *KubeClient) getSecret(ctx context.Context, namespace, name string) (*secret, error) {
+	var s secret
+	if err := k.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", namespace, name), "", nil, &s); err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s: %w", name, err)
+	}
+	return &s, nil
+}
+
+func (k *KubeClient) patchStatus(ctx context.Context, cfg *LabelerConfig, status LabelerConfigStatus) error {
+	path := fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s/%s/status", Group, Version, cfg.Metadata.Namespace, Resource, cfg.Metadata.Name)
+	patch := map[string]any{"status": status}
+	if err := k.do(ctx, http.MethodPatch, path, "application/merge-patch+json", patch, nil); err != nil {
+		return fmt.Errorf("failed to update LabelerConfig %s status: %w", cfg.Metadata.Name, err)
+	}
+	return nil
+}
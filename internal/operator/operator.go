@@ -0,0 +1,165 @@
+// Package operator reconciles LabelerConfig custom resources into webhook
+// registrations on the repositories they list.
+package operator
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/webhook"
+)
+
+const (
+	defaultTokenKey         = "token"
+	defaultWebhookSecretKey = "webhookSecret"
+)
+
+// Reconciler registers the webhooks declared by LabelerConfigs in one namespace.
+type Reconciler struct {
+	Kube      *KubeClient
+	Namespace string
+	// NewGitHubClient returns a GitHub client authenticated with token.
+	NewGitHubClient func(token string) *github.Client
+	// Now returns the current time, recorded as the last sync time.
+	Now func() time.Time
+
+	// secrets holds a fingerprint of the webhook secret each LabelerConfig
+	// was last reconciled with, as GitHub never returns a webhook's secret
+	// and a rotated one must be pushed to the targets again.
+	secrets map[string][sha256.Size]byte
+}
+
+// Run reconciles every interval until ctx is cancelled. Failures are
+// recorded on each LabelerConfig's status and retried on the next pass.
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.ReconcileAll(ctx); err != nil {
+			log.Printf("reconcile failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileAll reconciles every LabelerConfig in the namespace.
+func (r *Reconciler) ReconcileAll(ctx context.Context) error {
+	configs, err := r.Kube.listLabelerConfigs(ctx, r.Namespace)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range configs {
+		cfg := &configs[i]
+		status := r.reconcile(ctx, cfg)
+		if err := r.Kube.patchStatus(ctx, cfg, status); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reconcile ensures the webhook on every target of cfg and returns the
+// resulting status. One failing target does not stop the others. A target
+// already synced at cfg's generation with the same webhook secret is left
+// alone, and the webhook of a target removed from cfg since the last pass is
+// deleted.
+func (r *Reconciler) reconcile(ctx context.Context, cfg *LabelerConfig) LabelerConfigStatus {
+	status := LabelerConfigStatus{
+		ObservedGeneration: cfg.Metadata.Generation,
+		LastSyncTime:       r.Now().UTC().Format(time.RFC3339),
+	}
+	token, webhookSecret, err := r.credentials(ctx, cfg)
+	if err != nil {
+		status.Error = err.Error()
+		// kept so removed targets are still cleaned up once this is fixed
+		status.Repositories = cfg.Status.Repositories
+		return status
+	}
+	key := cfg.Metadata.Namespace + "/" + cfg.Metadata.Name
+	fingerprint := sha256.Sum256([]byte(webhookSecret))
+	synced := map[string]RepositoryStatus{}
+	if cfg.Status.ObservedGeneration == cfg.Metadata.Generation && r.secrets[key] == fingerprint {
+		for _, repoStatus := range cfg.Status.Repositories {
+			if repoStatus.Error == "" {
+				synced[repoStatus.Name] = repoStatus
+			}
+		}
+	}
+	client := r.NewGitHubClient(token)
+	spec := webhook.Spec{URL: cfg.Spec.WebhookURL, Secret: webhookSecret, Events: cfg.Spec.Events}
+	for _, name := range cfg.Spec.Repositories {
+		if repoStatus, ok := synced[name]; ok {
+			status.Repositories = append(status.Repositories, repoStatus)
+			continue
+		}
+		repoStatus := RepositoryStatus{Name: name}
+		target, err := webhook.ParseTarget(name)
+		if err == nil {
+			var result webhook.Result
+			result, err = webhook.Ensure(ctx, client, target, spec)
+			repoStatus.Result = string(result)
+		}
+		if err != nil {
+			repoStatus.Error = err.Error()
+			log.Printf("%s: %v", key, err)
+		}
+		status.Repositories = append(status.Repositories, repoStatus)
+	}
+	for _, old := range cfg.Status.Repositories {
+		if slices.Contains(cfg.Spec.Repositories, old.Name) {
+			continue
+		}
+		target, err := webhook.ParseTarget(old.Name)
+		if err != nil {
+			continue
+		}
+		if err := webhook.Remove(ctx, client, target, cfg.Spec.WebhookURL); err != nil {
+			// kept in the status so the removal is retried on the next pass
+			status.Repositories = append(status.Repositories, RepositoryStatus{Name: old.Name, Error: err.Error()})
+			log.Printf("%s: %v", key, err)
+		}
+	}
+	if r.secrets == nil {
+		r.secrets = map[string][sha256.Size]byte{}
+	}
+	r.secrets[key] = fingerprint
+	return status
+}
+
+func (r *Reconciler) credentials(ctx context.Context, cfg *LabelerConfig) (string, string, error) {
+	ref := cfg.Spec.SecretRef
+	if ref.Name == "" {
+		return "", "", fmt.Errorf("spec.secretRef.name is required")
+	}
+	s, err := r.Kube.getSecret(ctx, cfg.Metadata.Namespace, ref.Name)
+	if err != nil {
+		return "", "", err
+	}
+	tokenKey, secretKey := ref.TokenKey, ref.WebhookSecretKey
+	if tokenKey == "" {
+		tokenKey = defaultTokenKey
+	}
+	if secretKey == "" {
+		secretKey = defaultWebhookSecretKey
+	}
+	token, webhookSecret := string(s.Data[tokenKey]), string(s.Data[secretKey])
+	if token == "" {
+		return "", "", fmt.Errorf("secret %s has no %q key", ref.Name, tokenKey)
+	}
+	if webhookSecret == "" {
+		return "", "", fmt.Errorf("secret %s has no %q key", ref.Name, secretKey)
+	}
+	return token, webhookSecret, nil
+}
@@ -0,0 +1,207 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/webhook"
+)
+
+func TestReconcileAll(t *testing.T) {
+	var patched LabelerConfigStatus
+	kube := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sa-token" {
+			t.Errorf("expected service account token, got %q", got)
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/apis/labeler.kgateway.dev/v1alpha1/namespaces/labeler/labelerconfigs":
+			json.NewEncoder(w).Encode(labelerConfigList{Items: []LabelerConfig{{
+				Metadata: ObjectMeta{Name: "kgateway", Namespace: "labeler", Generation: 3},
+				Spec: LabelerConfigSpec{
+					WebhookURL:   "https://labeler.example.com/webhook",
+					Repositories: []string{"kgateway-dev/kgateway", "kgateway-dev/docs", "a/b/c"},
+					SecretRef:    SecretRef{Name: "github"},
+				},
+			}}})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/labeler/secrets/github":
+			json.NewEncoder(w).Encode(secret{Data: map[string][]byte{"token": []byte("gh-token"), "webhookSecret": []byte("s3cr3t")}})
+		case r.Method == http.MethodPatch && r.URL.Path == "/apis/labeler.kgateway.dev/v1alpha1/namespaces/labeler/labelerconfigs/kgateway/status":
+			if ct := r.Header.Get("Content-Type"); ct != "application/merge-patch+json" {
+				t.Errorf("expected a merge patch, got %q", ct)
+			}
+			var patch struct {
+				Status LabelerConfigStatus `json:"status"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+				t.Errorf("failed to decode status patch: %v", err)
+			}
+			patched = patch.Status
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer kube.Close()
+
+	var created, edited []string
+	gh := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposHooksByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hooks := []*github.Hook{}
+				if r.URL.Path == "/repos/kgateway-dev/docs/hooks" {
+					hooks = append(hooks, &github.Hook{ID: github.Ptr(int64(9)), Config: &github.HookConfig{URL: github.Ptr("https://labeler.example.com/webhook")}})
+				}
+				json.NewEncoder(w).Encode(hooks)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposHooksByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var hook github.Hook
+				json.NewDecoder(r.Body).Decode(&hook)
+				if hook.GetConfig().GetSecret() != "s3cr3t" || !reflect.DeepEqual(hook.Events, webhook.DefaultEvents) {
+					t.Errorf("unexpected webhook %+v", hook)
+				}
+				created = append(created, r.URL.Path)
+				json.NewEncoder(w).Encode(hook)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposHooksByOwnerByRepoByHookId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				edited = append(edited, r.URL.Path)
+				json.NewEncoder(w).Encode(github.Hook{})
+			}),
+		),
+	)
+
+	r := &Reconciler{
+		Kube:      &KubeClient{BaseURL: kube.URL, Token: "sa-token", HTTP: kube.Client()},
+		Namespace: "labeler",
+		NewGitHubClient: func(token string) *github.Client {
+			if token != "gh-token" {
+				t.Errorf("expected the token from the Secret, got %q", token)
+			}
+			return github.NewClient(gh)
+		},
+		Now: func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+	if err := r.ReconcileAll(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(created, []string{"/repos/kgateway-dev/kgateway/hooks"}) {
+		t.Fatalf("expected the webhook to be created on kgateway-dev/kgateway, got %v", created)
+	}
+	if !reflect.DeepEqual(edited, []string{"/repos/kgateway-dev/docs/hooks/9"}) {
+		t.Fatalf("expected the existing kgateway-dev/docs webhook to be updated, got %v", edited)
+	}
+	want := LabelerConfigStatus{
+		ObservedGeneration: 3,
+		LastSyncTime:       "2026-01-02T03:04:05Z",
+		Repositories: []RepositoryStatus{
+			{Name: "kgateway-dev/kgateway", Result: "created"},
+			{Name: "kgateway-dev/docs", Result: "updated"},
+			{Name: "a/b/c", Error: `invalid repository "a/b/c", expected owner/repo or owner`},
+		},
+	}
+	if !reflect.DeepEqual(patched, want) {
+		t.Fatalf("unexpected status\nwant: %+v\ngot:  %+v", want, patched)
+	}
+}
+
+func TestReconcileAll_SkipsSyncedAndRemovesDropped(t *testing.T) {
+	cfg := LabelerConfig{
+		Metadata: ObjectMeta{Name: "kgateway", Namespace: "labeler", Generation: 4},
+		Spec: LabelerConfigSpec{
+			WebhookURL:   "https://labeler.example.com/webhook",
+			Repositories: []string{"kgateway-dev/kgateway"},
+			SecretRef:    SecretRef{Name: "github"},
+		},
+		Status: LabelerConfigStatus{
+			ObservedGeneration: 3,
+			Repositories: []RepositoryStatus{
+				{Name: "kgateway-dev/kgateway", Result: "created"},
+				{Name: "kgateway-dev/docs", Result: "created"},
+			},
+		},
+	}
+	kube := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/apis/labeler.kgateway.dev/v1alpha1/namespaces/labeler/labelerconfigs":
+			json.NewEncoder(w).Encode(labelerConfigList{Items: []LabelerConfig{cfg}})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/labeler/secrets/github":
+			json.NewEncoder(w).Encode(secret{Data: map[string][]byte{"token": []byte("gh-token"), "webhookSecret": []byte("s3cr3t")}})
+		case r.Method == http.MethodPatch:
+			var patch struct {
+				Status LabelerConfigStatus `json:"status"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+				t.Errorf("failed to decode status patch: %v", err)
+			}
+			cfg.Status = patch.Status
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer kube.Close()
+
+	var edited, deleted []string
+	gh := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposHooksByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode([]*github.Hook{{ID: github.Ptr(int64(9)), Config: &github.HookConfig{URL: github.Ptr("https://labeler.example.com/webhook")}}})
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposHooksByOwnerByRepoByHookId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				edited = append(edited, r.URL.Path)
+				json.NewEncoder(w).Encode(github.Hook{})
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.DeleteReposHooksByOwnerByRepoByHookId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deleted = append(deleted, r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		),
+	)
+
+	r := &Reconciler{
+		Kube:            &KubeClient{BaseURL: kube.URL, Token: "sa-token", HTTP: kube.Client()},
+		Namespace:       "labeler",
+		NewGitHubClient: func(string) *github.Client { return github.NewClient(gh) },
+		Now:             func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+	for range 2 {
+		if err := r.ReconcileAll(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if !reflect.DeepEqual(edited, []string{"/repos/kgateway-dev/kgateway/hooks/9"}) {
+		t.Fatalf("expected the webhook to be updated once, then left alone as synced, got %v", edited)
+	}
+	if !reflect.DeepEqual(deleted, []string{"/repos/kgateway-dev/docs/hooks/9"}) {
+		t.Fatalf("expected the webhook of the removed repository to be deleted, got %v", deleted)
+	}
+	want := []RepositoryStatus{{Name: "kgateway-dev/kgateway", Result: "updated"}}
+	if !reflect.DeepEqual(cfg.Status.Repositories, want) {
+		t.Fatalf("unexpected repository status\nwant: %+v\ngot:  %+v", want, cfg.Status.Repositories)
+	}
+}
@@ -0,0 +1,70 @@
+package operator
+
+const (
+	// Group is the API group of the LabelerConfig custom resource.
+	Group = "labeler.kgateway.dev"
+	// Version is the API version of the LabelerConfig custom resource.
+	Version = "v1alpha1"
+	// Resource is the plural resource name of LabelerConfig.
+	Resource = "labelerconfigs"
+)
+
+// LabelerConfig declares the repositories that should deliver webhooks to a
+// server-mode labeler. See deploy/crd.yaml for the schema.
+type LabelerConfig struct {
+	Metadata ObjectMeta          `json:"metadata"`
+	Spec     LabelerConfigSpec   `json:"spec"`
+	Status   LabelerConfigStatus `json:"status,omitempty"`
+}
+
+// ObjectMeta is the subset of Kubernetes object metadata the operator uses.
+type ObjectMeta struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Generation int64  `json:"generation,omitempty"`
+}
+
+// LabelerConfigSpec is the desired webhook registration.
+type LabelerConfigSpec struct {
+	// WebhookURL is the server-mode endpoint GitHub delivers to.
+	WebhookURL string `json:"webhookURL"`
+	// Repositories lists owner/repo targets, or a bare owner for an org-wide
+	// webhook. The webhook of a target removed from the list is deleted.
+	Repositories []string `json:"repositories"`
+	// Events to subscribe to. Defaults to webhook.DefaultEvents.
+	Events []string `json:"events,omitempty"`
+	// SecretRef names the Secret holding the GitHub token and webhook secret.
+	SecretRef SecretRef `json:"secretRef"`
+}
+
+// SecretRef names a Secret in the LabelerConfig's namespace and its keys.
+type SecretRef struct {
+	Name string `json:"name"`
+	// TokenKey holds a GitHub token allowed to manage webhooks. Defaults to "token".
+	TokenKey string `json:"tokenKey,omitempty"`
+	// WebhookSecretKey holds the secret used to sign payloads. Defaults to "webhookSecret".
+	WebhookSecretKey string `json:"webhookSecretKey,omitempty"`
+}
+
+// LabelerConfigStatus is the observed state of a LabelerConfig.
+type LabelerConfigStatus struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	LastSyncTime       string             `json:"lastSyncTime,omitempty"`
+	Error              string             `json:"error,omitempty"`
+	Repositories       []RepositoryStatus `json:"repositories,omitempty"`
+}
+
+// RepositoryStatus is the outcome of registering the webhook on one target.
+type RepositoryStatus struct {
+	Name   string `json:"name"`
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type labelerConfigList struct {
+	Items []LabelerConfig `json:"items"`
+}
+
+type secret struct {
+	Data map[string][]byte `json:"data"`
+}
@@ -0,0 +1,160 @@
+// Package prlock serializes the labeler's workflow runs on a PR, so runs
+// triggered in quick succession, e.g. by opening and then editing a PR, do
+// not interleave their label changes and make labels flicker. Workflow runs
+// share no state but the PR, so the lock is an in-progress check run on the
+// PR head commit, and runs look for each other's across all the PR's
+// commits, as a push moves the head: check run IDs grow over time, which
+// orders the runs.
+package prlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// CheckRunName is the name of the check runs used as locks.
+const CheckRunName = "pr-kind-labeler lock"
+
+// Lock is a held lock on the runs of a PR.
+type Lock struct {
+	client      *github.Client
+	owner, repo string
+	id          int64
+	// Waited is set when the lock was held by an earlier run first, so the
+	// PR may have changed since the event of this run.
+	Waited bool
+}
+
+// Acquire locks the runs of PR prNum of owner/repo, whose head commit is
+// sha. While an earlier run holds the lock, it polls every poll until
+// timeout, after which the earlier run is assumed to have been cancelled
+// without releasing it. If a later run has started meanwhile, the lock is
+// given up and Acquire returns nil, as that run will process the PR as it is
+// now. On an error the lock check run is completed as cancelled.
+func Acquire(ctx context.Context, client *github.Client, owner, repo string, prNum int, sha string, poll, timeout time.Duration) (*Lock, error) {
+	run, _, err := client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:    CheckRunName,
+		HeadSHA: sha,
+		Status:  github.Ptr("in_progress"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock check run: %w", err)
+	}
+	l := &Lock{client: client, owner: owner, repo: repo, id: run.GetID()}
+	// the lock check run must not stay in progress, or it holds up later
+	// runs until their timeout, so it is completed even when ctx is done
+	abort := func(err error) (*Lock, error) {
+		return nil, errors.Join(err, l.complete(context.WithoutCancel(ctx), "cancelled", "The run failed to acquire the lock."))
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		earlier, later, err := l.contenders(ctx, prNum, sha)
+		if err != nil {
+			return abort(err)
+		}
+		if later {
+			return nil, l.complete(ctx, "skipped", "A later run processes the PR.")
+		}
+		if !earlier || !time.Now().Before(deadline) {
+			return l, nil
+		}
+		l.Waited = true
+		select {
+		case <-ctx.Done():
+			return abort(ctx.Err())
+		case <-time.After(poll):
+		}
+	}
+}
+
+// contenders reports whether earlier or later runs than l hold or wait for
+// the lock on any commit of PR prNum, whose head commit is sha.
+func (l *Lock) contenders(ctx context.Context, prNum int, sha string) (earlier, later bool, err error) {
+	shas, err := l.commits(ctx, prNum)
+	if err != nil {
+		return false, false, err
+	}
+	// the PR's commits may not list a just-pushed head yet
+	if !slices.Contains(shas, sha) {
+		shas = append(shas, sha)
+	}
+	for _, sha := range shas {
+		e, lt, err := l.contendersOn(ctx, sha)
+		if err != nil {
+			return false, false, err
+		}
+		earlier, later = earlier || e, later || lt
+	}
+	return earlier, later, nil
+}
+
+// commits returns the SHAs of the commits of PR prNum.
+func (l *Lock) commits(ctx context.Context, prNum int) ([]string, error) {
+	var shas []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		commits, resp, err := l.client.PullRequests.ListCommits(ctx, l.owner, l.repo, prNum, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list PR commits: %w", err)
+		}
+		for _, c := range commits {
+			shas = append(shas, c.GetSHA())
+		}
+		if resp.NextPage == 0 {
+			return shas, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// contendersOn reports whether earlier or later runs than l hold or wait
+// for the lock on the commit sha.
+func (l *Lock) contendersOn(ctx context.Context, sha string) (earlier, later bool, err error) {
+	opts := &github.ListCheckRunsOptions{
+		CheckName:   github.Ptr(CheckRunName),
+		Status:      github.Ptr("in_progress"),
+		Filter:      github.Ptr("all"),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		runs, resp, err := l.client.Checks.ListCheckRunsForRef(ctx, l.owner, l.repo, sha, opts)
+		if err != nil {
+			return false, false, fmt.Errorf("failed to list lock check runs: %w", err)
+		}
+		for _, run := range runs.CheckRuns {
+			switch id := run.GetID(); {
+			case id < l.id:
+				earlier = true
+			case id > l.id:
+				later = true
+			}
+		}
+		if resp.NextPage == 0 {
+			return earlier, later, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// Release releases the lock.
+func (l *Lock) Release(ctx context.Context) error {
+	return l.complete(ctx, "success", "The run processed the PR.")
+}
+
+func (l *Lock) complete(ctx context.Context, conclusion, summary string) error {
+	_, _, err := l.client.Checks.UpdateCheckRun(ctx, l.owner, l.repo, l.id, github.UpdateCheckRunOptions{
+		Name:       CheckRunName,
+		Status:     github.Ptr("completed"),
+		Conclusion: github.Ptr(conclusion),
+		Output:     &github.CheckRunOutput{Title: github.Ptr(CheckRunName), Summary: github.Ptr(summary)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release lock check run: %w", err)
+	}
+	return nil
+}
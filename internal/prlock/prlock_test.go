@@ -0,0 +1,147 @@
+package prlock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+// newClient returns a client whose lock check run is created with ID 2 on
+// the head commit abc of a PR whose earlier commit is old. It lists the
+// in-progress lock check runs of listed on abc, one list per call, repeating
+// the last one, and those of older on old. Completed runs are recorded in
+// conclusions.
+func newClient(t *testing.T, listed [][]int64, older []int64, conclusions *[]string) *github.Client {
+	t.Helper()
+	var mu sync.Mutex
+	calls := 0
+	return github.NewClient(mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.PostReposCheckRunsByOwnerByRepo, github.CheckRun{ID: github.Ptr[int64](2)}),
+		mock.WithRequestMatchHandler(
+			mock.GetReposPullsCommitsByOwnerByRepoByPullNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(mock.MustMarshal([]*github.RepositoryCommit{{SHA: github.Ptr("old")}, {SHA: github.Ptr("abc")}}))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposCommitsCheckRunsByOwnerByRepoByRef,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ids := older
+				if path.Base(path.Dir(r.URL.Path)) == "abc" {
+					mu.Lock()
+					ids = listed[min(calls, len(listed)-1)]
+					calls++
+					mu.Unlock()
+				}
+				runs := &github.ListCheckRunsResults{}
+				for _, id := range ids {
+					runs.CheckRuns = append(runs.CheckRuns, &github.CheckRun{ID: github.Ptr(id)})
+				}
+				w.Write(mock.MustMarshal(runs))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposCheckRunsByOwnerByRepoByCheckRunId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var opts github.UpdateCheckRunOptions
+				if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+					t.Errorf("failed to decode check run update: %v", err)
+				}
+				*conclusions = append(*conclusions, opts.GetConclusion())
+				w.Write(mock.MustMarshal(github.CheckRun{}))
+			}),
+		),
+	))
+}
+
+func TestAcquire(t *testing.T) {
+	tests := []struct {
+		name            string
+		listed          [][]int64
+		older           []int64
+		wantLock        bool
+		wantWaited      bool
+		wantConclusions []string
+	}{
+		{
+			name:            "uncontended",
+			listed:          [][]int64{{2}},
+			wantLock:        true,
+			wantConclusions: []string{"success"},
+		},
+		{
+			name:            "waits for an earlier run",
+			listed:          [][]int64{{1, 2}, {2}},
+			wantLock:        true,
+			wantWaited:      true,
+			wantConclusions: []string{"success"},
+		},
+		{
+			name:            "waits for a run on an earlier commit",
+			listed:          [][]int64{{2}},
+			older:           []int64{1},
+			wantLock:        true,
+			wantWaited:      true,
+			wantConclusions: []string{"success"},
+		},
+		{
+			name:            "superseded by a later run",
+			listed:          [][]int64{{1, 2, 3}},
+			wantConclusions: []string{"skipped"},
+		},
+		{
+			name:            "earlier run never releases",
+			listed:          [][]int64{{1, 2}},
+			wantLock:        true,
+			wantWaited:      true,
+			wantConclusions: []string{"success"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conclusions []string
+			client := newClient(t, tt.listed, tt.older, &conclusions)
+			l, err := Acquire(context.Background(), client, "owner", "repo", 1, "abc", time.Millisecond, 50*time.Millisecond)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (l != nil) != tt.wantLock {
+				t.Fatalf("Acquire() = %v, want lock %v", l, tt.wantLock)
+			}
+			if l != nil {
+				if l.Waited != tt.wantWaited {
+					t.Errorf("Waited = %v, want %v", l.Waited, tt.wantWaited)
+				}
+				if err := l.Release(context.Background()); err != nil {
+					t.Fatalf("failed to release: %v", err)
+				}
+			}
+			if !slices.Equal(conclusions, tt.wantConclusions) {
+				t.Errorf("conclusions = %v, want %v", conclusions, tt.wantConclusions)
+			}
+		})
+	}
+}
+
+func TestAcquireCancelled(t *testing.T) {
+	var conclusions []string
+	client := newClient(t, [][]int64{{1, 2}}, nil, &conclusions)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	l, err := Acquire(ctx, client, "owner", "repo", 1, "abc", time.Millisecond, time.Hour)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline error, got lock %v and error %v", l, err)
+	}
+	if !slices.Equal(conclusions, []string{"cancelled"}) {
+		t.Errorf("conclusions = %v, want the lock check run cancelled", conclusions)
+	}
+}
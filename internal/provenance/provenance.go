@@ -0,0 +1,157 @@
+// Package provenance signs and verifies records of the label decisions the
+// labeler makes, for teams that need audit-grade evidence of what merge
+// gating automation did and why.
+//
+// Records are wrapped in DSSE envelopes (https://github.com/secure-systems-lab/dsse)
+// signed with an ed25519 key, so they can also be checked with generic DSSE
+// tooling.
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// PayloadType identifies decision records inside DSSE envelopes.
+const PayloadType = "application/vnd.kgateway.pr-kind-labeler.decision+json"
+
+// Record is the decision the labeler made for one PR.
+type Record struct {
+	Repository     string   `json:"repository"`
+	PullRequest    int      `json:"pullRequest"`
+	BodySHA256     string   `json:"bodySHA256"`
+	Labels         []string `json:"labels"`
+	LabelsAdded    []string `json:"labelsAdded"`
+	LabelsRemoved  []string `json:"labelsRemoved"`
+	Valid          bool     `json:"valid"`
+	Timestamp      string   `json:"timestamp"`
+	LabelerVersion string   `json:"labelerVersion"`
+}
+
+// BodySHA256 returns the hex sha256 of a PR body, as recorded in Record.
+func BodySHA256(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// Envelope is a DSSE envelope.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     []byte      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a DSSE signature.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"`
+}
+
+// Sign wraps rec in an envelope signed with key.
+func Sign(rec Record, key ed25519.PrivateKey) (*Envelope, error) {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode decision record: %w", err)
+	}
+	keyID, err := KeyID(key.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures:  []Signature{{KeyID: keyID, Sig: ed25519.Sign(key, pae(PayloadType, payload))}},
+	}, nil
+}
+
+// Verify checks that env is signed by pub and returns the record it holds.
+func Verify(env *Envelope, pub ed25519.PublicKey) (*Record, error) {
+	if env.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", env.PayloadType)
+	}
+	keyID, err := KeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+	msg := pae(env.PayloadType, env.Payload)
+	for _, sig := range env.Signatures {
+		if sig.KeyID != keyID || !ed25519.Verify(pub, msg, sig.Sig) {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(env.Payload, &rec); err != nil {
+			return nil, fmt.Errorf("failed to decode decision record: %w", err)
+		}
+		return &rec, nil
+	}
+	return nil, errors.New("no valid signature for the given key")
+}
+
+// KeyID returns the hex sha256 of the PKIX encoding of pub.
+func KeyID(pub ed25519.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// pae is the DSSE pre-authentication encoding of a payload.
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS #8 ed25519 private key.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is %T, expected ed25519", key)
+	}
+	return edKey, nil
+}
+
+// ParsePublicKey parses a PEM encoded PKIX ed25519 public key.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is %T, expected ed25519", key)
+	}
+	return edKey, nil
+}
+
+// MarshalKeyPair PEM encodes an ed25519 key pair.
+func MarshalKeyPair(pub ed25519.PublicKey, priv ed25519.PrivateKey) (pubPEM, privPEM []byte, err error) {
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), nil
+}
@@ -0,0 +1,102 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"reflect"
+	"testing"
+)
+
+func generateKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return pub, priv
+}
+
+func testRecord() Record {
+	return Record{
+		Repository:     "owner/repo",
+		PullRequest:    1,
+		BodySHA256:     BodySHA256("/kind fix"),
+		Labels:         []string{"kind/fix", "release-note"},
+		LabelsAdded:    []string{"kind/fix"},
+		LabelsRemoved:  []string{},
+		Valid:          true,
+		Timestamp:      "2025-01-01T00:00:00Z",
+		LabelerVersion: "v1.2.3",
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	pub, priv := generateKey(t)
+	rec := testRecord()
+
+	env, err := Sign(rec, priv)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	got, err := Verify(env, pub)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !reflect.DeepEqual(*got, rec) {
+		t.Fatalf("record mismatch\nwant: %+v\ngot:  %+v", rec, *got)
+	}
+}
+
+func TestVerify_WrongKey(t *testing.T) {
+	_, priv := generateKey(t)
+	other, _ := generateKey(t)
+
+	env, err := Sign(testRecord(), priv)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := Verify(env, other); err == nil {
+		t.Fatal("expected verification with the wrong key to fail")
+	}
+}
+
+func TestVerify_TamperedPayload(t *testing.T) {
+	pub, priv := generateKey(t)
+	rec := testRecord()
+	env, err := Sign(rec, priv)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	rec.Valid = false
+	tampered, err := Sign(rec, priv)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	env.Payload = tampered.Payload
+	if _, err := Verify(env, pub); err == nil {
+		t.Fatal("expected verification of a tampered payload to fail")
+	}
+}
+
+func TestKeyPairRoundTrip(t *testing.T) {
+	pub, priv := generateKey(t)
+	pubPEM, privPEM, err := MarshalKeyPair(pub, priv)
+	if err != nil {
+		t.Fatalf("MarshalKeyPair: %v", err)
+	}
+	gotPub, err := ParsePublicKey(pubPEM)
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	gotPriv, err := ParsePrivateKey(privPEM)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	if !gotPub.Equal(pub) || !gotPriv.Equal(priv) {
+		t.Fatal("parsed key pair does not match the original")
+	}
+	if _, err := ParsePrivateKey(pubPEM); err == nil {
+		t.Fatal("expected parsing a public key as a private key to fail")
+	}
+}
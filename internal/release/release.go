@@ -0,0 +1,139 @@
+// Package release finds the PRs a release tag ships and publishes their
+// changelog on the tag's GitHub release, closing the loop from the release
+// notes of PRs to published notes.
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// Markers delimit the changelog in a release body, so the rest of the body,
+// e.g. highlights written by hand, is kept when the changelog is replaced.
+const (
+	startMarker = "<!-- pr-kind-labeler-changelog -->"
+	endMarker   = "<!-- /pr-kind-labeler-changelog -->"
+)
+
+// prRefRE captures the PR a commit merged: the (#123) GitHub appends to the
+// subject of squash merges, or the number of a "Merge pull request #123"
+// merge commit.
+var prRefRE = regexp.MustCompile(`^(?:Merge pull request #(\d+) |.*\(#(\d+)\)\s*$)`)
+
+// Post is a created or updated release.
+type Post struct {
+	URL string `json:"url"`
+	// Created is set when the release was created rather than updated.
+	Created bool `json:"created"`
+}
+
+// PreviousTag returns the tag of the release published before the release of
+// tag, or the latest published release if tag has none yet, e.g. when a tag
+// is pushed before its release is drafted. It returns "" if there is none.
+func PreviousTag(ctx context.Context, client *github.Client, owner, repo, tag string) (string, error) {
+	var releases []*github.RepositoryRelease
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to list releases: %w", err)
+		}
+		releases = append(releases, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	// releases are listed newest first
+	i := slices.IndexFunc(releases, func(r *github.RepositoryRelease) bool { return r.GetTagName() == tag })
+	for _, r := range releases[i+1:] {
+		if !r.GetDraft() {
+			return r.GetTagName(), nil
+		}
+	}
+	return "", nil
+}
+
+// PRs returns the numbers of the PRs merged between the base and head refs,
+// in merge order. PRs are found from the subjects of the commits GitHub
+// creates when merging or squash merging them; rebase-merged PRs leave no
+// such commit and are not found.
+func PRs(ctx context.Context, client *github.Client, owner, repo, base, head string) ([]int, error) {
+	var prs []int
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		cmp, resp, err := client.Repositories.CompareCommits(ctx, owner, repo, base, head, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s...%s: %w", base, head, err)
+		}
+		for _, c := range cmp.Commits {
+			subject, _, _ := strings.Cut(c.GetCommit().GetMessage(), "\n")
+			m := prRefRE.FindStringSubmatch(subject)
+			if m == nil {
+				continue
+			}
+			n, _ := strconv.Atoi(m[1] + m[2])
+			if !slices.Contains(prs, n) {
+				prs = append(prs, n)
+			}
+		}
+		if resp.NextPage == 0 {
+			return prs, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// Publish sets the changelog of the release of tag to notes, replacing the
+// changelog of a previous run but keeping the rest of the release body. A
+// tag without a release gets a draft release, for maintainers to review and
+// publish.
+func Publish(ctx context.Context, client *github.Client, owner, repo, tag, notes string) (*Post, error) {
+	section := startMarker + "\n" + notes + endMarker + "\n"
+	r, resp, err := client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+	switch {
+	case err == nil:
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		created, _, err := client.Repositories.CreateRelease(ctx, owner, repo, &github.RepositoryRelease{
+			TagName: github.Ptr(tag),
+			Name:    github.Ptr(tag),
+			Body:    github.Ptr(section),
+			Draft:   github.Ptr(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create release %s: %w", tag, err)
+		}
+		return &Post{URL: created.GetHTMLURL(), Created: true}, nil
+	default:
+		return nil, fmt.Errorf("failed to get release %s: %w", tag, err)
+	}
+	updated, _, err := client.Repositories.EditRelease(ctx, owner, repo, r.GetID(), &github.RepositoryRelease{
+		Body: github.Ptr(replaceSection(r.GetBody(), section)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update release %s: %w", tag, err)
+	}
+	return &Post{URL: updated.GetHTMLURL()}, nil
+}
+
+// replaceSection replaces the changelog section of body with section, or
+// appends section if body has none.
+func replaceSection(body, section string) string {
+	start := strings.Index(body, startMarker)
+	end := strings.Index(body, endMarker)
+	if start < 0 || end < start {
+		if body = strings.TrimRight(body, "\n"); body != "" {
+			body += "\n\n"
+		}
+		return body + section
+	}
+	rest := strings.TrimPrefix(body[end+len(endMarker):], "\n")
+	return body[:start] + section + rest
+}
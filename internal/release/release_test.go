@@ -0,0 +1,144 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestPreviousTag(t *testing.T) {
+	releases := []*github.RepositoryRelease{
+		{TagName: github.Ptr("v2.2.0"), Draft: github.Ptr(true)},
+		{TagName: github.Ptr("v2.1.0")},
+		{TagName: github.Ptr("v2.0.1"), Draft: github.Ptr(true)},
+		{TagName: github.Ptr("v2.0.0")},
+	}
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "v2.2.0", want: "v2.1.0"},
+		{tag: "v2.1.0", want: "v2.0.0"},
+		{tag: "v2.3.0", want: "v2.1.0"},
+		{tag: "v2.0.0", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			client := github.NewClient(mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposReleasesByOwnerByRepo, releases),
+			))
+			got, err := PreviousTag(context.Background(), client, "owner", "repo", tt.tag)
+			if err != nil {
+				t.Fatalf("PreviousTag() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PreviousTag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPRs(t *testing.T) {
+	commit := func(message string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{Commit: &github.Commit{Message: github.Ptr(message)}}
+	}
+	client := github.NewClient(mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposCompareByOwnerByRepoByBasehead, &github.CommitsComparison{
+			Commits: []*github.RepositoryCommit{
+				commit("Fix the route status (#12)\n\nSigned-off-by: someone"),
+				commit("Merge pull request #15 from fork/branch\n\nAdd a listener"),
+				commit("Bump the version"),
+				commit("Refer to #3 in the docs"),
+				commit(`Revert "Fix the route status (#12)" (#16)`),
+			},
+		}),
+	))
+	got, err := PRs(context.Background(), client, "owner", "repo", "v2.0.0", "v2.1.0")
+	if err != nil {
+		t.Fatalf("PRs() error = %v", err)
+	}
+	if want := []int{12, 15, 16}; !slices.Equal(got, want) {
+		t.Errorf("PRs() = %v, want %v", got, want)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	t.Run("creates a draft release", func(t *testing.T) {
+		var created github.RepositoryRelease
+		client := github.NewClient(mock.NewMockedHTTPClient(
+			mock.WithRequestMatchHandler(mock.GetReposReleasesTagsByOwnerByRepoByTag, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusNotFound, "Not Found")
+			})),
+			mock.WithRequestMatchHandler(mock.PostReposReleasesByOwnerByRepo, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+					t.Fatalf("invalid release: %v", err)
+				}
+				w.Write(mock.MustMarshal(&github.RepositoryRelease{HTMLURL: github.Ptr("https://github.com/owner/repo/releases/tag/v2.1.0")}))
+			})),
+		))
+		post, err := Publish(context.Background(), client, "owner", "repo", "v2.1.0", "### Bug Fixes\n\n- Fix (#12)\n")
+		if err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		if !post.Created || post.URL != "https://github.com/owner/repo/releases/tag/v2.1.0" {
+			t.Errorf("Publish() = %+v, want a created release", post)
+		}
+		if !created.GetDraft() || created.GetTagName() != "v2.1.0" {
+			t.Errorf("expected a draft release of v2.1.0, got %+v", created)
+		}
+		if want := startMarker + "\n### Bug Fixes\n\n- Fix (#12)\n" + endMarker + "\n"; created.GetBody() != want {
+			t.Errorf("body = %q, want %q", created.GetBody(), want)
+		}
+	})
+
+	t.Run("updates the changelog of a release", func(t *testing.T) {
+		var edited github.RepositoryRelease
+		client := github.NewClient(mock.NewMockedHTTPClient(
+			mock.WithRequestMatch(mock.GetReposReleasesTagsByOwnerByRepoByTag, &github.RepositoryRelease{
+				ID:   github.Ptr(int64(7)),
+				Body: github.Ptr("Highlights\n\n" + startMarker + "\nold\n" + endMarker + "\nThanks!\n"),
+			}),
+			mock.WithRequestMatchHandler(mock.PatchReposReleasesByOwnerByRepoByReleaseId, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&edited); err != nil {
+					t.Fatalf("invalid release: %v", err)
+				}
+				w.Write(mock.MustMarshal(&github.RepositoryRelease{HTMLURL: github.Ptr("https://github.com/owner/repo/releases/tag/v2.1.0")}))
+			})),
+		))
+		post, err := Publish(context.Background(), client, "owner", "repo", "v2.1.0", "new\n")
+		if err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		if post.Created {
+			t.Errorf("Publish() = %+v, want an updated release", post)
+		}
+		if want := "Highlights\n\n" + startMarker + "\nnew\n" + endMarker + "\nThanks!\n"; edited.GetBody() != want {
+			t.Errorf("body = %q, want %q", edited.GetBody(), want)
+		}
+	})
+}
+
+func TestReplaceSection(t *testing.T) {
+	section := startMarker + "\nnotes\n" + endMarker + "\n"
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "empty body", body: "", want: section},
+		{name: "appends to a body without changelog", body: "Highlights\n", want: "Highlights\n\n" + section},
+		{name: "replaces the changelog", body: "a\n" + startMarker + "\nold\n" + endMarker + "\nb\n", want: "a\n" + section + "b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceSection(tt.body, section); got != tt.want {
+				t.Errorf("replaceSection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
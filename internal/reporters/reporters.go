@@ -0,0 +1,108 @@
+// Package reporters implements labeler.Reporter for the channels the labeler
+// reports to besides the PR itself: the job summary of a GitHub Actions run
+// and chat webhooks.
+package reporters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/ghaction"
+)
+
+// StepSummary adds reports to the job summary of the GitHub Actions run.
+type StepSummary struct {
+	Action *ghaction.Action
+}
+
+// Name implements labeler.Reporter.
+func (s *StepSummary) Name() string { return labeler.ReporterSummary }
+
+// Report implements labeler.Reporter.
+func (s *StepSummary) Report(ctx context.Context, r *labeler.Report) error {
+	return s.Action.AppendSummary("## PR Kind Labeler\n\n" + r.Summary + labelChangesTable(r))
+}
+
+// labelChangesTable renders the label changes of r as a markdown table, or
+// returns "" if it changes none.
+func labelChangesTable(r *labeler.Report) string {
+	if len(r.LabelsAdded)+len(r.LabelsRemoved) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n| Label | Change |\n| --- | --- |\n")
+	for _, label := range r.LabelsAdded {
+		fmt.Fprintf(&sb, "| `%s` | added |\n", label)
+	}
+	for _, label := range r.LabelsRemoved {
+		fmt.Fprintf(&sb, "| `%s` | removed |\n", label)
+	}
+	return sb.String()
+}
+
+// Slack posts reports to a Slack incoming webhook, e.g. of a channel where
+// maintainers follow PRs. Wrap it with labeler.Deduplicate so a PR edited
+// without changing its outcome is not posted again.
+type Slack struct {
+	URL  string
+	HTTP *http.Client
+}
+
+// Name implements labeler.Reporter.
+func (s *Slack) Name() string { return labeler.ReporterSlack }
+
+// Report implements labeler.Reporter.
+func (s *Slack) Report(ctx context.Context, r *labeler.Report) error {
+	payload, err := json.Marshal(map[string]string{"text": slackText(r)})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// slackText renders r in Slack's mrkdwn.
+func slackText(r *labeler.Report) string {
+	var sb strings.Builder
+	link := fmt.Sprintf("<https://github.com/%s/%s/pull/%d|%s>", r.Owner, r.Repo, r.Number, r.PR())
+	if r.Valid {
+		fmt.Fprintf(&sb, ":white_check_mark: %s passed validation.", link)
+	} else {
+		fmt.Fprintf(&sb, ":x: %s: %s", link, r.Title)
+		for _, p := range r.Problems {
+			sb.WriteString("\n• " + strings.ReplaceAll(p, "\n", " "))
+		}
+	}
+	var changes []string
+	for _, label := range r.LabelsAdded {
+		changes = append(changes, "+`"+label+"`")
+	}
+	for _, label := range r.LabelsRemoved {
+		changes = append(changes, "-`"+label+"`")
+	}
+	if len(changes) > 0 {
+		sb.WriteString("\nLabels: " + strings.Join(changes, " "))
+	}
+	return sb.String()
+}
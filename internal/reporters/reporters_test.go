@@ -0,0 +1,79 @@
+package reporters
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/ghaction"
+)
+
+var failing = &labeler.Report{
+	Owner:       "owner",
+	Repo:        "repo",
+	Number:      7,
+	Conclusion:  "failure",
+	Title:       "1 problem(s) with the PR description",
+	Summary:     "Please update the PR description to fix the following:\n\n- no /kind\n",
+	Problems:    []string{"no /kind"},
+	LabelsAdded: []string{"do-not-merge/kind-invalid"},
+}
+
+func TestStepSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	action := &ghaction.Action{Getenv: func(key string) string {
+		if key == "GITHUB_STEP_SUMMARY" {
+			return path
+		}
+		return ""
+	}}
+	if err := (&StepSummary{Action: action}).Report(context.Background(), failing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"## PR Kind Labeler", "- no /kind", "| `do-not-merge/kind-invalid` | added |"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("summary does not contain %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestSlack(t *testing.T) {
+	var text string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		text = payload.Text
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	slack := &Slack{URL: srv.URL, HTTP: srv.Client()}
+	if err := slack.Report(context.Background(), failing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"<https://github.com/owner/repo/pull/7|owner/repo#7>", "• no /kind", "+`do-not-merge/kind-invalid`"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("message does not contain %q:\n%s", want, text)
+		}
+	}
+
+	status = http.StatusNotFound
+	if err := slack.Report(context.Background(), failing); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected the webhook failure, got %v", err)
+	}
+}
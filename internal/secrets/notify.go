@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WebhookNotifier posts possible-secret alerts to an incoming webhook, such as
+// a Slack or Microsoft Teams channel only maintainers can read. The message
+// names the PR and the kinds of credential found, never the credential.
+type WebhookNotifier struct {
+	URL  string
+	HTTP *http.Client
+}
+
+// NotifySecret implements labeler.SecretNotifier.
+func (n *WebhookNotifier) NotifySecret(ctx context.Context, owner, repo string, prNum int, kinds []string) error {
+	payload, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("Possible credential (%s) in the body of https://github.com/%s/%s/pull/%d. Rotate it and remove it from the PR body and its edit history.",
+			strings.Join(kinds, ", "), owner, repo, prNum),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := n.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Package secrets recognizes credentials that are commonly pasted into PR
+// bodies by accident, so the labeler never repeats them in its output.
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Placeholder replaces every credential removed by Redact.
+const Placeholder = "[REDACTED]"
+
+// pattern is a well-known credential format.
+type pattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// patterns match well-known credential formats. They are deliberately
+// specific so ordinary PR text is left alone.
+var patterns = []pattern{
+	// GitHub personal access, OAuth, user-to-server, server-to-server and refresh tokens.
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,255}\b`)},
+	// GitHub fine-grained personal access tokens.
+	{"GitHub token", regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,255}\b`)},
+	{"AWS access key ID", regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`)},
+	// AWS secret access keys are only recognizable next to their name.
+	{"AWS secret access key", regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}\b`)},
+	// Slack bot, user and app tokens.
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"private key", regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// Redact replaces every credential in s with Placeholder.
+func Redact(s string) string {
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, Placeholder)
+	}
+	return s
+}
+
+// candidateRE matches runs of characters that generated tokens are made of.
+// Slashes are excluded so URL paths are not mistaken for tokens.
+var candidateRE = regexp.MustCompile(`[A-Za-z0-9+_=-]{32,}`)
+
+const (
+	// highEntropyName names findings that match no known format.
+	highEntropyName = "high-entropy string"
+	// minEntropy is the Shannon entropy, in bits per character, above which a
+	// candidate is treated as random. Hex digests such as commit SHAs stay
+	// below it, since 16 symbols carry at most 4 bits.
+	minEntropy = 4.3
+)
+
+// Find returns the sorted, de-duplicated names of the kinds of credential
+// found in s: known token formats, and long random-looking strings. The
+// credentials themselves are never returned, so the result is safe to log.
+func Find(s string) []string {
+	found := map[string]bool{}
+	for _, p := range patterns {
+		if p.re.MatchString(s) {
+			found[p.name] = true
+		}
+	}
+	for _, candidate := range candidateRE.FindAllString(Redact(s), -1) {
+		if looksRandom(candidate) {
+			found[highEntropyName] = true
+			break
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// looksRandom reports whether s mixes upper case, lower case and digits and
+// has high entropy, as generated secrets do and identifiers rarely do.
+func looksRandom(s string) bool {
+	hasUpper := strings.IndexFunc(s, unicode.IsUpper) >= 0
+	hasLower := strings.IndexFunc(s, unicode.IsLower) >= 0
+	hasDigit := strings.IndexFunc(s, unicode.IsDigit) >= 0
+	return hasUpper && hasLower && hasDigit && entropy(s) >= minEntropy
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
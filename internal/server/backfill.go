@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+// maxRateLimitWait is the longest a backfill waits for a rate limit to reset
+// before failing the PR instead.
+const maxRateLimitWait = 15 * time.Minute
+
+// Backfilled is the outcome of backfilling a PR.
+type Backfilled struct {
+	// PR is the PR as owner/repo#number.
+	PR     string `json:"pr"`
+	Number int    `json:"number"`
+	// Added and Removed are the labels added and removed, or that would be
+	// without apply.
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// Error is the failure, other than validation, that stopped processing.
+	Error string `json:"error,omitempty"`
+}
+
+// Relabeled reports whether the PR's labels were changed.
+func (b *Backfilled) Relabeled() bool {
+	return len(b.Added)+len(b.Removed) > 0
+}
+
+// Backfill processes every open PR of owner/repo, e.g. to migrate them after
+// kinds were renamed, with workers processing PRs concurrently. Runs share
+// the tenant's rate limit with webhooks, and a run that hits GitHub's rate
+// limit waits for it to reset and is retried once. Unless apply is set,
+// nothing is changed on GitHub. The outcomes are returned by PR number; only
+// failures to list the PRs are returned as errors.
+func (s *Server) Backfill(ctx context.Context, owner, repo string, workers int, apply bool) ([]*Backfilled, error) {
+	var prs []*github.PullRequest
+	opts := &github.PullRequestListOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := s.client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list open PRs: %w", err)
+		}
+		prs = append(prs, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	results := make([]*Backfilled, len(prs))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = s.backfillPR(ctx, owner, repo, prs[i], apply)
+			}
+		}()
+	}
+	for i := range prs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Number < results[j].Number })
+	return results, nil
+}
+
+// backfillPR processes pr, retrying once after a rate limit resets.
+func (s *Server) backfillPR(ctx context.Context, owner, repo string, pr *github.PullRequest, apply bool) *Backfilled {
+	e := &event.PullRequest{Owner: owner, Repo: repo, Number: pr.GetNumber()}
+	event.FromGitHubPullRequest(e, pr)
+	b := &Backfilled{PR: e.String(), Number: e.Number}
+	for attempt := 0; ; attempt++ {
+		if err := s.tenants.limiter(tenantKey(e)).Wait(ctx); err != nil {
+			b.Error = err.Error()
+			return b
+		}
+		d, err := s.run(ctx, e, apply)
+		_, operational := labeler.Partition(err)
+		if len(operational) == 0 {
+			if d != nil {
+				b.Added, b.Removed = d.LabelsToAdd, d.LabelsToRemove
+			}
+			return b
+		}
+		err = errors.Join(operational...)
+		wait, limited := rateLimitReset(err)
+		if !limited || attempt > 0 || wait > maxRateLimitWait {
+			b.Error = err.Error()
+			return b
+		}
+		select {
+		case <-ctx.Done():
+			b.Error = ctx.Err().Error()
+			return b
+		case <-time.After(wait):
+		}
+	}
+}
+
+// rateLimitReset returns how long until the GitHub rate limit that failed
+// err resets, and whether err is a rate limit failure.
+func rateLimitReset(err error) (time.Duration, bool) {
+	var primary *github.RateLimitError
+	if errors.As(err, &primary) {
+		return max(time.Until(primary.Rate.Reset.Time), 0), true
+	}
+	var secondary *github.AbuseRateLimitError
+	if errors.As(err, &secondary) {
+		if after := secondary.GetRetryAfter(); after > 0 {
+			return after, true
+		}
+		return time.Minute, true
+	}
+	return 0, false
+}
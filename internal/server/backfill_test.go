@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestBackfill(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	body := "# Description\nFix.\n/kind fix\n```release-note\nFixed a crash.\n```"
+	pr := func(number int) *github.PullRequest {
+		return &github.PullRequest{Number: github.Ptr(number), State: github.Ptr("open"), Body: github.Ptr(body)}
+	}
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchPages(
+			mock.GetReposPullsByOwnerByRepo,
+			[]*github.PullRequest{pr(3), pr(1)},
+			[]*github.PullRequest{pr(2)},
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				current := []*github.Label{}
+				// PR 1 is labeled already
+				if strings.HasSuffix(r.URL.Path, "/issues/1/labels") {
+					current = append(current, &github.Label{Name: github.Ptr("kind/fix")}, &github.Label{Name: github.Ptr(labels.ReleaseNoteLabel)})
+				}
+				json.NewEncoder(w).Encode(current)
+			}),
+		),
+	)
+	s := New(cfg, github.NewClient(httpClient), nil)
+
+	results, err := s.Backfill(context.Background(), "owner", "repo", 2, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		if r.Error != "" {
+			t.Fatalf("unexpected failure of %s: %s", r.PR, r.Error)
+		}
+		got = append(got, r.PR)
+		if relabeled := r.Relabeled(); relabeled != (r.Number != 1) {
+			t.Errorf("%s relabeled = %v, added %v, removed %v", r.PR, relabeled, r.Added, r.Removed)
+		}
+	}
+	want := []string{"owner/repo#1", "owner/repo#2", "owner/repo#3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("backfilled %v, want %v", got, want)
+	}
+}
+
+func TestRateLimitReset(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+	tests := []struct {
+		name        string
+		err         error
+		min, max    time.Duration
+		wantLimited bool
+	}{
+		{
+			name:        "primary",
+			err:         &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: reset}}},
+			min:         59 * time.Minute,
+			max:         time.Hour,
+			wantLimited: true,
+		},
+		{
+			name:        "secondary with retry after",
+			err:         &github.AbuseRateLimitError{RetryAfter: github.Ptr(30 * time.Second)},
+			min:         30 * time.Second,
+			max:         30 * time.Second,
+			wantLimited: true,
+		},
+		{
+			name:        "secondary",
+			err:         &github.AbuseRateLimitError{},
+			min:         time.Minute,
+			max:         time.Minute,
+			wantLimited: true,
+		},
+		{
+			name: "other",
+			err:  context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, limited := rateLimitReset(tt.err)
+			if limited != tt.wantLimited || wait < tt.min || wait > tt.max {
+				t.Fatalf("rateLimitReset() = %v, %v, want %v..%v, %v", wait, limited, tt.min, tt.max, tt.wantLimited)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/teams"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/config"
+)
+
+// Config configures server mode. It mirrors the GitHub Action inputs and is
+// loaded from the YAML file passed to `serve --config`. Credentials are not
+// part of the config; they are read from the environment so they can come
+// from a Kubernetes Secret.
+type Config struct {
+	// Config holds the kinds and label names a repository defines in
+	// RepoConfigPath; they are set at the top level of the file.
+	config.Config
+	// EnforceDescription enforces that the Description section is filled out. Defaults to true.
+	EnforceDescription *bool `json:"enforceDescription,omitempty"`
+	// EnforceReleaseNoteQuality enforces naive publication-ready release note checks.
+	EnforceReleaseNoteQuality bool `json:"enforceReleaseNoteQuality,omitempty"`
+	// EnforceChangelogKindExclusivity enforces at most one changelog kind per PR.
+	EnforceChangelogKindExclusivity bool `json:"enforceChangelogKindExclusivity,omitempty"`
+	// Mode controls how validation failures are handled. Defaults to strict.
+	Mode labeler.Mode `json:"mode,omitempty"`
+	// RefetchPR fetches the PR of every event from the API instead of
+	// trusting the payload, whose body can predate the edit it reports.
+	RefetchPR bool `json:"refetchPR,omitempty"`
+	// KindPrefixes introduce kind commands besides /kind, e.g. "> /kind" or
+	// "#kind:".
+	KindPrefixes []string `json:"kindPrefixes,omitempty"`
+	// ReleaseNoteFences are fence names also accepted for the release-note
+	// block, e.g. releasenote or changelog.
+	ReleaseNoteFences []string `json:"releaseNoteFences,omitempty"`
+	// MaxReleaseNoteLength fails release notes longer than this many
+	// characters. 0 disables the limit.
+	MaxReleaseNoteLength int `json:"maxReleaseNoteLength,omitempty"`
+	// CheckLinks warns about URLs in the release note that do not resolve.
+	CheckLinks bool `json:"checkLinks,omitempty"`
+	// CheckLinksTimeoutSeconds is how long CheckLinks waits for each URL.
+	// Defaults to 5.
+	CheckLinksTimeoutSeconds int `json:"checkLinksTimeoutSeconds,omitempty"`
+	// CommandNamespace namespaces kind commands, e.g. kgateway for
+	// "/kgateway kind feature".
+	CommandNamespace string `json:"commandNamespace,omitempty"`
+	// KindMilestones maps kinds to the milestone PRs of that kind default to.
+	KindMilestones map[string]string `json:"kindMilestones,omitempty"`
+	// TriageAssignees is the rotation assigned when a do-not-merge/* label is applied.
+	TriageAssignees []string `json:"triageAssignees,omitempty"`
+	// DetectSecrets labels PRs whose body appears to contain a credential.
+	DetectSecrets bool `json:"detectSecrets,omitempty"`
+	// SpamEmptyDiff labels PRs that change no files as likely spam.
+	SpamEmptyDiff bool `json:"spamEmptyDiff,omitempty"`
+	// SpamMinBodyLength labels PRs without a /kind whose body is shorter than this as likely spam.
+	SpamMinBodyLength int `json:"spamMinBodyLength,omitempty"`
+	// SpamMinAccountAgeDays labels PRs without a /kind from accounts younger than this as likely spam.
+	SpamMinAccountAgeDays int `json:"spamMinAccountAgeDays,omitempty"`
+	// AuthorPolicies relax validation by author association, e.g. MEMBER, or
+	// by team, e.g. kgateway-dev/maintainers.
+	AuthorPolicies map[string]labeler.AuthorPolicy `json:"authorPolicies,omitempty"`
+	// MilestoneTeams restricts /milestone to members of these org/team-slug teams.
+	MilestoneTeams []string `json:"milestoneTeams,omitempty"`
+	// StickyComment tells PR authors what to fix in a comment updated on every run.
+	StickyComment bool `json:"stickyComment,omitempty"`
+	// MergeBlockersComment keeps a checklist comment of the PR's
+	// do-not-merge/* labels once more than one applies.
+	MergeBlockersComment bool `json:"mergeBlockersComment,omitempty"`
+	// CommentIntervalMinutes is the least time between new comments on a
+	// PR. 0 does not limit comments.
+	CommentIntervalMinutes int `json:"commentIntervalMinutes,omitempty"`
+	// CheckRun reports the result as a check run on the PR head commit.
+	CheckRun bool `json:"checkRun,omitempty"`
+	// Reporters are the reporters of the result, of labeler.ReporterNames
+	// but the job summary: comment and check-run are the same as
+	// StickyComment and CheckRun, status reports a commit status, and slack
+	// posts to the server's Slack webhook, if it has one.
+	Reporters []string `json:"reporters,omitempty"`
+	// EscalateAfter is the number of failed PRs after which an author's
+	// guidance is escalated. 0 disables escalation.
+	EscalateAfter int `json:"escalateAfter,omitempty"`
+	// ContributorDocsURL is linked from escalated guidance.
+	ContributorDocsURL string `json:"contributorDocsURL,omitempty"`
+	// Mentors are the users or org/team-slug teams pinged in escalated guidance.
+	Mentors []string `json:"mentors,omitempty"`
+	// RequireUpgradeDocs requires PRs whose release note starts with ACTION
+	// REQUIRED to change files matching UpgradeDocsPaths.
+	RequireUpgradeDocs bool `json:"requireUpgradeDocs,omitempty"`
+	// UpgradeDocsPaths are path patterns, where ** matches any directories,
+	// that count as upgrade docs. Defaults to docs/upgrading/**.
+	UpgradeDocsPaths []string `json:"upgradeDocsPaths,omitempty"`
+	// RequireDocsChanges requires /kind documentation PRs to change files
+	// matching DocsPaths.
+	RequireDocsChanges bool `json:"requireDocsChanges,omitempty"`
+	// DocsPaths are path patterns, where ** matches any directories, that
+	// count as docs. Defaults to docs/** and **/*.md.
+	DocsPaths []string `json:"docsPaths,omitempty"`
+	// DisabledLabels are label patterns, e.g. do-not-merge/*, the labeler
+	// leaves alone; one prefixed with ! enables labels again, the last match
+	// winning.
+	DisabledLabels []string `json:"disabledLabels,omitempty"`
+	// IgnorePaths are path patterns, where ** matches any directories; PRs
+	// changing only matching files are not validated.
+	IgnorePaths []string `json:"ignorePaths,omitempty"`
+	// DetectRenames defaults PRs that only rename files to /kind cleanup and
+	// treats their missing release note as NONE.
+	DetectRenames bool `json:"detectRenames,omitempty"`
+	// Bots, if set, labels the PRs of dependency update bots with a default
+	// kind and release note instead of failing validation.
+	Bots *labeler.BotPolicy `json:"bots,omitempty"`
+	// ReviewCommands also takes /kind and /release-note commands from the
+	// summaries of maintainers' reviews.
+	ReviewCommands bool `json:"reviewCommands,omitempty"`
+	// CommentCommands also takes /kind commands from PR comments of users
+	// who can write to the repository.
+	CommentCommands bool `json:"commentCommands,omitempty"`
+	// DetectReverts labels revert PRs with revert and defaults their kind to
+	// the kind of the PR they revert.
+	DetectReverts bool `json:"detectReverts,omitempty"`
+	// RiskLabels labels PRs with their release risk, risk/low, risk/medium
+	// or risk/high.
+	RiskLabels bool `json:"riskLabels,omitempty"`
+	// RiskKindWeights overrides the default risk weights per kind.
+	RiskKindWeights map[string]int `json:"riskKindWeights,omitempty"`
+	// NeedsLabels labels PRs with needs-kind, needs-release-note and
+	// needs-rebase for what they need before review.
+	NeedsLabels bool `json:"needsLabels,omitempty"`
+	// CILabels labels PRs ci/failing while a check of their head commit
+	// fails.
+	CILabels bool `json:"ciLabels,omitempty"`
+	// ResetApprovalLabel, e.g. lgtm, is removed from PRs that got commits
+	// after their latest approving review.
+	ResetApprovalLabel string `json:"resetApprovalLabel,omitempty"`
+	// ModuleLabels labels PRs in repositories with several Go modules with
+	// module/NAME for each module they change.
+	ModuleLabels bool `json:"moduleLabels,omitempty"`
+	// ExportMetadata exports the parsed PR metadata as JSON in the check run.
+	ExportMetadata bool `json:"exportMetadata,omitempty"`
+	// MetadataBranch, with ExportMetadata, is the existing branch the metadata
+	// is also committed to as pr-metadata/NUMBER.json.
+	MetadataBranch string `json:"metadataBranch,omitempty"`
+	// Repositories selects the repositories the server processes. It can
+	// only be set in the server config, not overridden by a repository.
+	Repositories RepositoryFilter `json:"repositories,omitempty"`
+}
+
+// RepositoryFilter selects repositories by owner/repo glob, e.g.
+// kgateway-dev/* or kgateway-dev/kgateway. Matching is case-insensitive.
+type RepositoryFilter struct {
+	// Include lists the repositories that opted in. Empty includes every
+	// repository the server receives events from.
+	Include []string `json:"include,omitempty"`
+	// Exclude lists repositories that are never processed, even if included,
+	// e.g. archived or experimental ones.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Enabled reports whether events from fullName, in the owner/repo format,
+// should be processed.
+func (f RepositoryFilter) Enabled(fullName string) bool {
+	if matchAny(f.Exclude, fullName) {
+		return false
+	}
+	return len(f.Include) == 0 || matchAny(f.Include, fullName)
+}
+
+func (f RepositoryFilter) validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (f RepositoryFilter) isZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+func matchAny(patterns []string, fullName string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(fullName)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// spamHeuristics returns the spam heuristics the config enables.
+func (c *Config) spamHeuristics() labeler.SpamHeuristics {
+	return labeler.SpamHeuristics{
+		EmptyDiff:     c.SpamEmptyDiff,
+		MinBodyLength: c.SpamMinBodyLength,
+		MinAccountAge: time.Duration(c.SpamMinAccountAgeDays) * 24 * time.Hour,
+	}
+}
+
+// linkCheckTimeout returns how long link checks wait for each URL.
+func (c *Config) linkCheckTimeout() time.Duration {
+	if c.CheckLinksTimeoutSeconds == 0 {
+		return labeler.DefaultLinkCheckTimeout
+	}
+	return time.Duration(c.CheckLinksTimeoutSeconds) * time.Second
+}
+
+// escalation returns the escalated guidance the config enables.
+func (c *Config) escalation() labeler.Escalation {
+	return labeler.Escalation{Threshold: c.EscalateAfter, DocsURL: c.ContributorDocsURL, Mentors: c.Mentors}
+}
+
+// LoadConfig reads and validates the config file at path. An empty path
+// returns the defaults.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+	if cfg.EnforceDescription == nil {
+		enforce := true
+		cfg.EnforceDescription = &enforce
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = labeler.ModeStrict
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	if err := c.Repositories.validate(); err != nil {
+		return err
+	}
+	if err := c.Config.Validate(); err != nil {
+		return err
+	}
+	if c.SpamMinBodyLength < 0 || c.SpamMinAccountAgeDays < 0 {
+		return fmt.Errorf("spam heuristics must not be negative")
+	}
+	if c.CommentIntervalMinutes < 0 {
+		return fmt.Errorf("commentIntervalMinutes must not be negative")
+	}
+	if c.EscalateAfter < 0 {
+		return fmt.Errorf("escalateAfter must not be negative")
+	}
+	if c.MaxReleaseNoteLength < 0 {
+		return fmt.Errorf("maxReleaseNoteLength must not be negative")
+	}
+	if c.CheckLinksTimeoutSeconds < 0 {
+		return fmt.Errorf("checkLinksTimeoutSeconds must not be negative")
+	}
+	for _, p := range c.KindPrefixes {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("kindPrefixes must not be empty")
+		}
+	}
+	for _, f := range c.ReleaseNoteFences {
+		if f == "" || strings.ContainsAny(f, "` \t\n") {
+			return fmt.Errorf("invalid releaseNoteFences entry %q", f)
+		}
+	}
+	for _, name := range c.Reporters {
+		if !slices.Contains(labeler.ReporterNames, name) || name == labeler.ReporterSummary {
+			return fmt.Errorf("unknown reporter %q", name)
+		}
+	}
+	supported := c.SupportedKinds()
+	for kind, w := range c.RiskKindWeights {
+		if !supported[kind] || w < 0 {
+			return fmt.Errorf("invalid riskKindWeights entry %s=%d", kind, w)
+		}
+	}
+	for kind, milestone := range c.KindMilestones {
+		if !supported[kind] || milestone == "" {
+			return fmt.Errorf("invalid kindMilestones entry %s=%q", kind, milestone)
+		}
+	}
+	if c.Bots != nil && c.Bots.Kind != "" && !supported[c.Bots.Kind] {
+		return fmt.Errorf("unknown bots kind %q", c.Bots.Kind)
+	}
+	for key := range c.AuthorPolicies {
+		if !labeler.ValidPolicyKey(key) {
+			return fmt.Errorf("unknown author association or team %q in authorPolicies", key)
+		}
+	}
+	for _, p := range c.DocsPaths {
+		if !labeler.ValidPathPattern(p) {
+			return fmt.Errorf("invalid docsPaths pattern %q", p)
+		}
+	}
+	for _, p := range c.UpgradeDocsPaths {
+		if !labeler.ValidPathPattern(p) {
+			return fmt.Errorf("invalid upgradeDocsPaths pattern %q", p)
+		}
+	}
+	for _, p := range c.IgnorePaths {
+		if !labeler.ValidPathPattern(p) {
+			return fmt.Errorf("invalid ignorePaths pattern %q", p)
+		}
+	}
+	for _, p := range c.DisabledLabels {
+		if !labeler.ValidLabelPattern(p) {
+			return fmt.Errorf("invalid disabledLabels pattern %q", p)
+		}
+	}
+	for _, team := range c.MilestoneTeams {
+		if _, _, err := teams.ParseTeam(team); err != nil {
+			return fmt.Errorf("invalid milestoneTeams: %w", err)
+		}
+	}
+	_, err := labeler.ParseMode(string(c.Mode))
+	return err
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+)
+
+// debouncer coalesces the events of each PR that arrive within a window, so
+// a PR is processed at most once per window however often its author edits
+// it. Events are coalesced by kind: only the latest opened, edited or
+// reopened event of a window is processed, and other events, which a
+// repository's config may not act on, are processed apart, unless such an
+// event is pending, which processes the PR in full anyway.
+type debouncer struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string]*pendingEvent
+	// run processes an event once its window closes.
+	run func(*event.PullRequest)
+}
+
+type pendingEvent struct {
+	e     *event.PullRequest
+	timer *time.Timer
+}
+
+// add schedules e to run once the PR's window closes, replacing any event
+// of the same kind already waiting. It reports whether e was coalesced with
+// a waiting event.
+func (d *debouncer) add(e *event.PullRequest) bool {
+	key := debounceKey(e)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.pending[e.String()]; ok && key != e.String() {
+		return true
+	}
+	if p, ok := d.pending[key]; ok {
+		p.e = e
+		return true
+	}
+	p := &pendingEvent{e: e}
+	p.timer = time.AfterFunc(d.window, func() {
+		if e := d.take(key); e != nil {
+			d.run(e)
+		}
+	})
+	d.pending[key] = p
+	return false
+}
+
+// debounceKey returns the key e is coalesced under: its PR for events that
+// process the PR in full, and its PR and action for others.
+func debounceKey(e *event.PullRequest) string {
+	switch e.Action {
+	case event.Opened, event.Edited, event.Reopened:
+		return e.String()
+	}
+	return e.String() + " " + string(e.Action)
+}
+
+// take removes and returns the event waiting for key, or nil if it was
+// already taken.
+func (d *debouncer) take(key string) *event.PullRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	p, ok := d.pending[key]
+	if !ok {
+		return nil
+	}
+	delete(d.pending, key)
+	p.timer.Stop()
+	return p.e
+}
+
+// flush runs every waiting event now, e.g. when the server shuts down.
+func (d *debouncer) flush() {
+	d.mu.Lock()
+	keys := make([]string, 0, len(d.pending))
+	for key := range d.pending {
+		keys = append(keys, key)
+	}
+	d.mu.Unlock()
+	for _, key := range keys {
+		if e := d.take(key); e != nil {
+			d.run(e)
+		}
+	}
+}
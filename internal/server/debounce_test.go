@@ -0,0 +1,99 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+)
+
+func TestDebouncer(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+	done := make(chan struct{}, 2)
+	d := &debouncer{
+		window:  20 * time.Millisecond,
+		pending: map[string]*pendingEvent{},
+		run: func(e *event.PullRequest) {
+			mu.Lock()
+			ran = append(ran, e.String()+" "+e.Body)
+			mu.Unlock()
+			done <- struct{}{}
+		},
+	}
+	if d.add(&event.PullRequest{Action: event.Opened, Owner: "owner", Repo: "repo", Number: 1, Body: "first"}) {
+		t.Fatalf("expected the first event not to be coalesced")
+	}
+	if !d.add(&event.PullRequest{Action: event.Edited, Owner: "owner", Repo: "repo", Number: 1, Body: "second"}) {
+		t.Fatalf("expected the second event to be coalesced")
+	}
+	if d.add(&event.PullRequest{Action: event.Edited, Owner: "owner", Repo: "repo", Number: 2, Body: "other"}) {
+		t.Fatalf("expected events of another PR not to be coalesced")
+	}
+	for range 2 {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for debounced events")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ran) != 2 {
+		t.Fatalf("expected 2 runs, got %v", ran)
+	}
+	for _, r := range ran {
+		if r == "owner/repo#1 first" {
+			t.Fatalf("expected only the latest event of a window to run, got %v", ran)
+		}
+	}
+}
+
+func TestDebouncerKeepsFullEvent(t *testing.T) {
+	var ran []event.Action
+	d := &debouncer{
+		window:  time.Hour,
+		pending: map[string]*pendingEvent{},
+		run:     func(e *event.PullRequest) { ran = append(ran, e.Action) },
+	}
+	d.add(&event.PullRequest{Action: event.Opened, Owner: "owner", Repo: "repo", Number: 1})
+	comment := &event.PullRequest{Action: event.Commented, Owner: "owner", Repo: "repo", Number: 1, Comment: &event.Comment{Body: "/kind fix"}}
+	if !d.add(comment) {
+		t.Fatalf("expected the comment to be coalesced with the pending opened event")
+	}
+	d.flush()
+	if len(ran) != 1 || ran[0] != event.Opened {
+		t.Fatalf("expected the opened event to run, got %v", ran)
+	}
+
+	ran = nil
+	d.add(&event.PullRequest{Action: event.Synchronized, Owner: "owner", Repo: "repo", Number: 1})
+	if d.add(comment) {
+		t.Fatalf("expected the comment not to be coalesced with a push")
+	}
+	d.flush()
+	if len(ran) != 2 {
+		t.Fatalf("expected the push and the comment to run apart, got %v", ran)
+	}
+}
+
+func TestDebouncerFlush(t *testing.T) {
+	var ran []string
+	d := &debouncer{
+		window:  time.Hour,
+		pending: map[string]*pendingEvent{},
+		run:     func(e *event.PullRequest) { ran = append(ran, e.String()) },
+	}
+	d.add(&event.PullRequest{Owner: "owner", Repo: "repo", Number: 1})
+	d.flush()
+	if len(ran) != 1 || ran[0] != "owner/repo#1" {
+		t.Fatalf("expected the pending event to run on flush, got %v", ran)
+	}
+	d.flush()
+	if len(ran) != 1 {
+		t.Fatalf("expected a flushed event to run once, got %v", ran)
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type labelCacheEntry struct {
+	labels  []string
+	etag    string
+	updated time.Time
+}
+
+// labelCache is an in-memory labeler.LabelCache kept current by
+// labeled/unlabeled webhooks. Entries are trusted for ttl after they were
+// last updated; after that the labeler revalidates them with their ETag.
+type labelCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]labelCacheEntry
+}
+
+func newLabelCache(ttl time.Duration, now func() time.Time) *labelCache {
+	return &labelCache{ttl: ttl, now: now, entries: map[string]labelCacheEntry{}}
+}
+
+func labelCacheKey(owner, repo string, prNum int) string {
+	return fmt.Sprintf("%s/%s#%d", owner, repo, prNum)
+}
+
+// Get implements labeler.LabelCache.
+func (c *labelCache) Get(owner, repo string, prNum int) ([]string, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[labelCacheKey(owner, repo, prNum)]
+	if !ok {
+		return nil, "", false
+	}
+	return e.labels, e.etag, c.now().Sub(e.updated) < c.ttl
+}
+
+// Set implements labeler.LabelCache.
+func (c *labelCache) Set(owner, repo string, prNum int, labels []string, etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[labelCacheKey(owner, repo, prNum)] = labelCacheEntry{labels: labels, etag: etag, updated: c.now()}
+}
+
+// Invalidate implements labeler.LabelCache.
+func (c *labelCache) Invalidate(owner, repo string, prNum int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, labelCacheKey(owner, repo, prNum))
+}
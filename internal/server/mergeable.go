@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+// WithAPIToken serves the merge gating API at
+// GET /mergeable/{owner}/{repo}/{number}, and the metrics at
+// GET /debug/vars, to clients that present token as a bearer token. Without
+// a token neither is served.
+func (s *Server) WithAPIToken(token string) *Server {
+	s.apiToken = token
+	return s
+}
+
+// authorized reports whether r presents the API token, answering it
+// otherwise.
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if s.apiToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.apiToken)) != 1 {
+		http.Error(w, "invalid API token", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// Mergeable decides whether a PR may be merged per the label policy of its
+// repository's config, without changing anything.
+func (s *Server) Mergeable(ctx context.Context, owner, repo string, prNum int) (*labeler.Verdict, error) {
+	pr, _, err := s.client.PullRequests.Get(ctx, owner, repo, prNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+	return s.MergeablePR(ctx, owner, repo, pr)
+}
+
+// MergeablePR is Mergeable for a PR already fetched, e.g. while listing a
+// repository's open PRs.
+func (s *Server) MergeablePR(ctx context.Context, owner, repo string, pr *github.PullRequest) (*labeler.Verdict, error) {
+	e := &event.PullRequest{Owner: owner, Repo: repo, Number: pr.GetNumber()}
+	event.FromGitHubPullRequest(e, pr)
+	cfg, err := s.tenants.config(ctx, tenantKey(e), owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return s.newLabeler(cfg, e).Mergeable(ctx, e.Body)
+}
+
+// Status returns where a PR stands per the label policy of its repository's
+// config and how to unblock it, without changing anything.
+func (s *Server) Status(ctx context.Context, owner, repo string, prNum int) (*labeler.PRStatus, error) {
+	pr, _, err := s.client.PullRequests.Get(ctx, owner, repo, prNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+	e := &event.PullRequest{Owner: owner, Repo: repo, Number: prNum}
+	event.FromGitHubPullRequest(e, pr)
+	cfg, err := s.tenants.config(ctx, tenantKey(e), owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return s.newLabeler(cfg, e).Status(ctx, e.Body)
+}
+
+// handleMergeable answers whether a PR may be merged, for merge automation
+// such as Tide to gate on. The verdict is served with 200 OK whether or not
+// the PR is mergeable.
+func (s *Server) handleMergeable(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+	owner, repo := r.PathValue("owner"), r.PathValue("repo")
+	prNum, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		http.Error(w, "invalid PR number", http.StatusBadRequest)
+		return
+	}
+	if !s.cfg.Repositories.Enabled(owner + "/" + repo) {
+		http.NotFound(w, r)
+		return
+	}
+	v, err := s.Mergeable(r.Context(), owner, repo, prNum)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"expvar"
+	"time"
+)
+
+// timeToGreen tracks how long PRs take to pass validation after first failing
+// it, so the effect of template and tooling changes on contributor friction
+// can be measured. It is served with the other expvars at /debug/vars.
+var timeToGreen = expvar.NewMap("pr_kind_labeler_time_to_green")
+
+// timeToGreenBuckets are the upper bounds of the cumulative time-to-green
+// histogram, as in Prometheus.
+var timeToGreenBuckets = []time.Duration{10 * time.Minute, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour}
+
+// deprecatedKinds counts the evaluations of PRs whose body or labels still
+// use a deprecated kind, per repository and kind, e.g.
+// "kgateway-dev/kgateway:bug_fix", to tell when the migration of deprecated
+// kinds can be removed. It is served with the other expvars at /debug/vars.
+var deprecatedKinds = expvar.NewMap("pr_kind_labeler_deprecated_kinds")
+
+// observeDeprecatedKinds records that a PR of owner/repo used kinds, which
+// are deprecated.
+func observeDeprecatedKinds(owner, repo string, kinds []string) {
+	for _, kind := range kinds {
+		deprecatedKinds.Add(owner+"/"+repo+":"+kind, 1)
+	}
+}
+
+// observeTimeToGreen records that a PR passed validation d after first
+// failing it.
+func observeTimeToGreen(d time.Duration) {
+	timeToGreen.Add("count", 1)
+	timeToGreen.AddFloat("seconds_sum", d.Seconds())
+	for _, b := range timeToGreenBuckets {
+		if d <= b {
+			timeToGreen.Add("le_"+b.String(), 1)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestObserveTimeToGreen(t *testing.T) {
+	count := func(key string) int64 {
+		if v, ok := timeToGreen.Get(key).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	before := map[string]int64{}
+	for _, key := range []string{"count", "le_10m0s", "le_1h0m0s", "le_168h0m0s"} {
+		before[key] = count(key)
+	}
+	observeTimeToGreen(30 * time.Minute)
+	want := map[string]int64{"count": 1, "le_10m0s": 0, "le_1h0m0s": 1, "le_168h0m0s": 1}
+	for key, delta := range want {
+		if got := count(key) - before[key]; got != delta {
+			t.Errorf("%s grew by %d, want %d", key, got, delta)
+		}
+	}
+}
+
+func TestObserveDeprecatedKinds(t *testing.T) {
+	count := func(key string) int64 {
+		if v, ok := deprecatedKinds.Get(key).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	before := count("owner/repo:bug_fix")
+	observeDeprecatedKinds("owner", "repo", []string{"bug_fix", "new_feature"})
+	observeDeprecatedKinds("owner", "repo", []string{"bug_fix"})
+	if got := count("owner/repo:bug_fix") - before; got != 2 {
+		t.Errorf("owner/repo:bug_fix grew by %d, want 2", got)
+	}
+	if got := count("owner/repo:new_feature"); got < 1 {
+		t.Errorf("owner/repo:new_feature = %d, want at least 1", got)
+	}
+}
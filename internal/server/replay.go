@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+// Replayed is the outcome of replaying a saved webhook event.
+type Replayed struct {
+	// Event is the webhook event type, e.g. pull_request.
+	Event string `json:"event"`
+	// PR is the PR processed for the event, as owner/repo#number, or "" if the
+	// event was ignored.
+	PR string `json:"pr,omitempty"`
+	// Decision is what the labeler decided for the PR. Its body is left out,
+	// as the saved payload has it.
+	Decision *labeler.Decision `json:"decision,omitempty"`
+	// Error is the failure, other than validation, that stopped processing.
+	Error string `json:"error,omitempty"`
+}
+
+// Replay processes a saved webhook payload the way a delivered webhook is
+// processed, but synchronously and without rate limits. Unless apply is set,
+// nothing is changed on GitHub: labels, comments and check runs are only
+// computed. Only payloads that cannot be parsed are returned as errors.
+func (s *Server) Replay(ctx context.Context, eventType string, payload []byte, apply bool) (*Replayed, error) {
+	r := &Replayed{Event: eventType}
+	e, err := s.dispatch(eventType, payload)
+	if err != nil || e == nil {
+		return r, err
+	}
+	r.PR = e.String()
+	d, err := s.run(ctx, e, apply)
+	if d != nil {
+		d.Body = ""
+		r.Decision = d
+	}
+	if _, operational := labeler.Partition(err); len(operational) > 0 {
+		r.Error = errors.Join(operational...).Error()
+	}
+	return r, nil
+}
+
+// EventType returns the webhook event type of a saved payload, and the
+// payload itself. data is either a delivery as returned by GitHub's webhook
+// deliveries API, which records the event type, or a bare payload, whose
+// type is told from its shape. An unknown shape yields "".
+func EventType(data []byte) (string, []byte, error) {
+	var delivery struct {
+		Event   string `json:"event"`
+		Request struct {
+			Payload json.RawMessage `json:"payload"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(data, &delivery); err != nil {
+		return "", nil, fmt.Errorf("failed to parse event: %w", err)
+	}
+	if delivery.Event != "" && len(delivery.Request.Payload) > 0 {
+		return delivery.Event, delivery.Request.Payload, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", nil, fmt.Errorf("failed to parse event: %w", err)
+	}
+	has := func(name string) bool { _, ok := fields[name]; return ok }
+	switch {
+	case has("issue") && has("comment"):
+		return "issue_comment", data, nil
+	case has("pull_request") && has("number"):
+		// review events carry the PR too, but not its number at the top level
+		return "pull_request", data, nil
+	}
+	return "", data, nil
+}
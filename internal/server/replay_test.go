@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestReplay(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	payload, _ := json.Marshal(pullRequestEvent("opened", "# Description\nFix.\n/kind fix\n```release-note\nFixed a crash.\n```"))
+
+	for _, apply := range []bool{false, true} {
+		added := make(chan []string, 1)
+		s := New(cfg, newTestClient(t, added), nil)
+		r, err := s.Replay(context.Background(), "pull_request", payload, apply)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if r.PR != "owner/repo#7" || r.Error != "" {
+			t.Fatalf("unexpected result %+v", r)
+		}
+		want := []string{"kind/fix", labels.ReleaseNoteLabel}
+		if !reflect.DeepEqual(r.Decision.LabelsToAdd, want) {
+			t.Fatalf("labels to add = %v, want %v", r.Decision.LabelsToAdd, want)
+		}
+		select {
+		case got := <-added:
+			if !apply {
+				t.Fatalf("labels %v added without --apply", got)
+			}
+		default:
+			if apply {
+				t.Fatal("labels not added with --apply")
+			}
+		}
+	}
+
+	s := New(cfg, newTestClient(t, nil), nil)
+	payload, _ = json.Marshal(pullRequestEvent("closed", "/kind fix"))
+	if r, err := s.Replay(context.Background(), "pull_request", payload, false); err != nil || r.PR != "" {
+		t.Fatalf("expected a closed PR to be ignored, got %+v, %v", r, err)
+	}
+}
+
+func TestEventType(t *testing.T) {
+	pr, _ := json.Marshal(pullRequestEvent("opened", "/kind fix"))
+	comment, _ := json.Marshal(issueCommentEvent("/release-note-none"))
+	delivery, _ := json.Marshal(map[string]any{
+		"event":   "pull_request",
+		"request": map[string]any{"headers": map[string]string{"X-GitHub-Event": "pull_request"}, "payload": json.RawMessage(pr)},
+	})
+	tests := []struct {
+		name        string
+		data        []byte
+		want        string
+		wantPayload []byte
+	}{
+		{name: "pull_request payload", data: pr, want: "pull_request", wantPayload: pr},
+		{name: "issue_comment payload", data: comment, want: "issue_comment", wantPayload: comment},
+		{name: "delivery", data: delivery, want: "pull_request", wantPayload: pr},
+		{name: "unknown payload", data: []byte(`{"ref": "refs/heads/main"}`), want: "", wantPayload: []byte(`{"ref": "refs/heads/main"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, payload, err := EventType(tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want || string(payload) != string(tt.wantPayload) {
+				t.Fatalf("EventType() = %q, %s, want %q, %s", got, payload, tt.want, tt.wantPayload)
+			}
+		})
+	}
+	if _, _, err := EventType([]byte("not json")); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,444 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"golang.org/x/time/rate"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/failures"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/teams"
+)
+
+// Server receives GitHub webhooks and processes pull_request events the same
+// way the GitHub Action does.
+type Server struct {
+	cfg    *Config
+	client *github.Client
+	secret []byte
+
+	// ctx is the parent of every in-flight processing run. It outlives
+	// request contexts so webhooks are acknowledged immediately, and is only
+	// cancelled when draining times out during shutdown.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// inFlight tracks processing runs so shutdown can drain them.
+	inFlight sync.WaitGroup
+	// tenants holds per-tenant config caches and rate limits.
+	tenants *tenants
+	// labels caches PR labels, kept current by labeled/unlabeled webhooks.
+	labels *labelCache
+	// teams caches team membership across events.
+	teams *teams.Resolver
+	// secretNotifier is told about possible credentials in PR bodies.
+	secretNotifier labeler.SecretNotifier
+	// reporters are the reporters tenants can select by name, shared across
+	// runs so their deduplication spans them.
+	reporters map[string]labeler.Reporter
+	// failures counts each author's failed PRs for escalated guidance. It is
+	// kept in memory, so counts restart with the server.
+	failures *failures.MemoryStore
+	// apiToken authenticates clients of the merge gating API.
+	apiToken string
+	// debounce, if set, coalesces each PR's events within a window.
+	debounce *debouncer
+}
+
+// New creates a server. secret is the webhook secret used to verify payload
+// signatures.
+func New(cfg *Config, client *github.Client, secret []byte) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		cfg:    cfg,
+		client: client,
+		secret: secret,
+		ctx:    ctx,
+		cancel: cancel,
+		tenants: &tenants{
+			ttl:      5 * time.Minute,
+			rps:      1,
+			burst:    10,
+			now:      time.Now,
+			load:     repoConfigLoader(client, cfg),
+			configs:  map[string]cachedConfig{},
+			limiters: map[string]*rate.Limiter{},
+		},
+		labels:   newLabelCache(10*time.Minute, time.Now),
+		teams:    teams.NewResolver(client, 10*time.Minute),
+		failures: failures.NewMemoryStore(),
+	}
+}
+
+// WithTenantLimits limits each tenant (GitHub App installation, or repository
+// owner) to rps processing runs per second with the given burst. Events over
+// the limit are queued rather than dropped. rps must be positive and burst
+// at least 1, or no run is ever allowed.
+func (s *Server) WithTenantLimits(rps float64, burst int) *Server {
+	s.tenants.rps = rate.Limit(rps)
+	s.tenants.burst = burst
+	return s
+}
+
+// WithTeamCacheTTL sets how long team membership answers are cached.
+func (s *Server) WithTeamCacheTTL(ttl time.Duration) *Server {
+	s.teams = teams.NewResolver(s.client, ttl)
+	return s
+}
+
+// WithConfigTTL sets how long per-repository configs are cached.
+func (s *Server) WithConfigTTL(ttl time.Duration) *Server {
+	s.tenants.ttl = ttl
+	return s
+}
+
+// WithSecretNotifier sets who is told privately when a tenant with
+// detectSecrets enabled receives a PR body that appears to contain a
+// credential.
+func (s *Server) WithSecretNotifier(n labeler.SecretNotifier) *Server {
+	s.secretNotifier = n
+	return s
+}
+
+// WithReporter lets tenants select r by its name in their reporters. Wrap r
+// with labeler.Deduplicate to deduplicate its reports across runs.
+func (s *Server) WithReporter(r labeler.Reporter) *Server {
+	if s.reporters == nil {
+		s.reporters = map[string]labeler.Reporter{}
+	}
+	s.reporters[r.Name()] = r
+	return s
+}
+
+// WithDebounce processes each PR at most once per window: events arriving
+// while one of the PR's events of the same kind waits replace it, and the
+// latest is processed when the window closes. Without a window every event
+// is processed.
+func (s *Server) WithDebounce(window time.Duration) *Server {
+	s.debounce = nil
+	if window > 0 {
+		s.debounce = &debouncer{
+			window:  window,
+			pending: map[string]*pendingEvent{},
+			run: func(e *event.PullRequest) {
+				s.process(e)
+				s.inFlight.Done()
+			},
+		}
+	}
+	return s
+}
+
+// Handler returns the HTTP handler serving webhooks at /webhook, the merge
+// gating API at /mergeable and metrics at /debug/vars to clients with the API
+// token, and a liveness probe at /healthz.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhook", s.handleWebhook)
+	mux.HandleFunc("GET /mergeable/{owner}/{repo}/{number}", s.handleMergeable)
+	mux.HandleFunc("GET /debug/vars", func(w http.ResponseWriter, r *http.Request) {
+		// the metrics name tenants' repositories
+		if s.authorized(w, r) {
+			expvar.Handler().ServeHTTP(w, r)
+		}
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// Run serves on addr until ctx is cancelled, e.g. by SIGTERM. It then stops
+// accepting connections and waits up to shutdownTimeout for in-flight
+// webhook processing to finish.
+func (s *Server) Run(ctx context.Context, addr string, shutdownTimeout time.Duration) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		s.cancel()
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down, draining in-flight webhooks for up to %s", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		s.cancel()
+		return fmt.Errorf("failed to shut down HTTP server: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.cancel()
+		return err
+	}
+	if s.debounce != nil {
+		s.debounce.flush()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		s.cancel()
+		return nil
+	case <-shutdownCtx.Done():
+		s.cancel()
+		return fmt.Errorf("timed out after %s waiting for in-flight webhooks", shutdownTimeout)
+	}
+}
+
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := github.ValidatePayload(r, s.secret)
+	if err != nil {
+		http.Error(w, "invalid webhook signature", http.StatusUnauthorized)
+		return
+	}
+	prEvent, err := s.dispatch(github.WebHookType(r), payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if prEvent == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.enqueue(prEvent)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// dispatch parses a webhook payload and returns the PR event to process for
+// it, or nil if there is nothing to process. Label changes only update the
+// label cache, comments are only processed when they are /release-note or
+// /help commands, and reviews when they give a command. Pushes are processed
+// for repositories with needs labels or an approval label to reset, and
+// completed checks for repositories with CI labels.
+func (s *Server) dispatch(eventType string, payload []byte) (*event.PullRequest, error) {
+	e, err := event.FromGitHub(eventType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook: %w", err)
+	}
+	if e == nil || !s.cfg.Repositories.Enabled(e.FullName()) {
+		return nil, nil
+	}
+	switch e.Action {
+	case event.Opened, event.Edited, event.Reopened, event.Synchronized:
+		return e, nil
+	case event.Commented:
+		if labeler.HasCommand(e.Comment.Body) || labeler.IsHelpCommand(e.Comment.Body) {
+			return e, nil
+		}
+	case event.Reviewed:
+		if labeler.HasCommand(e.Comment.Body) {
+			return e, nil
+		}
+	case event.ChecksCompleted:
+		// the labeler's own check run and status would otherwise trigger
+		// it again, in a loop
+		if e.Check != labeler.CheckRunName {
+			return e, nil
+		}
+	case event.Labeled, event.Unlabeled:
+		// the payload carries the PR's full label set after the change
+		s.labels.Set(e.Owner, e.Repo, e.Number, e.Labels, "")
+	}
+	return nil, nil
+}
+
+// enqueue processes e, once its debounce window closes if one is set. Events
+// on a commit are not debounced, as their PR is not known yet, nor are /help
+// comments, which are replied to at once and leave pending events alone.
+func (s *Server) enqueue(e *event.PullRequest) {
+	if s.debounce == nil || e.Number == 0 || (e.Action == event.Commented && labeler.IsHelpCommand(e.Comment.Body)) {
+		s.process(e)
+		return
+	}
+	// the pending event counts as in flight, so shutdown drains it
+	s.inFlight.Add(1)
+	if s.debounce.add(e) {
+		s.inFlight.Done()
+		log.Printf("%s: coalesced with a pending event", e)
+	}
+}
+
+// process runs the labeler for a PR event in the background. Each run is
+// isolated: a panic, a broken repository config, or a slow tenant only
+// affects that tenant's events.
+func (s *Server) process(e *event.PullRequest) {
+	s.inFlight.Add(1)
+	go func() {
+		defer s.inFlight.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("%s: panic while processing: %v", e, r)
+			}
+		}()
+		if err := s.tenants.limiter(tenantKey(e)).Wait(s.ctx); err != nil {
+			log.Printf("%s: dropped: %v", e, err)
+			return
+		}
+		if _, err := s.run(s.ctx, e, true); err != nil {
+			log.Printf("%s: %v", e, err)
+			return
+		}
+		log.Printf("%s: processed", e)
+	}()
+}
+
+// run runs the labeler for a PR event with the config of its repository and
+// returns the decision it made, or nil if there was nothing to do. A partial
+// event, as for a comment, has its PR fetched first, as do all events if the
+// config sets RefetchPR, and an event on a commit has its PR looked up. A
+// /help comment is replied to instead. Unless apply
+// is set, nothing is changed on GitHub.
+func (s *Server) run(ctx context.Context, e *event.PullRequest, apply bool) (*labeler.Decision, error) {
+	if e.Number == 0 {
+		prs, _, err := s.client.PullRequests.ListPullRequestsWithCommit(ctx, e.Owner, e.Repo, e.HeadSHA, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list PRs of commit %s: %w", e.HeadSHA, err)
+		}
+		i := slices.IndexFunc(prs, func(pr *github.PullRequest) bool {
+			return pr.GetState() == "open" && pr.GetHead().GetSHA() == e.HeadSHA
+		})
+		if i < 0 {
+			return nil, nil
+		}
+		e.Number = prs[i].GetNumber()
+	}
+	owner, repo, prNum := e.Owner, e.Repo, e.Number
+
+	cfg, err := s.tenants.config(ctx, tenantKey(e), owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if e.Action == event.Commented && labeler.IsHelpCommand(e.Comment.Body) {
+		if !apply {
+			return nil, nil
+		}
+		return nil, s.newLabeler(cfg, e).PostHelp(ctx)
+	}
+	if (e.Action == event.Commented && !commentCommand(cfg, e.Comment.Body)) || (e.Action == event.Reviewed && !cfg.ReviewCommands) ||
+		(e.Action == event.Synchronized && !cfg.NeedsLabels && cfg.ResetApprovalLabel == "") || (e.Action == event.ChecksCompleted && !cfg.CILabels && cfg.ResetApprovalLabel == "") {
+		return nil, nil
+	}
+	if e.Partial || cfg.RefetchPR {
+		pr, _, err := s.client.PullRequests.Get(ctx, owner, repo, prNum)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PR: %w", err)
+		}
+		event.FromGitHubPullRequest(e, pr)
+	}
+	l := s.newLabeler(cfg, e)
+	_, err = l.ProcessPR(ctx, e.Body, apply && cfg.Mode.SyncLabels())
+	d := l.Decision()
+	if apply {
+		if ttg, ok := l.TimeToGreen(); ok {
+			observeTimeToGreen(ttg)
+		}
+		observeDeprecatedKinds(owner, repo, d.DeprecatedKinds)
+	}
+	return d, err
+}
+
+// commentCommand reports whether a comment with body gives a command cfg
+// enables: a /release-note command, which needs the sticky comment to be
+// remembered, or a /kind command.
+func commentCommand(cfg *Config, body string) bool {
+	if _, ok := labeler.ParseReleaseNoteCommand(body); ok {
+		return cfg.StickyComment
+	}
+	return cfg.CommentCommands && labeler.HasCommand(body)
+}
+
+// prLabeler is the labeler as the server uses it.
+type prLabeler interface {
+	ProcessPR(ctx context.Context, body string, syncLabels bool) (*labeler.Result, error)
+	Mergeable(ctx context.Context, body string) (*labeler.Verdict, error)
+	Status(ctx context.Context, body string) (*labeler.PRStatus, error)
+	TimeToGreen() (time.Duration, bool)
+	Decision() *labeler.Decision
+	PostHelp(ctx context.Context) error
+}
+
+// newLabeler returns the labeler for a PR event, set up per cfg.
+func (s *Server) newLabeler(cfg *Config, e *event.PullRequest) prLabeler {
+	o := &labeler.Options{
+		EnforceDescription:              *cfg.EnforceDescription,
+		EnforceReleaseNoteQuality:       cfg.EnforceReleaseNoteQuality,
+		EnforceChangelogKindExclusivity: cfg.EnforceChangelogKindExclusivity,
+		RepoConfig:                      &cfg.Config,
+		KindPrefixes:                    cfg.KindPrefixes,
+		CommandNamespace:                cfg.CommandNamespace,
+		ReleaseNoteFences:               cfg.ReleaseNoteFences,
+		MaxReleaseNoteLength:            cfg.MaxReleaseNoteLength,
+		Milestones:                      cfg.KindMilestones,
+		MilestoneTeams:                  cfg.MilestoneTeams,
+		Triage:                          cfg.TriageAssignees,
+		AuthorPolicies:                  cfg.AuthorPolicies,
+		AuthorLogin:                     e.Author,
+		AuthorAssociation:               e.AuthorAssociation,
+		TeamResolver:                    s.teams,
+		LabelCache:                      s.labels,
+		Spam:                            cfg.spamHeuristics(),
+		DetectSecrets:                   cfg.DetectSecrets,
+		SecretNotifier:                  s.secretNotifier,
+		LinkCheck:                       cfg.CheckLinks,
+		LinkCheckTimeout:                cfg.linkCheckTimeout(),
+		UpgradeDocs:                     cfg.RequireUpgradeDocs,
+		UpgradeDocsPaths:                cfg.UpgradeDocsPaths,
+		DocsKindCheck:                   cfg.RequireDocsChanges,
+		DocsPaths:                       cfg.DocsPaths,
+		IgnoredPaths:                    cfg.IgnorePaths,
+		DisabledLabels:                  cfg.DisabledLabels,
+		DetectRenames:                   cfg.DetectRenames,
+		DetectReverts:                   cfg.DetectReverts,
+		Bots:                            cfg.Bots,
+		ReviewCommands:                  cfg.ReviewCommands,
+		CommentCommands:                 cfg.CommentCommands,
+		RiskScoring:                     cfg.RiskLabels,
+		KindWeights:                     cfg.RiskKindWeights,
+		ModuleLabels:                    cfg.ModuleLabels,
+		NeedsLabels:                     cfg.NeedsLabels,
+		CILabels:                        cfg.CILabels,
+		ApprovalLabel:                   cfg.ResetApprovalLabel,
+		ExportMetadata:                  cfg.ExportMetadata,
+		MetadataBranch:                  cfg.MetadataBranch,
+		StickyComment:                   cfg.StickyComment || slices.Contains(cfg.Reporters, labeler.ReporterComment),
+		MergeBlockersComment:            cfg.MergeBlockersComment,
+		CommentInterval:                 time.Duration(cfg.CommentIntervalMinutes) * time.Minute,
+		CheckRun:                        cfg.CheckRun || slices.Contains(cfg.Reporters, labeler.ReporterCheckRun),
+		CommitStatus:                    slices.Contains(cfg.Reporters, labeler.ReporterStatus),
+		HeadSHA:                         e.HeadSHA,
+		Blocking:                        cfg.Mode.FailOnValidation(),
+	}
+	for _, name := range cfg.Reporters {
+		if r, ok := s.reporters[name]; ok {
+			o.Reporters = append(o.Reporters, r)
+		}
+	}
+	if cfg.EscalateAfter > 0 {
+		o.FailureStore = s.failures
+		o.Escalation = cfg.escalation()
+	}
+	return labeler.NewFromOptions(s.client, e.Owner, e.Repo, e.Number, o)
+}
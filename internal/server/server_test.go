@@ -0,0 +1,624 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+const testSecret = "s3cr3t"
+
+func newWebhookRequest(t *testing.T, eventType string, event any, secret string) *http.Request {
+	t.Helper()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	r := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-GitHub-Event", eventType)
+	r.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+func pullRequestEvent(action, body string) *github.PullRequestEvent {
+	return &github.PullRequestEvent{
+		Action: github.Ptr(action),
+		Number: github.Ptr(7),
+		Repo: &github.Repository{
+			Name:     github.Ptr("repo"),
+			FullName: github.Ptr("owner/repo"),
+			Owner:    &github.User{Login: github.Ptr("owner")},
+		},
+		PullRequest: &github.PullRequest{Body: github.Ptr(body)},
+	}
+}
+
+func issueCommentEvent(body string) *github.IssueCommentEvent {
+	return &github.IssueCommentEvent{
+		Action: github.Ptr("created"),
+		Issue: &github.Issue{
+			Number:           github.Ptr(7),
+			PullRequestLinks: &github.PullRequestLinks{URL: github.Ptr("https://api.github.com/repos/owner/repo/pulls/7")},
+		},
+		Comment: &github.IssueComment{Body: github.Ptr(body), AuthorAssociation: github.Ptr("MEMBER")},
+		Repo: &github.Repository{
+			Name:     github.Ptr("repo"),
+			FullName: github.Ptr("owner/repo"),
+			Owner:    &github.User{Login: github.Ptr("owner")},
+		},
+	}
+}
+
+func reviewEvent(body string) *github.PullRequestReviewEvent {
+	return &github.PullRequestReviewEvent{
+		Action:      github.Ptr("submitted"),
+		PullRequest: &github.PullRequest{Number: github.Ptr(7), Body: github.Ptr("/kind fix")},
+		Review:      &github.PullRequestReview{Body: github.Ptr(body), AuthorAssociation: github.Ptr("MEMBER")},
+		Repo: &github.Repository{
+			Name:     github.Ptr("repo"),
+			FullName: github.Ptr("owner/repo"),
+			Owner:    &github.User{Login: github.Ptr("owner")},
+		},
+	}
+}
+
+// newTestClient returns a client whose label additions are recorded and
+// signalled on added.
+func newTestClient(t *testing.T, added chan<- []string) *github.Client {
+	t.Helper()
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			[]*github.Label{},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var got []string
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("AddLabels Handler: failed to decode body: %v", err)
+				}
+				sort.Strings(got)
+				added <- got
+				json.NewEncoder(w).Encode([]*github.Label{})
+			}),
+		),
+	)
+	return github.NewClient(httpClient)
+}
+
+func TestHandleWebhook(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	tests := []struct {
+		name       string
+		eventType  string
+		event      any
+		secret     string
+		wantStatus int
+		wantLabels []string
+	}{
+		{
+			name:       "opened PR is processed",
+			eventType:  "pull_request",
+			event:      pullRequestEvent("opened", "# Description\nFix.\n/kind fix\n```release-note\nFixed a crash.\n```"),
+			secret:     testSecret,
+			wantStatus: http.StatusAccepted,
+			wantLabels: []string{"kind/fix", labels.ReleaseNoteLabel},
+		},
+		{
+			// needs labels are off by default, so the push changes nothing
+			name:       "push to the PR is accepted",
+			eventType:  "pull_request",
+			event:      pullRequestEvent("synchronize", "/kind fix"),
+			secret:     testSecret,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "bad signature is rejected",
+			eventType:  "pull_request",
+			event:      pullRequestEvent("opened", "/kind fix"),
+			secret:     "wrong",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "closed PR is ignored",
+			eventType:  "pull_request",
+			event:      pullRequestEvent("closed", "/kind fix"),
+			secret:     testSecret,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "plain PR comment is ignored",
+			eventType:  "issue_comment",
+			event:      issueCommentEvent("LGTM"),
+			secret:     testSecret,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "release-note command is accepted",
+			eventType:  "issue_comment",
+			event:      issueCommentEvent("/release-note-none"),
+			secret:     testSecret,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "help command is accepted",
+			eventType:  "issue_comment",
+			event:      issueCommentEvent("/help"),
+			secret:     testSecret,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "review without a command is ignored",
+			eventType:  "pull_request_review",
+			event:      reviewEvent("LGTM"),
+			secret:     testSecret,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			// review commands are off by default, so nothing is labeled
+			name:       "review command is accepted",
+			eventType:  "pull_request_review",
+			event:      reviewEvent("/kind cleanup"),
+			secret:     testSecret,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "other events are ignored",
+			eventType:  "push",
+			event:      &github.PushEvent{},
+			secret:     testSecret,
+			wantStatus: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added := make(chan []string, 1)
+			s := New(cfg, newTestClient(t, added), []byte(testSecret))
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, newWebhookRequest(t, tt.eventType, tt.event, tt.secret))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			s.inFlight.Wait()
+			var got []string
+			select {
+			case got = <-added:
+			default:
+			}
+			if !reflect.DeepEqual(got, tt.wantLabels) {
+				t.Fatalf("expected labels %v to be added, got %v", tt.wantLabels, got)
+			}
+		})
+	}
+}
+
+func TestHandleWebhook_HelpIsNotDebounced(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	helped := make(chan struct{}, 1)
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.PostReposIssuesCommentsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				helped <- struct{}{}
+				json.NewEncoder(w).Encode(&github.IssueComment{})
+			}),
+		),
+	)
+	s := New(cfg, github.NewClient(httpClient), []byte(testSecret)).WithDebounce(time.Hour)
+	for _, req := range []*http.Request{
+		newWebhookRequest(t, "pull_request", pullRequestEvent("opened", "/kind fix"), testSecret),
+		newWebhookRequest(t, "issue_comment", issueCommentEvent("/help"), testSecret),
+	} {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, rec.Code, rec.Body.String())
+		}
+	}
+	select {
+	case <-helped:
+	case <-time.After(time.Second):
+		t.Fatalf("expected /help to be replied to without waiting for the debounce window")
+	}
+	s.debounce.mu.Lock()
+	p, ok := s.debounce.pending["owner/repo#7"]
+	s.debounce.mu.Unlock()
+	if !ok || p.e.Action != event.Opened {
+		t.Fatalf("expected the opened event to stay pending")
+	}
+	s.debounce.take("owner/repo#7")
+	s.inFlight.Done()
+	s.inFlight.Wait()
+}
+
+func TestDispatchIgnoresOwnChecks(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	repo := &github.Repository{
+		Name:     github.Ptr("repo"),
+		FullName: github.Ptr("owner/repo"),
+		Owner:    &github.User{Login: github.Ptr("owner")},
+	}
+	suite := func(app string) *github.CheckSuiteEvent {
+		return &github.CheckSuiteEvent{
+			Action: github.Ptr("completed"),
+			CheckSuite: &github.CheckSuite{
+				HeadSHA:      github.Ptr("abc123"),
+				App:          &github.App{Slug: github.Ptr(app)},
+				PullRequests: []*github.PullRequest{{Number: github.Ptr(7)}},
+			},
+			Repo: repo,
+		}
+	}
+	status := func(context string) *github.StatusEvent {
+		return &github.StatusEvent{State: github.Ptr("failure"), SHA: github.Ptr("abc123"), Context: github.Ptr(context), Repo: repo}
+	}
+	tests := []struct {
+		name      string
+		eventType string
+		event     any
+		want      bool
+	}{
+		{name: "check suite of CI", eventType: "check_suite", event: suite("github-actions"), want: true},
+		{name: "own check suite", eventType: "check_suite", event: suite(labeler.CheckRunName)},
+		{name: "status of CI", eventType: "status", event: status("ci/test"), want: true},
+		{name: "own status", eventType: "status", event: status(labeler.CheckRunName)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(cfg, github.NewClient(nil), []byte(testSecret))
+			payload, err := json.Marshal(tt.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := s.dispatch(tt.eventType, payload)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (e != nil) != tt.want {
+				t.Fatalf("dispatched = %v, want %v", e != nil, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunDrainsInFlightWebhooks(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	release := make(chan struct{})
+	var processed sync.WaitGroup
+	processed.Add(1)
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// hold the run open until the server has begun shutting down
+				<-release
+				json.NewEncoder(w).Encode([]*github.Label{})
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				processed.Done()
+				json.NewEncoder(w).Encode([]*github.Label{})
+			}),
+		),
+	)
+	s := New(cfg, github.NewClient(httpClient), []byte(testSecret))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx, addr, 5*time.Second) }()
+
+	// wait for the listener before delivering the webhook
+	for i := 0; ; i++ {
+		resp, err := http.Get("http://" + addr + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if i == 50 {
+			t.Fatalf("server never became ready: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	req := newWebhookRequest(t, "pull_request", pullRequestEvent("edited", "/kind fix\n```release-note\nNONE\n```"), testSecret)
+	req.RequestURI = ""
+	req.URL.Scheme, req.URL.Host = "http", addr
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to deliver webhook: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		t.Fatalf("expected Run to wait for the in-flight webhook, returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	processed.Wait()
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "enforceDescription: false\nmode: lenient\nkindMilestones:\n  breaking_change: next-major\ntriageAssignees: [alice]\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *cfg.EnforceDescription || cfg.Mode != labeler.ModeLenient || cfg.KindMilestones["breaking_change"] != "next-major" || !reflect.DeepEqual(cfg.TriageAssignees, []string{"alice"}) {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	if err := os.WriteFile(path, []byte("mode: loud\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected an invalid mode to be rejected")
+	}
+	if err := os.WriteFile(path, []byte("enforce_description: false\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected an unknown field to be rejected")
+	}
+	if err := os.WriteFile(path, []byte("authorPolicies:\n  maintainer:\n    autoNoneKinds: [flake]\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected an unknown author association to be rejected")
+	}
+	if err := os.WriteFile(path, []byte("reporters: [check-run, summary]\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected the job summary reporter to be rejected")
+	}
+	if err := os.WriteFile(path, []byte("bots:\n  kind: dependencies\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected an unsupported bots kind to be rejected")
+	}
+	if err := os.WriteFile(path, []byte("kindMilestones:\n  breaking: next-major\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected a milestone for an unsupported kind to be rejected")
+	}
+}
+
+func TestHandleWebhook_LabelEventsUpdateCache(t *testing.T) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	s := New(cfg, github.NewClient(nil), []byte(testSecret))
+	e := pullRequestEvent("labeled", "")
+	e.PullRequest.Labels = []*github.Label{{Name: github.Ptr("kind/fix")}, {Name: github.Ptr("lgtm")}}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, newWebhookRequest(t, "pull_request", e, testSecret))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+	got, _, fresh := s.labels.Get("owner", "repo", 7)
+	if !fresh || !reflect.DeepEqual(got, []string{"kind/fix", "lgtm"}) {
+		t.Fatalf("expected fresh cached labels [kind/fix lgtm], got %v (fresh=%v)", got, fresh)
+	}
+}
+
+func TestHandleWebhook_IgnoresDisabledRepositories(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     RepositoryFilter
+		wantStatus int
+	}{
+		{name: "no filter", wantStatus: http.StatusAccepted},
+		{name: "included by glob", filter: RepositoryFilter{Include: []string{"Owner/*"}}, wantStatus: http.StatusAccepted},
+		{name: "not opted in", filter: RepositoryFilter{Include: []string{"owner/other"}}, wantStatus: http.StatusNoContent},
+		{name: "excluded", filter: RepositoryFilter{Include: []string{"owner/*"}, Exclude: []string{"owner/repo"}}, wantStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig("")
+			if err != nil {
+				t.Fatalf("failed to load default config: %v", err)
+			}
+			cfg.Repositories = tt.filter
+			added := make(chan []string, 1)
+			s := New(cfg, newTestClient(t, added), []byte(testSecret))
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, newWebhookRequest(t, "pull_request", pullRequestEvent("opened", "/kind fix\n```release-note\nNONE\n```"), testSecret))
+			s.inFlight.Wait()
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleMergeable(t *testing.T) {
+	const token = "api-token"
+	tests := []struct {
+		name          string
+		token         string
+		auth          string
+		path          string
+		body          string
+		wantStatus    int
+		wantMergeable bool
+	}{
+		{name: "API disabled without a token", auth: "Bearer ", path: "/mergeable/owner/repo/1", wantStatus: http.StatusNotFound},
+		{name: "wrong token", token: token, auth: "Bearer wrong", path: "/mergeable/owner/repo/1", wantStatus: http.StatusUnauthorized},
+		{name: "invalid PR number", token: token, auth: "Bearer " + token, path: "/mergeable/owner/repo/abc", wantStatus: http.StatusBadRequest},
+		{
+			name:          "mergeable PR",
+			token:         token,
+			auth:          "Bearer " + token,
+			path:          "/mergeable/owner/repo/1",
+			body:          "# Description\nFix.\n/kind fix\n```release-note\nNONE\n```",
+			wantStatus:    http.StatusOK,
+			wantMergeable: true,
+		},
+		{
+			name:       "PR without a kind",
+			token:      token,
+			auth:       "Bearer " + token,
+			path:       "/mergeable/owner/repo/1",
+			body:       "# Description\nFix.\n```release-note\nNONE\n```",
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig("")
+			if err != nil {
+				t.Fatalf("failed to load default config: %v", err)
+			}
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposPullsByOwnerByRepoByPullNumber, &github.PullRequest{
+					Number: github.Ptr(1),
+					Body:   github.Ptr(tt.body),
+					User:   &github.User{Login: github.Ptr("author")},
+				}),
+				mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+			)
+			s := New(cfg, github.NewClient(httpClient), []byte(testSecret)).WithAPIToken(tt.token)
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.Header.Set("Authorization", tt.auth)
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, r)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var v labeler.Verdict
+			if err := json.NewDecoder(rec.Body).Decode(&v); err != nil {
+				t.Fatalf("failed to decode verdict: %v", err)
+			}
+			if v.Mergeable != tt.wantMergeable {
+				t.Fatalf("expected mergeable %v, got %+v", tt.wantMergeable, v)
+			}
+		})
+	}
+}
+
+func TestDebugVarsNeedsAPIToken(t *testing.T) {
+	const token = "t0ken"
+	tests := []struct {
+		name       string
+		token      string
+		auth       string
+		wantStatus int
+	}{
+		{name: "no API token configured", auth: "Bearer " + token, wantStatus: http.StatusNotFound},
+		{name: "missing token", token: token, wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: token, auth: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", token: token, auth: "Bearer " + token, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig("")
+			if err != nil {
+				t.Fatalf("failed to load default config: %v", err)
+			}
+			s := New(cfg, github.NewClient(nil), []byte(testSecret)).WithAPIToken(tt.token)
+			r := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+			r.Header.Set("Authorization", tt.auth)
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, r)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK && !strings.Contains(rec.Body.String(), "pr_kind_labeler_time_to_green") {
+				t.Fatalf("expected the metrics, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRun_RefetchPR(t *testing.T) {
+	current := "# Description\nFix.\n/kind fix\n```release-note\nFixed a crash.\n```"
+	stale := "# Description\nFix.\n/kind cleanup\n```release-note\nNONE\n```"
+	for _, refetch := range []bool{false, true} {
+		cfg, err := LoadConfig("")
+		if err != nil {
+			t.Fatalf("failed to load default config: %v", err)
+		}
+		cfg.RefetchPR = refetch
+		httpClient := mock.NewMockedHTTPClient(
+			mock.WithRequestMatch(mock.GetReposIssuesLabelsByOwnerByRepoByIssueNumber, []*github.Label{}),
+			mock.WithRequestMatch(mock.GetReposPullsByOwnerByRepoByPullNumber, &github.PullRequest{Body: github.Ptr(current)}),
+		)
+		s := New(cfg, github.NewClient(httpClient), []byte(testSecret))
+		e := &event.PullRequest{Action: event.Edited, Owner: "owner", Repo: "repo", Number: 7, Body: stale}
+		d, err := s.run(context.Background(), e, false)
+		if err != nil {
+			t.Fatalf("refetch %v: unexpected error: %v", refetch, err)
+		}
+		want := "kind/cleanup"
+		if refetch {
+			want = "kind/fix"
+		}
+		if !slices.Contains(d.LabelsToAdd, want) {
+			t.Errorf("refetch %v: labels to add = %v, want %s", refetch, d.LabelsToAdd, want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/config"
+)
+
+// RepoConfigPath is where a repository can override the server config.
+const RepoConfigPath = config.Path
+
+// tenantKey identifies the tenant an event belongs to: the GitHub App
+// installation when the event carries one, otherwise the repository owner.
+func tenantKey(e *event.PullRequest) string {
+	if e.Installation != 0 {
+		return "installation/" + strconv.FormatInt(e.Installation, 10)
+	}
+	return "owner/" + e.Owner
+}
+
+type cachedConfig struct {
+	cfg     *Config
+	err     error
+	expires time.Time
+}
+
+// tenants caches per-repository configs and rate limits processing per
+// tenant, so one busy or misconfigured tenant cannot degrade the others.
+type tenants struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	rps      rate.Limit
+	burst    int
+	now      func() time.Time
+	load     func(ctx context.Context, owner, repo string) (*Config, error)
+	configs  map[string]cachedConfig
+	limiters map[string]*rate.Limiter
+}
+
+// config returns the config for owner/repo, loading it at most once per TTL.
+// Load failures are cached too, so a broken config file is not refetched on
+// every event.
+func (t *tenants) config(ctx context.Context, tenant, owner, repo string) (*Config, error) {
+	key := tenant + "/" + owner + "/" + repo
+	t.mu.Lock()
+	cached, ok := t.configs[key]
+	t.mu.Unlock()
+	if ok && t.now().Before(cached.expires) {
+		return cached.cfg, cached.err
+	}
+
+	cfg, err := t.load(ctx, owner, repo)
+	t.mu.Lock()
+	t.configs[key] = cachedConfig{cfg: cfg, err: err, expires: t.now().Add(t.ttl)}
+	t.mu.Unlock()
+	return cfg, err
+}
+
+// limiter returns the rate limiter shared by every event of tenant.
+func (t *tenants) limiter(tenant string) *rate.Limiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l, ok := t.limiters[tenant]
+	if !ok {
+		l = rate.NewLimiter(t.rps, t.burst)
+		t.limiters[tenant] = l
+	}
+	return l
+}
+
+// repoConfigLoader returns a loader that overlays the repository's
+// RepoConfigPath, if present, on base.
+func repoConfigLoader(client *github.Client, base *Config) func(ctx context.Context, owner, repo string) (*Config, error) {
+	return func(ctx context.Context, owner, repo string) (*Config, error) {
+		file, _, resp, err := client.Repositories.GetContents(ctx, owner, repo, RepoConfigPath, nil)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return base, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", RepoConfigPath, err)
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", RepoConfigPath, err)
+		}
+		cfg := base.clone()
+		cfg.Repositories = RepositoryFilter{}
+		if err := yaml.UnmarshalStrict([]byte(content), cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", RepoConfigPath, err)
+		}
+		if !cfg.Repositories.isZero() {
+			return nil, fmt.Errorf("invalid %s: repositories can only be set in the server config", RepoConfigPath)
+		}
+		cfg.Repositories = base.Repositories
+		if err := cfg.validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", RepoConfigPath, err)
+		}
+		return cfg, nil
+	}
+}
+
+// clone returns a deep copy of c, so overlays never modify the server config.
+func (c *Config) clone() *Config {
+	out := *c
+	if c.EnforceDescription != nil {
+		enforce := *c.EnforceDescription
+		out.EnforceDescription = &enforce
+	}
+	out.KindMilestones = maps.Clone(c.KindMilestones)
+	out.AuthorPolicies = maps.Clone(c.AuthorPolicies)
+	out.Kinds = slices.Clone(c.Kinds)
+	out.DeprecatedKinds = maps.Clone(c.DeprecatedKinds)
+	out.Labels = maps.Clone(c.Labels)
+	return &out
+}
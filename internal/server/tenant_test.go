@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+func TestRepoConfigLoader(t *testing.T) {
+	base, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	base.KindMilestones = map[string]string{"breaking_change": "next-major"}
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var content string
+				switch r.URL.Path {
+				case "/repos/owner/overlay/contents/.github/pr-kind-labeler.yaml":
+					content = "enforceDescription: false\nmode: report-only\nkindMilestones:\n  feature: v1.20\n"
+				case "/repos/owner/broken/contents/.github/pr-kind-labeler.yaml":
+					content = "mode: [\n"
+				case "/repos/owner/sneaky/contents/.github/pr-kind-labeler.yaml":
+					content = "repositories:\n  include: [\"*/*\"]\n"
+				default:
+					mock.WriteError(w, http.StatusNotFound, "Not Found")
+					return
+				}
+				w.Write(mock.MustMarshal(github.RepositoryContent{
+					Encoding: github.Ptr("base64"),
+					Content:  github.Ptr(base64.StdEncoding.EncodeToString([]byte(content))),
+				}))
+			}),
+		),
+	)
+	load := repoConfigLoader(github.NewClient(httpClient), base)
+
+	cfg, err := load(context.Background(), "owner", "overlay")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *cfg.EnforceDescription || cfg.Mode != labeler.ModeReportOnly || cfg.KindMilestones["feature"] != "v1.20" || cfg.KindMilestones["breaking_change"] != "next-major" {
+		t.Fatalf("expected the repository config to overlay the base config, got %+v", cfg)
+	}
+	if !*base.EnforceDescription || base.Mode != labeler.ModeStrict || len(base.KindMilestones) != 1 {
+		t.Fatalf("expected the base config to be unchanged, got %+v", base)
+	}
+
+	if cfg, err := load(context.Background(), "owner", "missing"); err != nil || cfg != base {
+		t.Fatalf("expected the base config when the repository has no config, got %+v, %v", cfg, err)
+	}
+	if _, err := load(context.Background(), "owner", "broken"); err == nil {
+		t.Fatal("expected a broken repository config to fail")
+	}
+	if _, err := load(context.Background(), "owner", "sneaky"); err == nil {
+		t.Fatal("expected a repository config enabling repositories to be rejected")
+	}
+}
+
+func TestTenantsCacheConfigs(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	loads := map[string]int{}
+	tn := &tenants{
+		ttl: time.Minute,
+		now: func() time.Time { return now },
+		load: func(ctx context.Context, owner, repo string) (*Config, error) {
+			loads[owner+"/"+repo]++
+			return &Config{}, nil
+		},
+		configs: map[string]cachedConfig{},
+	}
+	tn.config(context.Background(), "installation/1", "owner", "repo")
+	tn.config(context.Background(), "installation/1", "owner", "repo")
+	if loads["owner/repo"] != 1 {
+		t.Fatalf("expected the config to be cached, loaded %d times", loads["owner/repo"])
+	}
+	now = now.Add(2 * time.Minute)
+	tn.config(context.Background(), "installation/1", "owner", "repo")
+	if loads["owner/repo"] != 2 {
+		t.Fatalf("expected the config to be reloaded after the TTL, loaded %d times", loads["owner/repo"])
+	}
+}
+
+func TestTenantKey(t *testing.T) {
+	e := &event.PullRequest{Owner: "owner", Repo: "repo", Number: 7}
+	if got := tenantKey(e); got != "owner/owner" {
+		t.Fatalf("expected events without an installation to be keyed by owner, got %q", got)
+	}
+	e.Installation = 42
+	if got := tenantKey(e); got != "installation/42" {
+		t.Fatalf("expected events to be keyed by installation, got %q", got)
+	}
+}
@@ -0,0 +1,295 @@
+// Package setup checks that a repository is set up for the labeler's
+// verdicts to gate merging: that branch protection requires its check, and
+// that the merge bot refuses to merge PRs with do-not-merge/* labels.
+package setup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// Check names what a Finding checked.
+type Check string
+
+const (
+	// RequiredCheck checks that the protected branch requires the labeler's
+	// check run or status.
+	RequiredCheck Check = "required-check"
+	// MergeBot checks that the merge bot does not merge PRs with
+	// do-not-merge/* labels.
+	MergeBot Check = "merge-bot"
+)
+
+// Finding is the result of one check of a repository.
+type Finding struct {
+	Repository string `json:"repository"`
+	Check      Check  `json:"check"`
+	OK         bool   `json:"ok"`
+	Message    string `json:"message"`
+}
+
+// Options controls what Verify expects.
+type Options struct {
+	// Branch is the branch PRs merge into. Empty means the default branch.
+	Branch string
+	// Checks are the names of the check runs or statuses that report the
+	// labeler's verdict, e.g. the workflow job running it; requiring any of
+	// them is enough. Defaults to the labeler's check run.
+	Checks []string
+	// Labels are the labels that must block merging. Defaults to the
+	// do-not-merge/* labels of the catalog.
+	Labels []string
+}
+
+// mergeBotConfigs are where supported merge bots keep their config.
+var mergeBotConfigs = []struct {
+	bot   string
+	paths []string
+	// blocks returns the labels among want that config blocks merging on.
+	blocks func(config []byte, want []string) ([]string, error)
+}{
+	{bot: "Mergify", paths: []string{".mergify.yml", ".mergify/config.yml", ".github/mergify.yml"}, blocks: mergifyBlocks},
+	{bot: "Kodiak", paths: []string{".kodiak.toml", ".github/.kodiak.toml"}, blocks: kodiakBlocks},
+}
+
+// Verify checks how owner/repo is set up and returns a finding per check.
+func Verify(ctx context.Context, client *github.Client, owner, repo string, opts Options) ([]Finding, error) {
+	if len(opts.Checks) == 0 {
+		opts.Checks = []string{labeler.CheckRunName}
+	}
+	if len(opts.Labels) == 0 {
+		for _, def := range labels.Catalog() {
+			if strings.HasPrefix(def.Name, "do-not-merge/") {
+				opts.Labels = append(opts.Labels, def.Name)
+			}
+		}
+	}
+	if opts.Branch == "" {
+		r, _, err := client.Repositories.Get(ctx, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repository: %w", err)
+		}
+		opts.Branch = r.GetDefaultBranch()
+	}
+
+	fullName := owner + "/" + repo
+	required, err := verifyRequiredCheck(ctx, client, owner, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	required.Repository = fullName
+	bots, err := verifyMergeBot(ctx, client, owner, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	findings := []Finding{required}
+	for _, f := range bots {
+		f.Repository = fullName
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+// verifyRequiredCheck checks that branch protection or a ruleset requires
+// one of opts.Checks on opts.Branch.
+func verifyRequiredCheck(ctx context.Context, client *github.Client, owner, repo string, opts Options) (Finding, error) {
+	var required []string
+	protection, _, err := client.Repositories.GetBranchProtection(ctx, owner, repo, opts.Branch)
+	switch {
+	case errors.Is(err, github.ErrBranchNotProtected):
+	case err != nil:
+		return Finding{}, fmt.Errorf("failed to get branch protection of %s: %w", opts.Branch, err)
+	default:
+		if checks := protection.GetRequiredStatusChecks(); checks != nil {
+			if checks.Contexts != nil {
+				required = append(required, *checks.Contexts...)
+			}
+			if checks.Checks != nil {
+				for _, c := range *checks.Checks {
+					required = append(required, c.Context)
+				}
+			}
+		}
+	}
+	rules, _, err := client.Repositories.GetRulesForBranch(ctx, owner, repo, opts.Branch)
+	if err != nil {
+		return Finding{}, fmt.Errorf("failed to get rulesets of %s: %w", opts.Branch, err)
+	}
+	for _, rule := range rules {
+		if rule.Type != "required_status_checks" || rule.Parameters == nil {
+			continue
+		}
+		var params github.RequiredStatusChecksRuleParameters
+		if err := json.Unmarshal(*rule.Parameters, &params); err != nil {
+			return Finding{}, fmt.Errorf("failed to parse ruleset %d: %w", rule.RulesetID, err)
+		}
+		for _, c := range params.RequiredStatusChecks {
+			required = append(required, c.Context)
+		}
+	}
+
+	for _, name := range opts.Checks {
+		for _, r := range required {
+			if r == name {
+				return Finding{Check: RequiredCheck, OK: true, Message: fmt.Sprintf("%s requires %s", opts.Branch, name)}, nil
+			}
+		}
+	}
+	msg := fmt.Sprintf("%s does not require %s in branch protection or a ruleset, so PRs failing validation can be merged", opts.Branch, strings.Join(opts.Checks, " or "))
+	if len(required) > 0 {
+		sort.Strings(required)
+		msg += fmt.Sprintf("; required checks are %s", strings.Join(required, ", "))
+	}
+	return Finding{Check: RequiredCheck, Message: msg}, nil
+}
+
+// verifyMergeBot checks that every merge bot configured in the repository
+// blocks merging on opts.Labels.
+func verifyMergeBot(ctx context.Context, client *github.Client, owner, repo string, opts Options) ([]Finding, error) {
+	var findings []Finding
+	for _, bot := range mergeBotConfigs {
+		for _, path := range bot.paths {
+			config, err := getFile(ctx, client, owner, repo, path, opts.Branch)
+			if err != nil {
+				return nil, err
+			}
+			if config == nil {
+				continue
+			}
+			blocked, err := bot.blocks(config, opts.Labels)
+			if err != nil {
+				findings = append(findings, Finding{Check: MergeBot, Message: fmt.Sprintf("%s config %s is invalid: %v", bot.bot, path, err)})
+				break
+			}
+			var missing []string
+			for _, label := range opts.Labels {
+				if !slices.Contains(blocked, label) {
+					missing = append(missing, label)
+				}
+			}
+			f := Finding{Check: MergeBot, OK: len(missing) == 0, Message: fmt.Sprintf("%s config %s blocks merging on every do-not-merge/* label", bot.bot, path)}
+			if !f.OK {
+				f.Message = fmt.Sprintf("%s config %s does not block merging on %s", bot.bot, path, strings.Join(missing, ", "))
+			}
+			findings = append(findings, f)
+			break
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Check: MergeBot, Message: "no Mergify or Kodiak config found, so do-not-merge/* labels only block merging through the required check"})
+	}
+	return findings, nil
+}
+
+// getFile returns the content of path on ref, or nil if there is none.
+func getFile(ctx context.Context, client *github.Client, owner, repo, path, ref string) ([]byte, error) {
+	file, _, resp, err := client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if file == nil {
+		// a directory
+		return nil, nil
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return []byte(content), nil
+}
+
+// mergifyBlocks returns the labels among want that a Mergify config requires
+// to be absent in any rule, with a condition such as -label=NAME,
+// label!=NAME or -label~=REGEXP.
+func mergifyBlocks(config []byte, want []string) ([]string, error) {
+	var doc any
+	if err := yaml.Unmarshal(config, &doc); err != nil {
+		return nil, err
+	}
+	var blocked []string
+	for _, cond := range allStrings(doc) {
+		cond = strings.Join(strings.Fields(cond), "")
+		negated := strings.HasPrefix(cond, "-")
+		rest, ok := strings.CutPrefix(strings.TrimPrefix(cond, "-"), "label")
+		if !ok {
+			continue
+		}
+		for _, label := range want {
+			var match bool
+			switch {
+			case strings.HasPrefix(rest, "!="):
+				match = !negated && rest[2:] == label
+			case strings.HasPrefix(rest, "~="):
+				re, err := regexp.Compile(rest[2:])
+				match = err == nil && negated && re.MatchString(label)
+			case strings.HasPrefix(rest, "=="):
+				match = negated && rest[2:] == label
+			case strings.HasPrefix(rest, "="):
+				match = negated && rest[1:] == label
+			}
+			if match && !slices.Contains(blocked, label) {
+				blocked = append(blocked, label)
+			}
+		}
+	}
+	return blocked, nil
+}
+
+// allStrings returns every string in a decoded YAML document.
+func allStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var all []string
+		for _, item := range v {
+			all = append(all, allStrings(item)...)
+		}
+		return all
+	case map[string]any:
+		var all []string
+		for _, item := range v {
+			all = append(all, allStrings(item)...)
+		}
+		return all
+	}
+	return nil
+}
+
+var (
+	kodiakBlockingLabelsRE = regexp.MustCompile(`(?m)^\s*blocking_labels\s*=\s*\[([^\]]*)\]`)
+	quotedRE               = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+)
+
+// kodiakBlocks returns the labels among want listed in the blocking_labels
+// of a Kodiak config.
+func kodiakBlocks(config []byte, want []string) ([]string, error) {
+	m := kodiakBlockingLabelsRE.FindSubmatch(config)
+	if m == nil {
+		return nil, nil
+	}
+	var blocked []string
+	for _, q := range quotedRE.FindAllSubmatch(m[1], -1) {
+		label := string(q[1]) + string(q[2])
+		if slices.Contains(want, label) {
+			blocked = append(blocked, label)
+		}
+	}
+	return blocked, nil
+}
@@ -0,0 +1,129 @@
+package setup
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func TestVerify(t *testing.T) {
+	requiredChecks := func(contexts ...string) []*github.RepositoryRule {
+		var params github.RequiredStatusChecksRuleParameters
+		for _, c := range contexts {
+			params.RequiredStatusChecks = append(params.RequiredStatusChecks, github.RuleRequiredStatusChecks{Context: c})
+		}
+		data, _ := json.Marshal(params)
+		raw := json.RawMessage(data)
+		return []*github.RepositoryRule{{Type: "required_status_checks", Parameters: &raw}}
+	}
+	tests := []struct {
+		name       string
+		protection *github.Protection
+		rules      []*github.RepositoryRule
+		files      map[string]string
+		want       map[Check]bool
+	}{
+		{
+			name: "branch protection and Mergify",
+			protection: &github.Protection{RequiredStatusChecks: &github.RequiredStatusChecks{
+				Checks: &[]*github.RequiredStatusCheck{{Context: "build"}, {Context: "pr-kind-labeler"}},
+			}},
+			files: map[string]string{
+				".mergify.yml": "queue_rules:\n  - name: default\n    queue_conditions:\n      - \"#approved-reviews-by>=1\"\n      - -label ~= ^do-not-merge/\n",
+			},
+			want: map[Check]bool{RequiredCheck: true, MergeBot: true},
+		},
+		{
+			name:  "ruleset and Kodiak missing labels",
+			rules: requiredChecks("pr-kind-labeler"),
+			files: map[string]string{
+				".github/.kodiak.toml": "version = 1\n[merge]\nblocking_labels = [\"do-not-merge/kind-invalid\", \"do-not-merge/hold\"]\n",
+			},
+			want: map[Check]bool{RequiredCheck: true, MergeBot: false},
+		},
+		{
+			name:  "not required and no merge bot",
+			rules: requiredChecks("build"),
+			want:  map[Check]bool{RequiredCheck: false, MergeBot: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.GetReposByOwnerByRepo, github.Repository{DefaultBranch: github.Ptr("main")}),
+				mock.WithRequestMatchHandler(
+					mock.GetReposBranchesProtectionByOwnerByRepoByBranch,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if tt.protection == nil {
+							mock.WriteError(w, http.StatusNotFound, "Branch not protected")
+							return
+						}
+						w.Write(mock.MustMarshal(tt.protection))
+					}),
+				),
+				mock.WithRequestMatch(mock.GetReposRulesBranchesByOwnerByRepoByBranch, tt.rules),
+				mock.WithRequestMatchHandler(
+					mock.GetReposContentsByOwnerByRepoByPath,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						content, ok := tt.files[r.URL.Path[len("/repos/owner/repo/contents/"):]]
+						if !ok {
+							mock.WriteError(w, http.StatusNotFound, "Not Found")
+							return
+						}
+						w.Write(mock.MustMarshal(github.RepositoryContent{
+							Encoding: github.Ptr("base64"),
+							Content:  github.Ptr(base64.StdEncoding.EncodeToString([]byte(content))),
+						}))
+					}),
+				),
+			)
+			findings, err := Verify(context.Background(), github.NewClient(httpClient), "owner", "repo", Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := map[Check]bool{}
+			for _, f := range findings {
+				if f.Repository != "owner/repo" {
+					t.Errorf("finding for %q, want owner/repo", f.Repository)
+				}
+				got[f.Check] = f.OK
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %+v, want ok %v", findings, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergifyBlocks(t *testing.T) {
+	want := []string{labels.InvalidKindLabel, labels.InvalidReleaseNoteLabel}
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{name: "regexp", config: "conditions: [\"-label~=^do-not-merge/\"]", want: want},
+		{name: "exact", config: "conditions: [\"-label=do-not-merge/kind-invalid\", \"label != do-not-merge/release-note-invalid\"]", want: want},
+		{name: "required rather than blocked", config: "conditions: [\"label=do-not-merge/kind-invalid\", \"-label!=do-not-merge/release-note-invalid\"]"},
+		{name: "other attribute", config: "conditions: [\"-title~=^WIP\"]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergifyBlocks([]byte(tt.config), want)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("blocked = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package teams resolves GitHub team membership for permission checks, with a
+// TTL cache so busy repositories do not spend their API quota re-checking the
+// same authors.
+package teams
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// ParseTeam splits a team reference in the org/team-slug format.
+func ParseTeam(ref string) (org, slug string, err error) {
+	org, slug, ok := strings.Cut(ref, "/")
+	if !ok || org == "" || slug == "" || strings.Contains(slug, "/") {
+		return "", "", fmt.Errorf("invalid team %q, expected org/team-slug", ref)
+	}
+	return org, slug, nil
+}
+
+type entry struct {
+	member  bool
+	expires time.Time
+}
+
+// Resolver answers whether users are active members of GitHub teams. Answers,
+// positive and negative, are cached for ttl. It is safe for concurrent use.
+type Resolver struct {
+	client *github.Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// NewResolver creates a resolver that caches answers for ttl. The client's
+// token needs read access to the organization's members.
+func NewResolver(client *github.Client, ttl time.Duration) *Resolver {
+	return &Resolver{client: client, ttl: ttl, now: time.Now, entries: map[string]entry{}}
+}
+
+// IsMember reports whether user is an active member of team, given as
+// org/team-slug. Pending invitations do not count.
+func (r *Resolver) IsMember(ctx context.Context, team, user string) (bool, error) {
+	org, slug, err := ParseTeam(team)
+	if err != nil {
+		return false, err
+	}
+	key := strings.ToLower(team + "@" + user)
+	r.mu.Lock()
+	e, ok := r.entries[key]
+	r.mu.Unlock()
+	if ok && r.now().Before(e.expires) {
+		return e.member, nil
+	}
+
+	membership, resp, err := r.client.Teams.GetTeamMembershipBySlug(ctx, org, slug, user)
+	member := false
+	switch {
+	case err == nil:
+		member = membership.GetState() == "active"
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		// GitHub answers 404 for non-members
+	default:
+		return false, fmt.Errorf("failed to get %s membership of team %s: %w", user, team, err)
+	}
+
+	r.mu.Lock()
+	r.entries[key] = entry{member: member, expires: r.now().Add(r.ttl)}
+	r.mu.Unlock()
+	return member, nil
+}
@@ -0,0 +1,83 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestResolverIsMember(t *testing.T) {
+	calls := 0
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetOrgsTeamsMembershipsByOrgByTeamSlugByUsername,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				switch r.URL.Path {
+				case "/orgs/org/teams/maintainers/memberships/alice":
+					w.Write(mock.MustMarshal(github.Membership{State: github.Ptr("active")}))
+				case "/orgs/org/teams/maintainers/memberships/bob":
+					w.Write(mock.MustMarshal(github.Membership{State: github.Ptr("pending")}))
+				default:
+					mock.WriteError(w, http.StatusNotFound, "Not Found")
+				}
+			}),
+		),
+	)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewResolver(github.NewClient(httpClient), time.Minute)
+	r.now = func() time.Time { return now }
+
+	tests := []struct {
+		user string
+		want bool
+	}{
+		{user: "alice", want: true},
+		{user: "bob", want: false},
+		{user: "mallory", want: false},
+	}
+	for _, tt := range tests {
+		got, err := r.IsMember(context.Background(), "org/maintainers", tt.user)
+		if err != nil {
+			t.Fatalf("IsMember(%s): %v", tt.user, err)
+		}
+		if got != tt.want {
+			t.Fatalf("IsMember(%s) = %v, want %v", tt.user, got, tt.want)
+		}
+	}
+
+	// answers, including negative ones, are cached until the TTL expires
+	for _, tt := range tests {
+		r.IsMember(context.Background(), "org/maintainers", tt.user)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 API calls within the TTL, got %d", calls)
+	}
+	now = now.Add(2 * time.Minute)
+	r.IsMember(context.Background(), "org/maintainers", "alice")
+	if calls != 4 {
+		t.Fatalf("expected an expired entry to be refetched, got %d calls", calls)
+	}
+}
+
+func TestResolverIsMember_Errors(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetOrgsTeamsMembershipsByOrgByTeamSlugByUsername,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusForbidden, "Resource not accessible by integration")
+			}),
+		),
+	)
+	r := NewResolver(github.NewClient(httpClient), time.Minute)
+	if _, err := r.IsMember(context.Background(), "org/maintainers", "alice"); err == nil {
+		t.Fatal("expected a permission error to be returned, not treated as non-membership")
+	}
+	if _, err := r.IsMember(context.Background(), "maintainers", "alice"); err == nil {
+		t.Fatal("expected a team without an org to be rejected")
+	}
+}
@@ -0,0 +1,189 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FaultsEnv is the environment variable that injects GitHub API failures, so
+// integration tests exercise retry, rollback and degradation paths. It holds
+// faults in the format read by ParseFaults.
+const FaultsEnv = "PR_KIND_LABELER_FAULTS"
+
+// Fault kinds, besides the HTTP statuses 403, 404, 422 and 500.
+const (
+	// FaultRateLimit responds as if the token's primary rate limit were
+	// exhausted.
+	FaultRateLimit = "ratelimit"
+	// FaultSecondaryRateLimit responds as if a secondary rate limit were hit.
+	FaultSecondaryRateLimit = "secondary"
+)
+
+var faultStatuses = map[string]int{
+	"403":                   http.StatusForbidden,
+	"404":                   http.StatusNotFound,
+	"422":                   http.StatusUnprocessableEntity,
+	"500":                   http.StatusInternalServerError,
+	FaultRateLimit:          http.StatusForbidden,
+	FaultSecondaryRateLimit: http.StatusForbidden,
+}
+
+// Fault is a failure injected in place of the response to matching requests.
+type Fault struct {
+	// Kind is 403, 404, 422, 500, FaultRateLimit or FaultSecondaryRateLimit.
+	Kind string
+	// Method matches the request method, or any if empty.
+	Method string
+	// Path matches the request path as a path.Match pattern, e.g.
+	// /repos/*/*/issues/*/labels, or any if empty.
+	Path string
+	// Probability is the chance that a matching request fails, from 0 to 1.
+	Probability float64
+	// Count is how many requests fail before the fault stops, or 0 for no
+	// limit, e.g. 1 to check that a single failure is retried.
+	Count int
+}
+
+// ParseFaults parses faults separated by semicolons or newlines. Each fault
+// is a kind followed by optional space-separated method=, path=, p= and n=
+// fields setting Method, Path, Probability (default 1) and Count, e.g.
+//
+//	500 method=POST n=1; ratelimit path=/repos/*/*/issues/*/labels p=0.5
+func ParseFaults(spec string) ([]Fault, error) {
+	var faults []Fault
+	for _, rule := range strings.FieldsFunc(spec, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(rule)
+		if len(fields) == 0 {
+			continue
+		}
+		f := Fault{Kind: strings.ToLower(fields[0]), Probability: 1}
+		if _, ok := faultStatuses[f.Kind]; !ok {
+			return nil, fmt.Errorf("unknown fault %q, expected 403, 404, 422, 500, %s or %s", fields[0], FaultRateLimit, FaultSecondaryRateLimit)
+		}
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			var err error
+			switch key {
+			case "method":
+				f.Method = strings.ToUpper(value)
+			case "path":
+				f.Path = value
+				_, err = path.Match(value, "/")
+			case "p":
+				f.Probability, err = strconv.ParseFloat(value, 64)
+				if err == nil && (f.Probability < 0 || f.Probability > 1) {
+					err = fmt.Errorf("must be between 0 and 1")
+				}
+			case "n":
+				f.Count, err = strconv.Atoi(value)
+				if err == nil && f.Count < 0 {
+					err = fmt.Errorf("must not be negative")
+				}
+			default:
+				err = fmt.Errorf("unknown field, expected method, path, p or n")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q in fault %q: %w", field, strings.TrimSpace(rule), err)
+			}
+		}
+		faults = append(faults, f)
+	}
+	return faults, nil
+}
+
+// FaultInjector fails requests matching its faults with the responses GitHub
+// would send, without sending them on. The first matching fault applies.
+type FaultInjector struct {
+	next   http.RoundTripper
+	faults []Fault
+	// rand returns a number in [0, 1) to decide probabilistic faults.
+	rand func() float64
+	now  func() time.Time
+
+	mu sync.Mutex
+	// injected counts the failures injected per fault.
+	injected []int
+}
+
+// NewFaultInjector injects faults into requests sent through next.
+func NewFaultInjector(next http.RoundTripper, faults []Fault) *FaultInjector {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &FaultInjector{next: next, faults: faults, rand: rand.Float64, now: time.Now, injected: make([]int, len(faults))}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (fi *FaultInjector) RoundTrip(req *http.Request) (*http.Response, error) {
+	if f := fi.match(req); f != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return fi.response(req, f), nil
+	}
+	return fi.next.RoundTrip(req)
+}
+
+// match returns the fault to inject for req, if any, and counts it.
+func (fi *FaultInjector) match(req *http.Request) *Fault {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	for i := range fi.faults {
+		f := &fi.faults[i]
+		if f.Method != "" && f.Method != req.Method {
+			continue
+		}
+		if ok, _ := path.Match(f.Path, req.URL.Path); f.Path != "" && !ok {
+			continue
+		}
+		if f.Count > 0 && fi.injected[i] >= f.Count {
+			continue
+		}
+		if f.Probability < 1 && fi.rand() >= f.Probability {
+			continue
+		}
+		fi.injected[i]++
+		return f
+	}
+	return nil
+}
+
+// response returns the response GitHub sends for the fault.
+func (fi *FaultInjector) response(req *http.Request, f *Fault) *http.Response {
+	header := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+	body := map[string]string{"message": "injected " + f.Kind + " fault"}
+	switch f.Kind {
+	case FaultRateLimit:
+		header.Set("X-RateLimit-Limit", "5000")
+		header.Set("X-RateLimit-Remaining", "0")
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(fi.now().Add(time.Minute).Unix(), 10))
+		body["message"] = "API rate limit exceeded (injected fault)"
+		body["documentation_url"] = "https://docs.github.com/rest/overview/resources-in-the-rest-api#rate-limiting"
+	case FaultSecondaryRateLimit:
+		header.Set("Retry-After", "1")
+		body["message"] = "You have exceeded a secondary rate limit (injected fault)"
+		body["documentation_url"] = "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"
+	}
+	data, _ := json.Marshal(body)
+	status := faultStatuses[f.Kind]
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}
+}
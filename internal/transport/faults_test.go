@@ -0,0 +1,104 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+)
+
+func TestParseFaults(t *testing.T) {
+	got, err := ParseFaults("500 method=post n=1; ratelimit path=/repos/*/*/issues/*/labels p=0.5\n404")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Fault{
+		{Kind: "500", Method: "POST", Probability: 1, Count: 1},
+		{Kind: FaultRateLimit, Path: "/repos/*/*/issues/*/labels", Probability: 0.5},
+		{Kind: "404", Probability: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseFaults() = %+v, want %+v", got, want)
+	}
+	if got, err := ParseFaults(""); err != nil || got != nil {
+		t.Fatalf("expected no faults, got %+v, %v", got, err)
+	}
+	for _, spec := range []string{"418", "500 p=2", "500 n=-1", "500 retries=3", "500 path=["} {
+		if _, err := ParseFaults(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestFaultInjector(t *testing.T) {
+	var sent int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	newClient := func(spec string) *github.Client {
+		faults, err := ParseFaults(spec)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		client := github.NewClient(&http.Client{Transport: NewFaultInjector(srv.Client().Transport, faults)})
+		client, _ = client.WithEnterpriseURLs(srv.URL+"/", srv.URL+"/")
+		return client
+	}
+	ctx := context.Background()
+
+	t.Run("status for matching requests only", func(t *testing.T) {
+		sent = 0
+		client := newClient("422 method=POST path=/api/v3/repos/*/*/issues/*/labels n=1")
+		if _, _, err := client.Issues.ListLabelsByIssue(ctx, "o", "r", 1, nil); err != nil {
+			t.Fatalf("expected reads to pass, got %v", err)
+		}
+		_, resp, err := client.Issues.AddLabelsToIssue(ctx, "o", "r", 1, []string{"kind/fix"})
+		if err == nil || resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("expected an injected 422, got %v", err)
+		}
+		if _, _, err := client.Issues.AddLabelsToIssue(ctx, "o", "r", 1, []string{"kind/fix"}); err != nil {
+			t.Fatalf("expected the fault to stop after n=1, got %v", err)
+		}
+		if sent != 2 {
+			t.Fatalf("expected 2 requests to reach the server, got %d", sent)
+		}
+	})
+
+	t.Run("primary rate limit", func(t *testing.T) {
+		_, _, err := newClient("ratelimit").Issues.ListLabelsByIssue(ctx, "o", "r", 1, nil)
+		var rateErr *github.RateLimitError
+		if !errors.As(err, &rateErr) {
+			t.Fatalf("expected a rate limit error, got %v", err)
+		}
+	})
+
+	t.Run("secondary rate limit", func(t *testing.T) {
+		_, _, err := newClient("secondary").Issues.ListLabelsByIssue(ctx, "o", "r", 1, nil)
+		var abuseErr *github.AbuseRateLimitError
+		if !errors.As(err, &abuseErr) {
+			t.Fatalf("expected a secondary rate limit error, got %v", err)
+		}
+	})
+
+	t.Run("probability", func(t *testing.T) {
+		sent = 0
+		fi := NewFaultInjector(srv.Client().Transport, []Fault{{Kind: "500", Probability: 0.5}})
+		rolls := []float64{0.7, 0.2}
+		fi.rand = func() float64 { r := rolls[0]; rolls = rolls[1:]; return r }
+		for _, want := range []int{http.StatusOK, http.StatusInternalServerError} {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			resp, err := fi.RoundTrip(req)
+			if err != nil || resp.StatusCode != want {
+				t.Fatalf("expected %d, got %v, %v", want, resp, err)
+			}
+			resp.Body.Close()
+		}
+	})
+}
@@ -0,0 +1,43 @@
+// Package transport provides http.RoundTripper middleware for the GitHub
+// API client.
+package transport
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// WriteLimiter smooths GitHub writes through a single token bucket shared by
+// every request made with it. GitHub applies secondary rate limits to bursts
+// of content-creating requests, which reprocess storms and busy webhook
+// periods would otherwise trigger. Reads are not limited.
+type WriteLimiter struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+// NewWriteLimiter limits writes sent through next to rps per second with the
+// given burst. A non-positive rps disables limiting.
+func NewWriteLimiter(next http.RoundTripper, rps float64, burst int) *WriteLimiter {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	limit := rate.Limit(rps)
+	if rps <= 0 {
+		limit = rate.Inf
+	}
+	return &WriteLimiter{next: next, limiter: rate.NewLimiter(limit, burst)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (w *WriteLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		if err := w.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	return w.next.RoundTrip(req)
+}
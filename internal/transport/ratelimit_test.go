@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteLimiter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	client := &http.Client{Transport: NewWriteLimiter(srv.Client().Transport, 10, 1)}
+
+	do := func(method string) {
+		req, _ := http.NewRequest(method, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", method, err)
+		}
+		resp.Body.Close()
+	}
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		do(http.MethodGet)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected reads not to be limited, took %s", elapsed)
+	}
+
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		do(http.MethodPost)
+	}
+	// the first write uses the burst, the next two wait ~100ms each
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("expected writes to be limited to 10 rps, took %s", elapsed)
+	}
+}
+
+func TestWriteLimiter_HonorsContext(t *testing.T) {
+	limiter := NewWriteLimiter(http.DefaultTransport, 0.001, 1)
+	limiter.limiter.Allow() // drain the burst
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://example.invalid", nil)
+	if _, err := limiter.RoundTrip(req); err == nil {
+		t.Fatal("expected a cancelled request to fail while waiting for the limiter")
+	}
+}
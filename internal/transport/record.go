@@ -0,0 +1,262 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/secrets"
+)
+
+// recordedHeaders are the headers kept in cassettes. Every other header is
+// dropped, so credentials such as Authorization and cookies never reach them.
+var recordedHeaders = []string{
+	"Accept",
+	"Content-Type",
+	"ETag",
+	"If-None-Match",
+	"Link",
+	"Location",
+	"Retry-After",
+	"X-GitHub-Api-Version",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"X-RateLimit-Resource",
+	"X-RateLimit-Used",
+}
+
+// Interaction is a GitHub API request and the response to it, as recorded in
+// a cassette.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a sanitized request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a sanitized response.
+type RecordedResponse struct {
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// Error is why the request got no response, if it did not.
+	Error string `json:"error,omitempty"`
+}
+
+// Recorder appends the GitHub API requests sent through its transports and
+// the responses to them to a cassette, a file of one Interaction per line,
+// that users can attach to bug reports and tests can replay with a Replayer.
+// Only allowlisted headers are kept and credentials are redacted from URLs
+// and bodies. Interactions are written as they complete, so a run that fails
+// or crashes is recorded up to the failure.
+type Recorder struct {
+	path string
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecorder creates a cassette in dir, which is created if needed.
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(dir, "cassette-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{path: f.Name(), enc: json.NewEncoder(f)}, nil
+}
+
+// Path returns the path of the cassette.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Transport returns a transport that records the requests sent through next.
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recordingTransport{next: next, recorder: r}
+}
+
+type recordingTransport struct {
+	next     http.RoundTripper
+	recorder *Recorder
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	in := Interaction{Request: RecordedRequest{
+		Method: req.Method,
+		URL:    secrets.Redact(req.URL.String()),
+		Header: sanitizeHeader(req.Header),
+		Body:   secrets.Redact(string(reqBody)),
+	}}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		in.Response.Error = secrets.Redact(err.Error())
+		t.recorder.record(in)
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return nil, err
+	}
+	in.Response = RecordedResponse{
+		Status: resp.StatusCode,
+		Header: sanitizeHeader(resp.Header),
+		Body:   secrets.Redact(string(respBody)),
+	}
+	t.recorder.record(in)
+	return resp, nil
+}
+
+// record appends in to the cassette. Failing to record does not fail the
+// request.
+func (r *Recorder) record(in Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(in); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record %s %s: %v\n", in.Request.Method, in.Request.URL, err)
+	}
+}
+
+// sanitizeHeader returns the recorded headers of h.
+func sanitizeHeader(h http.Header) http.Header {
+	kept := http.Header{}
+	for _, name := range recordedHeaders {
+		if values := h.Values(name); len(values) > 0 {
+			kept[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// LoadCassette reads the interactions of a cassette written by a Recorder.
+func LoadCassette(path string) ([]Interaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var interactions []Interaction
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var in Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &in); err != nil {
+			return nil, fmt.Errorf("invalid interaction on line %d: %w", line, err)
+		}
+		interactions = append(interactions, in)
+	}
+	return interactions, scanner.Err()
+}
+
+// Replayer answers requests with the responses of a cassette instead of
+// sending them, so a recorded run can be reproduced without GitHub. Each
+// request gets the first response to the same method and URL not replayed
+// yet, or the last one once all were, e.g. when a run reads more often than
+// the recorded one.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// NewReplayer replays interactions.
+func NewReplayer(interactions []Interaction) *Replayer {
+	return &Replayer{interactions: interactions, replayed: make([]bool, len(interactions))}
+}
+
+// Unreplayed returns the interactions no request was answered with yet, so
+// tests can tell when a run no longer makes a recorded request.
+func (r *Replayer) Unreplayed() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unreplayed []Interaction
+	for i, in := range r.interactions {
+		if !r.replayed[i] {
+			unreplayed = append(unreplayed, in)
+		}
+	}
+	return unreplayed
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	url := secrets.Redact(req.URL.String())
+	r.mu.Lock()
+	match := -1
+	for i, in := range r.interactions {
+		if in.Request.Method != req.Method || in.Request.URL != url {
+			continue
+		}
+		match = i
+		if !r.replayed[i] {
+			break
+		}
+	}
+	if match >= 0 {
+		r.replayed[match] = true
+	}
+	r.mu.Unlock()
+	if match < 0 {
+		return nil, fmt.Errorf("no recorded response to %s %s", req.Method, url)
+	}
+
+	recorded := r.interactions[match].Response
+	if recorded.Error != "" {
+		return nil, errors.New(recorded.Error)
+	}
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
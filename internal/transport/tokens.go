@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TokenRotator authenticates GitHub requests with one of several tokens. When
+// the current token is rejected or its rate limit is exhausted, the request
+// is retried with the next token, which then stays current, so long-running
+// modes are not limited to a single token's quota.
+type TokenRotator struct {
+	next   http.RoundTripper
+	tokens []string
+
+	mu      sync.Mutex
+	current int
+}
+
+// NewTokenRotator authenticates requests sent through next with tokens, in
+// order.
+func NewTokenRotator(next http.RoundTripper, tokens []string) *TokenRotator {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &TokenRotator{next: next, tokens: tokens}
+}
+
+// SplitTokens splits a list of tokens separated by commas or whitespace, so
+// several tokens can be passed in a single input or environment variable.
+func SplitTokens(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *TokenRotator) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.tokens) == 0 {
+		return t.next.RoundTrip(req)
+	}
+	t.mu.Lock()
+	start := t.current
+	t.mu.Unlock()
+
+	// the last token's response is returned whatever it is
+	for i := 0; ; i++ {
+		idx := (start + i) % len(t.tokens)
+		attempt := req.Clone(req.Context())
+		if i > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt.Body = body
+		}
+		attempt.Header.Set("Authorization", "Bearer "+t.tokens[idx])
+		resp, err := t.next.RoundTrip(attempt)
+		if err != nil {
+			return nil, err
+		}
+		last := i == len(t.tokens)-1 || (req.Body != nil && req.GetBody == nil)
+		if !tokenExhausted(resp) || last {
+			if idx != start {
+				t.mu.Lock()
+				t.current = idx
+				t.mu.Unlock()
+			}
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// tokenExhausted reports whether resp rejects the token itself, rather than
+// the request: an invalid or revoked token, or a primary or secondary rate
+// limit.
+func tokenExhausted(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return true
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenRotator(t *testing.T) {
+	var seen []string
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		seen = append(seen, strings.TrimPrefix(auth, "Bearer "))
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch auth {
+		case "Bearer revoked":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "Bearer exhausted":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: NewTokenRotator(srv.Client().Transport, []string{"revoked", "exhausted", "good"})}
+
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"labels":["kind/fix"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the request to succeed with the last token, got %s", resp.Status)
+	}
+	if want := []string{"revoked", "exhausted", "good"}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("tokens tried = %v, want %v", seen, want)
+	}
+	for _, body := range bodies {
+		if body != `{"labels":["kind/fix"]}` {
+			t.Fatalf("expected every attempt to resend the body, got %q", body)
+		}
+	}
+
+	// the working token stays current
+	seen = nil
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if want := []string{"good"}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("tokens tried = %v, want %v", seen, want)
+	}
+}
+
+func TestTokenRotator_AllExhausted(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: NewTokenRotator(srv.Client().Transport, []string{"a", "b"})}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls != 2 {
+		t.Fatalf("expected the last rate limited response after trying each token once, got %s after %d calls", resp.Status, calls)
+	}
+}
+
+func TestTokenRotator_OtherErrorsAreNotRetried(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: NewTokenRotator(srv.Client().Transport, []string{"a", "b"})}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if calls != 1 {
+		t.Fatalf("expected a permission error not to fail over, got %d calls", calls)
+	}
+}
+
+func TestSplitTokens(t *testing.T) {
+	got := SplitTokens(" a, b\nc,,")
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("SplitTokens = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+// Package undo restores PR labels from the snapshots the labeler records in
+// its check runs before changing them.
+package undo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+// Options controls an undo.
+type Options struct {
+	// Since undoes every run that changed labels at or after it, restoring
+	// the labels from before the first of them. The zero value undoes the
+	// latest run that changed labels.
+	Since time.Time
+	// DryRun reports what would change without changing anything.
+	DryRun bool
+}
+
+// Result reports the undo of a PR's label changes.
+type Result struct {
+	// Snapshot is the snapshot restored, or nil if no run changed labels.
+	Snapshot *labeler.Snapshot
+	// Runs is the number of runs undone.
+	Runs int
+	// Plan adds and removes the labels that restore Snapshot, or is nil if
+	// no run changed labels.
+	Plan *labeler.Plan
+}
+
+// Run undoes the label changes the labeler made to PR prNum of owner/repo,
+// as recorded in its check runs on the PR's commits. Only labels the
+// undone runs changed are restored, so labels people added or removed by
+// hand are kept.
+func Run(ctx context.Context, client *github.Client, owner, repo string, prNum int, opts Options) (*Result, error) {
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, prNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+	shas, err := listCommits(ctx, client, owner, repo, prNum)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []*labeler.Snapshot
+	for _, sha := range shas {
+		found, err := listSnapshots(ctx, client, owner, repo, sha)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, found...)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].TakenAt.Before(snapshots[j].TakenAt) })
+	if !opts.Since.IsZero() {
+		i := sort.Search(len(snapshots), func(i int) bool { return !snapshots[i].TakenAt.Before(opts.Since) })
+		snapshots = snapshots[i:]
+	} else if len(snapshots) > 0 {
+		snapshots = snapshots[len(snapshots)-1:]
+	}
+	result := &Result{Runs: len(snapshots)}
+	if len(snapshots) == 0 {
+		return result, nil
+	}
+	result.Snapshot = snapshots[0]
+	result.Plan = &labeler.Plan{PlannedAt: time.Now().UTC()}
+
+	want := map[string]bool{}
+	for _, label := range result.Snapshot.Labels {
+		want[label] = true
+	}
+	have := map[string]bool{}
+	for _, label := range pr.Labels {
+		have[label.GetName()] = true
+		result.Plan.CurrentLabels = append(result.Plan.CurrentLabels, label.GetName())
+	}
+	changed := map[string]bool{}
+	for _, s := range snapshots {
+		for _, label := range s.Added {
+			changed[label] = true
+		}
+		for _, label := range s.Removed {
+			changed[label] = true
+		}
+	}
+	for _, label := range sortedKeys(changed) {
+		switch {
+		case want[label] && !have[label]:
+			result.Plan.AddLabels = append(result.Plan.AddLabels, label)
+		case !want[label] && have[label]:
+			result.Plan.RemoveLabels = append(result.Plan.RemoveLabels, label)
+		}
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+	return result, labeler.New(client, owner, repo, prNum, false).Apply(ctx, result.Plan)
+}
+
+// listCommits returns the SHAs of the PR's commits. The labeler reports on
+// the head commit at the time, so earlier runs are on earlier commits.
+func listCommits(ctx context.Context, client *github.Client, owner, repo string, prNum int) ([]string, error) {
+	var shas []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		commits, resp, err := client.PullRequests.ListCommits(ctx, owner, repo, prNum, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list PR commits: %w", err)
+		}
+		for _, c := range commits {
+			shas = append(shas, c.GetSHA())
+		}
+		if resp.NextPage == 0 {
+			return shas, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listSnapshots returns the snapshots of the labeler's check runs on sha.
+func listSnapshots(ctx context.Context, client *github.Client, owner, repo, sha string) ([]*labeler.Snapshot, error) {
+	var snapshots []*labeler.Snapshot
+	opts := &github.ListCheckRunsOptions{
+		CheckName:   github.Ptr(labeler.CheckRunName),
+		Filter:      github.Ptr("all"),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		runs, resp, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, sha, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list check runs: %w", err)
+		}
+		for _, run := range runs.CheckRuns {
+			if s := labeler.ParseSnapshot(run.GetExternalID()); s != nil {
+				snapshots = append(snapshots, s)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return snapshots, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
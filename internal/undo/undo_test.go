@@ -0,0 +1,133 @@
+package undo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+func TestRun(t *testing.T) {
+	rollout := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	run := func(s labeler.Snapshot) *github.CheckRun {
+		data, _ := json.Marshal(s)
+		return &github.CheckRun{ExternalID: github.Ptr(string(data))}
+	}
+	// the runs before a push are on the earlier commit
+	runs := map[string][]*github.CheckRun{
+		"abc123": {
+			// listed newest first, as GitHub does
+			run(labeler.Snapshot{TakenAt: rollout.Add(time.Hour), Labels: []string{"kind/cleanup", "lgtm"}, Added: []string{"do-not-merge/release-note-invalid"}}),
+			{ExternalID: github.Ptr("")},
+		},
+		"def456": {
+			run(labeler.Snapshot{TakenAt: rollout.Add(time.Minute), Labels: []string{"kind/fix", "lgtm"}, Added: []string{"kind/cleanup"}, Removed: []string{"kind/fix"}}),
+			run(labeler.Snapshot{TakenAt: rollout.Add(-time.Hour), Labels: []string{}, Added: []string{"kind/fix"}}),
+		},
+	}
+	tests := []struct {
+		name       string
+		opts       Options
+		wantTaken  time.Time
+		wantRuns   int
+		wantAdd    []string
+		wantRemove []string
+	}{
+		{
+			name:       "latest run",
+			wantTaken:  rollout.Add(time.Hour),
+			wantRuns:   1,
+			wantRemove: []string{"do-not-merge/release-note-invalid"},
+		},
+		{
+			name:       "since the rollout",
+			opts:       Options{Since: rollout},
+			wantTaken:  rollout.Add(time.Minute),
+			wantRuns:   2,
+			wantAdd:    []string{"kind/fix"},
+			wantRemove: []string{"do-not-merge/release-note-invalid", "kind/cleanup"},
+		},
+		{
+			name:       "dry run",
+			opts:       Options{Since: rollout, DryRun: true},
+			wantTaken:  rollout.Add(time.Minute),
+			wantRuns:   2,
+			wantAdd:    []string{"kind/fix"},
+			wantRemove: []string{"do-not-merge/release-note-invalid", "kind/cleanup"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added, removed []string
+			httpClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposPullsByOwnerByRepoByPullNumber,
+					github.PullRequest{
+						Head: &github.PullRequestBranch{SHA: github.Ptr("abc123")},
+						// needs-review was added by hand and is kept
+						Labels: []*github.Label{{Name: github.Ptr("kind/cleanup")}, {Name: github.Ptr("do-not-merge/release-note-invalid")}, {Name: github.Ptr("needs-review")}},
+					},
+				),
+				mock.WithRequestMatch(mock.GetReposPullsCommitsByOwnerByRepoByPullNumber, []*github.RepositoryCommit{{SHA: github.Ptr("def456")}, {SHA: github.Ptr("abc123")}}),
+				mock.WithRequestMatchHandler(
+					mock.GetReposCommitsCheckRunsByOwnerByRepoByRef,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						w.Write(mock.MustMarshal(github.ListCheckRunsResults{CheckRuns: runs[path.Base(path.Dir(r.URL.Path))]}))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposIssuesLabelsByOwnerByRepoByIssueNumber,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						json.NewDecoder(r.Body).Decode(&added)
+						w.Write(mock.MustMarshal([]*github.Label{}))
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					mock.DeleteReposIssuesLabelsByOwnerByRepoByIssueNumberByName,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						removed = append(removed, r.PathValue("name"))
+					}),
+				),
+			)
+			result, err := Run(context.Background(), github.NewClient(httpClient), "owner", "repo", 1, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Snapshot.TakenAt.Equal(tt.wantTaken) || result.Runs != tt.wantRuns {
+				t.Fatalf("restored the snapshot of %s, undoing %d runs, want %s and %d", result.Snapshot.TakenAt, result.Runs, tt.wantTaken, tt.wantRuns)
+			}
+			if !reflect.DeepEqual(result.Plan.AddLabels, tt.wantAdd) || !reflect.DeepEqual(result.Plan.RemoveLabels, tt.wantRemove) {
+				t.Fatalf("add %v, remove %v, want %v and %v", result.Plan.AddLabels, result.Plan.RemoveLabels, tt.wantAdd, tt.wantRemove)
+			}
+			if tt.opts.DryRun {
+				tt.wantAdd, tt.wantRemove = nil, nil
+			}
+			if !reflect.DeepEqual(added, tt.wantAdd) || len(removed) != len(tt.wantRemove) {
+				t.Fatalf("added %v and removed %d labels, want %v and %v", added, len(removed), tt.wantAdd, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestRun_NothingToUndo(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetReposPullsByOwnerByRepoByPullNumber, github.PullRequest{Head: &github.PullRequestBranch{SHA: github.Ptr("abc123")}}),
+		mock.WithRequestMatch(mock.GetReposPullsCommitsByOwnerByRepoByPullNumber, []*github.RepositoryCommit{{SHA: github.Ptr("abc123")}}),
+		mock.WithRequestMatch(mock.GetReposCommitsCheckRunsByOwnerByRepoByRef, github.ListCheckRunsResults{CheckRuns: []*github.CheckRun{{}}}),
+	)
+	result, err := Run(context.Background(), github.NewClient(httpClient), "owner", "repo", 1, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Snapshot != nil || result.Runs != 0 {
+		t.Fatalf("expected nothing to undo, got %+v", result)
+	}
+}
@@ -0,0 +1,152 @@
+// Package webhook registers the labeler's server-mode webhook on GitHub
+// repositories and organizations.
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// DefaultEvents are the webhook events server mode consumes. check_suite and
+// status events are only acted on by repositories with CI labels or an
+// approval label to reset.
+var DefaultEvents = []string{"pull_request", "issue_comment", "pull_request_review", "check_suite", "status"}
+
+// Spec describes the webhook to register.
+type Spec struct {
+	// URL is the server-mode endpoint GitHub delivers to, e.g. https://labeler.example.com/webhook.
+	URL string
+	// Secret signs payloads so the server can verify them.
+	Secret string
+	// Events to subscribe to. Defaults to DefaultEvents.
+	Events []string
+}
+
+// Result reports what Ensure did.
+type Result string
+
+const (
+	// Created means no webhook for the URL existed and one was created.
+	Created Result = "created"
+	// Updated means an existing webhook for the URL was reconfigured.
+	Updated Result = "updated"
+)
+
+// Target is a repository (owner/repo) or, when Repo is empty, an organization.
+type Target struct {
+	Owner string
+	Repo  string
+}
+
+// String implements fmt.Stringer.
+func (t Target) String() string {
+	if t.Repo == "" {
+		return t.Owner
+	}
+	return t.Owner + "/" + t.Repo
+}
+
+// ParseTarget parses owner/repo, or a bare owner for an organization.
+func ParseTarget(name string) (Target, error) {
+	owner, repo, _ := strings.Cut(name, "/")
+	if owner == "" || strings.Contains(repo, "/") {
+		return Target{}, fmt.Errorf("invalid repository %q, expected owner/repo or owner", name)
+	}
+	return Target{Owner: owner, Repo: repo}, nil
+}
+
+// Ensure creates the webhook on target, or updates the existing webhook
+// delivering to the same URL so its secret, events, and active state match
+// spec. Updating is unconditional because GitHub never returns the secret,
+// so there is no way to tell whether it changed.
+func Ensure(ctx context.Context, client *github.Client, target Target, spec Spec) (Result, error) {
+	events := spec.Events
+	if len(events) == 0 {
+		events = DefaultEvents
+	}
+	hook := &github.Hook{
+		Events: events,
+		Active: github.Ptr(true),
+		Config: &github.HookConfig{
+			URL:         github.Ptr(spec.URL),
+			ContentType: github.Ptr("json"),
+			Secret:      github.Ptr(spec.Secret),
+			InsecureSSL: github.Ptr("0"),
+		},
+	}
+
+	existing, err := find(ctx, client, target, spec.URL)
+	if err != nil {
+		return "", err
+	}
+	if existing == nil {
+		if target.Repo == "" {
+			_, _, err = client.Organizations.CreateHook(ctx, target.Owner, hook)
+		} else {
+			_, _, err = client.Repositories.CreateHook(ctx, target.Owner, target.Repo, hook)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create webhook on %s: %w", target, err)
+		}
+		return Created, nil
+	}
+	if target.Repo == "" {
+		_, _, err = client.Organizations.EditHook(ctx, target.Owner, existing.GetID(), hook)
+	} else {
+		_, _, err = client.Repositories.EditHook(ctx, target.Owner, target.Repo, existing.GetID(), hook)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update webhook on %s: %w", target, err)
+	}
+	return Updated, nil
+}
+
+// Remove deletes the webhook on target delivering to url, if any.
+func Remove(ctx context.Context, client *github.Client, target Target, url string) error {
+	existing, err := find(ctx, client, target, url)
+	if err != nil || existing == nil {
+		return err
+	}
+	if target.Repo == "" {
+		_, err = client.Organizations.DeleteHook(ctx, target.Owner, existing.GetID())
+	} else {
+		_, err = client.Repositories.DeleteHook(ctx, target.Owner, target.Repo, existing.GetID())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook on %s: %w", target, err)
+	}
+	return nil
+}
+
+// find returns the webhook on target delivering to url, if any.
+func find(ctx context.Context, client *github.Client, target Target, url string) (*github.Hook, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		var (
+			hooks []*github.Hook
+			resp  *github.Response
+			err   error
+		)
+		if target.Repo == "" {
+			hooks, resp, err = client.Organizations.ListHooks(ctx, target.Owner, opts)
+		} else {
+			hooks, resp, err = client.Repositories.ListHooks(ctx, target.Owner, target.Repo, opts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks on %s: %w", target, err)
+		}
+		if i := slices.IndexFunc(hooks, func(h *github.Hook) bool {
+			return h.GetConfig().GetURL() == url
+		}); i >= 0 {
+			return hooks[i], nil
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestEnsure_Organization(t *testing.T) {
+	var created *github.Hook
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetOrgsHooksByOrg,
+			[]*github.Hook{
+				{ID: github.Ptr(int64(1)), Config: &github.HookConfig{URL: github.Ptr("https://other.example.com")}},
+			},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostOrgsHooksByOrg,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				created = &github.Hook{}
+				if err := json.NewDecoder(r.Body).Decode(created); err != nil {
+					t.Fatalf("CreateHook Handler: failed to decode body: %v", err)
+				}
+				json.NewEncoder(w).Encode(created)
+			}),
+		),
+	)
+	spec := Spec{URL: "https://labeler.example.com/webhook", Secret: "s3cr3t", Events: []string{"pull_request", "issue_comment"}}
+	result, err := Ensure(context.Background(), github.NewClient(httpClient), Target{Owner: "kgateway-dev"}, spec)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != Created {
+		t.Fatalf("expected %q, got %q", Created, result)
+	}
+	if created.GetConfig().GetURL() != spec.URL || created.GetConfig().GetSecret() != spec.Secret || created.GetConfig().GetContentType() != "json" {
+		t.Fatalf("unexpected webhook config %+v", created.GetConfig())
+	}
+	if !reflect.DeepEqual(created.Events, spec.Events) || !created.GetActive() {
+		t.Fatalf("expected an active webhook for %v, got %+v", spec.Events, created)
+	}
+}
+
+func TestEnsure_UpdatesExistingRepositoryWebhook(t *testing.T) {
+	var edited bool
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchPages(
+			mock.GetReposHooksByOwnerByRepo,
+			[]*github.Hook{
+				{ID: github.Ptr(int64(1)), Config: &github.HookConfig{URL: github.Ptr("https://other.example.com")}},
+			},
+			[]*github.Hook{
+				{ID: github.Ptr(int64(2)), Config: &github.HookConfig{URL: github.Ptr("https://labeler.example.com/webhook")}},
+			},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposHooksByOwnerByRepoByHookId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/repos/kgateway-dev/kgateway/hooks/2" {
+					t.Fatalf("expected webhook 2 to be updated, got %s", r.URL.Path)
+				}
+				edited = true
+				json.NewEncoder(w).Encode(github.Hook{})
+			}),
+		),
+	)
+	spec := Spec{URL: "https://labeler.example.com/webhook", Secret: "s3cr3t"}
+	result, err := Ensure(context.Background(), github.NewClient(httpClient), Target{Owner: "kgateway-dev", Repo: "kgateway"}, spec)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != Updated || !edited {
+		t.Fatalf("expected the existing webhook to be updated, got %q", result)
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Target
+		wantErr bool
+	}{
+		{name: "kgateway-dev/kgateway", want: Target{Owner: "kgateway-dev", Repo: "kgateway"}},
+		{name: "kgateway-dev", want: Target{Owner: "kgateway-dev"}},
+		{name: "/kgateway", wantErr: true},
+		{name: "a/b/c", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTarget(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
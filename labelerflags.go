@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+// labelerFlags are the flags of the checks the labeler runs. The commands
+// that evaluate PRs share them, so validate and local reach the verdict of
+// the labeling run they predict.
+type labelerFlags struct {
+	kindPrefixes   []string
+	namespace      string
+	noteFences     []string
+	maxNoteLength  int
+	checkLinks     bool
+	linkTimeout    time.Duration
+	kindMilestones string
+	milestoneTeams []string
+	autoNone       string
+	spam           labeler.SpamHeuristics
+	detectSecrets  bool
+	upgradeDocs    bool
+	upgradePaths   []string
+	docsKind       bool
+	docsPaths      []string
+	ignorePaths    []string
+	disabledLabels []string
+	detectRenames  bool
+	detectReverts  bool
+	botPRs         bool
+	bots           labeler.BotPolicy
+	reviewCmds     bool
+	commentCmds    bool
+	riskLabels     bool
+	riskWeights    string
+	moduleLabels   bool
+	needsLabels    bool
+	ciLabels       bool
+	approvalLabel  string
+}
+
+// register adds the flags to cmd.
+func (f *labelerFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&f.kindPrefixes, "kind-prefixes", nil, "comma-separated prefixes that also introduce kind commands, e.g. '> /kind,#kind:', for PR templates migrating to /kind")
+	cmd.Flags().StringSliceVar(&f.noteFences, "release-note-fences", nil, "comma-separated fence names also accepted for the release-note block, e.g. 'releasenote,changelog'; PRs using one are asked to switch to release-note")
+	cmd.Flags().IntVar(&f.maxNoteLength, "max-release-note-length", 0, "fail release notes longer than this many characters, previewing where they would be cut off; also replaces the limit of 500 of release note quality checks (0 disables)")
+	cmd.Flags().BoolVar(&f.checkLinks, "check-links", false, "warn about URLs in the release note that do not resolve, checked with a HEAD request")
+	cmd.Flags().DurationVar(&f.linkTimeout, "check-links-timeout", labeler.DefaultLinkCheckTimeout, "how long --check-links waits for each URL")
+	cmd.Flags().StringVar(&f.namespace, "command-namespace", "", "also recognize kind commands namespaced by this bot name, e.g. kgateway for /kgateway kind feature")
+	cmd.Flags().StringVar(&f.kindMilestones, "kind-milestones", "", "default milestone per kind, e.g. breaking_change=next-major; overridden by /milestone in the PR body")
+	cmd.Flags().BoolVar(&f.detectSecrets, "detect-secrets", false, "label PRs whose body appears to contain a credential with "+labels.PossibleSecretLabel)
+	cmd.Flags().BoolVar(&f.spam.EmptyDiff, "spam-empty-diff", false, "label PRs that change no files with "+labels.SuspectedSpamLabel+" instead of validating them")
+	cmd.Flags().IntVar(&f.spam.MinBodyLength, "spam-min-body-length", 0, "label PRs without a /kind whose body is shorter than this with "+labels.SuspectedSpamLabel+" (0 disables)")
+	cmd.Flags().DurationVar(&f.spam.MinAccountAge, "spam-min-account-age", 0, "label PRs without a /kind from accounts younger than this, e.g. 168h, with "+labels.SuspectedSpamLabel+" (0 disables)")
+	cmd.Flags().StringVar(&f.autoNone, "auto-none-release-note", "", "comma-separated author association=kind or org/team=kind pairs whose PRs may omit the release note, e.g. MEMBER=flake,kgateway-dev/maintainers=cleanup")
+	cmd.Flags().StringSliceVar(&f.milestoneTeams, "milestone-teams", nil, "comma-separated org/team-slug teams whose members may use /milestone (default anyone)")
+	cmd.Flags().BoolVar(&f.upgradeDocs, "require-upgrade-docs", false, "label PRs whose release note starts with ACTION REQUIRED but that change no upgrade docs with "+labels.NeedsUpgradeDocsLabel)
+	cmd.Flags().StringSliceVar(&f.upgradePaths, "upgrade-docs-paths", labeler.DefaultUpgradeDocsPaths, "comma-separated path patterns, where ** matches any directories, that count as upgrade docs")
+	cmd.Flags().BoolVar(&f.docsKind, "require-docs-changes", false, "label /kind documentation PRs that change no docs with "+labels.KindMismatchLabel+", and warn when they change Go code")
+	cmd.Flags().StringSliceVar(&f.docsPaths, "docs-paths", labeler.DefaultDocsPaths, "comma-separated path patterns, where ** matches any directories, that count as docs")
+	cmd.Flags().StringSliceVar(&f.disabledLabels, "disabled-labels", nil, "comma-separated label patterns the labeler leaves alone, e.g. release-note-none,do-not-merge/*; a pattern prefixed with ! enables labels again, the last match winning, so *,!kind/* manages only kind labels")
+	cmd.Flags().StringSliceVar(&f.ignorePaths, "ignore-paths", nil, "comma-separated path patterns, where ** matches any directories, e.g. .github/**,vendor/**; PRs changing only matching files are not validated")
+	cmd.Flags().BoolVar(&f.detectRenames, "detect-renames", false, "default PRs that only rename files to /kind cleanup and treat their missing release note as NONE")
+	cmd.Flags().BoolVar(&f.botPRs, "bot-prs", false, "label the PRs of dependency update bots, which never fill in the PR template, with --bot-kind and release note NONE instead of failing validation")
+	cmd.Flags().StringSliceVar(&f.bots.Authors, "bot-authors", labeler.DefaultBotAuthors, "comma-separated logins of the bots --bot-prs applies to")
+	cmd.Flags().StringVar(&f.bots.Kind, "bot-kind", kinds.Bump, "kind the PRs of --bot-authors default to when their body sets none")
+	cmd.Flags().BoolVar(&f.bots.TitleNote, "bot-title-note", false, "with --bot-prs, set the release note of bot PRs from their title instead of NONE, e.g. to list dependency updates in the changelog")
+	cmd.Flags().BoolVar(&f.reviewCmds, "review-commands", false, "also take /kind and /release-note commands from the summaries of maintainers' PR reviews")
+	cmd.Flags().BoolVar(&f.commentCmds, "comment-commands", false, "also take /kind commands from PR comments of users with write, maintain or admin permission on the repository")
+	cmd.Flags().BoolVar(&f.detectReverts, "detect-reverts", false, "label revert PRs with "+labels.RevertLabel+" and default their kind to the kind of the PR they revert")
+	cmd.Flags().BoolVar(&f.riskLabels, "risk-labels", false, "score the release risk of PRs from their kinds, size and changed areas, and label them "+labels.RiskLabelPrefix+"low, medium or high")
+	cmd.Flags().StringVar(&f.riskWeights, "risk-kind-weights", "", "comma-separated kind=weight pairs overriding the default risk weights, e.g. breaking_change=4,documentation=1")
+	cmd.Flags().BoolVar(&f.needsLabels, "needs-labels", false, "label PRs with "+labels.NeedsKindLabel+", "+labels.NeedsReleaseNoteLabel+" and "+labels.NeedsRebaseLabel+" for what they need before review")
+	cmd.Flags().BoolVar(&f.ciLabels, "ci-labels", false, "label PRs "+labels.CIFailingLabel+" while a check of their head commit fails; run on check_suite and status events too")
+	cmd.Flags().StringVar(&f.approvalLabel, "reset-approval-label", "", "remove this label, e.g. lgtm, from PRs that got commits after their latest approving review")
+	cmd.Flags().BoolVar(&f.moduleLabels, "module-labels", false, "in repositories with several Go modules, label PRs with "+labels.ModuleLabelPrefix+"NAME for each module they change")
+	cmd.RegisterFlagCompletionFunc("kind-milestones", completeKindPairs)
+	cmd.RegisterFlagCompletionFunc("risk-kind-weights", completeKindPairs)
+}
+
+// options checks the flags and returns the labeler options they set.
+func (f *labelerFlags) options() (*labeler.Options, error) {
+	milestones, err := parseKindMilestones(f.kindMilestones)
+	if err != nil {
+		return nil, &labeler.ConfigError{Err: fmt.Errorf("invalid --kind-milestones: %w", err)}
+	}
+	weights, err := parseKindWeights(f.riskWeights)
+	if err != nil {
+		return nil, &labeler.ConfigError{Err: fmt.Errorf("invalid --risk-kind-weights: %w", err)}
+	}
+	policies, err := parseAutoNone(f.autoNone)
+	if err != nil {
+		return nil, &labeler.ConfigError{Err: fmt.Errorf("invalid --auto-none-release-note: %w", err)}
+	}
+	if f.botPRs && !kinds.SupportedKinds[f.bots.Kind] {
+		return nil, &labeler.ConfigError{Err: fmt.Errorf("invalid --bot-kind %q, expected one of %s", f.bots.Kind, kinds.Render())}
+	}
+	for _, paths := range []struct {
+		flag     string
+		patterns []string
+	}{{"upgrade-docs-paths", f.upgradePaths}, {"docs-paths", f.docsPaths}, {"ignore-paths", f.ignorePaths}} {
+		for _, p := range paths.patterns {
+			if !labeler.ValidPathPattern(p) {
+				return nil, &labeler.ConfigError{Err: fmt.Errorf("invalid --%s pattern %q", paths.flag, p)}
+			}
+		}
+	}
+	for _, p := range f.disabledLabels {
+		if !labeler.ValidLabelPattern(p) {
+			return nil, &labeler.ConfigError{Err: fmt.Errorf("invalid --disabled-labels pattern %q", p)}
+		}
+	}
+	o := &labeler.Options{
+		KindPrefixes:         f.kindPrefixes,
+		CommandNamespace:     f.namespace,
+		ReleaseNoteFences:    f.noteFences,
+		MaxReleaseNoteLength: f.maxNoteLength,
+		Milestones:           milestones,
+		MilestoneTeams:       f.milestoneTeams,
+		AuthorPolicies:       policies,
+		Spam:                 f.spam,
+		DetectSecrets:        f.detectSecrets,
+		LinkCheck:            f.checkLinks,
+		LinkCheckTimeout:     f.linkTimeout,
+		UpgradeDocs:          f.upgradeDocs,
+		UpgradeDocsPaths:     f.upgradePaths,
+		DocsKindCheck:        f.docsKind,
+		DocsPaths:            f.docsPaths,
+		IgnoredPaths:         f.ignorePaths,
+		DisabledLabels:       f.disabledLabels,
+		DetectRenames:        f.detectRenames,
+		DetectReverts:        f.detectReverts,
+		ReviewCommands:       f.reviewCmds,
+		CommentCommands:      f.commentCmds,
+		RiskScoring:          f.riskLabels,
+		KindWeights:          weights,
+		ModuleLabels:         f.moduleLabels,
+		NeedsLabels:          f.needsLabels,
+		CILabels:             f.ciLabels,
+		ApprovalLabel:        f.approvalLabel,
+	}
+	if f.botPRs {
+		o.Bots = &f.bots
+	}
+	return o, nil
+}
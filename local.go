@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/changelog"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/config"
+)
+
+func newLocalCmd() *cobra.Command {
+	var (
+		lf                              labelerFlags
+		bodyFile                        string
+		base                            string
+		dir                             string
+		currentLabels                   []string
+		enforceDescription              bool
+		enforceReleaseNoteQuality       bool
+		enforceChangelogKindExclusivity bool
+	)
+	cmd := &cobra.Command{
+		Use:   "local",
+		Short: "Validate a PR body and local checkout without calling GitHub",
+		Long: `Read the PR body from a file and the changed files from git diff --name-only
+in a local checkout, then run every validator and print the labels that would
+change. The checks are set with the flags of the labeling run and the
+repository config is read from ` + config.Path + ` in the checkout.
+Nothing is sent to GitHub, so this can run as a pre-push check, and the flags
+of checks that need the GitHub API, such as --comment-commands, are rejected.`,
+		Example: `  # Validate a drafted PR description against the current branch
+  pr-kind-labeler local --body-file PR.md
+
+  # Pipe the body in and compare against a release branch
+  gh pr view 123 --json body -q .body | pr-kind-labeler local --body-file - --base origin/v2.0.x`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := lf.options()
+			if err != nil {
+				return err
+			}
+			for _, name := range apiOnlyFlags {
+				if f := cmd.Flags().Lookup(name); f.Value.String() != f.DefValue {
+					return &labeler.ConfigError{Err: fmt.Errorf("--%s needs the GitHub API, so it has no effect in a local run", name)}
+				}
+			}
+			body, err := readBody(bodyFile, cmd.InOrStdin())
+			if err != nil {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --body-file: %w", err)}
+			}
+			files, err := gitChangedFiles(dir, base)
+			if err != nil {
+				return err
+			}
+			repoConfig, err := readRepoConfig(dir)
+			if err != nil {
+				return err
+			}
+
+			opts.EnforceDescription = enforceDescription
+			opts.EnforceReleaseNoteQuality = enforceReleaseNoteQuality
+			opts.EnforceChangelogKindExclusivity = enforceChangelogKindExclusivity
+			opts.RepoConfig = repoConfig
+			l := labeler.NewFromOptions(nil, "", "", 0, opts).WithChangedFiles(files)
+			d, err := l.Simulate(body, currentLabels)
+			d.DeadLinks = l.CheckLinks(cmd.Context())
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "changed files: %d\n", len(d.ChangedFiles))
+			for _, label := range d.LabelsToAdd {
+				fmt.Fprintf(out, "+ %s\n", label)
+			}
+			for _, label := range d.LabelsToRemove {
+				fmt.Fprintf(out, "- %s\n", label)
+			}
+			if note := d.ReleaseNote; note != nil && note.Section != "" {
+				section, _ := changelog.Lookup(note.Section)
+				fmt.Fprintf(out, "changelog section: %s\n", section.Title)
+			}
+			for _, link := range d.DeadLinks {
+				fmt.Fprintf(out, "dead link: %s (%s)\n", link.URL, link.Reason)
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&bodyFile, "body-file", "", "file containing the PR body, or - for stdin")
+	cmd.Flags().StringVar(&base, "base", "origin/main", "git ref the PR will merge into")
+	cmd.Flags().StringVar(&dir, "dir", ".", "path to the local git checkout")
+	cmd.Flags().StringSliceVar(&currentLabels, "labels", nil, "labels the PR currently has")
+	cmd.Flags().BoolVar(&enforceDescription, "enforce-description", true, "enforce that the Description section is filled out")
+	cmd.Flags().BoolVar(&enforceReleaseNoteQuality, "enforce-release-note-quality", false, "enforce naive publication-ready release note checks")
+	cmd.Flags().BoolVar(&enforceChangelogKindExclusivity, "enforce-changelog-kind-exclusivity", false, "enforce at most one changelog kind per PR")
+	lf.register(cmd)
+	cmd.MarkFlagRequired("body-file")
+	cmd.MarkFlagFilename("body-file", "md", "txt")
+	cmd.MarkFlagDirname("dir")
+	return cmd
+}
+
+// apiOnlyFlags are the labeling run's flags whose checks need data only the
+// GitHub API has, such as the PR author, its comments and reviews, or its
+// checks, and so have no effect in a local run.
+var apiOnlyFlags = []string{
+	"spam-min-account-age",
+	"auto-none-release-note",
+	"milestone-teams",
+	"detect-renames",
+	"bot-prs",
+	"review-commands",
+	"comment-commands",
+	"detect-reverts",
+	"risk-labels",
+	"module-labels",
+	"ci-labels",
+	"reset-approval-label",
+}
+
+// readBody reads a PR body from path, or from stdin when path is "-".
+func readBody(path string, stdin io.Reader) (string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read PR body: %w", err)
+	}
+	return string(data), nil
+}
+
+// gitChangedFiles lists the files changed between the merge base of base and
+// HEAD in the checkout at dir, matching what the PR files API reports. A base
+// that names no commit is a ConfigError.
+func gitChangedFiles(dir, base string) ([]string, error) {
+	if _, err := git(dir, "rev-parse", "--verify", "--quiet", base+"^{commit}"); err != nil {
+		return nil, &labeler.ConfigError{Err: fmt.Errorf("invalid --base %q: no such commit in %s", base, dir)}
+	}
+	out, err := git(dir, "diff", "--name-only", base+"...HEAD")
+	if err != nil {
+		return nil, &labeler.OperationalError{Err: fmt.Errorf("failed to list changed files: %w", err)}
+	}
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// git runs git with args in the checkout at dir and returns its output.
+func git(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// readRepoConfig reads the repository config from the checkout at dir, or
+// returns the default config if it has none.
+func readRepoConfig(dir string) (*config.Config, error) {
+	data, err := os.ReadFile(filepath.Join(dir, config.Path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return config.Default(), nil
+	}
+	if err != nil {
+		return nil, &labeler.ConfigError{Err: fmt.Errorf("failed to read %s: %w", config.Path, err)}
+	}
+	c, err := config.Parse(data)
+	if err != nil {
+		return nil, &labeler.ConfigError{Err: err}
+	}
+	return c, nil
+}
@@ -1,38 +1,177 @@
 package main
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v68/github"
 	"github.com/spf13/cobra"
 
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/failures"
 	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/prlock"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/reporters"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/secrets"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/teams"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/transport"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/config"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/ghaction"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
 )
 
 func main() {
+	var (
+		lf             labelerFlags
+		mode           string
+		recordDir      string
+		triage         []string
+		provenanceKey  string
+		provenanceOut  string
+		secretNotify   string
+		stickyComment  bool
+		mergeBlockers  bool
+		commentEvery   time.Duration
+		checkRun       bool
+		commitStatus   bool
+		reporterNames  []string
+		dryRun         bool
+		slackWebhook   string
+		validateOnly   bool
+		escalation     labeler.Escalation
+		failureStore   string
+		exportMeta     bool
+		metadataBranch string
+		eventPath      string
+		refetchPR      bool
+		serialize      bool
+		serializeWait  time.Duration
+	)
 	cmd := cobra.Command{
-		Use:          "pr-kind-labeler",
-		Short:        "Sync /kind commands in PR body to GitHub labels and enforce changelog notes",
-		Args:         cobra.RangeArgs(1, 4),
-		SilenceUsage: true,
+		Use:   "pr-kind-labeler TOKEN[,TOKEN...] [ENFORCE_DESCRIPTION [ENFORCE_RELEASE_NOTE_QUALITY [ENFORCE_CHANGELOG_KIND_EXCLUSIVITY]]]",
+		Short: "Sync /kind commands in PR body to GitHub labels and enforce changelog notes",
+		Long: `Sync /kind commands in the PR body to GitHub labels and enforce changelog notes.
+
+Without a subcommand the labeler processes the pull_request event at
+GITHUB_EVENT_PATH, as it does when running as a GitHub Action, or at --event,
+which reads the payload from stdin when set to -. With
+--sticky-comment, issue_comment events are processed too, so maintainers can
+set the release note with a /release-note TEXT or /release-note-none comment.
+With --comment-commands, users who can write to the repository can also set
+the kind of a PR with a /kind comment. Set
+GHPR=owner/repo/PR to evaluate an existing PR without changing it; the
+labels, comment and check-run summary that would be published are printed
+as unified diffs against what the PR has now. With --dry-run, the event is
+evaluated as usual but the PR is left unchanged, and the label changes are
+printed as a unified diff instead.
+
+TOKEN may be a comma-separated list; when a token is rate limited or
+rejected, the labeler fails over to the next one.
+
+For integration tests, ` + transport.FaultsEnv + ` injects GitHub API failures
+into every command, e.g. "500 method=POST n=1; ratelimit p=0.2". Each fault
+is 403, 404, 422, 500, ratelimit or secondary, optionally limited by method=,
+path= (a pattern such as /repos/*/*/issues/*/labels), p= (probability) and
+n= (number of failures).
+
+With --record DIR, every command appends the GitHub API requests it sends
+and the responses to a cassette in DIR, one JSON interaction per line, to
+attach to bug reports. Only a few headers are kept and credentials are
+redacted, but review a cassette before sharing it: it holds the PRs and
+comments the run read. A cassette added to internal/labeler/testdata/cassettes
+with the case it was recorded for is replayed as a test.
+
+Exit codes: 0 when the PR is valid or --mode does not enforce validation,
+1 for other validation failures (e.g. the description), 2 for an invalid
+/kind, 3 for an invalid release note, 4 for a GitHub API or other internal
+error, and 5 for invalid flags, arguments, environment or config.`,
+		Example: `  # Process the current GitHub Actions pull_request event
+  pr-kind-labeler "$GITHUB_TOKEN" true false false
+
+  # Evaluate an existing PR without touching its labels
+  GHPR=kgateway-dev/kgateway/11221 pr-kind-labeler "$GITHUB_TOKEN"
+
+  # Print the label changes of the current event without making them
+  pr-kind-labeler "$GITHUB_TOKEN" --dry-run
+
+  # Label PRs but only report validation failures while a repo adopts the labeler
+  pr-kind-labeler "$GITHUB_TOKEN" --mode=lenient
+
+  # Load completions for the current bash session
+  source <(pr-kind-labeler completion bash)`,
+		Args:              cobra.RangeArgs(1, 4),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		Version:           version,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			faults, err := transport.ParseFaults(os.Getenv(transport.FaultsEnv))
+			if err != nil {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid %s: %w", transport.FaultsEnv, err)}
+			}
+			if len(faults) > 0 {
+				fmt.Fprintf(os.Stderr, "warning: injecting GitHub API faults from %s\n", transport.FaultsEnv)
+			}
+			apiFaults = faults
+			if recordDir != "" {
+				rec, err := transport.NewRecorder(recordDir)
+				if err != nil {
+					return &labeler.ConfigError{Err: fmt.Errorf("invalid --record: %w", err)}
+				}
+				fmt.Fprintf(os.Stderr, "recording GitHub API requests to %s\n", rec.Path())
+				apiRecorder = rec
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
+			runMode, err := labeler.ParseMode(mode)
+			if err != nil {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --mode: %w", err)}
+			}
+			opts, err := lf.options()
+			if err != nil {
+				return err
+			}
+			for _, name := range reporterNames {
+				if !slices.Contains(labeler.ReporterNames, name) {
+					return &labeler.ConfigError{Err: fmt.Errorf("invalid --reporters: unknown reporter %q, want one of %s", name, strings.Join(labeler.ReporterNames, ", "))}
+				}
+			}
+			stickyComment = stickyComment || slices.Contains(reporterNames, labeler.ReporterComment)
+			checkRun = checkRun || slices.Contains(reporterNames, labeler.ReporterCheckRun)
+			commitStatus = commitStatus || slices.Contains(reporterNames, labeler.ReporterStatus)
+			var slack labeler.Reporter
+			if slices.Contains(reporterNames, labeler.ReporterSlack) {
+				if slack = slackReporter(slackWebhook, 0); slack == nil {
+					return &labeler.ConfigError{Err: fmt.Errorf("--reporters=slack needs --slack-webhook-url or %s", slackWebhookEnv)}
+				}
+			}
+			signingKey, err := loadSigningKey(provenanceKey)
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
 			// verify the token is set and create GH API client
-			token := os.Args[1]
+			token := args[0]
 			if token == "" {
-				return fmt.Errorf("input token is not set")
+				return &labeler.ConfigError{Err: fmt.Errorf("input token is not set")}
 			}
-			client := github.NewClient(nil).WithAuthToken(token)
+			client := newGitHubClient(token, nil)
+			resolver := teams.NewResolver(client, time.Hour)
 
 			// parse enforce_description flag (defaults to true)
 			enforceDescription := true
-			if len(os.Args) > 2 {
-				enforceDescriptionStr := os.Args[2]
+			if len(args) > 1 {
+				enforceDescriptionStr := args[1]
 				if enforceDescriptionStr == "false" {
 					enforceDescription = false
 				}
@@ -40,8 +179,8 @@ func main() {
 
 			// parse enforce_release_note_quality flag (defaults to false)
 			enforceReleaseNoteQuality := false
-			if len(os.Args) > 3 {
-				enforceReleaseNoteQualityStr := os.Args[3]
+			if len(args) > 2 {
+				enforceReleaseNoteQualityStr := args[2]
 				if enforceReleaseNoteQualityStr == "true" {
 					enforceReleaseNoteQuality = true
 				}
@@ -49,59 +188,434 @@ func main() {
 
 			// parse enforce_changelog_kind_exclusivity flag (defaults to false)
 			enforceChangelogKindExclusivity := false
-			if len(os.Args) > 4 {
-				enforceChangelogKindExclusivityStr := os.Args[4]
+			if len(args) > 3 {
+				enforceChangelogKindExclusivityStr := args[3]
 				if enforceChangelogKindExclusivityStr == "true" {
 					enforceChangelogKindExclusivity = true
 				}
 			}
 
+			opts.EnforceDescription = enforceDescription
+			opts.EnforceReleaseNoteQuality = enforceReleaseNoteQuality
+			opts.EnforceChangelogKindExclusivity = enforceChangelogKindExclusivity
+			opts.Triage = triage
+			opts.TeamResolver = resolver
+			opts.SecretNotifier = secretNotifier(secretNotify)
+			opts.StickyComment = stickyComment
+			opts.MergeBlockersComment = mergeBlockers
+			opts.CommentInterval = commentEvery
+			opts.CheckRun = checkRun
+			opts.CommitStatus = commitStatus
+			opts.Blocking = runMode.FailOnValidation()
+			if slack != nil {
+				opts.Reporters = []labeler.Reporter{slack}
+			}
+			opts.ReadOnlyLabels = validateOnly
+			if failureStore != "" {
+				opts.FailureStore = failures.NewFileStore(failureStore)
+				opts.Escalation = escalation
+			}
+			opts.ExportMetadata = exportMeta
+			opts.MetadataBranch = metadataBranch
+
 			if ghprEnv := os.Getenv("GHPR"); ghprEnv != "" {
 				// You can manually test, like so:
 				// GHPR=kgateway-dev/kgateway/11221 go run . $GITHUB_API_TOKEN
-				parts := strings.Split(ghprEnv, "/")
-				if len(parts) != 3 {
-					return fmt.Errorf("invalid PR format, expected owner/repo/PR")
+				owner, repo, prNum, err := parsePRRef(ghprEnv)
+				if err != nil {
+					return &labeler.ConfigError{Err: fmt.Errorf("invalid GHPR: %w", err)}
 				}
-				owner := parts[0]
-				repo := parts[1]
-				prNum := parts[2]
-				prNumInt, err := strconv.Atoi(prNum)
+				repoConfig, err := loadRepoConfig(ctx, client, owner, repo)
 				if err != nil {
-					return fmt.Errorf("invalid PR number: %w", err)
+					return err
 				}
-				return manualTest(ctx, client, owner, repo, prNumInt, enforceDescription, enforceReleaseNoteQuality, enforceChangelogKindExclusivity)
+				opts.RepoConfig = repoConfig
+				l := labeler.NewFromOptions(client, owner, repo, prNum, opts)
+				return manualTest(ctx, client, l, owner, repo, prNum)
 			}
 
-			eventPath := os.Getenv("GITHUB_EVENT_PATH")
-			payload, err := os.ReadFile(eventPath)
+			action := ghaction.New()
+			prEvent, err := readEvent(ctx, client, action, eventPath, refetchPR)
 			if err != nil {
-				return fmt.Errorf("failed to read event path: %w", err)
+				return err
+			}
+			if prEvent == nil {
+				fmt.Fprintln(os.Stdout, "Event is not a PR change or command, nothing to do")
+				return nil
+			}
+			help := prEvent.Action == event.Commented && labeler.IsHelpCommand(prEvent.Comment.Body)
+			if prEvent.Action == event.Reviewed && (!lf.reviewCmds || !labeler.HasCommand(prEvent.Comment.Body)) {
+				fmt.Fprintln(os.Stdout, "Review gives no command, nothing to do")
+				return nil
 			}
-			var prEvent github.PullRequestEvent
-			if err := json.Unmarshal(payload, &prEvent); err != nil {
-				return fmt.Errorf("failed to parse event JSON: %w", err)
+			if prEvent.Action == event.Commented && !help && !lf.commentCmds && !isReleaseNoteCommand(prEvent.Comment.Body) {
+				fmt.Fprintln(os.Stdout, "Comment gives no enabled command, nothing to do")
+				return nil
 			}
 
-			owner := prEvent.GetRepo().GetOwner().GetLogin()
-			repo := prEvent.GetRepo().GetName()
-			prNum := prEvent.GetNumber()
-			body := prEvent.GetPullRequest().GetBody()
+			if serialize && !help && !dryRun {
+				lock, err := lockPR(ctx, client, prEvent, serializeWait)
+				if err != nil {
+					return &labeler.OperationalError{Err: err}
+				}
+				if lock == nil {
+					fmt.Fprintln(os.Stdout, "A later run processes this PR, nothing to do")
+					return nil
+				}
+				defer func() {
+					if err := lock.Release(context.WithoutCancel(ctx)); err != nil {
+						fmt.Fprintln(os.Stderr, err)
+					}
+				}()
+			}
 
-			l := labeler.New(client, owner, repo, prNum, enforceDescription, enforceReleaseNoteQuality, enforceChangelogKindExclusivity)
-			if err := l.ProcessPR(ctx, body, true); err != nil {
+			owner, repo, prNum, body := prEvent.Owner, prEvent.Repo, prEvent.Number, prEvent.Body
+			repoConfig, err := loadRepoConfig(ctx, client, owner, repo)
+			if err != nil {
 				return err
 			}
 
-			return nil
+			opts.RepoConfig = repoConfig
+			opts.AuthorLogin, opts.AuthorAssociation = prEvent.Author, prEvent.AuthorAssociation
+			opts.HeadSHA = prEvent.HeadSHA
+			l := labeler.NewFromOptions(client, owner, repo, prNum, opts)
+			if help && dryRun {
+				fmt.Fprintln(os.Stdout, "Would reply to /help")
+				return nil
+			}
+			if help {
+				if err := l.PostHelp(ctx); err != nil {
+					return &labeler.OperationalError{Err: err}
+				}
+				fmt.Fprintln(os.Stdout, "Replied to /help")
+				return nil
+			}
+			result, err := l.ProcessPR(ctx, body, runMode.SyncLabels() && !dryRun)
+			if _, operational := labeler.Partition(err); dryRun && len(operational) == 0 {
+				fmt.Fprint(os.Stdout, cmp.Or(l.LabelDiff(), "No label changes\n"))
+			}
+			if f := l.ReleaseNoteFence(); f != "" {
+				fmt.Fprintf(os.Stdout, "PR fences its release note as %q, which should be normalized to release-note\n", f)
+			}
+			if l.RenameOnly() {
+				fmt.Fprintln(os.Stdout, "PR only renames files, defaulting to /kind cleanup and release note NONE")
+			}
+			if files := l.DocsKindCode(); len(files) > 0 {
+				fmt.Fprintf(os.Stdout, "PR is /kind documentation but changes Go code (%s), check its kind\n", strings.Join(files, ", "))
+			}
+			if l.BotAuthored() {
+				fmt.Fprintf(os.Stdout, "PR was opened by a dependency update bot, defaulting to /kind %s\n", lf.bots.Kind)
+			}
+			if l.Skipped() {
+				fmt.Fprintln(os.Stdout, "PR only changes ignored paths, skipping validation")
+			}
+			if reasons := l.SuspectedSpam(); len(reasons) > 0 {
+				fmt.Fprintf(os.Stdout, "PR looks like spam (%s), labeling %q for triage\n", strings.Join(reasons, "; "), labels.SuspectedSpamLabel)
+			}
+			if msg := l.EscalationMessage(); msg != "" {
+				fmt.Fprint(os.Stdout, msg)
+			}
+			if d, ok := l.TimeToGreen(); ok {
+				fmt.Fprintf(os.Stdout, "PR passed validation %s after first failing it\n", d.Round(time.Second))
+			}
+			if deprecated := l.Decision().DeprecatedKinds; len(deprecated) > 0 {
+				fmt.Fprintf(os.Stdout, "PR uses deprecated kinds %s, migrated to their replacements\n", strings.Join(deprecated, ", "))
+			}
+			if _, operational := labeler.Partition(err); len(operational) == 0 && result != nil {
+				if perr := publishResults(ctx, action, l, result, slices.Contains(reporterNames, labeler.ReporterSummary)); perr != nil {
+					err = errors.Join(err, &labeler.OperationalError{Err: perr})
+				}
+			}
+			if _, operational := labeler.Partition(err); len(operational) == 0 && signingKey != nil {
+				if perr := writeProvenance(l.Provenance(version), signingKey, provenanceOut); perr != nil {
+					return errors.Join(err, perr)
+				}
+			}
+			return err
 		},
 	}
+	lf.register(&cmd)
+	cmd.Flags().StringVar(&mode, "mode", string(labeler.ModeStrict), "how validation failures are handled: strict (label and fail), lenient (label only), or report-only (report without labeling)")
+	cmd.Flags().StringSliceVar(&triage, "triage-assignees", nil, "comma-separated triage rotation (users, or org/team) assigned when a do-not-merge/* label is applied")
+	cmd.Flags().StringVar(&provenanceKey, "provenance-key", "", "PEM ed25519 key to sign a record of the label decision with (or set "+signingKeyEnv+")")
+	cmd.Flags().StringVar(&provenanceOut, "provenance-out", "pr-kind-labeler-provenance.json", "where to write the signed decision record")
+	cmd.Flags().StringVar(&secretNotify, "secret-notify-url", "", "incoming webhook told privately about possible credentials (or set "+secretNotifyEnv+")")
+	cmd.Flags().BoolVar(&stickyComment, "sticky-comment", false, "tell PR authors what to fix in a comment that is updated on every run")
+	cmd.Flags().BoolVar(&mergeBlockers, "merge-blockers-comment", false, "once a PR has several do-not-merge/* labels, keep a checklist comment of them and how to clear each")
+	cmd.Flags().DurationVar(&commentEvery, "comment-interval", 0, "post at most one new comment per this interval on a PR, e.g. 10m; edits to existing comments are not limited (0 disables)")
+	cmd.Flags().BoolVar(&checkRun, "check-run", false, "report the result as a "+labeler.CheckRunName+" check run on the PR head commit, with an annotation per problem")
+	cmd.Flags().BoolVar(&commitStatus, "commit-status", false, "report the result as a "+labeler.CheckRunName+" commit status on the PR head commit, for tokens that cannot create check runs")
+	cmd.Flags().StringSliceVar(&reporterNames, "reporters", []string{labeler.ReporterSummary}, "comma-separated reporters of the result: "+strings.Join(labeler.ReporterNames, ", ")+"; comment, check-run and status are the same as --sticky-comment, --check-run and --commit-status")
+	cmd.Flags().StringVar(&slackWebhook, "slack-webhook-url", "", "Slack incoming webhook the slack reporter posts to (or set "+slackWebhookEnv+")")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "evaluate the event and print the label changes as a unified diff without changing the PR")
+	cmd.Flags().BoolVar(&validateOnly, "validate-only", false, "never add or remove labels, only report the result, e.g. with --check-run or --commit-status")
+	cmd.Flags().StringVar(&failureStore, "failure-store", "", "JSON file counting each author's PRs that failed validation, e.g. restored with actions/cache; enables --escalate-after")
+	cmd.Flags().IntVar(&escalation.Threshold, "escalate-after", 3, "failed PRs after which an author's guidance is escalated")
+	cmd.Flags().StringVar(&escalation.DocsURL, "contributor-docs-url", "", "contributor docs linked from escalated guidance")
+	cmd.Flags().StringSliceVar(&escalation.Mentors, "mentors", nil, "comma-separated users or org/team-slug teams pinged in escalated guidance")
+	cmd.Flags().BoolVar(&exportMeta, "export-metadata", false, "export the parsed PR metadata (kinds, notes, areas, size) as JSON in the check run")
+	cmd.Flags().StringVar(&metadataBranch, "metadata-branch", "", "with --export-metadata, also commit the metadata to pr-metadata/NUMBER.json on this existing branch, e.g. gh-pages")
+	cmd.Flags().BoolVar(&refetchPR, "refetch-pr", false, "fetch the PR from the API instead of trusting the event payload, whose body can predate the edit that triggered the run")
+	cmd.Flags().BoolVar(&serialize, "serialize-runs", false, "serialize the runs on a PR with a lock check run, so concurrent runs do not interleave label changes; a run superseded by a later one exits without changes")
+	cmd.Flags().DurationVar(&serializeWait, "serialize-timeout", 5*time.Minute, "how long --serialize-runs waits for an earlier run before assuming it was cancelled")
+	cmd.Flags().StringVar(&eventPath, "event", "", "read the event payload from this file instead of GITHUB_EVENT_PATH, or from stdin if -; the event name is still read from GITHUB_EVENT_NAME")
+	cmd.PersistentFlags().StringVar(&recordDir, "record", "", "append the sanitized GitHub API requests and responses of the run to a cassette in this directory, e.g. to attach to a bug report")
+	cmd.MarkFlagFilename("failure-store", "json")
+	cmd.MarkPersistentFlagDirname("record")
+	cmd.MarkFlagFilename("event", "json")
+	cmd.RegisterFlagCompletionFunc("mode", cobra.FixedCompletions([]string{
+		string(labeler.ModeStrict) + "\tlabel the PR and fail the job",
+		string(labeler.ModeLenient) + "\tlabel the PR without failing the job",
+		string(labeler.ModeReportOnly) + "\treport without labeling or failing the job",
+	}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.AddCommand(newGoldenCmd())
+	cmd.AddCommand(newLocalCmd())
+	cmd.AddCommand(newSelfUpdateCmd())
+	cmd.AddCommand(newServeCmd())
+	cmd.AddCommand(newOperatorCmd())
+	cmd.AddCommand(newWebhookCmd())
+	cmd.AddCommand(newProvenanceCmd())
+	cmd.AddCommand(newMigrateLabelsCmd())
+	cmd.AddCommand(newAuditCmd())
+	cmd.AddCommand(newReplayCmd())
+	cmd.AddCommand(newChangelogCmd())
+	cmd.AddCommand(newUndoCmd())
+	cmd.AddCommand(newSetupCmd())
+	cmd.AddCommand(newMergeableCmd())
+	cmd.AddCommand(newDashboardCmd())
+	cmd.AddCommand(newDigestCmd())
+	cmd.AddCommand(newStatusCmd())
+	cmd.AddCommand(newCatalogCmd())
+	cmd.AddCommand(newValidateCmd())
+	cmd.AddCommand(newBackfillCmd())
+	markUsageErrors(&cmd)
 	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(report(err, mode))
 	}
 }
 
-func manualTest(ctx context.Context, client *github.Client, owner, repo string, prNum int, enforceDescription bool, enforceReleaseNoteQuality bool, enforceChangelogKindExclusivity bool) error {
+// Exit codes are a stable contract, so workflows and wrapper scripts can branch
+// on the kind of failure without parsing output.
+const (
+	// exitOK is returned when the PR is valid, or when validation failures are not enforced by --mode.
+	exitOK = 0
+	// exitInvalidPR is returned when the PR failed validation for reasons other
+	// than its kind or release note, e.g. an empty description.
+	exitInvalidPR = 1
+	// exitInvalidKind is returned when the PR's /kind commands are missing or invalid.
+	exitInvalidKind = 2
+	// exitInvalidReleaseNote is returned when the PR's release note is missing or invalid.
+	exitInvalidReleaseNote = 3
+	// exitAPIError is returned when the labeler itself failed, e.g. a GitHub API error.
+	exitAPIError = 4
+	// exitConfigError is returned for invalid flags, arguments, environment or config files.
+	exitConfigError = 5
+)
+
+// report prints validation problems to stdout and operational failures to
+// stderr, and returns the exit code CI should see. Operational failures take
+// precedence so a broken bot is never mistaken for a bad PR, and always fail
+// the job regardless of mode; config errors take precedence over API errors.
+// Among validation failures, kind problems take precedence over release note
+// problems, which take precedence over the rest.
+func report(err error, mode string) int {
+	validation, operational := labeler.Partition(err)
+	if len(validation) > 0 {
+		fmt.Fprintln(os.Stdout, "PR validation failed:")
+		action := ghaction.New()
+		for _, e := range validation {
+			if action.Running() {
+				// annotations are listed on the workflow run page too
+				action.Annotate(ghaction.Annotation{Level: ghaction.LevelError, Title: "PR validation failed", Message: e.Error()})
+				continue
+			}
+			fmt.Fprintf(os.Stdout, "- %s\n", e)
+		}
+	}
+	for _, e := range operational {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", e)
+	}
+	if len(operational) > 0 {
+		var configErr *labeler.ConfigError
+		if errors.As(errors.Join(operational...), &configErr) {
+			return exitConfigError
+		}
+		return exitAPIError
+	}
+	if len(validation) == 0 || !labeler.Mode(mode).FailOnValidation() {
+		return exitOK
+	}
+	code := exitInvalidPR
+	for _, e := range validation {
+		var v *labeler.ValidationError
+		errors.As(e, &v)
+		switch {
+		case v.Check == labeler.CheckKind:
+			return exitInvalidKind
+		case v.Check == labeler.CheckReleaseNote:
+			code = exitInvalidReleaseNote
+		}
+	}
+	return code
+}
+
+// markUsageErrors reports invalid flags and arguments of cmd and its
+// subcommands as config errors.
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &labeler.ConfigError{Err: err}
+	})
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			if err := validate(c, args); err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
+
+// actionResult is the subset of the labeler published to the workflow run.
+type actionResult interface {
+	Decision() *labeler.Decision
+	Report() *labeler.Report
+}
+
+// publishResults sets the valid, kinds, release-note, labels, labels-added,
+// labels-removed, release-note-section and deprecated-kinds step outputs,
+// for later steps to use without parsing the PR body, and, with summary set,
+// adds the validation result and a table of the label changes to the job
+// summary.
+func publishResults(ctx context.Context, action *ghaction.Action, l actionResult, r *labeler.Result, summary bool) error {
+	d := l.Decision()
+	note, section := "", ""
+	if r.ReleaseNote != nil {
+		note, section = r.ReleaseNote.Note, r.ReleaseNote.Section
+	}
+	outputs := [][2]string{
+		{"valid", strconv.FormatBool(r.Valid)},
+		{"kinds", strings.Join(r.Kinds, ",")},
+		{"release-note", note},
+		{"labels", strings.Join(d.FinalLabels(), ",")},
+		{"labels-added", strings.Join(r.LabelsAdded, ",")},
+		{"labels-removed", strings.Join(r.LabelsRemoved, ",")},
+		{"release-note-section", section},
+		{"deprecated-kinds", strings.Join(d.DeprecatedKinds, ",")},
+	}
+	for _, o := range outputs {
+		if err := action.SetOutput(o[0], o[1]); err != nil {
+			return err
+		}
+	}
+	if !summary {
+		return nil
+	}
+	return (&reporters.StepSummary{Action: action}).Report(ctx, l.Report())
+}
+
+// readEvent reads the event that triggered the workflow run, or the one at
+// path if set, where "-" reads it from stdin. Events other
+// than issue_comment, pull_request_review, check_suite and status are read as
+// pull_request events. A comment on a PR that is a /kind or /release-note
+// command is returned with the PR it was made on fetched, and a /help command
+// as is; other comments yield nil. Completed checks are returned with the PR of
+// their commit fetched, or nil if it has none. If refetch, the PR of a pull_request event is
+// fetched too, as edited events may carry the body from before the edit.
+func readEvent(ctx context.Context, client *github.Client, action *ghaction.Action, path string, refetch bool) (*event.PullRequest, error) {
+	var (
+		ghEvent *ghaction.Event
+		err     error
+	)
+	if path != "" {
+		ghEvent, err = action.EventFrom(path)
+	} else {
+		ghEvent, err = action.Event()
+	}
+	if err != nil {
+		return nil, &labeler.ConfigError{Err: err}
+	}
+	name := ghEvent.Name
+	switch name {
+	case "issue_comment", "pull_request_review", "check_suite", "status":
+	default:
+		name = "pull_request"
+	}
+	e, err := event.FromGitHub(name, ghEvent.Payload)
+	if err != nil {
+		return nil, &labeler.ConfigError{Err: err}
+	}
+	if e == nil || (!e.Partial && !refetch) {
+		return e, nil
+	}
+	if e.Number == 0 {
+		prs, _, err := client.PullRequests.ListPullRequestsWithCommit(ctx, e.Owner, e.Repo, e.HeadSHA, nil)
+		if err != nil {
+			return nil, &labeler.OperationalError{Err: fmt.Errorf("failed to list PRs of commit %s: %w", e.HeadSHA, err)}
+		}
+		i := slices.IndexFunc(prs, func(pr *github.PullRequest) bool {
+			return pr.GetState() == "open" && pr.GetHead().GetSHA() == e.HeadSHA
+		})
+		if i < 0 {
+			return nil, nil
+		}
+		e.Number = prs[i].GetNumber()
+	}
+	if e.Action == event.Commented {
+		if labeler.IsHelpCommand(e.Comment.Body) {
+			return e, nil
+		}
+		if !labeler.HasCommand(e.Comment.Body) {
+			return nil, nil
+		}
+	}
+	pr, _, err := client.PullRequests.Get(ctx, e.Owner, e.Repo, e.Number)
+	if err != nil {
+		return nil, &labeler.OperationalError{Err: fmt.Errorf("failed to get PR: %w", err)}
+	}
+	event.FromGitHubPullRequest(e, pr)
+	return e, nil
+}
+
+// loadRepoConfig returns the kinds and label names owner/repo defines in
+// config.Path, or the defaults if it defines none.
+func loadRepoConfig(ctx context.Context, client *github.Client, owner, repo string) (*config.Config, error) {
+	c, err := config.Load(ctx, client, owner, repo)
+	if errors.Is(err, config.ErrInvalid) {
+		return nil, &labeler.ConfigError{Err: err}
+	}
+	if err != nil {
+		return nil, &labeler.OperationalError{Err: err}
+	}
+	return c, nil
+}
+
+// lockPR serializes the run with the other runs on the PR of e, and returns
+// nil if a later run supersedes it. If it had to wait, e is updated from the
+// PR as it is now.
+func lockPR(ctx context.Context, client *github.Client, e *event.PullRequest, timeout time.Duration) (*prlock.Lock, error) {
+	if e.HeadSHA == "" {
+		pr, _, err := client.PullRequests.Get(ctx, e.Owner, e.Repo, e.Number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PR: %w", err)
+		}
+		event.FromGitHubPullRequest(e, pr)
+	}
+	lock, err := prlock.Acquire(ctx, client, e.Owner, e.Repo, e.Number, e.HeadSHA, 10*time.Second, timeout)
+	if lock == nil || err != nil || !lock.Waited {
+		return lock, err
+	}
+	pr, _, err := client.PullRequests.Get(ctx, e.Owner, e.Repo, e.Number)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to get PR: %w", err), lock.Release(ctx))
+	}
+	event.FromGitHubPullRequest(e, pr)
+	return lock, nil
+}
+
+func manualTest(ctx context.Context, client *github.Client, l labelProcessor, owner, repo string, prNum int) error {
 
 	prResp, _, err := client.PullRequests.Get(ctx, owner, repo, prNum)
 	if err != nil {
@@ -109,6 +623,190 @@ func manualTest(ctx context.Context, client *github.Client, owner, repo string,
 	}
 	body := prResp.GetBody()
 
-	l := labeler.New(client, owner, repo, prNum, enforceDescription, enforceReleaseNoteQuality, enforceChangelogKindExclusivity)
-	return l.ProcessPR(ctx, body, false)
+	_, err = l.ProcessPR(ctx, body, false)
+	if _, operational := labeler.Partition(err); len(operational) > 0 {
+		return err
+	}
+	preview, perr := l.Preview(ctx)
+	if perr != nil {
+		return errors.Join(err, &labeler.OperationalError{Err: perr})
+	}
+	fmt.Fprint(os.Stdout, preview)
+	return err
+}
+
+// secretNotifyEnv holds the possible-secret webhook URL when
+// --secret-notify-url is not set, since such URLs are credentials themselves.
+const secretNotifyEnv = "PR_KIND_LABELER_SECRET_NOTIFY_URL"
+
+// secretNotifier returns the notifier for url or secretNotifyEnv, or nil when
+// neither is set.
+func secretNotifier(url string) labeler.SecretNotifier {
+	if url == "" {
+		url = os.Getenv(secretNotifyEnv)
+	}
+	if url == "" {
+		return nil
+	}
+	return &secrets.WebhookNotifier{URL: url}
+}
+
+// slackWebhookEnv holds the Slack webhook URL when --slack-webhook-url is
+// unset, so workflows can pass it from a secret without it showing in args.
+const slackWebhookEnv = "PR_KIND_LABELER_SLACK_WEBHOOK_URL"
+
+// slackReporter returns the Slack reporter for url or slackWebhookEnv,
+// deduplicated and reporting each PR at most once per interval unless its
+// validity changes, or nil when neither is set.
+func slackReporter(url string, interval time.Duration) labeler.Reporter {
+	if url == "" {
+		url = os.Getenv(slackWebhookEnv)
+	}
+	if url == "" {
+		return nil
+	}
+	return labeler.Deduplicate(&reporters.Slack{URL: url}, interval)
+}
+
+// completeKindPairs completes the kind half of kind=value flags.
+func completeKindPairs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	var completions []string
+	for _, k := range kinds.Supported() {
+		completions = append(completions, prefix+k+"=")
+	}
+	return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// parseKeyValues parses a comma-separated list of key=value pairs. An empty
+// string yields an empty map so optional action inputs can be passed through.
+func parseKeyValues(s string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" || strings.TrimSpace(v) == "" {
+			return nil, fmt.Errorf("%q must be formatted as key=value", pair)
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return m, nil
+}
+
+// parseKindMilestones parses kind=milestone pairs into default milestones.
+func parseKindMilestones(s string) (map[string]string, error) {
+	milestones, err := parseKeyValues(s)
+	if err != nil {
+		return nil, err
+	}
+	for kind := range milestones {
+		if !kinds.SupportedKinds[kind] {
+			return nil, fmt.Errorf("unknown kind %q, expected one of %s", kind, kinds.Render())
+		}
+	}
+	return milestones, nil
+}
+
+// parseKindWeights parses kind=weight pairs into risk weights.
+func parseKindWeights(s string) (map[string]int, error) {
+	pairs, err := parseKeyValues(s)
+	if err != nil {
+		return nil, err
+	}
+	weights := map[string]int{}
+	for kind, v := range pairs {
+		if !kinds.SupportedKinds[kind] {
+			return nil, fmt.Errorf("unknown kind %q, expected one of %s", kind, kinds.Render())
+		}
+		w, err := strconv.Atoi(v)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("weight of %s must be a non-negative integer, got %q", kind, v)
+		}
+		weights[kind] = w
+	}
+	return weights, nil
+}
+
+// parseAutoNone parses association=kind and org/team=kind pairs into author
+// policies. A key may be repeated to allow several kinds.
+func parseAutoNone(s string) (map[string]labeler.AuthorPolicy, error) {
+	policies := map[string]labeler.AuthorPolicy{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, kind, ok := strings.Cut(pair, "=")
+		key, kind = strings.TrimSpace(key), strings.TrimSpace(kind)
+		if !ok || kind == "" {
+			return nil, fmt.Errorf("%q must be formatted as association=kind or org/team=kind", pair)
+		}
+		if !strings.Contains(key, "/") {
+			key = strings.ToUpper(key)
+		}
+		if !labeler.ValidPolicyKey(key) {
+			return nil, fmt.Errorf("unknown author association or team %q, expected org/team-slug or one of %s", key, strings.Join(labeler.AuthorAssociations, ", "))
+		}
+		if !kinds.SupportedKinds[kind] {
+			return nil, fmt.Errorf("unknown kind %q, expected one of %s", kind, kinds.Render())
+		}
+		p := policies[key]
+		p.AutoNoneKinds = append(p.AutoNoneKinds, kind)
+		policies[key] = p
+	}
+	return policies, nil
+}
+
+// apiFaults are the GitHub API failures injected from transport.FaultsEnv.
+var apiFaults []transport.Fault
+
+// apiRecorder, if set, records the GitHub API requests of the run for
+// --record.
+var apiRecorder *transport.Recorder
+
+// newGitHubClient creates a client authenticated with tokens, a comma-separated
+// list. Later tokens take over when earlier ones are rate limited or rejected.
+// base, if not nil, is the transport requests are sent through.
+func newGitHubClient(tokens string, base http.RoundTripper) *github.Client {
+	if len(apiFaults) > 0 {
+		// below the token rotator, so injected rate limits trigger failover
+		base = transport.NewFaultInjector(base, apiFaults)
+	}
+	if apiRecorder != nil {
+		// above the fault injector, so injected failures are recorded too
+		base = apiRecorder.Transport(base)
+	}
+	return github.NewClient(&http.Client{Transport: transport.NewTokenRotator(base, transport.SplitTokens(tokens))})
+}
+
+// isReleaseNoteCommand reports whether a comment with body is a
+// /release-note command.
+func isReleaseNoteCommand(body string) bool {
+	_, ok := labeler.ParseReleaseNoteCommand(body)
+	return ok
+}
+
+// labelProcessor is the subset of the labeler used by the CLI.
+type labelProcessor interface {
+	ProcessPR(ctx context.Context, body string, syncLabels bool) (*labeler.Result, error)
+	Preview(ctx context.Context) (string, error)
+}
+
+// parsePRRef parses a PR reference in the owner/repo/PR format.
+func parsePRRef(ref string) (string, string, int, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 3 {
+		return "", "", 0, fmt.Errorf("invalid PR format, expected owner/repo/PR")
+	}
+	prNum, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid PR number: %w", err)
+	}
+	return parts[0], parts[1], prNum, nil
 }
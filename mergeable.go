@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/server"
+)
+
+func newMergeableCmd() *cobra.Command {
+	var (
+		configPath string
+		output     string
+	)
+	cmd := &cobra.Command{
+		Use:   "mergeable owner/repo/PR",
+		Short: "Check whether a PR may be merged per the label policy",
+		Long: `Evaluate a PR as it is now, without changing anything, and report whether it
+may be merged per the label policy of the server config: it carries no
+do-not-merge/* label, and its kind and release note are valid. Merge
+automation can call this, or the server's /mergeable API, instead of
+duplicating the labeler's rules. The exit code is 0 for a mergeable PR and
+that of the failed validation otherwise. Reads the API token from GITHUB_TOKEN.`,
+		Example: `  # Gate a merge on the labeler's verdict
+  pr-kind-labeler mergeable kgateway-dev/kgateway/1234 --config config.yaml && gh pr merge 1234
+
+  # Print the verdict for merge automation to parse
+  pr-kind-labeler mergeable kgateway-dev/kgateway/1234 --output json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --output %q, expected text or json", output)}
+			}
+			owner, repo, prNum, err := parsePRRef(args[0])
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			cfg, err := server.LoadConfig(configPath)
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+
+			v, err := server.New(cfg, newGitHubClient(token, nil), nil).Mergeable(cmd.Context(), owner, repo, prNum)
+			if err != nil {
+				return &labeler.OperationalError{Err: err}
+			}
+			out := cmd.OutOrStdout()
+			switch {
+			case output == "json":
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(v); err != nil {
+					return err
+				}
+			case v.Mergeable:
+				fmt.Fprintf(out, "%s is mergeable\n", args[0])
+			default:
+				fmt.Fprintf(out, "%s is not mergeable\n", args[0])
+			}
+			return v.Err()
+		},
+	}
+	cmd.Flags().StringVar(&configPath, "config", "", "path to the server config file whose label policy applies")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "output format: text or json")
+	cmd.MarkFlagFilename("config", "yaml", "yml")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/migrate"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/labels"
+)
+
+func newMigrateLabelsCmd() *cobra.Command {
+	var (
+		mapping      string
+		closedWithin time.Duration
+		deleteOld    bool
+		apply        bool
+	)
+	cmd := &cobra.Command{
+		Use:   "migrate-labels owner/repo...",
+		Short: "Rename labels across repositories and the issues and PRs that carry them",
+		Long: `Rename each old label to its new name. When the new label does not exist yet,
+the label definition is renamed and GitHub moves every issue and PR to it.
+Otherwise open issues and PRs, and those closed within --closed-within, are
+moved from the old label to the new one.
+
+Without --map, the labels the labeler itself has retired are migrated, e.g.
+` + labels.DeprecatedReleaseNoteLabel + ` -> ` + labels.ReleaseNoteLabel + `. Nothing is changed on GitHub
+unless --apply is set. Reads the API token from GITHUB_TOKEN.`,
+		Example: `  # Preview migrating retired labels
+  pr-kind-labeler migrate-labels kgateway-dev/kgateway
+
+  # Rename a label, also relabeling PRs closed in the last 90 days
+  pr-kind-labeler migrate-labels kgateway-dev/kgateway --map area/gw=area/gateway --closed-within 2160h --apply`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			renames := labels.Renames()
+			if mapping != "" {
+				var err error
+				if renames, err = parseKeyValues(mapping); err != nil {
+					return fmt.Errorf("invalid --map: %w", err)
+				}
+			}
+			opts := migrate.Options{DeleteOld: deleteOld, DryRun: !apply}
+			if closedWithin > 0 {
+				opts.ClosedSince = time.Now().Add(-closedWithin)
+			}
+
+			client := newGitHubClient(token, nil)
+			out := cmd.OutOrStdout()
+			var failed int
+			for _, arg := range args {
+				owner, repo, err := parseRepoRef(arg)
+				if err != nil {
+					return err
+				}
+				changes, err := migrate.Run(cmd.Context(), client, owner, repo, renames, opts)
+				for _, c := range changes {
+					fmt.Fprintf(out, "%s: %s -> %s: %s", arg, c.Old, c.New, c.Action)
+					if c.Action == migrate.Relabeled {
+						fmt.Fprintf(out, " %d issues and PRs", len(c.Issues))
+					}
+					if c.Deleted {
+						fmt.Fprint(out, ", deleted old label")
+					}
+					fmt.Fprintln(out)
+				}
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", arg, err)
+					failed++
+				}
+			}
+			if !apply {
+				fmt.Fprintln(out, "dry run: nothing was changed, set --apply to migrate")
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to migrate %d of %d repositories", failed, len(args))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&mapping, "map", "", "comma-separated old=new label pairs (defaults to the labels the labeler has retired)")
+	cmd.Flags().DurationVar(&closedWithin, "closed-within", 0, "also relabel issues and PRs closed within this duration, e.g. 720h")
+	cmd.Flags().BoolVar(&deleteOld, "delete-old", false, "delete old label definitions once relabeled, removing them from older closed issues and PRs")
+	cmd.Flags().BoolVar(&apply, "apply", false, "make the changes instead of only reporting them")
+	return cmd
+}
+
+// parseRepoRef parses a repository reference in the owner/repo format.
+func parseRepoRef(ref string) (string, string, error) {
+	owner, repo, ok := strings.Cut(ref, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("invalid repository %q, expected owner/repo", ref)
+	}
+	return owner, repo, nil
+}
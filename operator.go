@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/operator"
+)
+
+func newOperatorCmd() *cobra.Command {
+	var (
+		namespace string
+		interval  time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "operator",
+		Short: "Reconcile LabelerConfig resources into repository webhooks",
+		Long: `Run in a Kubernetes cluster, periodically read the LabelerConfig resources
+(deploy/crd.yaml) in a namespace, and register or update the server-mode
+webhook on every repository they list, using the GitHub token from the
+referenced Secret. Repositories already synced are only updated again when
+their LabelerConfig or webhook secret changes, and the webhook of a repository
+removed from the list is deleted. Results are written to each LabelerConfig's
+status.`,
+		Example: `  # Reconcile the pod's own namespace every five minutes
+  pr-kind-labeler operator
+
+  # Reconcile another namespace more often
+  pr-kind-labeler operator --namespace kgateway-system --interval 1m`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return &labeler.ConfigError{Err: fmt.Errorf("--interval must be positive")}
+			}
+			kube, podNamespace, err := operator.InCluster()
+			if err != nil {
+				return err
+			}
+			if namespace == "" {
+				namespace = podNamespace
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, os.Interrupt)
+			defer stop()
+			r := &operator.Reconciler{
+				Kube:      kube,
+				Namespace: namespace,
+				NewGitHubClient: func(token string) *github.Client {
+					return newGitHubClient(token, nil)
+				},
+				Now: time.Now,
+			}
+			return r.Run(ctx, interval)
+		},
+	}
+	cmd.Flags().StringVar(&namespace, "namespace", "", "namespace to watch (defaults to the pod's namespace)")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "how often to reconcile")
+	return cmd
+}
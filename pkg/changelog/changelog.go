@@ -0,0 +1,276 @@
+// Package changelog maps release notes to the changelog sections they are
+// published under.
+package changelog
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+)
+
+// Section is a changelog section.
+type Section struct {
+	// ID is the kind, or category, whose notes the section lists.
+	ID string
+	// Title is the section heading.
+	Title string
+}
+
+const (
+	// Helm is a category for notes about the Helm charts.
+	Helm = "helm"
+	// Security is a category for notes about security fixes.
+	Security = "security"
+)
+
+// Sections lists the changelog sections in the order they are published.
+var Sections = []Section{
+	{ID: kinds.BreakingChange, Title: "Breaking Changes"},
+	{ID: Security, Title: "Security"},
+	{ID: kinds.Feature, Title: "New Features"},
+	{ID: kinds.Fix, Title: "Bug Fixes"},
+	{ID: kinds.Deprecation, Title: "Deprecations"},
+	{ID: Helm, Title: "Helm"},
+	{ID: kinds.Install, Title: "Installation"},
+	{ID: kinds.Documentation, Title: "Documentation"},
+	{ID: kinds.Bump, Title: "Dependency Bumps"},
+}
+
+// categoryRE captures a [category] prefix overriding a note's section.
+var categoryRE = regexp.MustCompile(`^\[([A-Za-z0-9_-]+)\][ \t]*`)
+
+// ParseCategory splits a release note into the section it overrides, e.g.
+// helm for "[helm] Added the foo value.", and the note without the prefix.
+// category is "" when the note has no prefix.
+func ParseCategory(note string) (category, rest string) {
+	m := categoryRE.FindStringSubmatch(note)
+	// a markdown link such as [kgateway](https://...) is not a category
+	if m == nil || strings.HasPrefix(note[len(m[1])+2:], "(") {
+		return "", note
+	}
+	return strings.ToLower(m[1]), note[len(m[0]):]
+}
+
+// Lookup returns the section with id.
+func Lookup(id string) (Section, bool) {
+	for _, s := range Sections {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
+
+// IDs returns the section IDs, in publishing order, as a comma-separated list.
+func IDs() string {
+	ids := make([]string, len(Sections))
+	for i, s := range Sections {
+		ids[i] = s.ID
+	}
+	return strings.Join(ids, ", ")
+}
+
+// SectionFor returns the ID of the section a note belongs in: its category
+// if it has one, otherwise the first section matching one of the PR's kinds.
+// It returns "" if no section lists notes of these kinds.
+func SectionFor(prKinds map[string]bool, category string) string {
+	if category != "" {
+		return category
+	}
+	for _, s := range Sections {
+		if prKinds[s.ID] {
+			return s.ID
+		}
+	}
+	return ""
+}
+
+// OtherComponent is the heading of notes of PRs with no component when a
+// changelog is grouped by component.
+const OtherComponent = "Other"
+
+// Entry is a release note to publish.
+type Entry struct {
+	// Note is the release note, without its category prefix.
+	Note string
+	// Section is the ID of the section the note is published in.
+	Section string
+	// PR is the number of the PR the note comes from.
+	PR int
+	// Components are the components the PR changes, e.g. from its module/
+	// labels. A note is listed under each of them.
+	Components []string
+	// Reverts is the PR this PR reverts, or 0 if none.
+	Reverts int
+	// RevertedBy is the PR that reverts this PR, set by Reconcile when
+	// marking reverted notes.
+	RevertedBy int
+	// Epic is the tracking issue the PR defers its note to, or 0 if none.
+	Epic int
+	// PRs are the PRs of the epic, set by ConsolidateEpics on the entry of
+	// an epic, whose PR is its tracking issue.
+	PRs []int
+}
+
+// Reconcile resolves the reverts among entries, which are the PRs of one
+// release range, so the changelog does not advertise changes that were
+// undone before the release. A PR reverted by another PR of the range is
+// dropped, or if mark, kept with a note of the revert; the revert itself is
+// always dropped, as it changes nothing the release ships. A revert that is
+// itself reverted, e.g. when a change is relanded, is resolved first, so the
+// original PR is kept as is.
+func Reconcile(entries []Entry, mark bool) []Entry {
+	revertedBy := map[int]int{}
+	for _, e := range entries {
+		if e.Reverts != 0 {
+			revertedBy[e.Reverts] = e.PR
+		}
+	}
+	// undone reports whether pr is reverted by a revert that is not itself
+	// undone. visiting guards against revert cycles in malformed metadata.
+	visiting := map[int]bool{}
+	var undone func(pr int) bool
+	undone = func(pr int) bool {
+		revert, ok := revertedBy[pr]
+		if !ok || visiting[pr] {
+			return false
+		}
+		visiting[pr] = true
+		defer delete(visiting, pr)
+		return !undone(revert)
+	}
+	inRange := map[int]bool{}
+	for _, e := range entries {
+		inRange[e.PR] = true
+	}
+
+	var reconciled []Entry
+	for _, e := range entries {
+		switch {
+		case e.Reverts != 0 && inRange[e.Reverts]:
+			continue
+		case undone(e.PR) && mark:
+			e.RevertedBy = revertedBy[e.PR]
+		case undone(e.PR):
+			continue
+		}
+		reconciled = append(reconciled, e)
+	}
+	return reconciled
+}
+
+// Render renders entries as a markdown changelog, with a section per kind in
+// the order of Sections and notes, sanitized, in PR order. Entries in no
+// known section are left out. If any entry has components, the changelog is
+// grouped by component first, in name order, with the notes of PRs without
+// one under OtherComponent last.
+func Render(entries []Entry) string {
+	return render(entries, "##", func(pr int) string { return fmt.Sprintf("#%d", pr) })
+}
+
+// Repository is the notes of one of the repositories a release spans.
+type Repository struct {
+	// Name is the repository as owner/repo.
+	Name    string
+	Entries []Entry
+}
+
+// RenderRollup renders the notes of the repositories a release spans as one
+// changelog, with a section per repository in the given order, grouped as
+// by Render within it. PRs are referenced as owner/repo#number, since their
+// numbers are only unique within a repository. Repositories without
+// published notes are left out.
+func RenderRollup(repos []Repository) string {
+	var sb strings.Builder
+	for _, r := range repos {
+		rendered := render(r.Entries, "###", func(pr int) string { return fmt.Sprintf("%s#%d", r.Name, pr) })
+		if rendered == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("## " + r.Name + "\n\n" + rendered)
+	}
+	return sb.String()
+}
+
+// render renders entries as Render does, with top-level headings of the
+// given level and PRs referenced with ref.
+func render(entries []Entry, heading string, ref func(pr int) string) string {
+	byComponent := map[string][]Entry{}
+	var components []string
+	for _, e := range entries {
+		if _, ok := Lookup(e.Section); !ok {
+			continue
+		}
+		for _, c := range e.Components {
+			if byComponent[c] == nil {
+				components = append(components, c)
+			}
+			byComponent[c] = append(byComponent[c], e)
+		}
+		if len(e.Components) == 0 {
+			byComponent[""] = append(byComponent[""], e)
+		}
+	}
+	if len(components) == 0 {
+		return renderSections(byComponent[""], heading, ref)
+	}
+	sort.Strings(components)
+	if len(byComponent[""]) > 0 {
+		components = append(components, "")
+	}
+	var sb strings.Builder
+	for i, c := range components {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(heading + " " + cmp.Or(c, OtherComponent) + "\n\n")
+		sb.WriteString(renderSections(byComponent[c], heading+"#", ref))
+	}
+	return sb.String()
+}
+
+// renderSections renders entries as sections with headings of the given
+// level.
+func renderSections(entries []Entry, heading string, ref func(pr int) string) string {
+	entries = slices.Clone(entries)
+	slices.SortStableFunc(entries, func(a, b Entry) int { return cmp.Compare(a.PR, b.PR) })
+	var sb strings.Builder
+	for _, s := range Sections {
+		first := true
+		for _, e := range entries {
+			if e.Section != s.ID {
+				continue
+			}
+			if first {
+				if sb.Len() > 0 {
+					sb.WriteString("\n")
+				}
+				sb.WriteString(heading + " " + s.Title + "\n\n")
+				first = false
+			}
+			note := strings.ReplaceAll(Sanitize(strings.TrimSpace(e.Note)), "\n", "\n  ")
+			if len(e.PRs) > 0 {
+				refs := make([]string, len(e.PRs))
+				for i, pr := range e.PRs {
+					refs[i] = ref(pr)
+				}
+				fmt.Fprintf(&sb, "- %s (%s: %s)\n", note, ref(e.PR), strings.Join(refs, ", "))
+				continue
+			}
+			if e.RevertedBy != 0 {
+				fmt.Fprintf(&sb, "- %s (%s, reverted in %s)\n", note, ref(e.PR), ref(e.RevertedBy))
+				continue
+			}
+			fmt.Fprintf(&sb, "- %s (%s)\n", note, ref(e.PR))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,175 @@
+package changelog
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    string
+	}{
+		{
+			name: "by kind",
+			entries: []Entry{
+				{Note: "Fixed a crash.", Section: "fix", PR: 12},
+				{Note: "Added the foo field.", Section: "feature", PR: 11},
+				{Note: "Fixed a leak.", Section: "fix", PR: 10},
+				{Note: "Not published.", Section: "", PR: 9},
+			},
+			want: "## New Features\n\n- Added the foo field. (#11)\n\n## Bug Fixes\n\n- Fixed a leak. (#10)\n- Fixed a crash. (#12)\n",
+		},
+		{
+			name: "by component then kind",
+			entries: []Entry{
+				{Note: "Fixed a crash.", Section: "fix", PR: 12, Components: []string{"controlplane"}},
+				{Note: "Added the foo value.", Section: Helm, PR: 13, Components: []string{"helm"}},
+				{Note: "Bumped Envoy.", Section: "bump", PR: 14, Components: []string{"dataplane", "controlplane"}},
+				{Note: "Fixed the docs.", Section: "documentation", PR: 15},
+			},
+			want: "## controlplane\n\n### Bug Fixes\n\n- Fixed a crash. (#12)\n\n### Dependency Bumps\n\n- Bumped Envoy. (#14)\n" +
+				"\n## dataplane\n\n### Dependency Bumps\n\n- Bumped Envoy. (#14)\n" +
+				"\n## helm\n\n### Helm\n\n- Added the foo value. (#13)\n" +
+				"\n## Other\n\n### Documentation\n\n- Fixed the docs. (#15)\n",
+		},
+		{
+			name:    "multi-line note",
+			entries: []Entry{{Note: "Changed the foo.\nSee the docs.", Section: "fix", PR: 1}},
+			want:    "## Bug Fixes\n\n- Changed the foo.\n  See the docs. (#1)\n",
+		},
+		{
+			name:    "malformed note",
+			entries: []Entry{{Note: "Added ![a](b.png) a | b\n## Oops", Section: "fix", PR: 1}},
+			want:    "## Bug Fixes\n\n- Added a a \\| b\n  \\## Oops (#1)\n",
+		},
+		{
+			name: "empty",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.entries); got != tt.want {
+				t.Fatalf("Render() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderRollup(t *testing.T) {
+	repos := []Repository{
+		{Name: "kgateway-dev/kgateway", Entries: []Entry{
+			{Note: "Fixed a crash.", Section: "fix", PR: 12},
+			{Note: "Added the foo field.", Section: "feature", PR: 11, PRs: []int{13, 14}},
+		}},
+		{Name: "kgateway-dev/docs", Entries: []Entry{{Note: "Not published.", PR: 3}}},
+		{Name: "kgateway-dev/kgateway-helm", Entries: []Entry{
+			{Note: "Added the foo value.", Section: Helm, PR: 12, RevertedBy: 15, Components: []string{"gateway"}},
+		}},
+	}
+	want := "## kgateway-dev/kgateway\n\n### New Features\n\n- Added the foo field. (kgateway-dev/kgateway#11: kgateway-dev/kgateway#13, kgateway-dev/kgateway#14)\n" +
+		"\n### Bug Fixes\n\n- Fixed a crash. (kgateway-dev/kgateway#12)\n" +
+		"\n## kgateway-dev/kgateway-helm\n\n### gateway\n\n#### Helm\n\n- Added the foo value. (kgateway-dev/kgateway-helm#12, reverted in kgateway-dev/kgateway-helm#15)\n"
+	if got := RenderRollup(repos); got != want {
+		t.Fatalf("RenderRollup() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	entries := []Entry{
+		{Note: "Added the frobnicator.", Section: "feature", PR: 1},
+		{Note: "Reverted the frobnicator.", Section: "feature", PR: 2, Reverts: 1},
+		{Note: "Added the foo field.", Section: "feature", PR: 3},
+		{PR: 4, Reverts: 3},
+		{PR: 5, Reverts: 4},
+		{Note: "Fixed a crash.", Section: "fix", PR: 6},
+		{Note: "Reverted a fix of the last release.", Section: "fix", PR: 7, Reverts: 100},
+	}
+	tests := []struct {
+		name string
+		mark bool
+		want string
+	}{
+		{
+			name: "drop",
+			want: "## New Features\n\n- Added the foo field. (#3)\n\n## Bug Fixes\n\n- Fixed a crash. (#6)\n- Reverted a fix of the last release. (#7)\n",
+		},
+		{
+			name: "mark",
+			mark: true,
+			want: "## New Features\n\n- Added the frobnicator. (#1, reverted in #2)\n- Added the foo field. (#3)\n\n## Bug Fixes\n\n- Fixed a crash. (#6)\n- Reverted a fix of the last release. (#7)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(Reconcile(entries, tt.mark)); got != tt.want {
+				t.Fatalf("Render(Reconcile()) =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		note string
+		want string
+	}{
+		{name: "plain", note: "Fixed a crash.", want: "Fixed a crash."},
+		{name: "image", note: "Added a dashboard ![screenshot](https://example.com/a.png).", want: "Added a dashboard screenshot."},
+		{name: "html image", note: `Added a logo <img src="logo.png" width="20">.`, want: "Added a logo ."},
+		{name: "html link", note: `See <a href="https://example.com/docs">the docs</a>.`, want: "See [the docs](https://example.com/docs)."},
+		{name: "link", note: `See [ the docs ]( <https://example.com/docs> "Docs").`, want: "See [the docs](https://example.com/docs)."},
+		{name: "link without text", note: "See [](https://example.com).", want: "See <https://example.com>."},
+		{name: "link without target", note: "See [the docs]().", want: "See the docs."},
+		{name: "pipes", note: `Accepts a|b and a\|b.`, want: `Accepts a\|b and a\|b.`},
+		{name: "heading", note: "Changed the foo.\n# Upgrading\nRun it.", want: "Changed the foo.\n\\# Upgrading\nRun it."},
+		{name: "encoding", note: "Fixed\x00 a \xffcrash.\r\nFor real.\uFEFF", want: "Fixed a crash.\nFor real."},
+		{name: "code is kept", note: "Run:\n```sh\nfoo | bar\n# comment\n```\nDone | ok.", want: "Run:\n```sh\nfoo | bar\n# comment\n```\nDone \\| ok."},
+		{name: "unterminated fence", note: "Run:\n```\nfoo | bar", want: "Run:\n```\nfoo | bar\n```"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.note); got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.note, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsolidateEpics(t *testing.T) {
+	entries := []Entry{
+		{Note: "Fixed a crash.", Section: "fix", PR: 1},
+		{Section: "feature", PR: 4, Epic: 100, Components: []string{"controlplane"}},
+		{Section: "feature", PR: 2, Epic: 100, Components: []string{"helm"}},
+		{Section: "feature", PR: 3, Epic: 200},
+		{Section: "fix", PR: 5, Epic: 300},
+	}
+	notes := map[int]string{
+		100: "Added the frobnicator.",
+		300: "[helm] Added the foo value.",
+	}
+	want := "## controlplane\n\n### New Features\n\n- Added the frobnicator. (#100: #2, #4)\n" +
+		"\n## helm\n\n### New Features\n\n- Added the frobnicator. (#100: #2, #4)\n" +
+		"\n## Other\n\n### Bug Fixes\n\n- Fixed a crash. (#1)\n\n### Helm\n\n- Added the foo value. (#300: #5)\n"
+	if got := Render(ConsolidateEpics(entries, notes)); got != want {
+		t.Fatalf("Render(ConsolidateEpics()) =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEpicNote(t *testing.T) {
+	tests := []struct {
+		body   string
+		want   string
+		wantOK bool
+	}{
+		{body: "Tracks the frobnicator.\r\n\r\n```release-note\r\nAdded the frobnicator.\r\n```\r\n", want: "Added the frobnicator.", wantOK: true},
+		{body: "Tracks the frobnicator."},
+		{body: "```release-note\nNONE\n```"},
+		{body: "```release-note\n\n```"},
+	}
+	for _, tt := range tests {
+		if got, ok := EpicNote(tt.body); got != tt.want || ok != tt.wantOK {
+			t.Errorf("EpicNote(%q) = %q, %v, want %q, %v", tt.body, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package changelog
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// epicNoteRE captures the release note block of a tracking issue.
+var epicNoteRE = regexp.MustCompile("(?s)```release-note[ \t]*\n(.*?)```")
+
+// EpicNote returns the consolidated release note in the release-note block
+// of the body of a tracking issue, and whether it has one. A NONE note is
+// reported as no note.
+func EpicNote(issueBody string) (string, bool) {
+	m := epicNoteRE.FindStringSubmatch(strings.ReplaceAll(issueBody, "\r\n", "\n"))
+	if m == nil {
+		return "", false
+	}
+	note := strings.TrimSpace(m[1])
+	if note == "" || strings.EqualFold(note, "NONE") {
+		return "", false
+	}
+	return note, true
+}
+
+// ConsolidateEpics replaces the entries of the PRs of each epic, those whose
+// Epic is set, with one entry for the epic: the note of its tracking issue in
+// notes, listed under the issue, with the PRs it covers and the components
+// they change. The section is that of the note's [category] prefix, or else
+// of the epic's first PR. Epics without a note in notes are left out, e.g.
+// while the tracking issue has none yet.
+func ConsolidateEpics(entries []Entry, notes map[int]string) []Entry {
+	// byEpic indexes the epic entries in consolidated
+	byEpic := map[int]int{}
+	var consolidated []Entry
+	for _, e := range entries {
+		if e.Epic == 0 {
+			consolidated = append(consolidated, e)
+			continue
+		}
+		note, ok := notes[e.Epic]
+		if !ok {
+			continue
+		}
+		if i, ok := byEpic[e.Epic]; ok {
+			epic := &consolidated[i]
+			epic.PRs = append(epic.PRs, e.PR)
+			for _, c := range e.Components {
+				if !slices.Contains(epic.Components, c) {
+					epic.Components = append(epic.Components, c)
+				}
+			}
+			continue
+		}
+		epic := Entry{Note: note, Section: e.Section, PR: e.Epic, Epic: e.Epic, PRs: []int{e.PR}, Components: slices.Clone(e.Components)}
+		if category, rest := ParseCategory(note); category != "" {
+			if _, ok := Lookup(category); ok {
+				epic.Note, epic.Section = rest, category
+			}
+		}
+		byEpic[e.Epic] = len(consolidated)
+		consolidated = append(consolidated, epic)
+	}
+	for i := range consolidated {
+		slices.Sort(consolidated[i].PRs)
+	}
+	return consolidated
+}
@@ -0,0 +1,105 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// imageRE matches markdown images, whose alt text is kept.
+	imageRE = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	// imgTagRE matches HTML images.
+	imgTagRE = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+	// anchorRE captures the target and text of HTML links.
+	anchorRE = regexp.MustCompile(`(?is)<a\b[^>]*\bhref\s*=\s*["']([^"']*)["'][^>]*>(.*?)</a>`)
+	// linkRE captures the text and target of markdown links.
+	linkRE = regexp.MustCompile(`\[([^\]]*)\]\(\s*<?([^()\s>]*)>?(?:\s+"[^"]*")?\s*\)`)
+	// headingRE matches the markers that would make a line a heading.
+	headingRE = regexp.MustCompile(`(?m)^(\s*)(#{1,6})(\s)`)
+)
+
+// Sanitize makes a release note safe to place into a markdown changelog,
+// including table cells, so one malformed note cannot break the document:
+// invalid UTF-8 and control characters are dropped, images are reduced to
+// their alt text, links are normalized to [text](url), pipes are escaped,
+// heading markers are escaped and an unterminated code fence is closed.
+// Fenced code is kept as is, apart from its encoding.
+func Sanitize(note string) string {
+	note = strings.ToValidUTF8(note, "")
+	note = strings.ReplaceAll(note, "\r\n", "\n")
+	note = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) || r == '\uFEFF' {
+			return -1
+		}
+		return r
+	}, note)
+
+	var sb strings.Builder
+	var prose []string
+	flush := func() {
+		if len(prose) > 0 {
+			sb.WriteString(sanitizeProse(strings.Join(prose, "\n")) + "\n")
+			prose = nil
+		}
+	}
+	fence := ""
+	for _, line := range strings.Split(note, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			flush()
+			fence = trimmed[:3]
+			sb.WriteString(line + "\n")
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			sb.WriteString(line + "\n")
+		default:
+			prose = append(prose, line)
+		}
+	}
+	flush()
+	if fence != "" {
+		sb.WriteString(fence + "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// sanitizeProse sanitizes markdown outside of code fences.
+func sanitizeProse(s string) string {
+	s = imageRE.ReplaceAllString(s, "$1")
+	s = imgTagRE.ReplaceAllString(s, "")
+	s = anchorRE.ReplaceAllString(s, "[$2]($1)")
+	s = linkRE.ReplaceAllStringFunc(s, func(link string) string {
+		m := linkRE.FindStringSubmatch(link)
+		text, url := strings.TrimSpace(m[1]), m[2]
+		switch {
+		case url == "":
+			return text
+		case text == "":
+			return "<" + url + ">"
+		}
+		return "[" + text + "](" + url + ")"
+	})
+	s = escapePipes(s)
+	return headingRE.ReplaceAllString(s, `$1\$2$3`)
+}
+
+// escapePipes escapes the pipes of s that are not escaped yet.
+func escapePipes(s string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '|' && !escaped {
+			sb.WriteRune('\\')
+		}
+		escaped = r == '\\' && !escaped
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
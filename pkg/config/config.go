@@ -0,0 +1,146 @@
+// Package config loads the kinds and labels a repository defines for itself
+// in .github/pr-kind-labeler.yaml, so maintainers can adapt the labeler
+// without forking it. Repositories without the file get the compiled-in
+// defaults of pkg/kinds and pkg/labels.
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+)
+
+// Path is where a repository keeps its config.
+const Path = ".github/pr-kind-labeler.yaml"
+
+// ErrInvalid is wrapped by the errors of config files that cannot be parsed
+// or are invalid, as opposed to failures to fetch them.
+var ErrInvalid = errors.New("invalid config")
+
+// kindRE matches the kinds a /kind command can name, lowercased.
+var kindRE = regexp.MustCompile(`^[a-z0-9_/-]+$`)
+
+// Config is the kinds and labels of a repository. The file may hold other
+// settings, e.g. for server mode, which are ignored here.
+type Config struct {
+	// Kinds are the supported kinds, replacing the compiled-in ones.
+	Kinds []string `json:"kinds,omitempty"`
+	// DeprecatedKinds map kinds still accepted to the supported kinds they
+	// are migrated to, replacing the compiled-in aliases; an empty map
+	// accepts none.
+	DeprecatedKinds map[string]string `json:"deprecatedKinds,omitempty"`
+	// Labels rename the labels the labeler manages, from their default
+	// names to the repository's, e.g. release-note-none to
+	// "changelog: none".
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Default returns the compiled-in config.
+func Default() *Config {
+	return &Config{}
+}
+
+// Parse parses a config file. Kinds and deprecated kinds that are not set
+// keep their defaults.
+func Parse(data []byte) (*Config, error) {
+	c := &Config{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrInvalid, Path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrInvalid, Path, err)
+	}
+	return c, nil
+}
+
+// Load returns the config of owner/repo from its default branch, or Default
+// if it has none. The default branch is read rather than the PR head, so a
+// PR cannot change the rules it is checked against.
+func Load(ctx context.Context, client *github.Client, owner, repo string) (*Config, error) {
+	file, _, resp, err := client.Repositories.GetContents(ctx, owner, repo, Path, nil)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", Path, err)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", Path, err)
+	}
+	return Parse([]byte(content))
+}
+
+// Validate reports kinds that /kind commands cannot name, aliases of
+// unsupported kinds, and labels renamed ambiguously.
+func (c *Config) Validate() error {
+	supported := c.SupportedKinds()
+	for _, k := range c.Kinds {
+		if !kindRE.MatchString(k) {
+			return fmt.Errorf("kind %q must be lowercase letters, digits, _, - or /", k)
+		}
+	}
+	for old, kind := range c.Aliases() {
+		if !kindRE.MatchString(old) {
+			return fmt.Errorf("deprecated kind %q must be lowercase letters, digits, _, - or /", old)
+		}
+		if supported[old] {
+			return fmt.Errorf("deprecated kind %q is also a supported kind", old)
+		}
+		if !supported[kind] {
+			return fmt.Errorf("deprecated kind %q maps to unsupported kind %q", old, kind)
+		}
+	}
+	renamed := map[string]string{}
+	for _, from := range slices.Sorted(maps.Keys(c.Labels)) {
+		to := c.Labels[from]
+		if strings.TrimSpace(to) == "" {
+			return fmt.Errorf("label %q is renamed to an empty name", from)
+		}
+		if other, ok := renamed[to]; ok {
+			return fmt.Errorf("labels %q and %q are both renamed to %q", other, from, to)
+		}
+		renamed[to] = from
+	}
+	return nil
+}
+
+// SupportedKinds returns the supported kinds as a set, the compiled-in ones
+// unless Kinds is set.
+func (c *Config) SupportedKinds() map[string]bool {
+	if len(c.Kinds) == 0 {
+		return maps.Clone(kinds.SupportedKinds)
+	}
+	supported := map[string]bool{}
+	for _, k := range c.Kinds {
+		supported[k] = true
+	}
+	return supported
+}
+
+// Aliases returns the deprecated kinds mapped to the kinds they are migrated
+// to, the compiled-in ones unless DeprecatedKinds is set.
+func (c *Config) Aliases() map[string]string {
+	if c.DeprecatedKinds == nil {
+		return maps.Clone(kinds.DeprecatedKindMap)
+	}
+	return maps.Clone(c.DeprecatedKinds)
+}
+
+// Label returns the repository's name for the label named name by default.
+func (c *Config) Label(name string) string {
+	if renamed, ok := c.Labels[name]; ok {
+		return renamed
+	}
+	return name
+}
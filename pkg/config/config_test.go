@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantKinds   []string
+		wantAliases map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "defaults",
+			data:        "",
+			wantKinds:   kinds.Supported(),
+			wantAliases: kinds.DeprecatedKindMap,
+		},
+		{
+			name:        "custom kinds and aliases",
+			data:        "kinds: [feature, fix, chore]\ndeprecatedKinds:\n  bug: fix\n",
+			wantKinds:   []string{"chore", "feature", "fix"},
+			wantAliases: map[string]string{"bug": "fix"},
+		},
+		{
+			name:        "no aliases",
+			data:        "deprecatedKinds: {}\n",
+			wantKinds:   kinds.Supported(),
+			wantAliases: map[string]string{},
+		},
+		{
+			name:        "other settings are ignored",
+			data:        "mode: report-only\nlabels:\n  release-note-none: \"changelog: none\"\n",
+			wantKinds:   kinds.Supported(),
+			wantAliases: kinds.DeprecatedKindMap,
+		},
+		{name: "invalid kind", data: "kinds: [Feature]\n", wantErr: true},
+		{name: "alias of unsupported kind", data: "kinds: [fix]\ndeprecatedKinds:\n  bug: bugfix\n", wantErr: true},
+		{name: "alias is a supported kind", data: "kinds: [fix, bug]\ndeprecatedKinds:\n  bug: fix\n", wantErr: true},
+		{name: "empty label", data: "labels:\n  kind/fix: \"\"\n", wantErr: true},
+		{name: "labels renamed alike", data: "labels:\n  kind/fix: fix\n  kind/bug: fix\n", wantErr: true},
+		{name: "malformed", data: "kinds: [\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse([]byte(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("expected ErrInvalid, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			var got []string
+			for k := range c.SupportedKinds() {
+				got = append(got, k)
+			}
+			if !reflect.DeepEqual(sorted(got), sorted(tt.wantKinds)) {
+				t.Errorf("supported kinds = %v, want %v", got, tt.wantKinds)
+			}
+			if !reflect.DeepEqual(c.Aliases(), tt.wantAliases) {
+				t.Errorf("aliases = %v, want %v", c.Aliases(), tt.wantAliases)
+			}
+		})
+	}
+}
+
+func TestLabel(t *testing.T) {
+	c := &Config{Labels: map[string]string{"release-note-none": "changelog: none"}}
+	if got := c.Label("release-note-none"); got != "changelog: none" {
+		t.Errorf("Label(release-note-none) = %q, want the renamed label", got)
+	}
+	if got := c.Label("kind/fix"); got != "kind/fix" {
+		t.Errorf("Label(kind/fix) = %q, want it unchanged", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var content string
+				switch r.URL.Path {
+				case "/repos/owner/custom/contents/.github/pr-kind-labeler.yaml":
+					content = "kinds: [fix]\ndeprecatedKinds: {}\n"
+				case "/repos/owner/broken/contents/.github/pr-kind-labeler.yaml":
+					content = "kinds: [Fix]\n"
+				case "/repos/owner/down/contents/.github/pr-kind-labeler.yaml":
+					mock.WriteError(w, http.StatusBadGateway, "Bad Gateway")
+					return
+				default:
+					mock.WriteError(w, http.StatusNotFound, "Not Found")
+					return
+				}
+				w.Write(mock.MustMarshal(github.RepositoryContent{
+					Encoding: github.Ptr("base64"),
+					Content:  github.Ptr(base64.StdEncoding.EncodeToString([]byte(content))),
+				}))
+			}),
+		),
+	)
+	client := github.NewClient(httpClient)
+	ctx := context.Background()
+
+	c, err := Load(ctx, client, "owner", "custom")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(c.SupportedKinds(), map[string]bool{"fix": true}) {
+		t.Errorf("supported kinds = %v, want only fix", c.SupportedKinds())
+	}
+
+	c, err = Load(ctx, client, "owner", "missing")
+	if err != nil {
+		t.Fatalf("expected the defaults without a config file, got %v", err)
+	}
+	if !reflect.DeepEqual(c, Default()) {
+		t.Errorf("config = %+v, want the defaults", c)
+	}
+
+	if _, err := Load(ctx, client, "owner", "broken"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for an invalid config, got %v", err)
+	}
+	if _, err := Load(ctx, client, "owner", "down"); err == nil || errors.Is(err, ErrInvalid) {
+		t.Errorf("expected a fetch error, got %v", err)
+	}
+}
+
+func sorted(s []string) []string {
+	s = append([]string{}, s...)
+	slices.Sort(s)
+	return s
+}
@@ -0,0 +1,186 @@
+// Package ghaction implements the parts of the GitHub Actions runner protocol
+// that automation binaries need: reading the triggering event, setting step
+// outputs, writing the job summary and annotating the log. Outside of a
+// workflow run, outputs and summaries are dropped.
+package ghaction
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Action talks to the runner a step runs on.
+type Action struct {
+	// Getenv looks up the environment the runner set for the step.
+	Getenv func(string) string
+	// Stdout receives workflow commands, such as annotations.
+	Stdout io.Writer
+	// Stdin supplies the event payload when its path is "-".
+	Stdin io.Reader
+}
+
+// New returns an Action for the current process.
+func New() *Action {
+	return &Action{Getenv: os.Getenv, Stdout: os.Stdout, Stdin: os.Stdin}
+}
+
+// Running reports whether the process runs as a GitHub Actions step.
+func (a *Action) Running() bool {
+	return a.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// Event is the webhook event that triggered the workflow run.
+type Event struct {
+	// Name is the event name, e.g. pull_request or issue_comment.
+	Name string
+	// Payload is the webhook payload.
+	Payload []byte
+}
+
+// Event reads the event that triggered the workflow run from
+// GITHUB_EVENT_NAME and GITHUB_EVENT_PATH.
+func (a *Action) Event() (*Event, error) {
+	path := a.Getenv("GITHUB_EVENT_PATH")
+	if path == "" {
+		return nil, fmt.Errorf("GITHUB_EVENT_PATH is not set")
+	}
+	return a.EventFrom(path)
+}
+
+// EventFrom reads the event payload from path instead of GITHUB_EVENT_PATH,
+// or from Stdin if path is "-", e.g. to pipe in a captured payload. The event
+// name is still read from GITHUB_EVENT_NAME.
+func (a *Action) EventFrom(path string) (*Event, error) {
+	var (
+		payload []byte
+		err     error
+	)
+	if path == "-" {
+		if a.Stdin == nil {
+			return nil, fmt.Errorf("failed to read event from stdin: no stdin")
+		}
+		payload, err = io.ReadAll(a.Stdin)
+	} else {
+		payload, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event path: %w", err)
+	}
+	return &Event{Name: a.Getenv("GITHUB_EVENT_NAME"), Payload: payload}, nil
+}
+
+// Decode decodes the payload into v, e.g. a *github.PullRequestEvent.
+func (e *Event) Decode(v any) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to parse event JSON: %w", err)
+	}
+	return nil
+}
+
+// SetOutput sets the step output name to value, which may span lines.
+func (a *Action) SetOutput(name, value string) error {
+	delimiter, err := newDelimiter(value)
+	if err != nil {
+		return err
+	}
+	return a.appendFile("GITHUB_OUTPUT", fmt.Sprintf("%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter))
+}
+
+// newDelimiter returns a random heredoc delimiter that value does not
+// contain, so a value can never end the heredoc early.
+func newDelimiter(value string) (string, error) {
+	for {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("failed to generate output delimiter: %w", err)
+		}
+		if d := "ghadelimiter_" + hex.EncodeToString(b); !strings.Contains(value, d) {
+			return d, nil
+		}
+	}
+}
+
+// AppendSummary adds markdown to the job summary shown on the workflow run
+// page.
+func (a *Action) AppendSummary(markdown string) error {
+	if !strings.HasSuffix(markdown, "\n") {
+		markdown += "\n"
+	}
+	return a.appendFile("GITHUB_STEP_SUMMARY", markdown)
+}
+
+// appendFile appends data to the runner file named by env, if it is set.
+func (a *Action) appendFile(env, data string) error {
+	path := a.Getenv(env)
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", env, err)
+	}
+	if _, err := f.WriteString(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", env, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", env, err)
+	}
+	return nil
+}
+
+// Level is the severity of an annotation.
+type Level string
+
+// Annotation levels.
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+	LevelNotice  Level = "notice"
+)
+
+// Annotation is a message shown on the workflow run and, if it has a file,
+// on the PR diff.
+type Annotation struct {
+	Level   Level
+	Message string
+	// Title, File and Line are optional.
+	Title string
+	File  string
+	Line  int
+}
+
+// Annotate writes a as a workflow command.
+func (a *Action) Annotate(an Annotation) {
+	var props []string
+	if an.Title != "" {
+		props = append(props, "title="+escapeProperty(an.Title))
+	}
+	if an.File != "" {
+		props = append(props, "file="+escapeProperty(an.File))
+	}
+	if an.Line > 0 {
+		props = append(props, "line="+strconv.Itoa(an.Line))
+	}
+	cmd := "::" + string(an.Level)
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	fmt.Fprintf(a.Stdout, "%s::%s\n", cmd, escapeData(an.Message))
+}
+
+// escapeData escapes a workflow command's message.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command's property value.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,116 @@
+package ghaction
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newTestAction(t *testing.T, env map[string]string) (*Action, *bytes.Buffer) {
+	t.Helper()
+	var stdout bytes.Buffer
+	return &Action{Getenv: func(key string) string { return env[key] }, Stdout: &stdout}, &stdout
+}
+
+func TestEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(path, []byte(`{"action": "opened", "number": 7}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := newTestAction(t, map[string]string{"GITHUB_EVENT_NAME": "pull_request", "GITHUB_EVENT_PATH": path})
+	event, err := a.Event()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var payload struct {
+		Action string `json:"action"`
+		Number int    `json:"number"`
+	}
+	if err := event.Decode(&payload); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Name != "pull_request" || payload.Action != "opened" || payload.Number != 7 {
+		t.Fatalf("unexpected event %q %+v", event.Name, payload)
+	}
+
+	if err := (&Event{Payload: []byte("{")}).Decode(&payload); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+	a, _ = newTestAction(t, nil)
+	if _, err := a.Event(); err == nil {
+		t.Fatal("expected an error without GITHUB_EVENT_PATH")
+	}
+}
+
+func TestEventFrom(t *testing.T) {
+	a, _ := newTestAction(t, map[string]string{"GITHUB_EVENT_NAME": "issue_comment"})
+	a.Stdin = strings.NewReader(`{"action": "created"}`)
+	event, err := a.EventFrom("-")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Name != "issue_comment" || string(event.Payload) != `{"action": "created"}` {
+		t.Fatalf("unexpected event %q %s", event.Name, event.Payload)
+	}
+
+	path := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(path, []byte(`{"action": "opened"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if event, err = a.EventFrom(path); err != nil || string(event.Payload) != `{"action": "opened"}` {
+		t.Fatalf("unexpected event %v, err %v", event, err)
+	}
+	if _, err := a.EventFrom(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	a, _ := newTestAction(t, map[string]string{"GITHUB_OUTPUT": path})
+	if err := a.SetOutput("valid", "true"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := a.SetOutput("note", "line one\nline two"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	re := regexp.MustCompile(`^valid<<(ghadelimiter_[0-9a-f]{16})\ntrue\n(ghadelimiter_[0-9a-f]{16})\nnote<<(ghadelimiter_[0-9a-f]{16})\nline one\nline two\n(ghadelimiter_[0-9a-f]{16})\n$`)
+	m := re.FindStringSubmatch(string(data))
+	if m == nil || m[1] != m[2] || m[3] != m[4] {
+		t.Fatalf("unexpected output file:\n%s", data)
+	}
+
+	// outside of a workflow run outputs are dropped
+	a, _ = newTestAction(t, nil)
+	if err := a.SetOutput("valid", "true"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAppendSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary")
+	a, _ := newTestAction(t, map[string]string{"GITHUB_STEP_SUMMARY": path})
+	a.AppendSummary("## One")
+	a.AppendSummary("Two\n")
+	if data, _ := os.ReadFile(path); string(data) != "## One\nTwo\n" {
+		t.Fatalf("unexpected summary %q", data)
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	a, stdout := newTestAction(t, map[string]string{"GITHUB_ACTIONS": "true"})
+	if !a.Running() {
+		t.Fatal("expected to run as an action")
+	}
+	a.Annotate(Annotation{Level: LevelError, Message: "missing release note\nadd one: 100%"})
+	a.Annotate(Annotation{Level: LevelWarning, Title: "a: b, c", File: "main.go", Line: 3, Message: "careful"})
+	want := "::error::missing release note%0Aadd one: 100%25\n" +
+		"::warning title=a%3A b%2C c,file=main.go,line=3::careful\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected annotations:\n%s\nwant:\n%s", stdout, want)
+	}
+}
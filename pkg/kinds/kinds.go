@@ -1,5 +1,10 @@
 package kinds
 
+import (
+	"sort"
+	"strings"
+)
+
 const (
 	// Design is a kind label that indicates the PR is a design.
 	Design = "design"
@@ -50,3 +55,20 @@ var DeprecatedKindMap = map[string]string{
 	DeprecatedNewFeature: Feature,
 	DeprecatedBugFix:     Fix,
 }
+
+// Supported returns the supported kinds in canonical (alphabetical) order.
+// Use it, rather than ranging over SupportedKinds, anywhere the kinds are
+// shown to users so errors, comments, and templates render identically.
+func Supported() []string {
+	supported := make([]string, 0, len(SupportedKinds))
+	for k := range SupportedKinds {
+		supported = append(supported, k)
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// Render returns the supported kinds as a comma-separated list in canonical order.
+func Render() string {
+	return strings.Join(Supported(), ", ")
+}
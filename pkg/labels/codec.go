@@ -0,0 +1,16 @@
+package labels
+
+import "net/url"
+
+// EncodeName escapes the label name for use as a path segment of a GitHub
+// API URL, e.g. to remove or edit the label. go-github does not escape the
+// names it is given, so names with slashes, spaces, question marks, percent
+// signs or non-ASCII characters would otherwise address the wrong resource.
+func EncodeName(name string) string {
+	return url.PathEscape(name)
+}
+
+// DecodeName returns the label name of a path segment encoded by EncodeName.
+func DecodeName(segment string) (string, error) {
+	return url.PathUnescape(segment)
+}
@@ -0,0 +1,27 @@
+package labels
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	for _, name := range []string{
+		InvalidKindLabel,
+		SuspectedSpamLabel,
+		"good first issue",
+		"100% done",
+		"área/négociation",
+		"🚀 release",
+		"a#b",
+	} {
+		encoded := EncodeName(name)
+		if strings.ContainsAny(encoded, "/?# ") {
+			t.Errorf("EncodeName(%q) = %q, want no unescaped path or query delimiters", name, encoded)
+		}
+		decoded, err := DecodeName(encoded)
+		if err != nil || decoded != name {
+			t.Errorf("DecodeName(%q) = %q, %v, want %q", encoded, decoded, err, name)
+		}
+	}
+}
@@ -1,5 +1,12 @@
 package labels
 
+import (
+	"sort"
+	"strings"
+
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/kinds"
+)
+
 const (
 	// InvalidKindLabel is a label that indicates the kind is invalid.
 	InvalidKindLabel = "do-not-merge/kind-invalid"
@@ -7,6 +14,32 @@ const (
 	InvalidReleaseNoteLabel = "do-not-merge/release-note-invalid"
 	// InvalidDescriptionLabel is a label that indicates the description is invalid or missing.
 	InvalidDescriptionLabel = "do-not-merge/description-invalid"
+	// PossibleSecretLabel is a label that indicates the PR body appears to contain a credential.
+	PossibleSecretLabel = "do-not-merge/possible-secret"
+	// SuspectedSpamLabel is a label that indicates the PR looks like spam and needs a maintainer to triage it.
+	SuspectedSpamLabel = "needs-triage/spam?"
+	// NeedsHumanReviewLabel is a label that indicates the PR body was too large or pathological to validate in full.
+	NeedsHumanReviewLabel = "needs-human-review"
+	// NeedsUpgradeDocsLabel is a label that indicates an ACTION REQUIRED release note lacks upgrade docs.
+	NeedsUpgradeDocsLabel = "do-not-merge/needs-upgrade-docs"
+	// KindMismatchLabel is a label that indicates the PR's kind does not match the files it changes.
+	KindMismatchLabel = "do-not-merge/kind-mismatch"
+	// NeedsKindLabel is a label that indicates the PR needs a valid /kind command.
+	NeedsKindLabel = "needs-kind"
+	// NeedsReleaseNoteLabel is a label that indicates the PR needs a valid release note.
+	NeedsReleaseNoteLabel = "needs-release-note"
+	// NeedsRebaseLabel is a label that indicates the PR conflicts with its base branch.
+	NeedsRebaseLabel = "needs-rebase"
+	// CIFailingLabel is a label that indicates a check of the PR head commit fails.
+	CIFailingLabel = "ci/failing"
+	// ModuleLabelPrefix prefixes the labels of the Go modules a PR changes in
+	// a multi-module repository, e.g. module/api.
+	ModuleLabelPrefix = "module/"
+	// RiskLabelPrefix prefixes the label of a PR's release risk, e.g.
+	// risk/high.
+	RiskLabelPrefix = "risk/"
+	// RevertLabel is a label that indicates the PR reverts another one.
+	RevertLabel = "revert"
 	// ReleaseNoteLabel is a label that indicates the release note is needed.
 	ReleaseNoteLabel = "release-note"
 	// DeprecatedReleaseNoteLabel is a deprecated label that indicates the release note is needed.
@@ -14,3 +47,110 @@ const (
 	// ReleaseNoteNoneLabel is a label that indicates the release note is not needed.
 	ReleaseNoteNoneLabel = "release-note-none"
 )
+
+// Definition is a label in the registry.
+type Definition struct {
+	Name string `json:"name"`
+	// Color is a hex color without the leading #, as GitHub stores it.
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	// Managed is set for the labels the labeler applies and removes. The
+	// others are retired labels it only migrates to ReplacedBy.
+	Managed    bool   `json:"managed"`
+	ReplacedBy string `json:"replacedBy,omitempty"`
+}
+
+// retiredColor is the color of retired labels.
+const retiredColor = "ededed"
+
+// registry holds every label the labeler knows, by name. Repository label
+// settings are derived from it rather than maintained by hand.
+var registry = func() map[string]Definition {
+	defs := []Definition{
+		{Name: InvalidKindLabel, Color: "e11d21", Description: "The PR body has no valid /kind command."},
+		{Name: InvalidReleaseNoteLabel, Color: "e11d21", Description: "The PR body has no valid release-note block."},
+		{Name: InvalidDescriptionLabel, Color: "e11d21", Description: "The PR body has no filled out Description section."},
+		{Name: PossibleSecretLabel, Color: "b60205", Description: "The PR body appears to contain a credential."},
+		{Name: NeedsUpgradeDocsLabel, Color: "e11d21", Description: "The release note requires action but the PR adds no upgrade docs."},
+		{Name: KindMismatchLabel, Color: "e11d21", Description: "The PR's /kind documentation does not match the files it changes."},
+		{Name: SuspectedSpamLabel, Color: "fbca04", Description: "The PR looks like spam and needs a maintainer to triage it."},
+		{Name: NeedsHumanReviewLabel, Color: "fbca04", Description: "The PR body is too large to validate in full and needs a maintainer to review it."},
+		{Name: NeedsKindLabel, Color: "fbca04", Description: "The PR needs a valid /kind command."},
+		{Name: NeedsReleaseNoteLabel, Color: "fbca04", Description: "The PR needs a valid release-note block."},
+		{Name: NeedsRebaseLabel, Color: "fbca04", Description: "The PR conflicts with its base branch and needs a rebase."},
+		{Name: CIFailingLabel, Color: "d93f0b", Description: "A check of the PR head commit fails."},
+		{Name: RiskLabelPrefix + "low", Color: "c2e0c6", Description: "The PR has a low release risk."},
+		{Name: RiskLabelPrefix + "medium", Color: "fbca04", Description: "The PR has a medium release risk."},
+		{Name: RiskLabelPrefix + "high", Color: "d93f0b", Description: "The PR has a high release risk."},
+		{Name: RevertLabel, Color: "d4c5f9", Description: "The PR reverts another PR."},
+		{Name: ReleaseNoteLabel, Color: "0e8a16", Description: "The PR has a release note."},
+		{Name: ReleaseNoteNoneLabel, Color: "c2e0c6", Description: "The PR does not need a release note."},
+	}
+	for i := range defs {
+		defs[i].Managed = true
+	}
+	for _, k := range kinds.Supported() {
+		defs = append(defs, Definition{Name: "kind/" + k, Color: "1d76db", Description: "Categorizes the PR as " + k + ".", Managed: true})
+	}
+	defs = append(defs, Definition{Name: DeprecatedReleaseNoteLabel, Color: retiredColor, Description: "Retired, replaced by " + ReleaseNoteLabel + ".", ReplacedBy: ReleaseNoteLabel})
+	for old, kind := range kinds.DeprecatedKindMap {
+		defs = append(defs, Definition{Name: "kind/" + old, Color: retiredColor, Description: "Retired, replaced by kind/" + kind + ".", ReplacedBy: "kind/" + kind})
+	}
+	m := make(map[string]Definition, len(defs))
+	for _, def := range defs {
+		m[def.Name] = def
+	}
+	return m
+}()
+
+// Registry returns every label the labeler knows, managed or retired,
+// sorted by name.
+func Registry() []Definition {
+	defs := make([]Definition, 0, len(registry))
+	for _, def := range registry {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// Lookup returns the registry definition of label.
+func Lookup(label string) (Definition, bool) {
+	def, ok := registry[label]
+	return def, ok
+}
+
+// Catalog returns the canonical definitions of every label the labeler
+// applies, sorted by name, so repositories can be checked for drift.
+func Catalog() []Definition {
+	var catalog []Definition
+	for _, def := range Registry() {
+		if def.Managed {
+			catalog = append(catalog, def)
+		}
+	}
+	return catalog
+}
+
+// Renames returns the retired labels the labeler still migrates, mapped to
+// their replacements: the release-note-needed label and the kind/ labels of
+// deprecated kinds. It is the default mapping of the migrate-labels
+// subcommand.
+func Renames() map[string]string {
+	renames := map[string]string{}
+	for name, def := range registry {
+		if def.ReplacedBy != "" {
+			renames[name] = def.ReplacedBy
+		}
+	}
+	return renames
+}
+
+// Managed reports whether the labeler manages label: it is a managed label
+// of the registry or a module/ or risk/ label.
+func Managed(label string) bool {
+	if strings.HasPrefix(label, ModuleLabelPrefix) || strings.HasPrefix(label, RiskLabelPrefix) {
+		return true
+	}
+	return registry[label].Managed
+}
@@ -0,0 +1,34 @@
+package labels
+
+import "testing"
+
+func TestRegistry(t *testing.T) {
+	for _, def := range Catalog() {
+		if !def.Managed || def.ReplacedBy != "" {
+			t.Errorf("catalog has %+v, want only managed labels", def)
+		}
+	}
+	for old, replacement := range Renames() {
+		def, ok := Lookup(old)
+		if !ok || def.Managed {
+			t.Errorf("renamed label %q is %+v, want a retired label", old, def)
+		}
+		if !Managed(replacement) {
+			t.Errorf("%q is replaced by unmanaged label %q", old, replacement)
+		}
+	}
+	if got := Renames()["kind/bug_fix"]; got != "kind/fix" {
+		t.Errorf("kind/bug_fix is replaced by %q, want kind/fix", got)
+	}
+	for label, want := range map[string]bool{
+		InvalidKindLabel:           true,
+		"kind/feature":             true,
+		ModuleLabelPrefix + "api":  true,
+		DeprecatedReleaseNoteLabel: false,
+		"lgtm":                     false,
+	} {
+		if got := Managed(label); got != want {
+			t.Errorf("Managed(%q) = %v, want %v", label, got, want)
+		}
+	}
+}
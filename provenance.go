@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/provenance"
+)
+
+// signingKeyEnv holds a PEM private key when --provenance-key is not set, so
+// the Action can pass the key from a secret without writing it to disk.
+const signingKeyEnv = "PR_KIND_LABELER_SIGNING_KEY"
+
+// loadSigningKey returns the provenance signing key from path or
+// signingKeyEnv, or nil when neither is set.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	var data []byte
+	switch {
+	case path != "":
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read provenance key: %w", err)
+		}
+	case os.Getenv(signingKeyEnv) != "":
+		data = []byte(os.Getenv(signingKeyEnv))
+	default:
+		return nil, nil
+	}
+	return provenance.ParsePrivateKey(data)
+}
+
+// writeProvenance signs rec with key and writes the envelope to path.
+func writeProvenance(rec provenance.Record, key ed25519.PrivateKey, path string) error {
+	env, err := provenance.Sign(rec, key)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode provenance: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write provenance: %w", err)
+	}
+	return nil
+}
+
+func newProvenanceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "provenance",
+		Short: "Create keys for and verify signed label decision records",
+		Long: `The labeler signs a record of each decision (PR body hash, resulting labels)
+when run with --provenance-key or ` + signingKeyEnv + `. Records are DSSE
+envelopes signed with ed25519.`,
+	}
+	cmd.AddCommand(newProvenanceKeygenCmd(), newProvenanceVerifyCmd())
+	return cmd
+}
+
+func newProvenanceKeygenCmd() *cobra.Command {
+	var prefix string
+	cmd := &cobra.Command{
+		Use:          "keygen",
+		Short:        "Generate an ed25519 signing key pair",
+		Example:      `  pr-kind-labeler provenance keygen --out labeler`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pub, priv, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				return fmt.Errorf("failed to generate key: %w", err)
+			}
+			pubPEM, privPEM, err := provenance.MarshalKeyPair(pub, priv)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(prefix+".key", privPEM, 0o600); err != nil {
+				return fmt.Errorf("failed to write private key: %w", err)
+			}
+			if err := os.WriteFile(prefix+".pub", pubPEM, 0o644); err != nil {
+				return fmt.Errorf("failed to write public key: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "wrote %s.key and %s.pub\n", prefix, prefix)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "out", "pr-kind-labeler", "path prefix for the .key and .pub files")
+	return cmd
+}
+
+func newProvenanceVerifyCmd() *cobra.Command {
+	var (
+		keyPath string
+		body    string
+	)
+	cmd := &cobra.Command{
+		Use:   "verify FILE",
+		Short: "Verify a signed decision record and print it",
+		Example: `  # Verify a record and check it matches the PR body it claims
+  gh pr view 123 --json body -q .body > body.md
+  pr-kind-labeler provenance verify --key labeler.pub --body-file body.md provenance.json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyData, err := os.ReadFile(keyPath)
+			if err != nil {
+				return fmt.Errorf("failed to read public key: %w", err)
+			}
+			pub, err := provenance.ParsePublicKey(keyData)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read provenance: %w", err)
+			}
+			var env provenance.Envelope
+			if err := json.Unmarshal(data, &env); err != nil {
+				return fmt.Errorf("failed to parse provenance: %w", err)
+			}
+			rec, err := provenance.Verify(&env, pub)
+			if err != nil {
+				return err
+			}
+			if body != "" {
+				prBody, err := readBody(body, cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				if got := provenance.BodySHA256(prBody); got != rec.BodySHA256 {
+					return fmt.Errorf("PR body does not match the record: sha256 %s, recorded %s", got, rec.BodySHA256)
+				}
+			}
+			out, err := json.MarshalIndent(rec, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "verified\n%s\n", out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&keyPath, "key", "", "PEM public key the record must be signed with")
+	cmd.Flags().StringVar(&body, "body-file", "", "PR body to check against the recorded hash, or - for stdin")
+	cmd.MarkFlagRequired("key")
+	return cmd
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/release"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/ghaction"
+)
+
+// releaseFlags are the flags of the commands that can publish their output
+// on the GitHub release of a tag.
+type releaseFlags struct {
+	tag       string
+	fromEvent bool
+	since     string
+	repo      string
+
+	client *github.Client
+}
+
+// register adds the flags to cmd.
+func (f *releaseFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.tag, "release", "", "also publish the output on the GitHub release of this tag, limited to the PRs merged since the previous release; needs a GITHUB_TOKEN with contents: write")
+	cmd.Flags().BoolVar(&f.fromEvent, "release-from-event", false, "take the --release tag from the push of a tag or the release event that triggered the workflow run")
+	cmd.Flags().StringVar(&f.since, "since", "", "tag the release's PRs are merged after (default the previous published release)")
+	cmd.Flags().StringVar(&f.repo, "release-repo", "", "owner/repo of the release (default the repository of the event, or GITHUB_REPOSITORY)")
+	cmd.MarkFlagsMutuallyExclusive("release", "release-from-event")
+}
+
+// enabled reports whether the output is published on a release.
+func (f *releaseFlags) enabled() bool {
+	return f.tag != "" || f.fromEvent
+}
+
+// resolve reads the tag from the event with --release-from-event, checks the
+// flags and sets up the client, if enabled.
+func (f *releaseFlags) resolve() error {
+	if !f.enabled() {
+		return nil
+	}
+	if f.fromEvent {
+		tag, repo, err := eventTag(ghaction.New())
+		if err != nil {
+			return err
+		}
+		f.tag = tag
+		if f.repo == "" {
+			f.repo = repo
+		}
+	}
+	if f.repo == "" {
+		f.repo = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if owner, repo, ok := strings.Cut(f.repo, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return &labeler.ConfigError{Err: fmt.Errorf("invalid --release-repo %q, expected owner/repo", f.repo)}
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+	}
+	f.client = newGitHubClient(token, nil)
+	return nil
+}
+
+// eventTag returns the tag of the workflow run's event, a push of a tag or a
+// release event, and the owner/repo it happened in.
+func eventTag(action *ghaction.Action) (tag, repo string, err error) {
+	e, err := action.Event()
+	if err != nil {
+		return "", "", &labeler.ConfigError{Err: err}
+	}
+	var payload struct {
+		Ref     string `json:"ref"`
+		Release struct {
+			TagName string `json:"tag_name"`
+		} `json:"release"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	if err := e.Decode(&payload); err != nil {
+		return "", "", &labeler.ConfigError{Err: err}
+	}
+	switch {
+	case e.Name == "release" && payload.Release.TagName != "":
+		tag = payload.Release.TagName
+	case e.Name == "push" && strings.HasPrefix(payload.Ref, "refs/tags/"):
+		tag = strings.TrimPrefix(payload.Ref, "refs/tags/")
+	default:
+		return "", "", &labeler.ConfigError{Err: fmt.Errorf("--release-from-event needs a push of a tag or a release event, got %s", e.Name)}
+	}
+	return tag, payload.Repository.FullName, nil
+}
+
+// prs returns the numbers of the PRs the release ships: those merged since
+// --since, or since the previous release, up to its tag.
+func (f *releaseFlags) prs(cmd *cobra.Command) (map[int]bool, error) {
+	owner, repo, _ := strings.Cut(f.repo, "/")
+	since := f.since
+	if since == "" {
+		var err error
+		if since, err = release.PreviousTag(cmd.Context(), f.client, owner, repo, f.tag); err != nil {
+			return nil, &labeler.OperationalError{Err: err}
+		}
+		if since == "" {
+			return nil, &labeler.ConfigError{Err: fmt.Errorf("%s has no release before %s to list the PRs since; set --since", f.repo, f.tag)}
+		}
+	}
+	numbers, err := release.PRs(cmd.Context(), f.client, owner, repo, since, f.tag)
+	if err != nil {
+		return nil, &labeler.OperationalError{Err: err}
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s ships %d PRs merged since %s\n", f.tag, len(numbers), since)
+	prs := map[int]bool{}
+	for _, n := range numbers {
+		prs[n] = true
+	}
+	return prs, nil
+}
+
+// publish publishes body on the release, if enabled.
+func (f *releaseFlags) publish(cmd *cobra.Command, body string) error {
+	if !f.enabled() {
+		return nil
+	}
+	owner, repo, _ := strings.Cut(f.repo, "/")
+	post, err := release.Publish(cmd.Context(), f.client, owner, repo, f.tag, body)
+	if err != nil {
+		return &labeler.OperationalError{Err: err}
+	}
+	verb := "Updated release"
+	if post.Created {
+		verb = "Created draft release"
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s %s\n", verb, post.URL)
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/server"
+)
+
+func newReplayCmd() *cobra.Command {
+	var (
+		eventsDir  string
+		configPath string
+		apply      bool
+		output     string
+	)
+	cmd := &cobra.Command{
+		Use:   "replay --events-dir DIR",
+		Short: "Replay saved webhook events through the server's event handling",
+		Long: `Feed every saved webhook payload (*.json) under --events-dir, in file name
+order, through the same dispatch as the webhook server and report what the
+labeler decides for each. Nothing is changed on GitHub unless --apply is set,
+so parser and policy changes can be regression tested against captured
+traffic by diffing the --output json of two builds.
+
+A file holds either a delivery as returned by GitHub's webhook deliveries API
+(GET /repos/OWNER/REPO/hooks/ID/deliveries/DELIVERY_ID), or a bare payload,
+whose event type is told from its shape. Labels and comments are read from
+GitHub as they are now, not as they were when the event was delivered. Reads
+the API token from GITHUB_TOKEN.`,
+		Example: `  # Replay captured deliveries with the current build and keep the decisions
+  pr-kind-labeler replay --events-dir events/ --config config.yaml --output json > before.jsonl
+
+  # Replay and apply the decisions, e.g. after an outage
+  pr-kind-labeler replay --events-dir events/ --apply`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --output %q, expected text or json", output)}
+			}
+			cfg, err := server.LoadConfig(configPath)
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			var paths []string
+			err = filepath.WalkDir(eventsDir, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() && strings.HasSuffix(path, ".json") {
+					paths = append(paths, path)
+				}
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list events: %w", err)
+			}
+
+			srv := server.New(cfg, newGitHubClient(token, nil), nil)
+			out := cmd.OutOrStdout()
+			enc := json.NewEncoder(out)
+			var processed, ignored, failed int
+			for _, path := range paths {
+				r, err := replayFile(cmd, srv, path, apply)
+				switch {
+				case err != nil:
+					r = &server.Replayed{Error: err.Error()}
+					failed++
+				case r.Error != "":
+					failed++
+				case r.PR == "":
+					ignored++
+				default:
+					processed++
+				}
+				if output == "json" {
+					if err := enc.Encode(struct {
+						File string `json:"file"`
+						*server.Replayed
+					}{path, r}); err != nil {
+						return fmt.Errorf("failed to encode result: %w", err)
+					}
+					continue
+				}
+				fmt.Fprintf(out, "%s: %s\n", path, replaySummary(r))
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "replayed %d events: %d processed, %d ignored, %d failed\n", len(paths), processed, ignored, failed)
+			if failed > 0 {
+				return fmt.Errorf("%d of %d events failed to replay", failed, len(paths))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&eventsDir, "events-dir", "", "directory of saved webhook payloads, searched recursively")
+	cmd.Flags().StringVar(&configPath, "config", "", "path to the server config file")
+	cmd.Flags().BoolVar(&apply, "apply", false, "apply the decisions to GitHub instead of only reporting them")
+	cmd.Flags().StringVar(&output, "output", "text", "output format: text or json (one object per event)")
+	cmd.MarkFlagRequired("events-dir")
+	cmd.MarkFlagDirname("events-dir")
+	cmd.MarkFlagFilename("config", "yaml", "yml")
+	return cmd
+}
+
+// replayFile replays the saved event at path.
+func replayFile(cmd *cobra.Command, srv *server.Server, path string, apply bool) (*server.Replayed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event: %w", err)
+	}
+	eventType, payload, err := server.EventType(data)
+	if err != nil {
+		return nil, err
+	}
+	if eventType == "" {
+		return &server.Replayed{}, nil
+	}
+	return srv.Replay(cmd.Context(), eventType, payload, apply)
+}
+
+// replaySummary describes a replayed event on one line.
+func replaySummary(r *server.Replayed) string {
+	switch {
+	case r.Error != "":
+		return "error: " + r.Error
+	case r.PR == "":
+		return fmt.Sprintf("%s ignored", cmp.Or(r.Event, "unknown event"))
+	}
+	summary := fmt.Sprintf("%s %s:", r.Event, r.PR)
+	if r.Decision == nil {
+		return summary + " nothing to do"
+	}
+	for _, label := range r.Decision.LabelsToAdd {
+		summary += " +" + label
+	}
+	for _, label := range r.Decision.LabelsToRemove {
+		summary += " -" + label
+	}
+	if len(r.Decision.LabelsToAdd)+len(r.Decision.LabelsToRemove) == 0 {
+		summary += " no label changes"
+	}
+	if r.Decision.Error != "" {
+		summary += " (invalid)"
+	}
+	return summary
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+)
+
+// version is the released version of the binary, set at build time with
+// -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+const (
+	releaseOwner = "kgateway-dev"
+	releaseRepo  = "pr-kind-labeler"
+	// checksumsAsset is the release asset listing the sha256 of every binary,
+	// in sha256sum format.
+	checksumsAsset = "checksums.txt"
+)
+
+// releaseAssetName returns the name of the release asset holding the binary
+// for the given platform, e.g. pr-kind-labeler_linux_amd64.
+func releaseAssetName(goos, goarch string) string {
+	name := fmt.Sprintf("%s_%s_%s", releaseRepo, goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+func newSelfUpdateCmd() *cobra.Command {
+	var (
+		check bool
+		force bool
+	)
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Replace this binary with the latest release",
+		Long: fmt.Sprintf(`Check the latest %s/%s release and, if its semantic version is newer than
+this binary's, download the %s asset, verify it against
+%s, and replace the running executable. Builds that are not
+releases, such as dev builds, are only replaced with --force. Reads an
+optional API token from GITHUB_TOKEN.`, releaseOwner, releaseRepo, releaseAssetName("<os>", "<arch>"), checksumsAsset),
+		Example: `  # Show whether a newer release is available
+  pr-kind-labeler self-update --check
+
+  # Update in place
+  pr-kind-labeler self-update`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+			client := newGitHubClient(os.Getenv("GITHUB_TOKEN"), nil)
+
+			release, _, err := client.Repositories.GetLatestRelease(ctx, releaseOwner, releaseRepo)
+			if err != nil {
+				return fmt.Errorf("failed to get latest release: %w", err)
+			}
+			latest := release.GetTagName()
+			update, err := shouldUpdate(version, latest, force)
+			switch {
+			case errors.Is(err, errNotRelease):
+				if check {
+					fmt.Fprintf(out, "%s is not a release build, the latest release is %s\n", version, latest)
+					return nil
+				}
+				return &labeler.ConfigError{Err: fmt.Errorf("%s is not a release build; set --force to replace it with %s", version, latest)}
+			case err != nil:
+				return err
+			case !update:
+				fmt.Fprintf(out, "already up to date (%s)\n", version)
+				return nil
+			}
+			if check {
+				fmt.Fprintf(out, "update available: %s -> %s\n", version, latest)
+				return nil
+			}
+
+			assets := map[string]*github.ReleaseAsset{}
+			for _, a := range release.Assets {
+				assets[a.GetName()] = a
+			}
+			name := releaseAssetName(runtime.GOOS, runtime.GOARCH)
+			asset, ok := assets[name]
+			if !ok {
+				return fmt.Errorf("release %s has no %s asset", latest, name)
+			}
+			sums, ok := assets[checksumsAsset]
+			if !ok {
+				return fmt.Errorf("release %s has no %s asset", latest, checksumsAsset)
+			}
+			want, err := readChecksum(ctx, client, sums.GetID(), name)
+			if err != nil {
+				return err
+			}
+
+			exe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to locate executable: %w", err)
+			}
+			exe, err = filepath.EvalSymlinks(exe)
+			if err != nil {
+				return fmt.Errorf("failed to locate executable: %w", err)
+			}
+			if err := downloadAsset(ctx, client, asset.GetID(), exe, want); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "updated %s -> %s\n", version, latest)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&check, "check", false, "only report whether an update is available")
+	cmd.Flags().BoolVar(&force, "force", false, "replace the binary with the latest release even if it is not newer, or the binary is not a release build")
+	return cmd
+}
+
+// errNotRelease is returned by shouldUpdate for binaries that are not
+// release builds.
+var errNotRelease = errors.New("not a release build")
+
+// shouldUpdate reports whether a binary of version current is replaced with
+// the latest release: only by a newer release, unless force is set. A binary
+// that is not a release build, such as a dev build, has no version to compare
+// and is only replaced with force; errNotRelease is returned otherwise.
+func shouldUpdate(current, latest string, force bool) (bool, error) {
+	if !semver.IsValid(latest) {
+		return false, fmt.Errorf("latest release %q is not a semantic version", latest)
+	}
+	if force {
+		return true, nil
+	}
+	if !semver.IsValid(current) {
+		return false, errNotRelease
+	}
+	return semver.Compare(latest, current) > 0, nil
+}
+
+// readChecksum downloads the checksums asset and returns the sha256 listed for name.
+func readChecksum(ctx context.Context, client *github.Client, id int64, name string) (string, error) {
+	rc, _, err := client.Repositories.DownloadReleaseAsset(ctx, releaseOwner, releaseRepo, id, http.DefaultClient)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", checksumsAsset, err)
+	}
+	defer rc.Close()
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", checksumsAsset, err)
+	}
+	return "", fmt.Errorf("%s has no entry for %s", checksumsAsset, name)
+}
+
+// downloadAsset writes the release asset next to exe, verifies its sha256,
+// and renames it over exe so a failed download never leaves a partial binary.
+func downloadAsset(ctx context.Context, client *github.Client, id int64, exe, wantSum string) error {
+	rc, _, err := client.Repositories.DownloadReleaseAsset(ctx, releaseOwner, releaseRepo, id, http.DefaultClient)
+	if err != nil {
+		return fmt.Errorf("failed to download release asset: %w", err)
+	}
+	defer rc.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".pr-kind-labeler-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), rc); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download release asset: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write release asset: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != wantSum {
+		return fmt.Errorf("checksum mismatch for downloaded binary: got %s, want %s", got, wantSum)
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return fmt.Errorf("failed to make binary executable: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
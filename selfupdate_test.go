@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestShouldUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		latest  string
+		force   bool
+		want    bool
+		wantErr error
+	}{
+		{name: "newer release", current: "v1.2.3", latest: "v1.3.0", want: true},
+		{name: "same release", current: "v1.3.0", latest: "v1.3.0"},
+		{name: "older release", current: "v1.3.0", latest: "v1.2.9"},
+		{name: "semver ordering, not string ordering", current: "v1.9.0", latest: "v1.10.0", want: true},
+		{name: "prerelease is older than its release", current: "v1.3.0", latest: "v1.3.0-rc.1"},
+		{name: "forced to the same release", current: "v1.3.0", latest: "v1.3.0", force: true, want: true},
+		{name: "dev build", current: "dev", latest: "v1.3.0", wantErr: errNotRelease},
+		{name: "dev build with force", current: "dev", latest: "v1.3.0", force: true, want: true},
+		{name: "latest is not a semantic version", current: "v1.2.3", latest: "nightly", wantErr: errors.New("latest release \"nightly\" is not a semantic version")},
+		{name: "latest is not a semantic version with force", current: "dev", latest: "nightly", force: true, wantErr: errors.New("latest release \"nightly\" is not a semantic version")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shouldUpdate(tt.current, tt.latest, tt.force)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()):
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("shouldUpdate(%q, %q, %v) = %v, want %v", tt.current, tt.latest, tt.force, got, tt.want)
+			}
+		})
+	}
+}
+
+// newAssetClient returns a client serving content as every release asset.
+func newAssetClient(content string) *github.Client {
+	return github.NewClient(mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposReleasesAssetsByOwnerByRepoByAssetId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Write([]byte(content))
+			}),
+		),
+	))
+}
+
+func TestReadChecksum(t *testing.T) {
+	tests := []struct {
+		name    string
+		sums    string
+		asset   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "text mode entry",
+			sums:  "aaa  pr-kind-labeler_darwin_arm64\nbbb  pr-kind-labeler_linux_amd64\n",
+			asset: "pr-kind-labeler_linux_amd64",
+			want:  "bbb",
+		},
+		{
+			name:  "binary mode entry",
+			sums:  "ccc *pr-kind-labeler_windows_amd64.exe\n",
+			asset: "pr-kind-labeler_windows_amd64.exe",
+			want:  "ccc",
+		},
+		{
+			name:    "name is only a prefix of an entry",
+			sums:    "ddd  pr-kind-labeler_linux_amd64.exe\n",
+			asset:   "pr-kind-labeler_linux_amd64",
+			wantErr: "checksums.txt has no entry for pr-kind-labeler_linux_amd64",
+		},
+		{
+			name:    "malformed lines are skipped",
+			sums:    "pr-kind-labeler_linux_amd64\neee pr-kind-labeler_linux_amd64 extra\n",
+			asset:   "pr-kind-labeler_linux_amd64",
+			wantErr: "checksums.txt has no entry for pr-kind-labeler_linux_amd64",
+		},
+		{
+			name:    "empty file",
+			asset:   "pr-kind-labeler_linux_amd64",
+			wantErr: "checksums.txt has no entry for pr-kind-labeler_linux_amd64",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readChecksum(context.Background(), newAssetClient(tt.sums), 1, tt.asset)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("readChecksum() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadAsset(t *testing.T) {
+	const binary = "new binary"
+	sum := sha256.Sum256([]byte(binary))
+	tests := []struct {
+		name    string
+		wantSum string
+		want    string
+		wantErr string
+	}{
+		{name: "checksum matches", wantSum: hex.EncodeToString(sum[:]), want: binary},
+		{name: "checksum mismatch keeps the binary", wantSum: "0000", want: "old binary", wantErr: "checksum mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			exe := filepath.Join(dir, "pr-kind-labeler")
+			if err := os.WriteFile(exe, []byte("old binary"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			err := downloadAsset(context.Background(), newAssetClient(binary), 1, exe, tt.wantSum)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			got, err := os.ReadFile(exe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("executable holds %q, want %q", got, tt.want)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("expected no temporary files to be left behind, got %v", entries)
+			}
+		})
+	}
+}
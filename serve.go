@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/server"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/transport"
+)
+
+// apiTokenEnv holds the bearer token clients of the server's merge gating
+// API present.
+const apiTokenEnv = "PR_KIND_LABELER_API_TOKEN"
+
+func newServeCmd() *cobra.Command {
+	var (
+		configPath      string
+		listenAddr      string
+		shutdownTimeout time.Duration
+		tenantRPS       float64
+		tenantBurst     int
+		configTTL       time.Duration
+		writeRPS        float64
+		writeBurst      int
+		teamCacheTTL    time.Duration
+		debounce        time.Duration
+		reportInterval  time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run as a webhook server instead of a GitHub Action",
+		Long: `Serve GitHub webhooks and process pull_request events as the GitHub Action does.
+
+The API token is read from GITHUB_TOKEN, which may hold a comma-separated
+list of tokens to fail over between, and the webhook secret from
+WEBHOOK_SECRET. Possible credentials found in PR bodies are reported to the
+incoming webhook in ` + secretNotifyEnv + `, if set, and repositories
+selecting the slack reporter are reported to the Slack webhook in
+` + slackWebhookEnv + `.
+
+Repositories can override the config with their own
+.github/pr-kind-labeler.yaml; overrides are cached per GitHub App installation
+(or repository owner) and each tenant is rate limited separately. On SIGTERM or SIGINT the server stops accepting connections
+and drains in-flight webhook processing before exiting.
+
+With --debounce, each PR is processed at most once per window: edits arriving
+in quick succession are coalesced and only the latest is processed. The
+config's commentIntervalMinutes limits how often new comments are posted.
+
+Merge automation, such as Tide, can ask whether a PR may be merged per the
+label policy at GET /mergeable/OWNER/REPO/NUMBER, which answers with a JSON
+verdict. It is only served when ` + apiTokenEnv + ` is set, to clients
+presenting it as a bearer token.
+
+Metrics, such as how long PRs take to pass validation after first failing it,
+are served as JSON at /debug/vars, to the same clients as /mergeable.`,
+		Example: `  # Serve with defaults on :8080
+  GITHUB_TOKEN=... WEBHOOK_SECRET=... pr-kind-labeler serve
+
+  # Serve with a mounted config file, as in a Kubernetes Deployment
+  pr-kind-labeler serve --config /etc/pr-kind-labeler/config.yaml --listen-addr :9443`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := server.LoadConfig(configPath)
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			// a limiter that can never grant a run fails every wait, which
+			// would drop every event
+			if tenantRPS <= 0 {
+				return &labeler.ConfigError{Err: fmt.Errorf("--tenant-rps must be positive")}
+			}
+			if tenantBurst < 1 {
+				return &labeler.ConfigError{Err: fmt.Errorf("--tenant-burst must be at least 1")}
+			}
+			if writeRPS > 0 && writeBurst < 1 {
+				return &labeler.ConfigError{Err: fmt.Errorf("--write-burst must be at least 1 when --write-rps is set")}
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			secret := os.Getenv("WEBHOOK_SECRET")
+			if secret == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("WEBHOOK_SECRET is not set")}
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, os.Interrupt)
+			defer stop()
+			httpClient := &http.Client{Transport: transport.NewWriteLimiter(http.DefaultTransport, writeRPS, writeBurst)}
+			srv := server.New(cfg, newGitHubClient(token, httpClient.Transport), []byte(secret)).
+				WithTenantLimits(tenantRPS, tenantBurst).
+				WithConfigTTL(configTTL).
+				WithTeamCacheTTL(teamCacheTTL).
+				WithSecretNotifier(secretNotifier("")).
+				WithAPIToken(os.Getenv(apiTokenEnv)).
+				WithDebounce(debounce)
+			if slack := slackReporter("", reportInterval); slack != nil {
+				srv.WithReporter(slack)
+			}
+			return srv.Run(ctx, listenAddr, shutdownTimeout)
+		},
+	}
+	cmd.Flags().StringVar(&configPath, "config", "", "path to the server config file")
+	cmd.Flags().StringVar(&listenAddr, "listen-addr", ":8080", "address to serve webhooks on")
+	cmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "how long to wait for in-flight webhooks on shutdown")
+	cmd.Flags().Float64Var(&tenantRPS, "tenant-rps", 1, "processing runs per second allowed per installation or repository owner")
+	cmd.Flags().IntVar(&tenantBurst, "tenant-burst", 10, "processing runs a tenant may burst above --tenant-rps")
+	cmd.Flags().DurationVar(&configTTL, "config-ttl", 5*time.Minute, "how long per-repository "+server.RepoConfigPath+" overrides are cached")
+	cmd.Flags().Float64Var(&writeRPS, "write-rps", 1, "GitHub write requests per second across all tenants, to avoid secondary rate limits (0 disables)")
+	cmd.Flags().IntVar(&writeBurst, "write-burst", 5, "GitHub write requests allowed to burst above --write-rps")
+	cmd.Flags().DurationVar(&teamCacheTTL, "team-cache-ttl", 10*time.Minute, "how long team membership used by team policies and restricted commands is cached")
+	cmd.Flags().DurationVar(&debounce, "debounce", 0, "process each PR at most once per this window, e.g. 30s, coalescing its events (0 disables)")
+	cmd.Flags().DurationVar(&reportInterval, "report-interval", 0, "report each PR to Slack at most once per this interval unless it starts or stops passing validation, e.g. 1h (0 reports every change)")
+	cmd.MarkFlagFilename("config", "yaml", "yml")
+	return cmd
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/setup"
+)
+
+func newSetupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "Check how repositories are set up for the labeler",
+	}
+	cmd.AddCommand(newSetupVerifyCmd())
+	return cmd
+}
+
+func newSetupVerifyCmd() *cobra.Command {
+	var (
+		output string
+		opts   setup.Options
+	)
+	cmd := &cobra.Command{
+		Use:   "verify owner/repo...",
+		Short: "Check that branch protection and the merge bot enforce the labeler's verdict",
+		Long: `Check, for each repository, that the labeler's verdict actually gates merging:
+branch protection or a ruleset on the target branch must require the
+labeler's check, and a Mergify or Kodiak config, if any, must refuse to merge
+PRs with the do-not-merge/* labels the labeler applies. Misconfigurations are
+reported and fail the command. Reads the API token from GITHUB_TOKEN, which
+needs to read branch protection.`,
+		Example: `  # Verify a repository where the labeler reports a check run
+  pr-kind-labeler setup verify kgateway-dev/kgateway
+
+  # The labeler runs as a workflow job named "label" instead
+  pr-kind-labeler setup verify kgateway-dev/kgateway --check label`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "json" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --output %q, expected table or json", output)}
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			client := newGitHubClient(token, nil)
+
+			findings := []setup.Finding{}
+			for _, arg := range args {
+				owner, repo, err := parseRepoRef(arg)
+				if err != nil {
+					return &labeler.ConfigError{Err: err}
+				}
+				found, err := setup.Verify(cmd.Context(), client, owner, repo, opts)
+				if err != nil {
+					return fmt.Errorf("%s: %w", arg, err)
+				}
+				findings = append(findings, found...)
+			}
+
+			var problems int
+			for _, f := range findings {
+				if !f.OK {
+					problems++
+				}
+			}
+			out := cmd.OutOrStdout()
+			if output == "json" {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(findings); err != nil {
+					return err
+				}
+			} else {
+				tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(tw, "REPOSITORY\tCHECK\tSTATUS\tMESSAGE")
+				for _, f := range findings {
+					status := "ok"
+					if !f.OK {
+						status = "problem"
+					}
+					fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Repository, f.Check, status, f.Message)
+				}
+				if err := tw.Flush(); err != nil {
+					return err
+				}
+			}
+			if problems > 0 {
+				return &labeler.ConfigError{Err: fmt.Errorf("found %d misconfiguration(s)", problems)}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table or json")
+	cmd.Flags().StringVar(&opts.Branch, "branch", "", "branch PRs merge into (default the repository's default branch)")
+	cmd.Flags().StringSliceVar(&opts.Checks, "check", []string{labeler.CheckRunName}, "comma-separated names of the check runs or statuses reporting the labeler's verdict, any of which must be required")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
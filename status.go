@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/server"
+)
+
+func newStatusCmd() *cobra.Command {
+	var (
+		configPath string
+		output     string
+	)
+	cmd := &cobra.Command{
+		Use:   "status owner/repo/PR",
+		Short: "Explain whether a PR is blocked and how to unblock it",
+		Long: `Evaluate a PR as it is now, without changing anything, and print whether it
+is blocked, the problems with its description, the labels the labeler
+manages on it and the exact steps to unblock it, for contributors who would
+rather not dig through workflow logs. The label policy of the server config
+applies, with the repository's own config on top. Reads the API token from
+GITHUB_TOKEN.`,
+		Example: `  # Am I blocked?
+  pr-kind-labeler status kgateway-dev/kgateway/1234`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --output %q, expected text or json", output)}
+			}
+			owner, repo, prNum, err := parsePRRef(args[0])
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			cfg, err := server.LoadConfig(configPath)
+			if err != nil {
+				return &labeler.ConfigError{Err: err}
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+
+			s, err := server.New(cfg, newGitHubClient(token, nil), nil).Status(cmd.Context(), owner, repo, prNum)
+			if err != nil {
+				return &labeler.OperationalError{Err: err}
+			}
+			out := cmd.OutOrStdout()
+			if output == "json" {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(s)
+			}
+			fmt.Fprint(out, formatStatus(fmt.Sprintf("%s/%s#%d", owner, repo, prNum), s))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&configPath, "config", "", "path to the server config file whose label policy applies")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "output format: text or json")
+	cmd.MarkFlagFilename("config", "yaml", "yml")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// formatStatus renders the status of the PR named ref for a terminal.
+func formatStatus(ref string, s *labeler.PRStatus) string {
+	var sb strings.Builder
+	if s.Mergeable {
+		fmt.Fprintf(&sb, "%s is not blocked by pr-kind-labeler.\n", ref)
+	} else {
+		fmt.Fprintf(&sb, "%s is blocked.\n", ref)
+	}
+	if len(s.Problems) > 0 {
+		sb.WriteString("\nProblems with the PR description:\n")
+		for _, p := range s.Problems {
+			sb.WriteString("  - " + strings.ReplaceAll(p, "\n", "\n    ") + "\n")
+		}
+	}
+	managed := "none"
+	if len(s.ManagedLabels) > 0 {
+		managed = strings.Join(s.ManagedLabels, ", ")
+	}
+	fmt.Fprintf(&sb, "\nLabels managed by pr-kind-labeler: %s\n", managed)
+	if len(s.PendingAdd)+len(s.PendingRemove) > 0 {
+		var changes []string
+		for _, label := range s.PendingAdd {
+			changes = append(changes, "+"+label)
+		}
+		for _, label := range s.PendingRemove {
+			changes = append(changes, "-"+label)
+		}
+		fmt.Fprintf(&sb, "Pending label changes: %s\n", strings.Join(changes, " "))
+	}
+	if len(s.Steps) > 0 {
+		sb.WriteString("\nTo unblock the PR:\n")
+		for i, step := range s.Steps {
+			fmt.Fprintf(&sb, "  %d. %s\n", i+1, step)
+		}
+	}
+	return sb.String()
+}
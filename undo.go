@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/undo"
+)
+
+func newUndoCmd() *cobra.Command {
+	var (
+		since string
+		apply bool
+	)
+	cmd := &cobra.Command{
+		Use:   "undo owner/repo/PR...",
+		Short: "Restore the labels PRs had before the labeler changed them",
+		Long: `Restore PR labels from the snapshot the labeler records in its check run
+whenever it changes labels, e.g. after a bad config rollout mislabeled PRs.
+Snapshots are only recorded with --check-run, and the check runs on every
+commit of the PR are considered.
+
+By default the latest run that changed labels is undone. With --since, every
+run since then is undone. Only labels the undone runs changed are restored,
+so labels people added or removed by hand are kept. Nothing is changed on
+GitHub unless --apply is set. Reads the API token from GITHUB_TOKEN.`,
+		Example: `  # Preview undoing the labeler's latest change to a PR
+  pr-kind-labeler undo kgateway-dev/kgateway/1234
+
+  # Undo everything the labeler did to two PRs since a rollout
+  pr-kind-labeler undo kgateway-dev/kgateway/1234 kgateway-dev/kgateway/1240 --since 2026-10-14T09:00:00Z --apply`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			opts := undo.Options{DryRun: !apply}
+			if since != "" {
+				t, err := time.Parse(time.RFC3339, since)
+				if err != nil {
+					return &labeler.ConfigError{Err: fmt.Errorf("invalid --since: %w", err)}
+				}
+				opts.Since = t
+			}
+
+			client := newGitHubClient(token, nil)
+			out := cmd.OutOrStdout()
+			var failed int
+			for _, arg := range args {
+				owner, repo, prNum, err := parsePRRef(arg)
+				if err != nil {
+					return &labeler.ConfigError{Err: err}
+				}
+				result, err := undo.Run(cmd.Context(), client, owner, repo, prNum, opts)
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", arg, err)
+					failed++
+					continue
+				}
+				switch {
+				case result.Snapshot == nil:
+					fmt.Fprintf(out, "%s: no label changes to undo\n", arg)
+				case len(result.Plan.AddLabels)+len(result.Plan.RemoveLabels) == 0:
+					fmt.Fprintf(out, "%s: labels already match the snapshot of %s\n", arg, result.Snapshot.TakenAt.Format(time.RFC3339))
+				default:
+					fmt.Fprintf(out, "%s: undid %d run(s) back to %s:%s\n", arg, result.Runs, result.Snapshot.TakenAt.Format(time.RFC3339), labelChanges(result.Plan.AddLabels, result.Plan.RemoveLabels))
+				}
+			}
+			if !apply {
+				fmt.Fprintln(out, "dry run: nothing was changed, set --apply to undo")
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to undo %d of %d PRs", failed, len(args))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "undo every run that changed labels at or after this RFC 3339 time, instead of the latest one")
+	cmd.Flags().BoolVar(&apply, "apply", false, "restore the labels instead of only reporting what would change")
+	return cmd
+}
+
+// labelChanges renders labels to add and remove as " +a -b".
+func labelChanges(add, remove []string) string {
+	var sb strings.Builder
+	for _, label := range add {
+		sb.WriteString(" +" + label)
+	}
+	for _, label := range remove {
+		sb.WriteString(" -" + label)
+	}
+	return sb.String()
+}
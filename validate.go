@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/event"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/teams"
+	"github.com/kgateway-dev/pr-kind-labeler/pkg/ghaction"
+)
+
+// validation is the report of the validate command.
+type validation struct {
+	PR    string `json:"pr"`
+	Valid bool   `json:"valid"`
+	// Problems are the validation failures of the PR description.
+	Problems []labeler.Problem `json:"problems,omitempty"`
+	// LabelsToAdd and LabelsToRemove are the label changes a labeling run
+	// would make.
+	LabelsToAdd    []string             `json:"labelsToAdd"`
+	LabelsToRemove []string             `json:"labelsToRemove"`
+	ReleaseNote    *labeler.ReleaseNote `json:"releaseNote,omitempty"`
+	// DeadLinks are the URLs of the release note that do not resolve, with
+	// --check-links.
+	DeadLinks []labeler.DeadLink `json:"deadLinks,omitempty"`
+}
+
+func newValidateCmd() *cobra.Command {
+	var (
+		lf                              labelerFlags
+		eventPath                       string
+		output                          string
+		enforceDescription              bool
+		enforceReleaseNoteQuality       bool
+		enforceChangelogKindExclusivity bool
+	)
+	cmd := &cobra.Command{
+		Use:   "validate [owner/repo/PR]",
+		Short: "Validate a PR without changing its labels, for CI gating",
+		Long: `Run the labeler's parsing and validation on a PR and report the problems and
+the label changes a labeling run would make, without adding or removing
+labels, commenting or reporting checks. Only read access is needed, so it
+works on pull_request events of fork PRs, whose token cannot write. Without
+an argument, the PR is read from the GitHub Actions event. The exit code is
+0 for a valid PR and that of the failed validation otherwise. Takes the
+check flags of the labeling run, so it reaches the same verdict. Reads the
+API token from GITHUB_TOKEN.`,
+		Example: `  # Gate a workflow job on the PR of the current event
+  GITHUB_TOKEN=${{ github.token }} pr-kind-labeler validate
+
+  # Validate a PR and print the report for tooling to parse
+  pr-kind-labeler validate kgateway-dev/kgateway/1234 --output json`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return &labeler.ConfigError{Err: fmt.Errorf("invalid --output %q, expected text or json", output)}
+			}
+			opts, err := lf.options()
+			if err != nil {
+				return err
+			}
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			client := newGitHubClient(token, nil)
+			ctx := cmd.Context()
+
+			var (
+				owner, repo, body string
+				prNum             int
+			)
+			if len(args) == 1 {
+				owner, repo, prNum, err = parsePRRef(args[0])
+				if err != nil {
+					return &labeler.ConfigError{Err: err}
+				}
+				pr, _, err := client.PullRequests.Get(ctx, owner, repo, prNum)
+				if err != nil {
+					return &labeler.OperationalError{Err: fmt.Errorf("failed to get PR: %w", err)}
+				}
+				body = pr.GetBody()
+				opts.AuthorLogin, opts.AuthorAssociation = pr.GetUser().GetLogin(), pr.GetAuthorAssociation()
+			} else {
+				e, err := readEvent(ctx, client, ghaction.New(), eventPath, false)
+				if err != nil {
+					return err
+				}
+				if e == nil || e.Action == event.Commented && labeler.IsHelpCommand(e.Comment.Body) {
+					fmt.Fprintln(cmd.ErrOrStderr(), "Event is not a PR change or command, nothing to do")
+					return nil
+				}
+				owner, repo, prNum, body = e.Owner, e.Repo, e.Number, e.Body
+				opts.AuthorLogin, opts.AuthorAssociation = e.Author, e.AuthorAssociation
+			}
+			repoConfig, err := loadRepoConfig(ctx, client, owner, repo)
+			if err != nil {
+				return err
+			}
+
+			opts.EnforceDescription = enforceDescription
+			opts.EnforceReleaseNoteQuality = enforceReleaseNoteQuality
+			opts.EnforceChangelogKindExclusivity = enforceChangelogKindExclusivity
+			opts.RepoConfig = repoConfig
+			opts.TeamResolver = teams.NewResolver(client, time.Hour)
+			l := labeler.NewFromOptions(client, owner, repo, prNum, opts)
+			_, err = l.Plan(ctx, body)
+			problems, operational := labeler.Partition(err)
+			if len(operational) > 0 {
+				return errors.Join(operational...)
+			}
+			d := l.Decision()
+			v := &validation{
+				PR:             fmt.Sprintf("%s/%s#%d", owner, repo, prNum),
+				Valid:          len(problems) == 0,
+				LabelsToAdd:    d.LabelsToAdd,
+				LabelsToRemove: d.LabelsToRemove,
+				ReleaseNote:    d.ReleaseNote,
+				DeadLinks:      d.DeadLinks,
+			}
+			for _, p := range problems {
+				var ve *labeler.ValidationError
+				errors.As(p, &ve)
+				v.Problems = append(v.Problems, labeler.Problem{Check: ve.Check, Message: ve.Error()})
+			}
+
+			out := cmd.OutOrStdout()
+			if output == "json" {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if encErr := enc.Encode(v); encErr != nil {
+					return encErr
+				}
+				return err
+			}
+			fmt.Fprint(out, formatValidation(v))
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&eventPath, "event", "", "without a PR argument, read the event payload from this file instead of GITHUB_EVENT_PATH, or from stdin if -")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "output format: text or json")
+	cmd.Flags().BoolVar(&enforceDescription, "enforce-description", true, "enforce that the Description section is filled out")
+	cmd.Flags().BoolVar(&enforceReleaseNoteQuality, "enforce-release-note-quality", false, "enforce naive publication-ready release note checks")
+	cmd.Flags().BoolVar(&enforceChangelogKindExclusivity, "enforce-changelog-kind-exclusivity", false, "enforce at most one changelog kind per PR")
+	lf.register(cmd)
+	cmd.MarkFlagFilename("event", "json")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// formatValidation renders v for a terminal. The problems are not listed, as
+// they are printed with the exit code.
+func formatValidation(v *validation) string {
+	var sb strings.Builder
+	if v.Valid {
+		fmt.Fprintf(&sb, "%s is valid.\n", v.PR)
+	} else {
+		fmt.Fprintf(&sb, "%s failed validation.\n", v.PR)
+	}
+	if len(v.LabelsToAdd)+len(v.LabelsToRemove) > 0 {
+		var changes []string
+		for _, label := range v.LabelsToAdd {
+			changes = append(changes, "+"+label)
+		}
+		for _, label := range v.LabelsToRemove {
+			changes = append(changes, "-"+label)
+		}
+		fmt.Fprintf(&sb, "Label changes a labeling run would make: %s\n", strings.Join(changes, " "))
+	}
+	for _, d := range v.DeadLinks {
+		fmt.Fprintf(&sb, "Warning: the release note links to %s, which does not resolve (%s).\n", d.URL, d.Reason)
+	}
+	return sb.String()
+}
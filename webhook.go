@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kgateway-dev/pr-kind-labeler/internal/labeler"
+	"github.com/kgateway-dev/pr-kind-labeler/internal/webhook"
+)
+
+func newWebhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Manage the server-mode webhook on repositories and organizations",
+	}
+	cmd.AddCommand(newWebhookInstallCmd())
+	return cmd
+}
+
+func newWebhookInstallCmd() *cobra.Command {
+	var (
+		url    string
+		events []string
+	)
+	cmd := &cobra.Command{
+		Use:   "install owner/repo|org...",
+		Short: "Register or update the server-mode webhook",
+		Long: `Register the server-mode webhook on each repository (owner/repo) or
+organization (owner), or update the existing webhook delivering to the same
+URL so its secret and events match. Reads the API token from GITHUB_TOKEN and
+the webhook secret from WEBHOOK_SECRET.`,
+		Example: `  # Register on two repositories
+  pr-kind-labeler webhook install --url https://labeler.example.com/webhook kgateway-dev/kgateway kgateway-dev/docs
+
+  # Register once for the whole organization
+  pr-kind-labeler webhook install --url https://labeler.example.com/webhook kgateway-dev`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := os.Getenv("GITHUB_TOKEN")
+			if token == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("GITHUB_TOKEN is not set")}
+			}
+			secret := os.Getenv("WEBHOOK_SECRET")
+			if secret == "" {
+				return &labeler.ConfigError{Err: fmt.Errorf("WEBHOOK_SECRET is not set")}
+			}
+			var targets []webhook.Target
+			for _, arg := range args {
+				target, err := webhook.ParseTarget(arg)
+				if err != nil {
+					return err
+				}
+				targets = append(targets, target)
+			}
+
+			client := newGitHubClient(token, nil)
+			spec := webhook.Spec{URL: url, Secret: secret, Events: events}
+			var failed int
+			for _, target := range targets {
+				result, err := webhook.Ensure(cmd.Context(), client, target, spec)
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", target, err)
+					failed++
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", target, result)
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to install the webhook on %d of %d targets", failed, len(targets))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&url, "url", "", "server-mode webhook URL, e.g. https://labeler.example.com/webhook")
+	cmd.Flags().StringSliceVar(&events, "events", webhook.DefaultEvents, "webhook events to subscribe to")
+	cmd.MarkFlagRequired("url")
+	return cmd
+}